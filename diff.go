@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/api"
+	"gwapi-graph/internal/diff"
+	"gwapi-graph/internal/k8s"
+	"gwapi-graph/internal/snapshot"
+	"gwapi-graph/internal/source"
+	"gwapi-graph/internal/types"
+)
+
+// errDifferences is returned by runDiff with -exit-code when the graphs differ
+var errDifferences = errors.New("graphs differ")
+
+// runDiff implements `gwapi-graph diff`: it compares the graph at two points, each being the
+// live cluster, a snapshot or a manifest directory
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	from := fs.String("from", "", "old state: live, snapshot:<id>, an RFC 3339 time, or a manifest directory (- for stdin)")
+	to := fs.String("to", "live", "new state, in the same forms as -from")
+	snapshotDir := fs.String("snapshot-dir", "", "snapshot directory used to resolve snapshot:<id> and time references")
+	format := fs.String("format", "markdown", "output format: markdown or json")
+	output := fs.String("o", "", "output file (default stdout)")
+	exitCode := fs.Bool("exit-code", false, "exit with status 1 when the graphs differ")
+	graphOpts := addGraphFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s diff -from <ref> [-to <ref>] [flags]\n\nCompare the Gateway API graph at two points.\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *from == "" {
+		fs.Usage()
+		return errors.New("-from is required")
+	}
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	opts, policyKinds, err := graphOpts.options()
+	if err != nil {
+		return err
+	}
+
+	resolver := &refResolver{policyKinds: policyKinds}
+	if *snapshotDir != "" {
+		store, err := snapshot.NewStore(*snapshotDir, snapshot.Retention{})
+		if err != nil {
+			return err
+		}
+		resolver.snapshots = store
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	fromResources, err := resolver.resolve(ctx, *from)
+	if err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	toResources, err := resolver.resolve(ctx, *to)
+	if err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+
+	result := api.NewHandler(resolver.k8sClient, opts...).Diff(fromResources, toResources, *from, *to)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(result)
+	} else {
+		err = diff.WriteMarkdown(w, result)
+	}
+	if err != nil {
+		return err
+	}
+
+	s := result.Summary
+	if *exitCode && s.AddedNodes+s.RemovedNodes+s.ChangedNodes+s.AddedLinks+s.RemovedLinks > 0 {
+		return errDifferences
+	}
+	return nil
+}
+
+// refResolver loads the resources a diff reference points to, creating the cluster client only
+// when a reference needs it
+type refResolver struct {
+	k8sClient   *k8s.Client
+	snapshots   *snapshot.Store
+	policyKinds []types.PolicyKind // Policy kinds to list from the cluster besides the labeled ones
+}
+
+// resolve loads the resources for a reference
+func (r *refResolver) resolve(ctx context.Context, ref string) (*types.ResourceCollection, error) {
+	if ref == "live" {
+		if r.k8sClient == nil {
+			client, err := k8s.NewClient()
+			if err != nil {
+				return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+			}
+			r.k8sClient = client
+		}
+		return source.NewCluster(r.k8sClient, r.policyKinds...).Fetch(ctx)
+	}
+
+	id, isSnapshot := strings.CutPrefix(ref, "snapshot:")
+	t, timeErr := time.Parse(time.RFC3339, ref)
+	if isSnapshot || timeErr == nil {
+		if r.snapshots == nil {
+			return nil, errors.New("-snapshot-dir is required for snapshot references")
+		}
+		var resources *types.ResourceCollection
+		var err error
+		if isSnapshot {
+			resources, _, err = r.snapshots.Load(id)
+		} else {
+			resources, _, err = r.snapshots.At(t)
+		}
+		return resources, err
+	}
+
+	src, err := manifestSource(ref)
+	if err != nil {
+		return nil, err
+	}
+	return src.Fetch(ctx)
+}
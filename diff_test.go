@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/diff"
+	"gwapi-graph/internal/snapshot"
+	"gwapi-graph/internal/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewayManifests has a single Gateway
+const gatewayManifests = `apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw
+  namespace: infra
+spec:
+  gatewayClassName: missing
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+`
+
+// writeManifests writes a manifest file into a new directory and returns the directory
+func writeManifests(t *testing.T, manifests string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gateway.yaml"), []byte(manifests), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRefResolver(t *testing.T) {
+	store, err := snapshot.NewStore(t.TempDir(), snapshot.Retention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	info, err := store.Save(&types.ResourceCollection{Gateways: []gatewayv1.Gateway{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "snapshotted"},
+	}}}, saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := writeManifests(t, gatewayManifests)
+
+	tests := []struct {
+		name      string
+		ref       string
+		snapshots *snapshot.Store
+		gateway   string
+		wantErr   bool
+	}{
+		{name: "snapshot ID", ref: "snapshot:" + info.ID, snapshots: store, gateway: "snapshotted"},
+		{name: "time after the snapshot", ref: "2024-01-01T13:00:00Z", snapshots: store, gateway: "snapshotted"},
+		{name: "time before any snapshot", ref: "2024-01-01T11:00:00Z", snapshots: store, wantErr: true},
+		{name: "unknown snapshot", ref: "snapshot:20240101T000000.000Z", snapshots: store, wantErr: true},
+		{name: "snapshot without -snapshot-dir", ref: "snapshot:" + info.ID, wantErr: true},
+		{name: "time without -snapshot-dir", ref: "2024-01-01T13:00:00Z", wantErr: true},
+		{name: "manifest directory", ref: dir, gateway: "gw"},
+		// Anything that is neither live, a snapshot nor a time is taken for a directory
+		{name: "missing directory", ref: filepath.Join(dir, "missing"), snapshots: store, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &refResolver{snapshots: tt.snapshots}
+			resources, err := resolver.resolve(context.Background(), tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolve(%q) succeeded, want an error", tt.ref)
+				}
+				return
+			}
+			if err != nil || len(resources.Gateways) != 1 || resources.Gateways[0].Name != tt.gateway {
+				t.Errorf("resolve(%q) = %v, %v, want Gateway %s", tt.ref, resources, err, tt.gateway)
+			}
+			if resolver.k8sClient != nil {
+				t.Errorf("resolve(%q) created a cluster client", tt.ref)
+			}
+		})
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	from := writeManifests(t, gatewayManifests)
+	to := writeManifests(t, strings.Replace(gatewayManifests, "port: 80", "port: 8080", 1))
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+		changed int
+	}{
+		// The port shows on both the Gateway and its listener node
+		{name: "changed listener", args: []string{"-from", from, "-to", to}, changed: 2},
+		{name: "exit code on differences", args: []string{"-from", from, "-to", to, "-exit-code"}, wantErr: errDifferences, changed: 2},
+		{name: "exit code without differences", args: []string{"-from", from, "-to", from, "-exit-code"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "diff.json")
+			err := runDiff(append([]string{"-format", "json", "-o", output}, tt.args...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("runDiff = %v, want %v", err, tt.wantErr)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result diff.GraphDiff
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatalf("invalid JSON diff: %v", err)
+			}
+			if result.From != from || result.Summary.ChangedNodes != tt.changed {
+				t.Errorf("diff from %s with %d changed nodes, want from %s with %d", result.From, result.Summary.ChangedNodes, from, tt.changed)
+			}
+		})
+	}
+}
+
+func TestRunDiffErrors(t *testing.T) {
+	dir := writeManifests(t, gatewayManifests)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"missing -from", []string{"-to", dir}, "-from is required"},
+		{"unsupported format", []string{"-from", dir, "-to", dir, "-format", "html"}, "unsupported format"},
+		{"snapshot without -snapshot-dir", []string{"-from", "snapshot:20240101T000000.000Z", "-to", dir}, "-from: -snapshot-dir is required"},
+		{"missing -to directory", []string{"-from", dir, "-to", filepath.Join(dir, "missing")}, "-to: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runDiff(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("runDiff = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"sort"
+
+	"gwapi-graph/internal/types"
+)
+
+// Rule checks the resources for one kind of problem
+type Rule interface {
+	// Name identifies the rule in findings, e.g. backend-not-found
+	Name() string
+	// Check returns the problems found; the analyzer fills in the rule name
+	Check(resources *types.ResourceCollection) []types.Finding
+}
+
+// ruleFunc adapts a function to the Rule interface
+type ruleFunc struct {
+	name  string
+	check func(resources *types.ResourceCollection) []types.Finding
+}
+
+func (r ruleFunc) Name() string {
+	return r.name
+}
+
+func (r ruleFunc) Check(resources *types.ResourceCollection) []types.Finding {
+	return r.check(resources)
+}
+
+// NewRule creates a rule from a check function
+func NewRule(name string, check func(resources *types.ResourceCollection) []types.Finding) Rule {
+	return ruleFunc{name: name, check: check}
+}
+
+// DefaultRules returns the built-in rules
+func DefaultRules() []Rule {
+	return []Rule{
+		NewRule("route-not-accepted", checkRouteAttachment),
+		NewRule("backend-not-found", checkBackendRefs),
+		NewRule("unused-referencegrant", checkUnusedReferenceGrants),
+		NewRule("unknown-gatewayclass", checkGatewayClasses),
+		NewRule("listener-conflict", checkListenerConflicts),
+		NewRule("wildcard-shadowing", checkWildcardShadowing),
+		NewRule("route-shadowed", checkRouteShadowing),
+		NewRule("hostname-outside-zones", checkHostedZones),
+		NewRule("dnsrecord-not-published", checkDNSRecordPublication),
+		NewRule("dnsrecord-stale-target", checkDNSRecordTargets),
+		NewRule("backendtls-ca-not-found", checkBackendTLSPolicies),
+	}
+}
+
+// Analyzer runs a set of rules over a ResourceCollection
+type Analyzer struct {
+	rules []Rule
+}
+
+// New creates an analyzer running the given rules
+func New(rules ...Rule) *Analyzer {
+	return &Analyzer{rules: rules}
+}
+
+// Rules returns the rules the analyzer runs
+func (a *Analyzer) Rules() []Rule {
+	return a.rules
+}
+
+// Run checks the resources against every rule and returns the findings, most serious first
+func (a *Analyzer) Run(resources *types.ResourceCollection) []types.Finding {
+	findings := []types.Finding{}
+	for _, rule := range a.rules {
+		for _, finding := range rule.Check(resources) {
+			if finding.Rule == "" {
+				finding.Rule = rule.Name()
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity.Rank() != b.Severity.Rank() {
+			return a.Severity.Rank() > b.Severity.Rank()
+		}
+		if a.Resource.Kind != b.Resource.Kind {
+			return a.Resource.Kind < b.Resource.Kind
+		}
+		if a.Resource.Namespace != b.Resource.Namespace {
+			return a.Resource.Namespace < b.Resource.Namespace
+		}
+		return a.Resource.Name < b.Resource.Name
+	})
+	return findings
+}
+
+// Attach adds each finding to the graph node it refers to
+func Attach(graph *types.Graph, findings []types.Finding) {
+	index := make(map[string]int)
+	for i, node := range graph.Nodes {
+		index[node.ID] = i
+	}
+
+	for _, finding := range findings {
+		if i, ok := index[finding.NodeID]; ok {
+			graph.Nodes[i].Findings = append(graph.Nodes[i].Findings, finding)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"fmt"
+
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ListenerID returns the graph node ID of a Gateway listener
+func ListenerID(gw *gatewayv1.Gateway, index int) string {
+	return fmt.Sprintf("%s-listener-%d", gw.UID, index)
+}
+
+// ParentGateway returns the Gateway a route's parentRef points to, or nil when the parent is not
+// a Gateway or does not exist
+func ParentGateway(resources *types.ResourceCollection, routeNamespace string, ref gatewayv1.ParentReference) *gatewayv1.Gateway {
+	if !isGatewayRef(ref) {
+		return nil
+	}
+
+	namespace := routeNamespace
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		if gw.Namespace == namespace && gw.Name == string(ref.Name) {
+			return gw
+		}
+	}
+	return nil
+}
+
+// isGatewayRef reports whether a parentRef points to a Gateway (the default kind)
+func isGatewayRef(ref gatewayv1.ParentReference) bool {
+	if ref.Group != nil && string(*ref.Group) != gatewayv1.GroupName {
+		return false
+	}
+	return ref.Kind == nil || string(*ref.Kind) == "Gateway"
+}
+
+// AttachedListeners returns the indexes of the listeners of gw that an HTTPRoute can attach to
+// through ref, following the Gateway API attachment rules: sectionName and port, allowed route
+// kinds and namespaces, and hostname intersection. When no listener accepts the route, the
+// reason of the last rejection is returned. Namespace selectors cannot be evaluated without the
+// namespace labels and are assumed to match.
+func AttachedListeners(gw *gatewayv1.Gateway, route *gatewayv1.HTTPRoute, ref gatewayv1.ParentReference) ([]int, string) {
+	var attached []int
+	reason := "the Gateway has no listeners"
+
+	for i, listener := range gw.Spec.Listeners {
+		if ref.SectionName != nil && *ref.SectionName != listener.Name {
+			reason = fmt.Sprintf("no listener named %q", *ref.SectionName)
+			continue
+		}
+		if ref.Port != nil && *ref.Port != listener.Port {
+			reason = fmt.Sprintf("no listener on port %d", *ref.Port)
+			continue
+		}
+		if !allowsHTTPRoute(listener) {
+			reason = fmt.Sprintf("listener %q does not allow HTTPRoutes", listener.Name)
+			continue
+		}
+		if !allowsNamespace(gw, listener, route.Namespace) {
+			reason = fmt.Sprintf("listener %q does not allow routes from namespace %s", listener.Name, route.Namespace)
+			continue
+		}
+		if listener.Hostname != nil && len(route.Spec.Hostnames) > 0 && !anyHostnameIntersects(string(*listener.Hostname), route.Spec.Hostnames) {
+			reason = fmt.Sprintf("no route hostname matches listener %q hostname %s", listener.Name, *listener.Hostname)
+			continue
+		}
+		attached = append(attached, i)
+	}
+
+	if len(attached) > 0 {
+		return attached, ""
+	}
+	return nil, reason
+}
+
+// allowsHTTPRoute reports whether a listener accepts HTTPRoutes. Without an explicit kinds list,
+// HTTP and HTTPS listeners accept them.
+func allowsHTTPRoute(listener gatewayv1.Listener) bool {
+	if listener.AllowedRoutes != nil && len(listener.AllowedRoutes.Kinds) > 0 {
+		for _, kind := range listener.AllowedRoutes.Kinds {
+			group := gatewayv1.GroupName
+			if kind.Group != nil {
+				group = string(*kind.Group)
+			}
+			if group == gatewayv1.GroupName && kind.Kind == "HTTPRoute" {
+				return true
+			}
+		}
+		return false
+	}
+	return listener.Protocol == gatewayv1.HTTPProtocolType || listener.Protocol == gatewayv1.HTTPSProtocolType
+}
+
+// allowsNamespace reports whether a listener accepts routes from a namespace. The default is
+// routes from the Gateway's own namespace.
+func allowsNamespace(gw *gatewayv1.Gateway, listener gatewayv1.Listener, namespace string) bool {
+	from := gatewayv1.NamespacesFromSame
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil && listener.AllowedRoutes.Namespaces.From != nil {
+		from = *listener.AllowedRoutes.Namespaces.From
+	}
+
+	switch from {
+	case gatewayv1.NamespacesFromAll, gatewayv1.NamespacesFromSelector:
+		return true
+	default:
+		return namespace == gw.Namespace
+	}
+}
+
+// anyHostnameIntersects reports whether any of the route hostnames intersects a listener hostname
+func anyHostnameIntersects(listenerHostname string, routeHostnames []gatewayv1.Hostname) bool {
+	for _, routeHostname := range routeHostnames {
+		if _, ok := hostname.Intersect(listenerHostname, string(routeHostname)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveHostnames returns the hostnames a route serves on a listener: the intersections of the
+// route hostnames with the listener hostname. It is [""] when neither restricts the hostname.
+func EffectiveHostnames(listener gatewayv1.Listener, route *gatewayv1.HTTPRoute) []string {
+	routeHostnames := make([]string, 0, len(route.Spec.Hostnames))
+	for _, routeHostname := range route.Spec.Hostnames {
+		routeHostnames = append(routeHostnames, string(routeHostname))
+	}
+	return hostname.IntersectAll(listenerHostname(listener), routeHostnames)
+}
+
+// routeAcceptance reads the Accepted conditions a controller wrote to a route's status. known is
+// false when the route has no parent status, e.g. when it was loaded from manifests.
+func routeAcceptance(route *gatewayv1.HTTPRoute) (known bool, accepted []gatewayv1.RouteParentStatus, rejected []gatewayv1.RouteParentStatus) {
+	for _, parent := range route.Status.Parents {
+		condition := findCondition(parent.Conditions, string(gatewayv1.RouteConditionAccepted))
+		if condition == nil {
+			continue
+		}
+		known = true
+		if condition.Status == metav1.ConditionTrue {
+			accepted = append(accepted, parent)
+		} else {
+			rejected = append(rejected, parent)
+		}
+	}
+	return known, accepted, rejected
+}
+
+// findCondition returns the condition of the given type, or nil
+func findCondition(conditions []metav1.Condition, conditionType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package analysis
+
+import (
+	"fmt"
+
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha3 "sigs.k8s.io/gateway-api/apis/v1alpha3"
+)
+
+// BackendTLSPolicies returns the BackendTLSPolicies among the collected policies. Objects that do
+// not match the v1alpha3 schema are skipped.
+func BackendTLSPolicies(resources *types.ResourceCollection) []gatewayv1alpha3.BackendTLSPolicy {
+	var policies []gatewayv1alpha3.BackendTLSPolicy
+	for i := range resources.Policies {
+		obj := &resources.Policies[i]
+		gvk := obj.GroupVersionKind()
+		if gvk.Group != gatewayv1.GroupName || gvk.Kind != "BackendTLSPolicy" {
+			continue
+		}
+		var policy gatewayv1alpha3.BackendTLSPolicy
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &policy); err != nil {
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+// CACertificateRefs returns the ConfigMaps and Secrets a BackendTLSPolicy names in
+// validation.caCertificateRefs, which are in the policy's namespace. References to other kinds
+// are left out.
+func CACertificateRefs(policy *gatewayv1alpha3.BackendTLSPolicy) []types.ResourceRef {
+	var refs []types.ResourceRef
+	for _, ref := range policy.Spec.Validation.CACertificateRefs {
+		if ref.Group != "" || (ref.Kind != "ConfigMap" && ref.Kind != "Secret") {
+			continue
+		}
+		refs = append(refs, types.ResourceRef{Kind: string(ref.Kind), Namespace: policy.Namespace, Name: string(ref.Name)})
+	}
+	return refs
+}
+
+// checkBackendTLSPolicies reports BackendTLSPolicies whose CA ConfigMaps or Secrets do not exist
+// or hold no readable certificate in ca.crt
+func checkBackendTLSPolicies(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+
+	policies := BackendTLSPolicies(resources)
+	for i := range policies {
+		policy := &policies[i]
+		for r, ref := range policy.Spec.Validation.CACertificateRefs {
+			if ref.Group != "" || (ref.Kind != "ConfigMap" && ref.Kind != "Secret") {
+				continue
+			}
+
+			location := fmt.Sprintf("validation.caCertificateRefs[%d]", r)
+			finding := types.Finding{
+				Severity: types.SeverityError,
+				Resource: types.ResourceRef{Kind: "BackendTLSPolicy", Namespace: policy.Namespace, Name: policy.Name},
+				NodeID:   string(policy.UID),
+			}
+
+			bundle := certs.Find(resources.Certificates, string(ref.Kind), policy.Namespace, string(ref.Name), certs.CACertKey)
+			switch {
+			case bundle == nil:
+				// Not read, e.g. for lack of permissions
+				continue
+			case bundle.Missing:
+				finding.Message = fmt.Sprintf("%s refers to %s %s/%s, which does not exist.", location, ref.Kind, policy.Namespace, ref.Name)
+				finding.Remediation = fmt.Sprintf("Create the %s with the CA certificates in its %s key, or correct the reference.", ref.Kind, certs.CACertKey)
+			case bundle.Error != "":
+				finding.Rule = "backendtls-ca-invalid"
+				finding.Message = fmt.Sprintf("%s holds no usable CA certificate: %s.", location, bundle.Error)
+				finding.Remediation = fmt.Sprintf("Store the PEM-encoded CA certificates in the %s key.", certs.CACertKey)
+			default:
+				continue
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// backendTLSPolicy returns the BackendTLSPolicy app/tls with CA certificate references given as
+// kind and name
+func backendTLSPolicy(refs ...[2]string) unstructured.Unstructured {
+	var caRefs []interface{}
+	for _, ref := range refs {
+		caRefs = append(caRefs, map[string]interface{}{"group": "", "kind": ref[0], "name": ref[1]})
+	}
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1alpha3",
+		"kind":       "BackendTLSPolicy",
+		"metadata":   map[string]interface{}{"namespace": "app", "name": "tls", "uid": "tls-uid"},
+		"spec": map[string]interface{}{
+			"targetRefs": []interface{}{map[string]interface{}{"group": "", "kind": "Service", "name": "web"}},
+			"validation": map[string]interface{}{"caCertificateRefs": caRefs, "hostname": "web.example.com"},
+		},
+	}}
+}
+
+func TestCheckBackendTLSPolicies(t *testing.T) {
+	valid := types.CertificateBundle{Kind: "ConfigMap", Namespace: "app", Name: "valid", Key: certs.CACertKey, Certificates: []types.Certificate{{Subject: "CN=ca"}}}
+
+	tests := []struct {
+		name    string
+		ref     [2]string
+		bundles []types.CertificateBundle
+		want    []string
+		rule    string
+	}{
+		{
+			name:    "readable CA",
+			ref:     [2]string{"ConfigMap", "valid"},
+			bundles: []types.CertificateBundle{valid},
+			want:    []string{},
+		},
+		{
+			name:    "missing ConfigMap",
+			ref:     [2]string{"ConfigMap", "missing"},
+			bundles: []types.CertificateBundle{certs.Missing("ConfigMap", "app", "missing", certs.CACertKey)},
+			want:    []string{"error BackendTLSPolicy app/tls: validation.caCertificateRefs[0] refers to ConfigMap app/missing, which does not exist."},
+		},
+		{
+			name:    "missing Secret",
+			ref:     [2]string{"Secret", "missing"},
+			bundles: []types.CertificateBundle{certs.Missing("Secret", "app", "missing", certs.CACertKey)},
+			want:    []string{"error BackendTLSPolicy app/tls: validation.caCertificateRefs[0] refers to Secret app/missing, which does not exist."},
+		},
+		{
+			name:    "unparsable CA",
+			ref:     [2]string{"Secret", "garbage"},
+			bundles: []types.CertificateBundle{{Kind: "Secret", Namespace: "app", Name: "garbage", Key: certs.CACertKey, Error: "no PEM certificate found"}},
+			want:    []string{"error BackendTLSPolicy app/tls: validation.caCertificateRefs[0] holds no usable CA certificate: no PEM certificate found."},
+			rule:    "backendtls-ca-invalid",
+		},
+		{
+			// Not read, e.g. because reading Secrets in the namespace is forbidden
+			name: "unknown",
+			ref:  [2]string{"Secret", "forbidden"},
+			want: []string{},
+		},
+		{
+			name: "other kinds are not checked",
+			ref:  [2]string{"Service", "ca"},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := &types.ResourceCollection{
+				Policies:     []unstructured.Unstructured{backendTLSPolicy(tt.ref)},
+				Certificates: tt.bundles,
+			}
+			findings := checkBackendTLSPolicies(resources)
+			if got := findingSummaries(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+			if len(findings) > 0 && findings[0].Rule != tt.rule {
+				t.Errorf("rule = %q, want %q", findings[0].Rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestCACertificateRefs(t *testing.T) {
+	policies := BackendTLSPolicies(&types.ResourceCollection{Policies: []unstructured.Unstructured{
+		backendTLSPolicy([2]string{"ConfigMap", "a"}, [2]string{"Service", "b"}, [2]string{"Secret", "c"}),
+	}})
+	if len(policies) != 1 {
+		t.Fatalf("BackendTLSPolicies returned %d policies, want 1", len(policies))
+	}
+	want := []types.ResourceRef{{Kind: "ConfigMap", Namespace: "app", Name: "a"}, {Kind: "Secret", Namespace: "app", Name: "c"}}
+	if got := CACertificateRefs(&policies[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("CACertificateRefs = %+v, want %+v", got, want)
+	}
+}
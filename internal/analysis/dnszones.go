@@ -0,0 +1,126 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"gwapi-graph/internal/dnszone"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// checkHostedZones reports hostnames of listeners, routes and DNSRecords outside every hosted zone
+// of the OpenShift cluster DNS config. The cluster cannot publish records for them. Clusters
+// without a DNS config are not checked.
+func checkHostedZones(resources *types.ResourceCollection) []types.Finding {
+	zones := dnszone.HostedZones(resources.DNSConfig)
+	if len(zones) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(zones))
+	for i, zone := range zones {
+		names[i] = zone.Name()
+	}
+	outside := func(host string) bool {
+		for _, zone := range zones {
+			if zone.Contains(host) {
+				return false
+			}
+		}
+		return true
+	}
+	remediation := fmt.Sprintf("Use a hostname within %s, or publish the records in a zone the cluster does not manage.", strings.Join(names, " or "))
+	for _, zone := range zones {
+		if zone.Inferred {
+			remediation += fmt.Sprintf(" The domain of the %s zone is inferred from the base domain, as the DNS config identifies the zone only by ID or tags; disregard this finding if the zone serves the hostname.", zone.Kind)
+		}
+	}
+
+	var findings []types.Finding
+	for g := range resources.Gateways {
+		gw := &resources.Gateways[g]
+		for i, listener := range gw.Spec.Listeners {
+			if listener.Hostname == nil || !outside(string(*listener.Hostname)) {
+				continue
+			}
+			findings = append(findings, types.Finding{
+				Severity:    types.SeverityWarning,
+				Resource:    gatewayRef(gw, string(listener.Name)),
+				NodeID:      ListenerID(gw, i),
+				Message:     fmt.Sprintf("Listener %q hostname %s is outside every hosted zone of the cluster (%s).", listener.Name, *listener.Hostname, strings.Join(names, ", ")),
+				Remediation: remediation,
+			})
+		}
+	}
+
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		for _, routeHostname := range route.Spec.Hostnames {
+			if !outside(string(routeHostname)) {
+				continue
+			}
+			findings = append(findings, types.Finding{
+				Severity:    types.SeverityWarning,
+				Resource:    routeRef(route),
+				NodeID:      string(route.UID),
+				Message:     fmt.Sprintf("Hostname %s is outside every hosted zone of the cluster (%s).", routeHostname, strings.Join(names, ", ")),
+				Remediation: remediation,
+			})
+		}
+	}
+
+	for i := range resources.DNSRecords {
+		record := &resources.DNSRecords[i]
+		dnsName := dnsRecordName(record)
+		if dnsName == "" || !outside(dnsName) {
+			continue
+		}
+		findings = append(findings, types.Finding{
+			Severity:    types.SeverityWarning,
+			Resource:    types.ResourceRef{Kind: "DNSRecord", Namespace: record.GetNamespace(), Name: record.GetName()},
+			NodeID:      string(record.GetUID()),
+			Message:     fmt.Sprintf("DNS name %s is outside every hosted zone of the cluster (%s).", dnsName, strings.Join(names, ", ")),
+			Remediation: remediation,
+		})
+	}
+
+	return findings
+}
+
+// checkDNSRecordPublication reports DNSRecords whose status.zones shows a zone that failed to
+// publish them
+func checkDNSRecordPublication(resources *types.ResourceCollection) []types.Finding {
+	zones := dnszone.HostedZones(resources.DNSConfig)
+
+	var findings []types.Finding
+	for i := range resources.DNSRecords {
+		record := &resources.DNSRecords[i]
+		for _, status := range dnszone.RecordStatuses(record, zones) {
+			if status.Status != dnszone.StatusFailed {
+				continue
+			}
+			message := fmt.Sprintf("DNS name %s failed to publish in %s.", dnsRecordName(record), status.Zone)
+			if status.Message != "" {
+				message = fmt.Sprintf("%s %s", message, status.Message)
+			}
+			findings = append(findings, types.Finding{
+				Severity:    types.SeverityError,
+				Resource:    types.ResourceRef{Kind: "DNSRecord", Namespace: record.GetNamespace(), Name: record.GetName()},
+				NodeID:      string(record.GetUID()),
+				Message:     message,
+				Remediation: "Check the ingress operator logs and the cloud provider credentials and quotas for the zone.",
+			})
+		}
+	}
+
+	return findings
+}
+
+// dnsRecordName returns the normalized spec.dnsName of a DNSRecord
+func dnsRecordName(record *unstructured.Unstructured) string {
+	dnsName, _, _ := unstructured.NestedString(record.Object, "spec", "dnsName")
+	return hostname.Normalize(dnsName)
+}
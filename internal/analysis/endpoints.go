@@ -0,0 +1,34 @@
+package analysis
+
+import (
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// ServiceEndpointSlices returns the EndpointSlices of a Service, found by their service-name label
+func ServiceEndpointSlices(resources *types.ResourceCollection, svc *corev1.Service) []*discoveryv1.EndpointSlice {
+	var slices []*discoveryv1.EndpointSlice
+	for i := range resources.EndpointSlices {
+		slice := &resources.EndpointSlices[i]
+		if slice.Namespace == svc.Namespace && slice.Labels[discoveryv1.LabelServiceName] == svc.Name {
+			slices = append(slices, slice)
+		}
+	}
+	return slices
+}
+
+// ReadyEndpoints counts the endpoints of EndpointSlices and how many of them are ready. An
+// endpoint without a ready condition is ready, as the API defines.
+func ReadyEndpoints(slices []*discoveryv1.EndpointSlice) (ready, total int) {
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			total++
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready++
+			}
+		}
+	}
+	return ready, total
+}
@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewayNameLabel names the Gateway of DNSRecords and of the Services gateway controllers create
+const gatewayNameLabel = "gateway.networking.k8s.io/gateway-name"
+
+// RecordGateway returns the Gateway a DNSRecord publishes, named by its gateway-name label in the
+// record's namespace, or nil when the label is missing or the Gateway does not exist
+func RecordGateway(resources *types.ResourceCollection, record *unstructured.Unstructured) *gatewayv1.Gateway {
+	name, ok := record.GetLabels()[gatewayNameLabel]
+	if !ok {
+		return nil
+	}
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		if gw.Name == name && gw.Namespace == record.GetNamespace() {
+			return gw
+		}
+	}
+	return nil
+}
+
+// LoadBalancerService returns the Service that owns a Gateway's load balancer: the Service whose
+// status.loadBalancer.ingress holds an address from Gateway.status.addresses, preferring one
+// labeled with the Gateway's name. A Gateway without status addresses falls back to a
+// LoadBalancer Service in its namespace labeled with its name. Nil when there is none.
+func LoadBalancerService(resources *types.ResourceCollection, gw *gatewayv1.Gateway) *corev1.Service {
+	statusAddresses := make(map[string]bool)
+	for _, address := range gw.Status.Addresses {
+		statusAddresses[hostname.Normalize(address.Value)] = true
+	}
+
+	var match *corev1.Service
+	for i := range resources.Services {
+		svc := &resources.Services[i]
+		labeled := svc.Namespace == gw.Namespace && svc.Labels[gatewayNameLabel] == gw.Name
+		if len(statusAddresses) == 0 {
+			if labeled && svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+				return svc
+			}
+			continue
+		}
+		for _, address := range LoadBalancerAddresses(svc) {
+			if !statusAddresses[address] {
+				continue
+			}
+			if labeled {
+				return svc
+			}
+			if match == nil {
+				match = svc
+			}
+			break
+		}
+	}
+	return match
+}
+
+// LoadBalancerAddresses returns the normalized IPs and hostnames of a Service's load balancer
+func LoadBalancerAddresses(svc *corev1.Service) []string {
+	var addresses []string
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			addresses = append(addresses, ingress.IP)
+		}
+		if ingress.Hostname != "" {
+			addresses = append(addresses, hostname.Normalize(ingress.Hostname))
+		}
+	}
+	return addresses
+}
+
+// TargetGateway returns the Gateway whose load balancer one of the given DNS targets points to:
+// the target is one of the Gateway's status addresses or an address of its load balancer Service.
+// Nil when no target does.
+func TargetGateway(resources *types.ResourceCollection, targets []string) *gatewayv1.Gateway {
+	wanted := make(map[string]bool)
+	for _, target := range targets {
+		wanted[hostname.Normalize(target)] = true
+	}
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		for _, address := range gatewayAddresses(resources, gw) {
+			if wanted[address] {
+				return gw
+			}
+		}
+	}
+	return nil
+}
+
+// gatewayAddresses returns the current addresses of a Gateway's load balancer: the addresses of
+// its load balancer Service when one is found, else the Gateway's status addresses
+func gatewayAddresses(resources *types.ResourceCollection, gw *gatewayv1.Gateway) []string {
+	var addresses []string
+	if svc := LoadBalancerService(resources, gw); svc != nil {
+		addresses = LoadBalancerAddresses(svc)
+	}
+	if len(addresses) == 0 {
+		for _, address := range gw.Status.Addresses {
+			addresses = append(addresses, hostname.Normalize(address.Value))
+		}
+	}
+	return addresses
+}
+
+// StaleTargets returns the DNS targets published for a Gateway that are not among the current
+// addresses of its load balancer, along with those addresses. It returns nothing when the load
+// balancer has no address yet, since nothing can be compared.
+func StaleTargets(resources *types.ResourceCollection, gw *gatewayv1.Gateway, targets []string) (stale, current []string) {
+	current = gatewayAddresses(resources, gw)
+	if len(current) == 0 {
+		return nil, nil
+	}
+
+	valid := make(map[string]bool)
+	for _, address := range current {
+		valid[address] = true
+	}
+	for _, target := range targets {
+		if !valid[hostname.Normalize(target)] {
+			stale = append(stale, target)
+		}
+	}
+	sort.Strings(current)
+	return stale, current
+}
+
+// checkDNSRecordTargets reports DNSRecords whose targets no longer match the address of their
+// Gateway's load balancer, so clients resolve the hostname to an old or foreign load balancer
+func checkDNSRecordTargets(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+	for i := range resources.DNSRecords {
+		record := &resources.DNSRecords[i]
+		gw := RecordGateway(resources, record)
+		if gw == nil {
+			continue
+		}
+		targets, _, _ := unstructured.NestedStringSlice(record.Object, "spec", "targets")
+		stale, current := StaleTargets(resources, gw, targets)
+		if len(stale) == 0 {
+			continue
+		}
+		findings = append(findings, types.Finding{
+			Severity: types.SeverityError,
+			Resource: types.ResourceRef{Kind: "DNSRecord", Namespace: record.GetNamespace(), Name: record.GetName()},
+			NodeID:   string(record.GetUID()),
+			Message: fmt.Sprintf("DNS name %s points to %s, but the load balancer of Gateway %s/%s is at %s.",
+				dnsRecordName(record), strings.Join(stale, ", "), gw.Namespace, gw.Name, strings.Join(current, ", ")),
+			Remediation: "Check why the ingress operator has not updated spec.targets, or delete the DNSRecord so it is recreated with the current address.",
+		})
+	}
+	return findings
+}
@@ -0,0 +1,180 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// loadBalancer returns a Service of type LoadBalancer in infra with the given ingress addresses,
+// labeled with the name of a Gateway unless gateway is empty
+func loadBalancer(name, gateway string, ingress ...corev1.LoadBalancerIngress) corev1.Service {
+	svc := testutil.Service("infra", name, 80)
+	svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	svc.Status.LoadBalancer.Ingress = ingress
+	if gateway != "" {
+		svc.Labels = map[string]string{gatewayNameLabel: gateway}
+	}
+	return svc
+}
+
+// addressedGateway returns the Gateway infra/gw with the given status addresses
+func addressedGateway(addresses ...string) gatewayv1.Gateway {
+	gw := httpGateway()
+	for _, address := range addresses {
+		gw.Status.Addresses = append(gw.Status.Addresses, gatewayv1.GatewayStatusAddress{Value: address})
+	}
+	return gw
+}
+
+func TestLoadBalancerService(t *testing.T) {
+	tests := []struct {
+		name     string
+		gateway  gatewayv1.Gateway
+		services []corev1.Service
+		want     string
+	}{
+		{
+			name:    "ingress IP matches a status address",
+			gateway: addressedGateway("10.0.0.1"),
+			services: []corev1.Service{
+				loadBalancer("other", "", corev1.LoadBalancerIngress{IP: "10.0.0.2"}),
+				loadBalancer("lb", "", corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+			},
+			want: "lb",
+		},
+		{
+			name:     "ingress hostname matches a status address",
+			gateway:  addressedGateway("LB.example.net."),
+			services: []corev1.Service{loadBalancer("lb", "", corev1.LoadBalancerIngress{Hostname: "lb.example.net"})},
+			want:     "lb",
+		},
+		{
+			name:    "the Service labeled with the Gateway wins",
+			gateway: addressedGateway("10.0.0.1"),
+			services: []corev1.Service{
+				loadBalancer("shared", "", corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+				loadBalancer("owned", "gw", corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+			},
+			want: "owned",
+		},
+		{
+			name:     "no address matches",
+			gateway:  addressedGateway("10.0.0.1"),
+			services: []corev1.Service{loadBalancer("owned", "gw", corev1.LoadBalancerIngress{IP: "10.0.0.9"})},
+		},
+		{
+			name:     "without status addresses the labeled LoadBalancer Service is used",
+			gateway:  addressedGateway(),
+			services: []corev1.Service{testutil.Service("infra", "cluster-ip", 80), loadBalancer("owned", "gw")},
+			want:     "owned",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := &types.ResourceCollection{Gateways: []gatewayv1.Gateway{tt.gateway}, Services: tt.services}
+			got := ""
+			if svc := LoadBalancerService(resources, &resources.Gateways[0]); svc != nil {
+				got = svc.Name
+			}
+			if got != tt.want {
+				t.Errorf("LoadBalancerService = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleTargets(t *testing.T) {
+	tests := []struct {
+		name        string
+		gateway     gatewayv1.Gateway
+		services    []corev1.Service
+		targets     []string
+		wantStale   []string
+		wantCurrent []string
+	}{
+		{
+			name:        "matching target",
+			gateway:     addressedGateway("10.0.0.1"),
+			services:    []corev1.Service{loadBalancer("lb", "gw", corev1.LoadBalancerIngress{IP: "10.0.0.1"})},
+			targets:     []string{"10.0.0.1"},
+			wantCurrent: []string{"10.0.0.1"},
+		},
+		{
+			// The Service moved to a new address before the Gateway status caught up
+			name:        "changed load balancer address",
+			gateway:     addressedGateway(),
+			services:    []corev1.Service{loadBalancer("lb", "gw", corev1.LoadBalancerIngress{IP: "10.0.0.2"})},
+			targets:     []string{"10.0.0.1"},
+			wantStale:   []string{"10.0.0.1"},
+			wantCurrent: []string{"10.0.0.2"},
+		},
+		{
+			name:        "hostname ingress",
+			gateway:     addressedGateway("lb.example.net"),
+			services:    []corev1.Service{loadBalancer("lb", "gw", corev1.LoadBalancerIngress{Hostname: "lb.example.net"})},
+			targets:     []string{"LB.example.net.", "10.0.0.1"},
+			wantStale:   []string{"10.0.0.1"},
+			wantCurrent: []string{"lb.example.net"},
+		},
+		{
+			name:        "status addresses without a Service",
+			gateway:     addressedGateway("10.0.0.1"),
+			targets:     []string{"10.0.0.3"},
+			wantStale:   []string{"10.0.0.3"},
+			wantCurrent: []string{"10.0.0.1"},
+		},
+		{
+			name:    "no address yet",
+			gateway: addressedGateway(),
+			targets: []string{"10.0.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := &types.ResourceCollection{Gateways: []gatewayv1.Gateway{tt.gateway}, Services: tt.services}
+			stale, current := StaleTargets(resources, &resources.Gateways[0], tt.targets)
+			if !reflect.DeepEqual(stale, tt.wantStale) || !reflect.DeepEqual(current, tt.wantCurrent) {
+				t.Errorf("StaleTargets = %q, %q, want %q, %q", stale, current, tt.wantStale, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestCheckDNSRecordTargets(t *testing.T) {
+	record := func(name, gateway string, targets ...interface{}) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "ingress.operator.openshift.io/v1",
+			"kind":       "DNSRecord",
+			"metadata":   map[string]interface{}{"namespace": "infra", "name": name},
+			"spec":       map[string]interface{}{"dnsName": "*.apps.example.com.", "targets": targets},
+		}}
+		if gateway != "" {
+			obj.SetLabels(map[string]string{gatewayNameLabel: gateway})
+		}
+		return obj
+	}
+	resources := &types.ResourceCollection{
+		Gateways: []gatewayv1.Gateway{addressedGateway("10.0.0.2")},
+		Services: []corev1.Service{loadBalancer("lb", "gw", corev1.LoadBalancerIngress{IP: "10.0.0.2"})},
+		DNSRecords: []unstructured.Unstructured{
+			record("current", "gw", "10.0.0.2"),
+			record("stale", "gw", "10.0.0.1"),
+			record("unlabeled", "", "10.0.0.1"),
+			record("other-gateway", "missing", "10.0.0.1"),
+		},
+	}
+
+	want := []string{"error DNSRecord infra/stale: DNS name *.apps.example.com points to 10.0.0.1, but the load balancer of Gateway infra/gw is at 10.0.0.2."}
+	if got := findingSummaries(checkDNSRecordTargets(resources)); !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+package analysis
+
+import (
+	"strings"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// RouteMatch is one match of an HTTPRoute rule
+type RouteMatch struct {
+	Route *gatewayv1.HTTPRoute
+	Rule  int // Index of the rule in the route
+	Index int // Index of the match in the rule
+	Match gatewayv1.HTTPRouteMatch
+}
+
+// defaultPath is what a rule without matches, or a match without a path, matches
+var defaultPath = gatewayv1.HTTPPathMatch{Type: ptrTo(gatewayv1.PathMatchPathPrefix), Value: ptrTo("/")}
+
+// RouteMatches returns every match of a route's rules, with the defaults of the API applied: a
+// rule without matches matches every path
+func RouteMatches(route *gatewayv1.HTTPRoute) []RouteMatch {
+	var matches []RouteMatch
+	for r, rule := range route.Spec.Rules {
+		ruleMatches := rule.Matches
+		if len(ruleMatches) == 0 {
+			ruleMatches = []gatewayv1.HTTPRouteMatch{{}}
+		}
+		for m, match := range ruleMatches {
+			if match.Path == nil {
+				match.Path = &defaultPath
+			}
+			matches = append(matches, RouteMatch{Route: route, Rule: r, Index: m, Match: match})
+		}
+	}
+	return matches
+}
+
+// PathMatch returns the path match type and value, defaulting to a PathPrefix of /
+func PathMatch(match gatewayv1.HTTPRouteMatch) (gatewayv1.PathMatchType, string) {
+	matchType, value := gatewayv1.PathMatchPathPrefix, "/"
+	if match.Path != nil {
+		if match.Path.Type != nil {
+			matchType = *match.Path.Type
+		}
+		if match.Path.Value != nil {
+			value = *match.Path.Value
+		}
+	}
+	return matchType, value
+}
+
+// MatchPrecedes reports whether a takes precedence over b when both match a request, following
+// the Gateway API ordering: an exact path, the longest prefix, a method, the most header matches,
+// the most query parameter matches, then the oldest route, the route name and the rule order
+func MatchPrecedes(a, b RouteMatch) bool {
+	return precedenceReason(a, b) != "" && precedes(a, b)
+}
+
+// precedes applies the ordering of MatchPrecedes
+func precedes(a, b RouteMatch) bool {
+	aType, aValue := PathMatch(a.Match)
+	bType, bValue := PathMatch(b.Match)
+	if ae, be := aType == gatewayv1.PathMatchExact, bType == gatewayv1.PathMatchExact; ae != be {
+		return ae
+	}
+	if al, bl := prefixLength(aType, aValue), prefixLength(bType, bValue); al != bl {
+		return al > bl
+	}
+	if am, bm := a.Match.Method != nil, b.Match.Method != nil; am != bm {
+		return am
+	}
+	if len(a.Match.Headers) != len(b.Match.Headers) {
+		return len(a.Match.Headers) > len(b.Match.Headers)
+	}
+	if len(a.Match.QueryParams) != len(b.Match.QueryParams) {
+		return len(a.Match.QueryParams) > len(b.Match.QueryParams)
+	}
+	if a.Route != b.Route {
+		at, bt := a.Route.CreationTimestamp, b.Route.CreationTimestamp
+		if !at.Equal(&bt) {
+			return at.Before(&bt)
+		}
+		return a.Route.Namespace+"/"+a.Route.Name < b.Route.Namespace+"/"+b.Route.Name
+	}
+	if a.Rule != b.Rule {
+		return a.Rule < b.Rule
+	}
+	return a.Index < b.Index
+}
+
+// precedenceReason names the criterion that decides between two matches, or "" when they are the
+// same match
+func precedenceReason(a, b RouteMatch) string {
+	aType, aValue := PathMatch(a.Match)
+	bType, bValue := PathMatch(b.Match)
+	switch {
+	case (aType == gatewayv1.PathMatchExact) != (bType == gatewayv1.PathMatchExact):
+		return "an exact path match"
+	case prefixLength(aType, aValue) != prefixLength(bType, bValue):
+		return "a longer path prefix"
+	case (a.Match.Method != nil) != (b.Match.Method != nil):
+		return "a method match"
+	case len(a.Match.Headers) != len(b.Match.Headers):
+		return "more header matches"
+	case len(a.Match.QueryParams) != len(b.Match.QueryParams):
+		return "more query parameter matches"
+	case a.Route != b.Route && !a.Route.CreationTimestamp.Equal(&b.Route.CreationTimestamp):
+		return "an older creation timestamp"
+	case a.Route != b.Route:
+		return "its namespace/name sorting first"
+	case a.Rule != b.Rule:
+		return "an earlier rule"
+	case a.Index != b.Index:
+		return "an earlier match"
+	}
+	return ""
+}
+
+// prefixLength returns the number of characters of a prefix match, which ranks longer prefixes
+// first. A trailing slash is ignored, as /api/ matches the same paths as /api.
+func prefixLength(matchType gatewayv1.PathMatchType, value string) int {
+	if matchType != gatewayv1.PathMatchPathPrefix {
+		return 0
+	}
+	return len(strings.TrimSuffix(value, "/")) + 1
+}
+
+// ptrTo returns a pointer to a value
+func ptrTo[T any](value T) *T {
+	return &value
+}
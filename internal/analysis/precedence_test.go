@@ -0,0 +1,186 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/testutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// createdRoute returns an HTTPRoute created at the given minute
+func createdRoute(namespace, name string, minute int) *gatewayv1.HTTPRoute {
+	route := testutil.HTTPRoute(namespace, name)
+	route.CreationTimestamp = metav1.NewTime(time.Date(2024, 1, 1, 0, minute, 0, 0, time.UTC))
+	return &route
+}
+
+// withMethod adds a method to a match
+func withMethod(match gatewayv1.HTTPRouteMatch, method gatewayv1.HTTPMethod) gatewayv1.HTTPRouteMatch {
+	match.Method = ptrTo(method)
+	return match
+}
+
+// withHeaders adds exact header matches to a match
+func withHeaders(match gatewayv1.HTTPRouteMatch, names ...string) gatewayv1.HTTPRouteMatch {
+	for _, name := range names {
+		match.Headers = append(match.Headers, gatewayv1.HTTPHeaderMatch{Name: gatewayv1.HTTPHeaderName(name), Value: "1"})
+	}
+	return match
+}
+
+// withQueryParams adds exact query parameter matches to a match
+func withQueryParams(match gatewayv1.HTTPRouteMatch, names ...string) gatewayv1.HTTPRouteMatch {
+	for _, name := range names {
+		match.QueryParams = append(match.QueryParams, gatewayv1.HTTPQueryParamMatch{Name: gatewayv1.HTTPHeaderName(name), Value: "1"})
+	}
+	return match
+}
+
+func TestPrecedes(t *testing.T) {
+	older := createdRoute("b", "older", 0)
+	newer := createdRoute("a", "newer", 5)
+	sameTimeA := createdRoute("a", "z", 0)
+	sameTimeB := createdRoute("b", "a", 0)
+
+	prefix := func(value string) gatewayv1.HTTPRouteMatch {
+		return testutil.PathMatch(gatewayv1.PathMatchPathPrefix, value)
+	}
+
+	tests := []struct {
+		name   string
+		a, b   RouteMatch
+		reason string
+	}{
+		{
+			name:   "exact path before any prefix",
+			a:      RouteMatch{Route: newer, Match: testutil.PathMatch(gatewayv1.PathMatchExact, "/")},
+			b:      RouteMatch{Route: older, Match: prefix("/api/v1/users")},
+			reason: "an exact path match",
+		},
+		{
+			name:   "longer prefix first",
+			a:      RouteMatch{Route: newer, Match: prefix("/api/v1")},
+			b:      RouteMatch{Route: older, Match: prefix("/api")},
+			reason: "a longer path prefix",
+		},
+		{
+			name:   "a trailing slash does not lengthen a prefix",
+			a:      RouteMatch{Route: older, Match: prefix("/api")},
+			b:      RouteMatch{Route: newer, Match: prefix("/api/")},
+			reason: "an older creation timestamp",
+		},
+		{
+			name:   "regular expression after any prefix",
+			a:      RouteMatch{Route: newer, Match: prefix("/")},
+			b:      RouteMatch{Route: older, Match: testutil.PathMatch(gatewayv1.PathMatchRegularExpression, "/api/.*")},
+			reason: "a longer path prefix",
+		},
+		{
+			name:   "method before none",
+			a:      RouteMatch{Route: newer, Match: withMethod(prefix("/api"), gatewayv1.HTTPMethodGet)},
+			b:      RouteMatch{Route: older, Match: withHeaders(prefix("/api"), "x-a", "x-b")},
+			reason: "a method match",
+		},
+		{
+			name:   "more headers first",
+			a:      RouteMatch{Route: newer, Match: withHeaders(prefix("/api"), "x-a", "x-b")},
+			b:      RouteMatch{Route: older, Match: withQueryParams(withHeaders(prefix("/api"), "x-a"), "q", "r")},
+			reason: "more header matches",
+		},
+		{
+			name:   "more query parameters first",
+			a:      RouteMatch{Route: newer, Match: withQueryParams(prefix("/api"), "q")},
+			b:      RouteMatch{Route: older, Match: prefix("/api")},
+			reason: "more query parameter matches",
+		},
+		{
+			name:   "older route first",
+			a:      RouteMatch{Route: older, Match: prefix("/api")},
+			b:      RouteMatch{Route: newer, Match: prefix("/api")},
+			reason: "an older creation timestamp",
+		},
+		{
+			name:   "namespace/name order for routes created together",
+			a:      RouteMatch{Route: sameTimeA, Match: prefix("/api")},
+			b:      RouteMatch{Route: sameTimeB, Match: prefix("/api")},
+			reason: "its namespace/name sorting first",
+		},
+		{
+			name:   "earlier rule first",
+			a:      RouteMatch{Route: newer, Rule: 0, Index: 1, Match: prefix("/api")},
+			b:      RouteMatch{Route: newer, Rule: 1, Index: 0, Match: prefix("/api")},
+			reason: "an earlier rule",
+		},
+		{
+			name:   "earlier match first",
+			a:      RouteMatch{Route: newer, Rule: 1, Index: 0, Match: prefix("/api")},
+			b:      RouteMatch{Route: newer, Rule: 1, Index: 1, Match: prefix("/api")},
+			reason: "an earlier match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !precedes(tt.a, tt.b) {
+				t.Errorf("precedes(a, b) = false, want true")
+			}
+			if precedes(tt.b, tt.a) {
+				t.Errorf("precedes(b, a) = true, want false")
+			}
+			if !MatchPrecedes(tt.a, tt.b) {
+				t.Errorf("MatchPrecedes(a, b) = false, want true")
+			}
+			if got := precedenceReason(tt.a, tt.b); got != tt.reason {
+				t.Errorf("precedenceReason(a, b) = %q, want %q", got, tt.reason)
+			}
+			if got := precedenceReason(tt.b, tt.a); got != tt.reason {
+				t.Errorf("precedenceReason(b, a) = %q, want %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestMatchPrecedesSameMatch(t *testing.T) {
+	route := createdRoute("a", "r", 0)
+	match := RouteMatch{Route: route, Rule: 1, Index: 2, Match: testutil.PathMatch(gatewayv1.PathMatchPathPrefix, "/")}
+	if MatchPrecedes(match, match) {
+		t.Errorf("MatchPrecedes(m, m) = true, want false")
+	}
+	if got := precedenceReason(match, match); got != "" {
+		t.Errorf("precedenceReason(m, m) = %q, want \"\"", got)
+	}
+}
+
+func TestRouteMatchesDefaults(t *testing.T) {
+	route := createdRoute("a", "r", 0)
+	route.Spec.Rules = []gatewayv1.HTTPRouteRule{
+		{},
+		{Matches: []gatewayv1.HTTPRouteMatch{{Method: ptrTo(gatewayv1.HTTPMethodPost)}, testutil.PathMatch(gatewayv1.PathMatchExact, "/login")}},
+	}
+
+	tests := []struct {
+		rule, index int
+		pathType    gatewayv1.PathMatchType
+		path        string
+	}{
+		{0, 0, gatewayv1.PathMatchPathPrefix, "/"},
+		{1, 0, gatewayv1.PathMatchPathPrefix, "/"},
+		{1, 1, gatewayv1.PathMatchExact, "/login"},
+	}
+
+	matches := RouteMatches(route)
+	if len(matches) != len(tests) {
+		t.Fatalf("RouteMatches returned %d matches, want %d", len(matches), len(tests))
+	}
+	for i, tt := range tests {
+		got := matches[i]
+		pathType, path := PathMatch(got.Match)
+		if got.Rule != tt.rule || got.Index != tt.index || pathType != tt.pathType || path != tt.path {
+			t.Errorf("match %d = rules[%d].matches[%d] %s %s, want rules[%d].matches[%d] %s %s",
+				i, got.Rule, got.Index, pathType, path, tt.rule, tt.index, tt.pathType, tt.path)
+		}
+	}
+}
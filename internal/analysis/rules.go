@@ -0,0 +1,424 @@
+package analysis
+
+import (
+	"fmt"
+
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// checkRouteAttachment reports HTTPRoutes that no parent accepted. The controller's verdict in
+// status.parents is used when present; routes without status (e.g. from manifests) are checked
+// against the Gateway API attachment rules instead.
+func checkRouteAttachment(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		ref := routeRef(route)
+
+		if known, accepted, rejected := routeAcceptance(route); known {
+			for _, parent := range rejected {
+				severity := types.SeverityWarning
+				if len(accepted) == 0 {
+					severity = types.SeverityError
+				}
+				condition := findCondition(parent.Conditions, string(gatewayv1.RouteConditionAccepted))
+				findings = append(findings, types.Finding{
+					Severity:    severity,
+					Resource:    ref,
+					NodeID:      string(route.UID),
+					Message:     fmt.Sprintf("Not accepted by %s: %s (%s)", parentName(route.Namespace, parent.ParentRef), condition.Message, condition.Reason),
+					Remediation: "Fix the parentRef or the listener's allowedRoutes so that the Gateway accepts the route.",
+				})
+			}
+			continue
+		}
+
+		if len(route.Spec.ParentRefs) == 0 {
+			findings = append(findings, types.Finding{
+				Severity:    types.SeverityError,
+				Resource:    ref,
+				NodeID:      string(route.UID),
+				Message:     "The route has no parentRefs and is not attached to any Gateway.",
+				Remediation: "Add a parentRef pointing to a Gateway.",
+			})
+			continue
+		}
+
+		var problems []string
+		gateways := 0
+		for _, parentRef := range route.Spec.ParentRefs {
+			if !isGatewayRef(parentRef) {
+				continue
+			}
+			gateways++
+			gw := ParentGateway(resources, route.Namespace, parentRef)
+			if gw == nil {
+				problems = append(problems, fmt.Sprintf("Gateway %s does not exist", parentName(route.Namespace, parentRef)))
+				continue
+			}
+			if listeners, reason := AttachedListeners(gw, route, parentRef); len(listeners) == 0 {
+				problems = append(problems, fmt.Sprintf("Gateway %s rejects the route: %s", parentName(route.Namespace, parentRef), reason))
+			}
+		}
+
+		for _, problem := range problems {
+			severity := types.SeverityWarning
+			if len(problems) == gateways {
+				severity = types.SeverityError
+			}
+			findings = append(findings, types.Finding{
+				Severity:    severity,
+				Resource:    ref,
+				NodeID:      string(route.UID),
+				Message:     problem + ".",
+				Remediation: "Fix the parentRef or the listener's allowedRoutes so that the Gateway accepts the route.",
+			})
+		}
+	}
+
+	return findings
+}
+
+// BackendRefProblems returns the findings of the backend-not-found rule without running the
+// other rules, e.g. to compare which backendRefs resolve before and after a change
+func BackendRefProblems(resources *types.ResourceCollection) []types.Finding {
+	return checkBackendRefs(resources)
+}
+
+// checkBackendRefs reports backendRefs to Services or ports that do not exist, and
+// cross-namespace backendRefs that no ReferenceGrant permits
+func checkBackendRefs(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		for r, rule := range route.Spec.Rules {
+			for b, backend := range rule.BackendRefs {
+				if !isServiceRef(backend.BackendObjectReference) {
+					continue
+				}
+
+				namespace := route.Namespace
+				if backend.Namespace != nil {
+					namespace = string(*backend.Namespace)
+				}
+				location := fmt.Sprintf("rules[%d].backendRefs[%d]", r, b)
+				finding := types.Finding{
+					Severity: types.SeverityError,
+					Resource: routeRef(route),
+					NodeID:   string(route.UID),
+				}
+
+				svc := FindService(resources, namespace, string(backend.Name))
+				switch {
+				case svc == nil:
+					finding.Message = fmt.Sprintf("%s refers to Service %s/%s, which does not exist.", location, namespace, backend.Name)
+					finding.Remediation = "Create the Service or correct the backendRef name and namespace."
+				case backend.Port == nil:
+					finding.Message = fmt.Sprintf("%s refers to Service %s/%s without a port.", location, namespace, backend.Name)
+					finding.Remediation = "Set the port of the backendRef; it is required for Services."
+				case !servicePortExists(svc.Spec.Ports, int32(*backend.Port)):
+					finding.Message = fmt.Sprintf("%s refers to port %d of Service %s/%s, which does not expose it.", location, *backend.Port, namespace, backend.Name)
+					finding.Remediation = "Use one of the ports listed in the Service spec."
+				case namespace != route.Namespace && !referenceGranted(resources, "HTTPRoute", route.Namespace, "Service", namespace, string(backend.Name)):
+					finding.Rule = "backend-not-permitted"
+					finding.Message = fmt.Sprintf("%s refers to Service %s/%s in another namespace, and no ReferenceGrant permits it.", location, namespace, backend.Name)
+					finding.Remediation = fmt.Sprintf("Create a ReferenceGrant in namespace %s allowing HTTPRoutes from %s to refer to Services.", namespace, route.Namespace)
+				default:
+					continue
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkUnusedReferenceGrants reports ReferenceGrants that no cross-namespace reference uses
+func checkUnusedReferenceGrants(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+
+	for i := range resources.ReferenceGrants {
+		grant := &resources.ReferenceGrants[i]
+		if referenceGrantUsed(resources, grant.Namespace, grant.Name) {
+			continue
+		}
+		findings = append(findings, types.Finding{
+			Severity:    types.SeverityInfo,
+			Resource:    types.ResourceRef{Kind: "ReferenceGrant", Namespace: grant.Namespace, Name: grant.Name},
+			NodeID:      string(grant.UID),
+			Message:     "No route or Gateway uses the references this ReferenceGrant permits.",
+			Remediation: "Delete the ReferenceGrant if it is no longer needed; unused grants widen access without purpose.",
+		})
+	}
+
+	return findings
+}
+
+// checkGatewayClasses reports Gateways whose GatewayClass does not exist
+func checkGatewayClasses(resources *types.ResourceCollection) []types.Finding {
+	classes := make(map[string]bool)
+	for _, gc := range resources.GatewayClasses {
+		classes[gc.Name] = true
+	}
+
+	var findings []types.Finding
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		if classes[string(gw.Spec.GatewayClassName)] {
+			continue
+		}
+		findings = append(findings, types.Finding{
+			Severity:    types.SeverityError,
+			Resource:    gatewayRef(gw, ""),
+			NodeID:      string(gw.UID),
+			Message:     fmt.Sprintf("GatewayClass %q does not exist, so no controller will program this Gateway.", gw.Spec.GatewayClassName),
+			Remediation: "Set gatewayClassName to an installed GatewayClass.",
+		})
+	}
+	return findings
+}
+
+// checkListenerConflicts reports listeners of a Gateway that share a port and hostname, or use
+// incompatible protocols on the same port
+func checkListenerConflicts(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+
+	for g := range resources.Gateways {
+		gw := &resources.Gateways[g]
+		listeners := gw.Spec.Listeners
+		for i := range listeners {
+			for j := 0; j < i; j++ {
+				a, b := listeners[j], listeners[i]
+				if a.Port != b.Port {
+					continue
+				}
+
+				var message string
+				switch {
+				case !compatibleProtocols(a.Protocol, b.Protocol):
+					message = fmt.Sprintf("Listeners %q (%s) and %q (%s) use incompatible protocols on port %d.", a.Name, a.Protocol, b.Name, b.Protocol, a.Port)
+				case listenerHostname(a) == listenerHostname(b):
+					served := listenerHostname(a)
+					if served == "" {
+						served = "any hostname"
+					}
+					message = fmt.Sprintf("Listeners %q and %q both serve %s on port %d.", a.Name, b.Name, served, a.Port)
+				default:
+					continue
+				}
+
+				findings = append(findings, types.Finding{
+					Severity:    types.SeverityError,
+					Resource:    gatewayRef(gw, string(b.Name)),
+					NodeID:      ListenerID(gw, i),
+					Message:     message,
+					Remediation: "Give each listener on a port a distinct hostname, or move one of them to another port.",
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkWildcardShadowing reports wildcard (or hostname-less) listeners that overlap a listener
+// with an explicit hostname on the same port. Requests for the explicit hostname go to the more
+// specific listener, so routes attached only to the wildcard listener never receive them.
+func checkWildcardShadowing(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+
+	for g := range resources.Gateways {
+		gw := &resources.Gateways[g]
+		for i, wildcard := range gw.Spec.Listeners {
+			pattern := listenerHostname(wildcard)
+			if pattern != "" && !hostname.IsWildcard(pattern) {
+				continue
+			}
+
+			for _, explicit := range gw.Spec.Listeners {
+				explicitHostname := listenerHostname(explicit)
+				if explicit.Port != wildcard.Port || explicitHostname == "" || hostname.IsWildcard(explicitHostname) {
+					continue
+				}
+				if !hostname.Matches(pattern, explicitHostname) {
+					continue
+				}
+
+				display := pattern
+				if display == "" {
+					display = "any hostname"
+				}
+				findings = append(findings, types.Finding{
+					Severity: types.SeverityWarning,
+					Resource: gatewayRef(gw, string(wildcard.Name)),
+					NodeID:   ListenerID(gw, i),
+					Message: fmt.Sprintf("Listener %q (%s) overlaps listener %q (%s) on port %d; requests for %s are served by %q only.",
+						wildcard.Name, display, explicit.Name, explicitHostname, wildcard.Port, explicitHostname, explicit.Name),
+					Remediation: fmt.Sprintf("Attach routes for %s to listener %q, or remove the explicit listener if the wildcard should serve it.", explicitHostname, explicit.Name),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// referenceGrantUsed reports whether any cross-namespace reference relies on the grant
+func referenceGrantUsed(resources *types.ResourceCollection, namespace, name string) bool {
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		for _, rule := range route.Spec.Rules {
+			for _, backend := range rule.BackendRefs {
+				if !isServiceRef(backend.BackendObjectReference) || backend.Namespace == nil {
+					continue
+				}
+				if string(*backend.Namespace) == namespace && route.Namespace != namespace &&
+					grantPermits(resources, namespace, name, "HTTPRoute", route.Namespace, "Service", string(backend.Name)) {
+					return true
+				}
+			}
+		}
+	}
+
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		for _, listener := range gw.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+			for _, cert := range listener.TLS.CertificateRefs {
+				if cert.Namespace == nil || string(*cert.Namespace) != namespace || gw.Namespace == namespace {
+					continue
+				}
+				if grantPermits(resources, namespace, name, "Gateway", gw.Namespace, "Secret", string(cert.Name)) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// referenceGranted reports whether any ReferenceGrant in the target namespace permits the reference
+func referenceGranted(resources *types.ResourceCollection, fromKind, fromNamespace, toKind, toNamespace, toName string) bool {
+	return len(PermittingGrants(resources, fromKind, fromNamespace, toKind, toNamespace, toName)) > 0
+}
+
+// PermittingGrants returns the names of the ReferenceGrants in the target namespace that permit a
+// reference from a kind in a namespace to a named object
+func PermittingGrants(resources *types.ResourceCollection, fromKind, fromNamespace, toKind, toNamespace, toName string) []string {
+	var names []string
+	for _, grant := range resources.ReferenceGrants {
+		if grant.Namespace == toNamespace && grantPermits(resources, grant.Namespace, grant.Name, fromKind, fromNamespace, toKind, toName) {
+			names = append(names, grant.Name)
+		}
+	}
+	return names
+}
+
+// grantPermits reports whether the named ReferenceGrant permits a reference from a kind in a
+// namespace to a named object of a kind in the grant's namespace
+func grantPermits(resources *types.ResourceCollection, namespace, name, fromKind, fromNamespace, toKind, toName string) bool {
+	for _, grant := range resources.ReferenceGrants {
+		if grant.Namespace != namespace || grant.Name != name {
+			continue
+		}
+
+		fromAllowed := false
+		for _, from := range grant.Spec.From {
+			if string(from.Group) == gatewayv1.GroupName && string(from.Kind) == fromKind && string(from.Namespace) == fromNamespace {
+				fromAllowed = true
+				break
+			}
+		}
+		if !fromAllowed {
+			return false
+		}
+
+		for _, to := range grant.Spec.To {
+			if string(to.Group) == "" && string(to.Kind) == toKind && (to.Name == nil || string(*to.Name) == toName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isServiceRef reports whether a backend reference points to a core Service (the default kind)
+func isServiceRef(ref gatewayv1.BackendObjectReference) bool {
+	if ref.Group != nil && *ref.Group != "" {
+		return false
+	}
+	return ref.Kind == nil || *ref.Kind == "Service"
+}
+
+// FindService returns the Service with the given namespace and name, or nil
+func FindService(resources *types.ResourceCollection, namespace, name string) *corev1.Service {
+	for i := range resources.Services {
+		if resources.Services[i].Namespace == namespace && resources.Services[i].Name == name {
+			return &resources.Services[i]
+		}
+	}
+	return nil
+}
+
+// servicePortExists reports whether a Service exposes a port
+func servicePortExists(ports []corev1.ServicePort, port int32) bool {
+	for _, p := range ports {
+		if p.Port == port {
+			return true
+		}
+	}
+	return false
+}
+
+// compatibleProtocols reports whether two listeners can share a port. HTTPS and TLS both
+// terminate TLS and are distinguished by SNI; HTTP cannot share a port with either.
+func compatibleProtocols(a, b gatewayv1.ProtocolType) bool {
+	if a == b {
+		return true
+	}
+	tls := func(p gatewayv1.ProtocolType) bool {
+		return p == gatewayv1.HTTPSProtocolType || p == gatewayv1.TLSProtocolType
+	}
+	return tls(a) && tls(b)
+}
+
+// listenerHostname returns a listener's hostname in lower case, or "" when it matches any hostname
+func listenerHostname(listener gatewayv1.Listener) string {
+	if listener.Hostname == nil {
+		return ""
+	}
+	return hostname.Normalize(string(*listener.Hostname))
+}
+
+// parentName formats the namespace/name of a parentRef
+func parentName(routeNamespace string, ref gatewayv1.ParentReference) string {
+	namespace := routeNamespace
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	name := namespace + "/" + string(ref.Name)
+	if ref.SectionName != nil {
+		name += "#" + string(*ref.SectionName)
+	}
+	return name
+}
+
+// routeRef identifies an HTTPRoute in a finding
+func routeRef(route *gatewayv1.HTTPRoute) types.ResourceRef {
+	return types.ResourceRef{Kind: "HTTPRoute", Namespace: route.Namespace, Name: route.Name}
+}
+
+// gatewayRef identifies a Gateway, or one of its listeners, in a finding
+func gatewayRef(gw *gatewayv1.Gateway, listener string) types.ResourceRef {
+	return types.ResourceRef{Kind: "Gateway", Namespace: gw.Namespace, Name: gw.Name, Listener: listener}
+}
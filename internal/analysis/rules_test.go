@@ -0,0 +1,353 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// findingSummaries summarizes findings as "severity resource: message" for comparison
+func findingSummaries(findings []types.Finding) []string {
+	summaries := []string{}
+	for _, finding := range findings {
+		resource := finding.Resource.Kind + " " + finding.Resource.Namespace + "/" + finding.Resource.Name
+		if finding.Resource.Listener != "" {
+			resource += "/" + finding.Resource.Listener
+		}
+		summaries = append(summaries, string(finding.Severity)+" "+resource+": "+finding.Message)
+	}
+	return summaries
+}
+
+// httpGateway returns the Gateway infra/gw with one HTTP listener on port 80 for any hostname
+func httpGateway() gatewayv1.Gateway {
+	return testutil.Gateway("infra", "gw", testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, ""))
+}
+
+// routeParent returns a route status entry for a parent with its Accepted condition
+func routeParent(name string, accepted metav1.ConditionStatus, reason string) gatewayv1.RouteParentStatus {
+	return gatewayv1.RouteParentStatus{
+		ParentRef:  testutil.ParentRef("infra", name),
+		Conditions: []metav1.Condition{{Type: string(gatewayv1.RouteConditionAccepted), Status: accepted, Reason: reason, Message: "verdict"}},
+	}
+}
+
+func TestCheckRouteAttachment(t *testing.T) {
+	missingSection := testutil.ParentRef("infra", "gw")
+	missingSection.SectionName = testutil.Ptr(gatewayv1.SectionName("https"))
+	service := testutil.ParentRef("app", "mesh")
+	service.Group, service.Kind = testutil.Ptr(gatewayv1.Group("")), testutil.Ptr(gatewayv1.Kind("Service"))
+
+	tests := []struct {
+		name    string
+		parents []gatewayv1.ParentReference
+		status  []gatewayv1.RouteParentStatus
+		want    []string
+	}{
+		{
+			name:    "attached",
+			parents: []gatewayv1.ParentReference{testutil.ParentRef("infra", "gw")},
+			want:    []string{},
+		},
+		{
+			name: "no parentRefs",
+			want: []string{"error HTTPRoute app/r: The route has no parentRefs and is not attached to any Gateway."},
+		},
+		{
+			name:    "missing Gateway",
+			parents: []gatewayv1.ParentReference{testutil.ParentRef("infra", "missing")},
+			want:    []string{"error HTTPRoute app/r: Gateway infra/missing does not exist."},
+		},
+		{
+			name:    "missing listener next to an attached parent",
+			parents: []gatewayv1.ParentReference{testutil.ParentRef("infra", "gw"), missingSection},
+			want:    []string{`warning HTTPRoute app/r: Gateway infra/gw#https rejects the route: no listener named "https".`},
+		},
+		{
+			name: "rejected in status",
+			status: []gatewayv1.RouteParentStatus{
+				routeParent("gw", metav1.ConditionTrue, "Accepted"),
+				routeParent("other", metav1.ConditionFalse, "NotAllowedByListeners"),
+			},
+			want: []string{"warning HTTPRoute app/r: Not accepted by infra/other: verdict (NotAllowedByListeners)"},
+		},
+		{
+			name:   "rejected by every parent in status",
+			status: []gatewayv1.RouteParentStatus{routeParent("gw", metav1.ConditionFalse, "NoMatchingListenerHostname")},
+			want:   []string{"error HTTPRoute app/r: Not accepted by infra/gw: verdict (NoMatchingListenerHostname)"},
+		},
+		{
+			// Only Gateway parents count when deciding whether the route is attached anywhere
+			name:    "missing Gateway next to a parent other than a Gateway",
+			parents: []gatewayv1.ParentReference{service, testutil.ParentRef("infra", "missing")},
+			want:    []string{"error HTTPRoute app/r: Gateway infra/missing does not exist."},
+		},
+		{
+			name:    "parents other than Gateways are not checked",
+			parents: []gatewayv1.ParentReference{service},
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := testutil.HTTPRoute("app", "r", tt.parents...)
+			route.Status.Parents = tt.status
+			resources := &types.ResourceCollection{Gateways: []gatewayv1.Gateway{httpGateway()}, HTTPRoutes: []gatewayv1.HTTPRoute{route}}
+			if got := findingSummaries(checkRouteAttachment(resources)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBackendRefs(t *testing.T) {
+	services := []corev1.Service{testutil.Service("app", "web", 80), testutil.Service("data", "db", 5432)}
+	withoutPort := testutil.BackendRef("", "web", 0)
+	withoutPort.Port = nil
+	external := testutil.BackendRef("", "bucket", 443)
+	external.Group, external.Kind = testutil.Ptr(gatewayv1.Group("storage.example.com")), testutil.Ptr(gatewayv1.Kind("Bucket"))
+
+	tests := []struct {
+		name   string
+		ref    gatewayv1.HTTPBackendRef
+		grants []gatewayv1beta1.ReferenceGrant
+		want   []string
+	}{
+		{
+			name: "existing Service and port",
+			ref:  testutil.BackendRef("", "web", 80),
+			want: []string{},
+		},
+		{
+			name: "missing Service",
+			ref:  testutil.BackendRef("", "missing", 80),
+			want: []string{"error HTTPRoute app/r: rules[0].backendRefs[0] refers to Service app/missing, which does not exist."},
+		},
+		{
+			name: "missing port",
+			ref:  testutil.BackendRef("", "web", 8080),
+			want: []string{"error HTTPRoute app/r: rules[0].backendRefs[0] refers to port 8080 of Service app/web, which does not expose it."},
+		},
+		{
+			name: "no port",
+			ref:  withoutPort,
+			want: []string{"error HTTPRoute app/r: rules[0].backendRefs[0] refers to Service app/web without a port."},
+		},
+		{
+			name: "cross-namespace without a grant",
+			ref:  testutil.BackendRef("data", "db", 5432),
+			want: []string{"error HTTPRoute app/r: rules[0].backendRefs[0] refers to Service data/db in another namespace, and no ReferenceGrant permits it."},
+		},
+		{
+			name:   "grant in the wrong namespace",
+			ref:    testutil.BackendRef("data", "db", 5432),
+			grants: []gatewayv1beta1.ReferenceGrant{testutil.ReferenceGrant("app", "allow-app", "HTTPRoute", "app", "Service")},
+			want:   []string{"error HTTPRoute app/r: rules[0].backendRefs[0] refers to Service data/db in another namespace, and no ReferenceGrant permits it."},
+		},
+		{
+			name:   "cross-namespace with a grant",
+			ref:    testutil.BackendRef("data", "db", 5432),
+			grants: []gatewayv1beta1.ReferenceGrant{testutil.ReferenceGrant("data", "allow-app", "HTTPRoute", "app", "Service")},
+			want:   []string{},
+		},
+		{
+			name: "backends other than Services are not checked",
+			ref:  external,
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := testutil.HTTPRoute("app", "r", testutil.ParentRef("infra", "gw"))
+			route.Spec.Rules = []gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{tt.ref}}}
+			resources := &types.ResourceCollection{HTTPRoutes: []gatewayv1.HTTPRoute{route}, Services: services, ReferenceGrants: tt.grants}
+			findings := checkBackendRefs(resources)
+			if got := findingSummaries(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+			if tt.name == "cross-namespace without a grant" && findings[0].Rule != "backend-not-permitted" {
+				t.Errorf("rule = %q, want backend-not-permitted", findings[0].Rule)
+			}
+		})
+	}
+}
+
+func TestCheckUnusedReferenceGrants(t *testing.T) {
+	route := testutil.HTTPRoute("app", "r", testutil.ParentRef("infra", "gw"))
+	route.Spec.Rules = []gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{testutil.BackendRef("data", "db", 5432)}}}
+
+	certListener := testutil.Listener("https", gatewayv1.HTTPSProtocolType, 443, "")
+	certListener.TLS = &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{{
+		Name:      "cert",
+		Namespace: testutil.Ptr(gatewayv1.Namespace("certs")),
+	}}}
+
+	resources := &types.ResourceCollection{
+		Gateways:   []gatewayv1.Gateway{testutil.Gateway("infra", "gw", certListener)},
+		HTTPRoutes: []gatewayv1.HTTPRoute{route},
+		ReferenceGrants: []gatewayv1beta1.ReferenceGrant{
+			testutil.ReferenceGrant("data", "routes", "HTTPRoute", "app", "Service"),
+			testutil.ReferenceGrant("certs", "gateways", "Gateway", "infra", "Secret"),
+			testutil.ReferenceGrant("data", "other-namespace", "HTTPRoute", "web", "Service"),
+			testutil.ReferenceGrant("certs", "secrets-for-routes", "HTTPRoute", "app", "Secret"),
+		},
+	}
+
+	want := []string{
+		"info ReferenceGrant data/other-namespace: No route or Gateway uses the references this ReferenceGrant permits.",
+		"info ReferenceGrant certs/secrets-for-routes: No route or Gateway uses the references this ReferenceGrant permits.",
+	}
+	if got := findingSummaries(checkUnusedReferenceGrants(resources)); !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+}
+
+func TestCheckGatewayClasses(t *testing.T) {
+	resources := &types.ResourceCollection{
+		GatewayClasses: []gatewayv1.GatewayClass{testutil.GatewayClass("example")},
+		Gateways:       []gatewayv1.Gateway{httpGateway(), testutil.Gateway("infra", "unknown")},
+	}
+	resources.Gateways[1].Spec.GatewayClassName = "missing"
+
+	want := []string{`error Gateway infra/unknown: GatewayClass "missing" does not exist, so no controller will program this Gateway.`}
+	if got := findingSummaries(checkGatewayClasses(resources)); !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %q, want %q", got, want)
+	}
+}
+
+func TestCheckListenerConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []gatewayv1.Listener
+		want      []string
+	}{
+		{
+			name: "distinct hostnames",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("a", gatewayv1.HTTPProtocolType, 80, "a.example.com"),
+				testutil.Listener("b", gatewayv1.HTTPProtocolType, 80, "b.example.com"),
+			},
+			want: []string{},
+		},
+		{
+			name: "duplicate hostname on a port",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("a", gatewayv1.HTTPProtocolType, 80, "www.example.com"),
+				testutil.Listener("b", gatewayv1.HTTPProtocolType, 80, "WWW.example.com"),
+			},
+			want: []string{`error Gateway infra/gw/b: Listeners "a" and "b" both serve www.example.com on port 80.`},
+		},
+		{
+			name: "duplicate port without hostnames",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("a", gatewayv1.HTTPProtocolType, 80, ""),
+				testutil.Listener("b", gatewayv1.HTTPProtocolType, 80, ""),
+			},
+			want: []string{`error Gateway infra/gw/b: Listeners "a" and "b" both serve any hostname on port 80.`},
+		},
+		{
+			name: "incompatible protocols",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("a", gatewayv1.HTTPProtocolType, 443, "a.example.com"),
+				testutil.Listener("b", gatewayv1.HTTPSProtocolType, 443, "b.example.com"),
+			},
+			want: []string{`error Gateway infra/gw/b: Listeners "a" (HTTP) and "b" (HTTPS) use incompatible protocols on port 443.`},
+		},
+		{
+			name: "HTTPS and TLS share a port",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("a", gatewayv1.HTTPSProtocolType, 443, "a.example.com"),
+				testutil.Listener("b", gatewayv1.TLSProtocolType, 443, "b.example.com"),
+			},
+			want: []string{},
+		},
+		{
+			name: "same hostname on different ports",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("a", gatewayv1.HTTPProtocolType, 80, "www.example.com"),
+				testutil.Listener("b", gatewayv1.HTTPProtocolType, 8080, "www.example.com"),
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := &types.ResourceCollection{Gateways: []gatewayv1.Gateway{testutil.Gateway("infra", "gw", tt.listeners...)}}
+			findings := checkListenerConflicts(resources)
+			if got := findingSummaries(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+			if len(findings) > 0 && findings[0].NodeID != "infra/gw-listener-1" {
+				t.Errorf("node ID = %q, want the second listener", findings[0].NodeID)
+			}
+		})
+	}
+}
+
+func TestCheckWildcardShadowing(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []gatewayv1.Listener
+		want      []string
+	}{
+		{
+			name: "wildcard overlaps an explicit hostname",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("wildcard", gatewayv1.HTTPProtocolType, 80, "*.example.com"),
+				testutil.Listener("api", gatewayv1.HTTPProtocolType, 80, "api.example.com"),
+			},
+			want: []string{`warning Gateway infra/gw/wildcard: Listener "wildcard" (*.example.com) overlaps listener "api" (api.example.com) on port 80; requests for api.example.com are served by "api" only.`},
+		},
+		{
+			name: "listener without a hostname",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("any", gatewayv1.HTTPProtocolType, 80, ""),
+				testutil.Listener("api", gatewayv1.HTTPProtocolType, 80, "api.example.com"),
+			},
+			want: []string{`warning Gateway infra/gw/any: Listener "any" (any hostname) overlaps listener "api" (api.example.com) on port 80; requests for api.example.com are served by "api" only.`},
+		},
+		{
+			name: "other domain",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("wildcard", gatewayv1.HTTPProtocolType, 80, "*.example.com"),
+				testutil.Listener("api", gatewayv1.HTTPProtocolType, 80, "api.example.org"),
+			},
+			want: []string{},
+		},
+		{
+			name: "other port",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("wildcard", gatewayv1.HTTPProtocolType, 80, "*.example.com"),
+				testutil.Listener("api", gatewayv1.HTTPProtocolType, 8080, "api.example.com"),
+			},
+			want: []string{},
+		},
+		{
+			name: "two wildcards",
+			listeners: []gatewayv1.Listener{
+				testutil.Listener("wildcard", gatewayv1.HTTPProtocolType, 80, "*.example.com"),
+				testutil.Listener("any", gatewayv1.HTTPProtocolType, 8080, ""),
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := &types.ResourceCollection{Gateways: []gatewayv1.Gateway{testutil.Gateway("infra", "gw", tt.listeners...)}}
+			if got := findingSummaries(checkWildcardShadowing(resources)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Shadow is a route rule match that can never receive traffic on a listener and hostname because
+// a match with higher precedence covers every request it matches
+type Shadow struct {
+	Gateway   *gatewayv1.Gateway
+	Listener  int    // Index of the listener in the Gateway
+	Hostname  string // Effective hostname the routes share on the listener, "*" for any
+	Winner    RouteMatch
+	Loser     RouteMatch
+	Identical bool   // Whether both matches have the same conditions
+	Reason    string // The precedence criterion that decides for the winner
+}
+
+// Shadowing groups the attached route rules by listener and effective hostname and returns the
+// matches that a higher-precedence match shadows. Each loser is reported once per hostname,
+// against the first match that shadows it.
+func Shadowing(resources *types.ResourceCollection) []Shadow {
+	var shadows []Shadow
+	reported := make(map[string]bool)
+
+	for g := range resources.Gateways {
+		gw := &resources.Gateways[g]
+		for l, listener := range gw.Spec.Listeners {
+			groups := make(map[string][]RouteMatch)
+			for i := range resources.HTTPRoutes {
+				route := &resources.HTTPRoutes[i]
+				if !AttachesTo(resources, route, gw, l) {
+					continue
+				}
+				for _, effective := range EffectiveHostnames(listener, route) {
+					if effective == "" {
+						effective = "*"
+					}
+					if receivingListener(gw, l, effective) != l {
+						continue
+					}
+					groups[effective] = append(groups[effective], RouteMatches(route)...)
+				}
+			}
+
+			for _, host := range sortedGroupKeys(groups) {
+				matches := groups[host]
+				sort.SliceStable(matches, func(i, j int) bool {
+					return precedes(matches[i], matches[j])
+				})
+
+				for j, loser := range matches {
+					for _, winner := range matches[:j] {
+						if winner.Route == loser.Route && winner.Rule == loser.Rule {
+							continue
+						}
+						if !covers(winner.Match, loser.Match) {
+							continue
+						}
+
+						key := fmt.Sprintf("%s/%s/%d/%d@%s", loser.Route.Namespace, loser.Route.Name, loser.Rule, loser.Index, host)
+						if !reported[key] {
+							reported[key] = true
+							shadows = append(shadows, Shadow{
+								Gateway:   gw,
+								Listener:  l,
+								Hostname:  host,
+								Winner:    winner,
+								Loser:     loser,
+								Identical: covers(loser.Match, winner.Match),
+								Reason:    precedenceReason(winner, loser),
+							})
+						}
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return shadows
+}
+
+// checkRouteShadowing reports route rule matches that never receive traffic because another match
+// wins for every request they match
+func checkRouteShadowing(resources *types.ResourceCollection) []types.Finding {
+	var findings []types.Finding
+
+	for _, shadow := range Shadowing(resources) {
+		loser, winner := shadow.Loser, shadow.Winner
+		kind := "is shadowed by"
+		if shadow.Identical {
+			kind = "is identical to"
+		}
+		findings = append(findings, types.Finding{
+			Severity: types.SeverityWarning,
+			Resource: routeRef(loser.Route),
+			NodeID:   string(loser.Route.UID),
+			Message: fmt.Sprintf("rules[%d].matches[%d] (%s) on %s via listener %s/%s#%s %s HTTPRoute %s/%s rules[%d].matches[%d], which wins with %s.",
+				loser.Rule, loser.Index, DescribeRouteMatch(loser.Match), shadow.Hostname,
+				shadow.Gateway.Namespace, shadow.Gateway.Name, shadow.Gateway.Spec.Listeners[shadow.Listener].Name,
+				kind, winner.Route.Namespace, winner.Route.Name, winner.Rule, winner.Index, shadow.Reason),
+			Remediation: "Remove the unreachable match, or make it more specific than the winning one (a longer path, a method, or more header or query matches).",
+		})
+	}
+
+	return findings
+}
+
+// AttachesTo reports whether a route attaches to a listener of a Gateway through any parentRef
+func AttachesTo(resources *types.ResourceCollection, route *gatewayv1.HTTPRoute, gw *gatewayv1.Gateway, listener int) bool {
+	for _, ref := range route.Spec.ParentRefs {
+		if ParentGateway(resources, route.Namespace, ref) != gw {
+			continue
+		}
+		attached, _ := AttachedListeners(gw, route, ref)
+		for _, i := range attached {
+			if i == listener {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// receivingListener returns the listener that receives requests for an exact hostname among
+// those sharing the port and protocol of listener l: the one with the most specific matching
+// hostname. Wildcard hostnames are left to l.
+func receivingListener(gw *gatewayv1.Gateway, l int, host string) int {
+	if host == "*" || hostname.IsWildcard(host) {
+		return l
+	}
+
+	best, bestScore := l, -1
+	for i, listener := range gw.Spec.Listeners {
+		if listener.Port != gw.Spec.Listeners[l].Port || listener.Protocol != gw.Spec.Listeners[l].Protocol {
+			continue
+		}
+		score, ok := hostname.Specificity(listenerHostname(listener), host)
+		if ok && score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// covers reports whether every request that matches b also matches a
+func covers(a, b gatewayv1.HTTPRouteMatch) bool {
+	if !pathCovers(a, b) {
+		return false
+	}
+	if a.Method != nil && (b.Method == nil || *a.Method != *b.Method) {
+		return false
+	}
+
+	for _, header := range a.Headers {
+		found := false
+		for _, other := range b.Headers {
+			if strings.EqualFold(string(header.Name), string(other.Name)) && headerType(header) == headerType(other) && header.Value == other.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for _, param := range a.QueryParams {
+		found := false
+		for _, other := range b.QueryParams {
+			if param.Name == other.Name && queryType(param) == queryType(other) && param.Value == other.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pathCovers reports whether every path b matches is also matched by a. Regular expressions only
+// cover identical expressions.
+func pathCovers(a, b gatewayv1.HTTPRouteMatch) bool {
+	aType, aValue := PathMatch(a)
+	bType, bValue := PathMatch(b)
+
+	switch aType {
+	case gatewayv1.PathMatchExact:
+		return bType == gatewayv1.PathMatchExact && aValue == bValue
+	case gatewayv1.PathMatchRegularExpression:
+		return bType == gatewayv1.PathMatchRegularExpression && aValue == bValue
+	}
+
+	prefix := strings.TrimSuffix(aValue, "/")
+	if prefix == "" {
+		return true
+	}
+	if bType == gatewayv1.PathMatchRegularExpression {
+		return false
+	}
+	path := bValue
+	if bType == gatewayv1.PathMatchPathPrefix {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// headerType returns a header match type, defaulting to Exact
+func headerType(match gatewayv1.HTTPHeaderMatch) gatewayv1.HeaderMatchType {
+	if match.Type == nil {
+		return gatewayv1.HeaderMatchExact
+	}
+	return *match.Type
+}
+
+// queryType returns a query parameter match type, defaulting to Exact
+func queryType(match gatewayv1.HTTPQueryParamMatch) gatewayv1.QueryParamMatchType {
+	if match.Type == nil {
+		return gatewayv1.QueryParamMatchExact
+	}
+	return *match.Type
+}
+
+// DescribeRouteMatch formats the conditions of a match, e.g. "PathPrefix /api, GET"
+func DescribeRouteMatch(match gatewayv1.HTTPRouteMatch) string {
+	matchType, value := PathMatch(match)
+	parts := []string{fmt.Sprintf("%s %s", matchType, value)}
+	if match.Method != nil {
+		parts = append(parts, string(*match.Method))
+	}
+	for _, header := range match.Headers {
+		parts = append(parts, fmt.Sprintf("header %s=%s", header.Name, header.Value))
+	}
+	for _, param := range match.QueryParams {
+		parts = append(parts, fmt.Sprintf("query %s=%s", param.Name, param.Value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortedGroupKeys returns the hostnames of the groups in order, for stable output
+func sortedGroupKeys(groups map[string][]RouteMatch) []string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,213 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestCovers(t *testing.T) {
+	prefix := func(value string) gatewayv1.HTTPRouteMatch {
+		return testutil.PathMatch(gatewayv1.PathMatchPathPrefix, value)
+	}
+	exact := func(value string) gatewayv1.HTTPRouteMatch {
+		return testutil.PathMatch(gatewayv1.PathMatchExact, value)
+	}
+	regex := func(value string) gatewayv1.HTTPRouteMatch {
+		return testutil.PathMatch(gatewayv1.PathMatchRegularExpression, value)
+	}
+
+	tests := []struct {
+		name string
+		a, b gatewayv1.HTTPRouteMatch
+		want bool
+	}{
+		{"root prefix covers everything", prefix("/"), exact("/anything"), true},
+		{"root prefix covers a regex", prefix("/"), regex("/a.*"), true},
+		{"default path covers a prefix", gatewayv1.HTTPRouteMatch{}, prefix("/api"), true},
+		{"prefix covers itself", prefix("/api"), prefix("/api"), true},
+		{"prefix ignores a trailing slash", prefix("/api/"), prefix("/api"), true},
+		{"prefix covers a longer prefix", prefix("/api"), prefix("/api/v1"), true},
+		{"prefix covers an exact path below it", prefix("/api"), exact("/api/users"), true},
+		{"prefix matches whole segments only", prefix("/api"), prefix("/apiv2"), false},
+		{"prefix does not cover a shorter prefix", prefix("/api/v1"), prefix("/api"), false},
+		{"prefix does not cover a regex", prefix("/api"), regex("/api/.*"), false},
+		{"exact covers the same path", exact("/login"), exact("/login"), true},
+		{"exact does not cover a prefix", exact("/login"), prefix("/login"), false},
+		{"exact does not cover another path", exact("/login"), exact("/logout"), false},
+		{"regex covers the same expression", regex("/a.*"), regex("/a.*"), true},
+		{"regex does not cover another expression", regex("/a.*"), regex("/ab.*"), false},
+		{"no method covers a method", prefix("/"), withMethod(prefix("/"), gatewayv1.HTTPMethodGet), true},
+		{"a method does not cover no method", withMethod(prefix("/"), gatewayv1.HTTPMethodGet), prefix("/"), false},
+		{"a method covers the same method", withMethod(prefix("/"), gatewayv1.HTTPMethodGet), withMethod(prefix("/x"), gatewayv1.HTTPMethodGet), true},
+		{"a method does not cover another", withMethod(prefix("/"), gatewayv1.HTTPMethodGet), withMethod(prefix("/"), gatewayv1.HTTPMethodPost), false},
+		{"fewer headers cover more", withHeaders(prefix("/"), "x-a"), withHeaders(prefix("/"), "x-a", "x-b"), true},
+		{"header names are case-insensitive", withHeaders(prefix("/"), "X-A"), withHeaders(prefix("/"), "x-a"), true},
+		{"more headers do not cover fewer", withHeaders(prefix("/"), "x-a", "x-b"), withHeaders(prefix("/"), "x-a"), false},
+		{"fewer query parameters cover more", withQueryParams(prefix("/"), "q"), withQueryParams(prefix("/"), "q", "r"), true},
+		{"query parameters must be present", withQueryParams(prefix("/"), "q"), prefix("/"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := covers(tt.a, tt.b); got != tt.want {
+				t.Errorf("covers(%s; %s) = %v, want %v", DescribeRouteMatch(tt.a), DescribeRouteMatch(tt.b), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoversHeaderValueAndType(t *testing.T) {
+	regexType := gatewayv1.HeaderMatchRegularExpression
+	a := gatewayv1.HTTPRouteMatch{Headers: []gatewayv1.HTTPHeaderMatch{{Name: "x-env", Value: "prod"}}}
+
+	tests := []struct {
+		name   string
+		header gatewayv1.HTTPHeaderMatch
+		want   bool
+	}{
+		{"same value", gatewayv1.HTTPHeaderMatch{Name: "x-env", Value: "prod"}, true},
+		{"other value", gatewayv1.HTTPHeaderMatch{Name: "x-env", Value: "dev"}, false},
+		{"regular expression", gatewayv1.HTTPHeaderMatch{Name: "x-env", Type: &regexType, Value: "prod"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := gatewayv1.HTTPRouteMatch{Headers: []gatewayv1.HTTPHeaderMatch{tt.header}}
+			if got := covers(a, b); got != tt.want {
+				t.Errorf("covers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// shadowingResources returns a Gateway with two HTTP listeners, one for *.example.com and one
+// for api.example.com, and the given routes attached to it
+func shadowingResources(routes ...*gatewayv1.HTTPRoute) *types.ResourceCollection {
+	resources := &types.ResourceCollection{Gateways: []gatewayv1.Gateway{testutil.Gateway("infra", "gw",
+		testutil.Listener("wildcard", gatewayv1.HTTPProtocolType, 80, "*.example.com"),
+		testutil.Listener("api", gatewayv1.HTTPProtocolType, 80, "api.example.com"),
+	)}}
+	for _, route := range routes {
+		route.Spec.ParentRefs = []gatewayv1.ParentReference{testutil.ParentRef("infra", "gw")}
+		resources.HTTPRoutes = append(resources.HTTPRoutes, *route)
+	}
+	return resources
+}
+
+// shadowingRoute returns a route for the hostnames whose rules each have one match
+func shadowingRoute(namespace, name string, minute int, hostnames []string, matches ...gatewayv1.HTTPRouteMatch) *gatewayv1.HTTPRoute {
+	route := createdRoute(namespace, name, minute)
+	route.Spec.Hostnames = testutil.Hostnames(hostnames...)
+	for _, match := range matches {
+		route.Spec.Rules = append(route.Spec.Rules, gatewayv1.HTTPRouteRule{Matches: []gatewayv1.HTTPRouteMatch{match}})
+	}
+	return route
+}
+
+func TestShadowing(t *testing.T) {
+	prefix := func(value string) gatewayv1.HTTPRouteMatch {
+		return testutil.PathMatch(gatewayv1.PathMatchPathPrefix, value)
+	}
+
+	type shadow struct {
+		winner, loser string // namespace/name rules[i]
+		listener      string
+		hostname      string
+		identical     bool
+		reason        string
+	}
+
+	tests := []struct {
+		name   string
+		routes []*gatewayv1.HTTPRoute
+		want   []shadow
+	}{
+		{
+			name: "newer identical match on the same hostname",
+			routes: []*gatewayv1.HTTPRoute{
+				shadowingRoute("a", "old", 0, []string{"www.example.com"}, prefix("/")),
+				shadowingRoute("b", "new", 1, []string{"www.example.com"}, prefix("/")),
+			},
+			want: []shadow{{"a/old rules[0]", "b/new rules[0]", "wildcard", "www.example.com", true, "an older creation timestamp"}},
+		},
+		{
+			name: "a later rule repeating an earlier one",
+			routes: []*gatewayv1.HTTPRoute{
+				shadowingRoute("a", "r", 0, []string{"www.example.com"}, prefix("/api"), prefix("/api/")),
+			},
+			want: []shadow{{"a/r rules[0]", "a/r rules[1]", "wildcard", "www.example.com", true, "an earlier rule"}},
+		},
+		{
+			name: "an earlier rule with a method leaves the later rule reachable",
+			routes: []*gatewayv1.HTTPRoute{
+				shadowingRoute("a", "r", 0, []string{"www.example.com"}, withMethod(prefix("/api"), gatewayv1.HTTPMethodGet), prefix("/api")),
+			},
+			want: nil,
+		},
+		{
+			name: "more specific match is not shadowed",
+			routes: []*gatewayv1.HTTPRoute{
+				shadowingRoute("a", "catchall", 0, []string{"www.example.com"}, prefix("/")),
+				shadowingRoute("b", "api", 1, []string{"www.example.com"}, prefix("/api")),
+			},
+			want: nil,
+		},
+		{
+			name: "a method match wins over the same path without one",
+			routes: []*gatewayv1.HTTPRoute{
+				shadowingRoute("a", "get", 1, []string{"www.example.com"}, withMethod(prefix("/api"), gatewayv1.HTTPMethodGet)),
+				shadowingRoute("b", "any", 0, []string{"www.example.com"}, prefix("/api")),
+			},
+			want: nil,
+		},
+		{
+			name: "different hostnames do not shadow each other",
+			routes: []*gatewayv1.HTTPRoute{
+				shadowingRoute("a", "www", 0, []string{"www.example.com"}, prefix("/")),
+				shadowingRoute("b", "shop", 1, []string{"shop.example.com"}, prefix("/")),
+			},
+			want: nil,
+		},
+		{
+			name: "routes compete on the listener that receives the hostname",
+			routes: []*gatewayv1.HTTPRoute{
+				shadowingRoute("a", "first", 0, []string{"api.example.com"}, prefix("/")),
+				shadowingRoute("b", "second", 1, []string{"api.example.com"}, prefix("/v1")),
+				shadowingRoute("c", "third", 2, nil, prefix("/v1")),
+			},
+			want: []shadow{{"b/second rules[0]", "c/third rules[0]", "api", "api.example.com", true, "an older creation timestamp"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shadows := Shadowing(shadowingResources(tt.routes...))
+
+			var got []shadow
+			for _, s := range shadows {
+				got = append(got, shadow{
+					winner:    fmt.Sprintf("%s/%s rules[%d]", s.Winner.Route.Namespace, s.Winner.Route.Name, s.Winner.Rule),
+					loser:     fmt.Sprintf("%s/%s rules[%d]", s.Loser.Route.Namespace, s.Loser.Route.Name, s.Loser.Rule),
+					listener:  string(s.Gateway.Spec.Listeners[s.Listener].Name),
+					hostname:  s.Hostname,
+					identical: s.Identical,
+					reason:    s.Reason,
+				})
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Shadowing returned %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("shadow %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/audit"
+	"gwapi-graph/internal/diff"
+	"gwapi-graph/internal/k8s"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// userHeaders are the headers set by authenticating proxies (oauth-proxy, oauth2-proxy,
+// kube-rbac-proxy) in front of the visualizer, checked in order. Any client can send them, so they
+// are only trusted when the handler is configured with WithTrustedProxyHeaders.
+var userHeaders = []string{
+	"X-Forwarded-User",
+	"X-Forwarded-Email",
+	"X-Auth-Request-User",
+	"X-Auth-Request-Email",
+	"X-Remote-User",
+}
+
+// GetAudit returns recorded changes, newest first. Supported query filters: user, action, type,
+// namespace, name, result, since and until (RFC 3339) and limit.
+func (h *Handler) GetAudit(c *gin.Context) {
+	if h.auditLog == nil {
+		c.JSON(http.StatusOK, []audit.Entry{})
+		return
+	}
+
+	filter := audit.Filter{
+		User:      c.Query("user"),
+		Action:    c.Query("action"),
+		Type:      c.Query("type"),
+		Namespace: c.Query("namespace"),
+		Name:      c.Query("name"),
+		Result:    c.Query("result"),
+		Limit:     100,
+	}
+
+	for param, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + " timestamp, expected RFC 3339"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	c.JSON(http.StatusOK, h.auditLog.Query(filter))
+}
+
+// currentObject fetches an object as it is before a change, for the audit diff. It returns nil
+// when auditing is disabled or the object cannot be read.
+func (h *Handler) currentObject(ctx context.Context, resourceType, namespace, name string) map[string]interface{} {
+	if h.auditLog == nil || h.offline() {
+		return nil
+	}
+
+	obj, err := h.k8sClient.GetResource(ctx, resourceType, namespace, name)
+	if err != nil {
+		return nil
+	}
+	return obj.Object
+}
+
+// recordAudit records the outcome of a mutating call. after may be a typed object, an
+// unstructured object or nil.
+func (h *Handler) recordAudit(c *gin.Context, action, resourceType, namespace, name string, before map[string]interface{}, after interface{}, err error) {
+	if h.auditLog == nil {
+		return
+	}
+
+	before = auditObject(resourceType, before)
+	afterObject := auditObject(resourceType, toObjectMap(after))
+
+	entry := audit.Entry{
+		User:     h.requestUser(c),
+		SourceIP: c.ClientIP(),
+		Action:   action,
+		Resource: audit.Resource{
+			Type:      resourceType,
+			Namespace: namespace,
+			Name:      name,
+		},
+		Result: audit.ResultSuccess,
+	}
+
+	if gvk, ok := k8s.GroupVersionKind(resourceType); ok {
+		entry.Resource.APIVersion = gvk.GroupVersion().String()
+		entry.Resource.Kind = gvk.Kind
+	}
+
+	for _, obj := range []map[string]interface{}{afterObject, before} {
+		if uid, _, _ := unstructured.NestedString(obj, "metadata", "uid"); uid != "" {
+			entry.Resource.UID = uid
+			break
+		}
+	}
+
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Error = err.Error()
+	} else {
+		entry.Changes = diff.Fields(before, afterObject)
+	}
+
+	h.auditLog.Record(entry)
+}
+
+// auditObject puts an object in the shape the audit diff compares: converted to its typed form
+// and back, so that the dynamic object read before a change and the typed one a write returns
+// carry the same fields, and without status, which controllers change and users do not. The
+// object passed in is not modified.
+func auditObject(resourceType string, obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	var shaped interface{} = obj
+	if typed, err := typedResource(resourceType, &unstructured.Unstructured{Object: obj}); err == nil {
+		shaped = typed
+	}
+	object := toObjectMap(shaped)
+	delete(object, "status")
+	return object
+}
+
+// requestUser identifies the caller from the headers or basic auth user an authenticating proxy
+// sets, when proxies are trusted, falling back to the client address. The server checks no
+// credentials itself, so neither is taken from a client directly.
+func (h *Handler) requestUser(c *gin.Context) string {
+	if h.trustProxyHeaders {
+		for _, header := range userHeaders {
+			if user := strings.TrimSpace(c.GetHeader(header)); user != "" {
+				return user
+			}
+		}
+		if user, _, ok := c.Request.BasicAuth(); ok && user != "" {
+			return user
+		}
+	}
+	return "anonymous@" + c.ClientIP()
+}
+
+// toObjectMap converts a typed or unstructured object into a JSON-compatible map. Nil objects
+// (including typed nil pointers) become a nil map.
+func toObjectMap(obj interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil
+	}
+	return object
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"testing"
+
+	"gwapi-graph/internal/diff"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestAuditObjectDiff(t *testing.T) {
+	// As read through the dynamic client before the change
+	before := map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata": map[string]interface{}{
+			"namespace":       "infra",
+			"name":            "gw",
+			"uid":             "gw-uid",
+			"resourceVersion": "1",
+		},
+		"spec": map[string]interface{}{
+			"gatewayClassName": "example",
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "http", "port": int64(80), "protocol": "HTTP"},
+			},
+		},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Programmed", "status": "False", "reason": "Pending"},
+			},
+		},
+	}
+
+	// As returned, typed, by the write, with a new label and a status the controller updated
+	after := &gatewayv1.Gateway{
+		TypeMeta: metav1.TypeMeta{APIVersion: "gateway.networking.k8s.io/v1", Kind: "Gateway"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "infra",
+			Name:            "gw",
+			UID:             "gw-uid",
+			ResourceVersion: "2",
+			Labels:          map[string]string{"team": "a"},
+		},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "example",
+			Listeners:        []gatewayv1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType}},
+		},
+		Status: gatewayv1.GatewayStatus{
+			Conditions: []metav1.Condition{{Type: "Programmed", Status: metav1.ConditionTrue, Reason: "Programmed"}},
+		},
+	}
+
+	changes := diff.Fields(auditObject("gateway", before), auditObject("gateway", toObjectMap(after)))
+	if len(changes) != 1 || changes[0].Path != "metadata.labels" || changes[0].Type != "added" {
+		t.Errorf("changes = %+v, want only metadata.labels added", changes)
+	}
+	if _, ok := before["status"]; !ok {
+		t.Errorf("auditObject removed status from the object passed in")
+	}
+}
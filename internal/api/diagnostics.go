@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiagnosticsResponse is the body returned by /api/diagnostics
+type DiagnosticsResponse struct {
+	Summary  map[types.Severity]int `json:"summary"`
+	Findings []types.Finding        `json:"findings"`
+}
+
+// GetDiagnostics runs the analyzer over the resources and returns its findings. The severity
+// (minimum), namespace, kind and rule query parameters filter them; ?at=<RFC 3339 time> analyzes
+// a snapshot instead of the current state.
+func (h *Handler) GetDiagnostics(c *gin.Context) {
+	minimum := types.Severity(strings.ToLower(c.Query("severity")))
+	if minimum != "" && minimum.Rank() == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid severity, expected error, warning or info"})
+		return
+	}
+
+	resources, ok := h.resourcesFor(c)
+	if !ok {
+		return
+	}
+
+	namespace, kind, rule := c.Query("namespace"), c.Query("kind"), c.Query("rule")
+	response := DiagnosticsResponse{
+		Summary: map[types.Severity]int{
+			types.SeverityError:   0,
+			types.SeverityWarning: 0,
+			types.SeverityInfo:    0,
+		},
+		Findings: []types.Finding{},
+	}
+	for _, finding := range h.analyzer.Run(resources) {
+		if (minimum != "" && finding.Severity.Rank() < minimum.Rank()) ||
+			(namespace != "" && finding.Resource.Namespace != namespace) ||
+			(kind != "" && !strings.EqualFold(finding.Resource.Kind, kind)) ||
+			(rule != "" && finding.Rule != rule) {
+			continue
+		}
+		response.Summary[finding.Severity]++
+		response.Findings = append(response.Findings, finding)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
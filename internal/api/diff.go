@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/diff"
+	"gwapi-graph/internal/snapshot"
+	"gwapi-graph/internal/source"
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDiffManifestBytes limits the manifests a diff request may upload
+const maxDiffManifestBytes = 10 << 20
+
+// GetDiff compares the graph at two points, given by the from and to query parameters. Each is
+// "live", "snapshot:<id>" or an RFC 3339 time (the latest snapshot at or before it); to defaults
+// to live. A POST with a body of YAML/JSON manifests compares from against the manifests instead.
+// Pass ?format=markdown for a summary to attach to a change review.
+func (h *Handler) GetDiff(c *gin.Context) {
+	from := c.Query("from")
+	to := c.DefaultQuery("to", "live")
+	if from == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fromResources, status, err := h.resolveRef(ctx, from)
+	if err != nil {
+		c.JSON(status, gin.H{"error": fmt.Sprintf("from: %v", err)})
+		return
+	}
+
+	var toResources *types.ResourceCollection
+	if c.Request.Method == http.MethodPost {
+		to = "manifests"
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxDiffManifestBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("manifests exceed %d bytes", tooLarge.Limit)})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("failed to read request body: %v", err)})
+			return
+		}
+		manifests, err := source.NewManifestReader("request body", bytes.NewReader(body))
+		if err == nil {
+			toResources, err = manifests.Fetch(ctx)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else if toResources, status, err = h.resolveRef(ctx, to); err != nil {
+		c.JSON(status, gin.H{"error": fmt.Sprintf("to: %v", err)})
+		return
+	}
+
+	result := h.Diff(fromResources, toResources, from, to)
+
+	if c.Query("format") == "markdown" {
+		var out bytes.Buffer
+		if err := diff.WriteMarkdown(&out, result); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", out.Bytes())
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Diff builds the graphs of two resource collections and compares them, including field-level
+// spec changes of the resources behind changed nodes
+func (h *Handler) Diff(from, to *types.ResourceCollection, fromName, toName string) *diff.GraphDiff {
+	fromGraph := h.buildGraph(from)
+	toGraph := h.buildGraph(to)
+
+	result := diff.Graphs(fromGraph, toGraph, objectMaps(from), objectMaps(to))
+	result.From = fromName
+	result.To = toName
+	return result
+}
+
+// resolveRef loads the resources a diff reference points to, returning the HTTP status to
+// respond with when it cannot be resolved
+func (h *Handler) resolveRef(ctx context.Context, ref string) (*types.ResourceCollection, int, error) {
+	if ref == "live" {
+		// Unlike the graph, a diff must not take resources that could not be read for removed ones
+		resources, err := h.source.Fetch(ctx)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return resources, http.StatusOK, nil
+	}
+
+	if h.snapshots == nil {
+		return nil, http.StatusNotFound, errSnapshotsDisabled
+	}
+
+	var resources *types.ResourceCollection
+	var err error
+	if id, ok := strings.CutPrefix(ref, "snapshot:"); ok {
+		resources, _, err = h.snapshots.Load(id)
+	} else if t, parseErr := time.Parse(time.RFC3339, ref); parseErr == nil {
+		resources, _, err = h.snapshots.At(t)
+	} else {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid reference %q, expected live, snapshot:<id> or an RFC 3339 time", ref)
+	}
+
+	if errors.Is(err, snapshot.ErrNotFound) {
+		return nil, http.StatusNotFound, err
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return resources, http.StatusOK, nil
+}
+
+// objectMaps converts every resource of a collection into a JSON-compatible map keyed by
+// diff.ObjectKey
+func objectMaps(resources *types.ResourceCollection) map[string]map[string]interface{} {
+	objects := make(map[string]map[string]interface{})
+	for key, obj := range resourceDetails(resources) {
+		objects[key] = toObjectMap(obj)
+	}
+	return objects
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/snapshot"
+	"gwapi-graph/internal/source"
+	"gwapi-graph/internal/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// liveManifests stands in for the cluster as the live state
+const liveManifests = `apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: live
+  namespace: infra
+spec:
+  gatewayClassName: example
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+`
+
+// gatewayNamed returns a collection holding a single Gateway
+func gatewayNamed(name string) *types.ResourceCollection {
+	return &types.ResourceCollection{Gateways: []gatewayv1.Gateway{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: name},
+	}}}
+}
+
+func TestResolveRef(t *testing.T) {
+	store, err := snapshot.NewStore(t.TempDir(), snapshot.Retention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first, err := store.Save(gatewayNamed("first"), start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Save(gatewayNamed("second"), start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	live, err := source.NewManifestReader("live", strings.NewReader(liveManifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(nil, WithSource(live), WithSnapshotStore(store))
+
+	tests := []struct {
+		ref     string
+		status  int
+		gateway string
+	}{
+		{"live", http.StatusOK, "live"},
+		{"snapshot:" + first.ID, http.StatusOK, "first"},
+		{"2024-01-01T12:30:00Z", http.StatusOK, "first"},
+		{"2024-01-01T14:30:00+01:00", http.StatusOK, "second"},
+		{"2024-01-01T11:00:00Z", http.StatusNotFound, ""},
+		{"snapshot:20240101T000000.000Z", http.StatusNotFound, ""},
+		{"snapshot:latest", http.StatusNotFound, ""},
+		{"yesterday", http.StatusBadRequest, ""},
+		{"2024-01-01", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		resources, status, err := h.resolveRef(context.Background(), tt.ref)
+		if status != tt.status {
+			t.Errorf("resolveRef(%q) status = %d (%v), want %d", tt.ref, status, err, tt.status)
+			continue
+		}
+		if tt.gateway == "" {
+			if err == nil {
+				t.Errorf("resolveRef(%q) succeeded, want an error", tt.ref)
+			}
+			continue
+		}
+		if err != nil || len(resources.Gateways) != 1 || resources.Gateways[0].Name != tt.gateway {
+			t.Errorf("resolveRef(%q) = %v, %v, want Gateway %s", tt.ref, resources, err, tt.gateway)
+		}
+	}
+}
+
+func TestResolveRefWithoutSnapshots(t *testing.T) {
+	h := NewHandler(nil, WithSource(&source.Manifests{}))
+	for _, ref := range []string{"snapshot:20240101T000000.000Z", "2024-01-01T12:00:00Z"} {
+		if _, status, err := h.resolveRef(context.Background(), ref); status != http.StatusNotFound || err != errSnapshotsDisabled {
+			t.Errorf("resolveRef(%q) without snapshots = %d, %v, want %d, %v", ref, status, err, http.StatusNotFound, errSnapshotsDisabled)
+		}
+	}
+}
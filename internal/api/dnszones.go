@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DNSZonesResponse is the body returned by /api/dnszones
+type DNSZonesResponse struct {
+	Rules      []ZoneRule      `json:"rules"` // The configured zone rules, in the order they are tried
+	Zones      []types.DNSZone `json:"zones"`
+	Placements []NodePlacement `json:"placements"`
+}
+
+// ZoneRule describes a configured zone rule
+type ZoneRule struct {
+	Rule  string `json:"rule"`  // rules[<index>]
+	Match string `json:"match"` // e.g. "suffix apps.example.com"
+	Zone  string `json:"zone"`
+}
+
+// NodePlacement explains the DNS zones of one graph node
+type NodePlacement struct {
+	NodeID      string              `json:"nodeId"`
+	Kind        string              `json:"kind"`
+	Namespace   string              `json:"namespace,omitempty"`
+	Name        string              `json:"name"`
+	PrimaryZone string              `json:"primaryZone"`
+	Zones       []string            `json:"zones"` // Zones shown in the graph that contain the node
+	Placement   types.ZonePlacement `json:"placement"`
+}
+
+// GetDNSZones returns the DNS zones of the graph and, for every node in a zone, the hostname and
+// rule that placed it there. ?zone=<name> only explains the members of one zone; ?at=<RFC 3339
+// time> explains a snapshot instead of the current state.
+func (h *Handler) GetDNSZones(c *gin.Context) {
+	resources, ok := h.resourcesFor(c)
+	if !ok {
+		return
+	}
+	graph := h.buildGraph(resources)
+
+	response := DNSZonesResponse{
+		Rules:      []ZoneRule{},
+		Zones:      graph.DNSZones,
+		Placements: []NodePlacement{},
+	}
+	for i, rule := range h.zones.Config().Rules {
+		response.Rules = append(response.Rules, ZoneRule{
+			Rule:  fmt.Sprintf("rules[%d]", i),
+			Match: rule.String(),
+			Zone:  rule.Zone,
+		})
+	}
+
+	members := make(map[string][]string) // node ID -> zones shown in the graph
+	for _, zone := range graph.DNSZones {
+		for _, nodeID := range zone.Nodes {
+			members[nodeID] = append(members[nodeID], zone.Name)
+		}
+	}
+
+	filter := c.Query("zone")
+	for _, node := range graph.Nodes {
+		if node.ZonePlacement == nil {
+			continue
+		}
+		if filter != "" && !containsString(members[node.ID], filter) {
+			continue
+		}
+		response.Placements = append(response.Placements, NodePlacement{
+			NodeID:      node.ID,
+			Kind:        node.Kind,
+			Namespace:   node.Namespace,
+			Name:        node.Name,
+			PrimaryZone: node.DNSZone,
+			Zones:       append([]string{}, members[node.ID]...),
+			Placement:   *node.ZonePlacement,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/export"
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportTitle is the page title of HTML exports
+const exportTitle = "Gateway API Graph Visualizer"
+
+// ExportHTML returns the current graph as a single HTML file that opens offline, with the same
+// layouts and detail panels as the live UI and editing disabled. Pass ?details=true to embed the
+// resource details shown when clicking a node.
+func (h *Handler) ExportHTML(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	snapshot, err := h.Snapshot(ctx, c.Query("details") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var page bytes.Buffer
+	if err := export.WriteHTML(&page, h.webDir, exportTitle, snapshot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("gwapi-graph-%s.html", snapshot.GeneratedAt.Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// Snapshot builds the data embedded in an HTML export, optionally including the resource
+// objects behind each node
+func (h *Handler) Snapshot(ctx context.Context, includeDetails bool) (*export.Snapshot, error) {
+	resources, err := h.fetchAllResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &export.Snapshot{
+		GeneratedAt: time.Now().UTC(),
+		Graph:       h.buildGraph(resources),
+	}
+	if includeDetails {
+		snapshot.Details = resourceDetails(resources)
+	}
+	return snapshot, nil
+}
+
+// resourceDetails collects every resource of the collection as shown in the detail panel,
+// keyed by export.DetailsKey
+func resourceDetails(resources *types.ResourceCollection) map[string]interface{} {
+	details := make(map[string]interface{})
+	add := func(resourceType, nodeType, namespace, name string, obj interface{}) {
+		prepareForOutput(resourceType, obj, false)
+		details[export.DetailsKey(nodeType, namespace, name)] = obj
+	}
+
+	for i := range resources.GatewayClasses {
+		gc := &resources.GatewayClasses[i]
+		add("gatewayclass", "GatewayClass", "", gc.Name, gc)
+	}
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		add("gateway", "Gateway", gw.Namespace, gw.Name, gw)
+	}
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		add("httproute", "HTTPRoute", route.Namespace, route.Name, route)
+	}
+	for i := range resources.ReferenceGrants {
+		grant := &resources.ReferenceGrants[i]
+		add("referencegrant", "ReferenceGrant", grant.Namespace, grant.Name, grant)
+	}
+	for i := range resources.Services {
+		svc := &resources.Services[i]
+		add("service", "Service", svc.Namespace, svc.Name, svc)
+	}
+	for i := range resources.DNSRecords {
+		record := &resources.DNSRecords[i]
+		add("dnsrecord", "DNSRecord", record.GetNamespace(), record.GetName(), record)
+	}
+	for i := range resources.DNSEndpoints {
+		endpoint := &resources.DNSEndpoints[i]
+		add("dnsendpoint", "DNSEndpoint", endpoint.GetNamespace(), endpoint.GetName(), endpoint)
+	}
+	for i := range resources.Policies {
+		obj := &resources.Policies[i]
+		add(strings.ToLower(obj.GetKind()), obj.GetKind(), obj.GetNamespace(), obj.GetName(), obj)
+	}
+
+	return details
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/source"
+)
+
+const exportManifests = `apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw
+  namespace: infra
+spec:
+  gatewayClassName: example
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+---
+apiVersion: kuadrant.io/v1
+kind: RateLimitPolicy
+metadata:
+  name: limits
+  namespace: infra
+spec:
+  targetRef:
+    group: gateway.networking.k8s.io
+    kind: Gateway
+    name: gw
+---
+apiVersion: externaldns.k8s.io/v1alpha1
+kind: DNSEndpoint
+metadata:
+  name: records
+  namespace: infra
+spec:
+  endpoints:
+  - dnsName: www.example.com
+    recordType: A
+    targets: [10.0.0.1]
+`
+
+func TestSnapshotDetailsSurvivePrune(t *testing.T) {
+	manifests, err := source.NewManifestReader("manifests", strings.NewReader(exportManifests))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := NewHandler(nil, WithSource(manifests)).Snapshot(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	// Without a filter every node keeps the details of the object behind it
+	snapshot.Prune()
+	for _, key := range []string{"gateway/infra/gw", "ratelimitpolicy/infra/limits", "dnsendpoint/infra/records"} {
+		if _, ok := snapshot.Details[key]; !ok {
+			t.Errorf("details lack %s after Prune, have %d entries", key, len(snapshot.Details))
+		}
+	}
+}
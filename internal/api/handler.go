@@ -2,19 +2,38 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
 
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/audit"
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/dnszone"
+	"gwapi-graph/internal/hostname"
 	"gwapi-graph/internal/k8s"
+	"gwapi-graph/internal/ownership"
+	"gwapi-graph/internal/policy"
+	"gwapi-graph/internal/snapshot"
+	"gwapi-graph/internal/source"
 	"gwapi-graph/internal/types"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 var upgrader = websocket.Upgrader{
@@ -23,45 +42,169 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// errUnsupportedResourceType is returned for resource types the API does not handle
+var errUnsupportedResourceType = errors.New("unsupported resource type")
+
+// apiErrorStatus returns the HTTP status to answer an API server error with: 400 for a bad
+// request, 403 when the service account lacks permission, 404 for a missing object, 409 for a
+// conflict, such as fields owned by another field manager, 422 for an invalid object and 500
+// otherwise
+func apiErrorStatus(err error) int {
+	switch {
+	case apierrors.IsBadRequest(err):
+		return http.StatusBadRequest
+	case apierrors.IsForbidden(err):
+		return http.StatusForbidden
+	case apierrors.IsNotFound(err):
+		return http.StatusNotFound
+	case apierrors.IsConflict(err), apierrors.IsAlreadyExists(err):
+		return http.StatusConflict
+	case apierrors.IsInvalid(err):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // Handler handles API requests
 type Handler struct {
 	k8sClient *k8s.Client
+	source    source.Source
+	auditLog  *audit.Logger
+	snapshots *snapshot.Store
+	analyzer  *analysis.Analyzer
+	zones     *dnszone.Extractor
+	dns       []dnssource.Provider
+	policies  []types.PolicyKind
+	webDir    string
+
+	trustProxyHeaders bool // Take the audit user from authenticating proxy headers
+}
+
+// Option configures optional Handler dependencies
+type Option func(*Handler)
+
+// WithAuditLogger records every mutating call to the given audit logger
+func WithAuditLogger(auditLog *audit.Logger) Option {
+	return func(h *Handler) {
+		h.auditLog = auditLog
+	}
+}
+
+// WithTrustedProxyHeaders takes the audit user from the headers of an authenticating proxy. Only
+// enable it when every request reaches the visualizer through such a proxy.
+func WithTrustedProxyHeaders(trust bool) Option {
+	return func(h *Handler) {
+		h.trustProxyHeaders = trust
+	}
+}
+
+// WithSource reads resources from the given source instead of the cluster. k8sClient may be nil
+// when the source is not live, in which case the server runs read-only.
+func WithSource(src source.Source) Option {
+	return func(h *Handler) {
+		h.source = src
+	}
+}
+
+// WithSnapshotStore serves graph history from the given snapshot store
+func WithSnapshotStore(store *snapshot.Store) Option {
+	return func(h *Handler) {
+		h.snapshots = store
+	}
+}
+
+// WithAnalyzer replaces the analyzer whose findings are attached to graph nodes and served by
+// /api/diagnostics
+func WithAnalyzer(analyzer *analysis.Analyzer) Option {
+	return func(h *Handler) {
+		h.analyzer = analyzer
+	}
+}
+
+// WithZoneExtractor replaces the extractor that groups hostnames into DNS zones, e.g. to apply
+// zone rules or a newer public suffix list than the embedded one
+func WithZoneExtractor(zones *dnszone.Extractor) Option {
+	return func(h *Handler) {
+		h.zones = zones
+	}
+}
+
+// WithDNSSources replaces the providers whose DNS records become DNSRecord nodes; with none, the
+// graph has no DNS records
+func WithDNSSources(providers ...dnssource.Provider) Option {
+	return func(h *Handler) {
+		h.dns = append([]dnssource.Provider{}, providers...)
+	}
+}
+
+// WithPolicyKinds lists the objects of these policy kinds along with those of the CRDs labeled as
+// Gateway API policies, and sets whether they are direct or inherited
+func WithPolicyKinds(kinds ...types.PolicyKind) Option {
+	return func(h *Handler) {
+		h.policies = append([]types.PolicyKind{}, kinds...)
+	}
+}
+
+// WithWebDir sets the directory holding the web UI templates and static assets, used for HTML exports
+func WithWebDir(dir string) Option {
+	return func(h *Handler) {
+		h.webDir = dir
+	}
 }
 
 // NewHandler creates a new API handler
-func NewHandler(k8sClient *k8s.Client) *Handler {
-	return &Handler{
+func NewHandler(k8sClient *k8s.Client, opts ...Option) *Handler {
+	h := &Handler{
 		k8sClient: k8sClient,
+		webDir:    "web",
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.source == nil {
+		h.source = source.NewCluster(k8sClient, h.policies...)
+	}
+	if h.analyzer == nil {
+		h.analyzer = analysis.New(analysis.DefaultRules()...)
+	}
+	if h.zones == nil {
+		h.zones = dnszone.New(nil, nil)
+	}
+	if h.dns == nil {
+		h.dns = dnssource.Defaults()
+	}
+	return h
 }
 
-// GetResources returns all Gateway API resources
+// GetResources returns all Gateway API resources. With ?at=<RFC 3339 time> the resources are
+// read from the latest snapshot taken at or before that time.
 func (h *Handler) GetResources(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	resources, err := h.fetchAllResources(ctx)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	resources, ok := h.resourcesFor(c)
+	if !ok {
 		return
 	}
-
 	c.JSON(http.StatusOK, resources)
 }
 
-// GetGraph returns the graph data structure for visualization
+// GetGraph returns the graph data structure for visualization. With ?at=<RFC 3339 time> the graph
+// is built from the latest snapshot taken at or before that time.
 func (h *Handler) GetGraph(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	resources, ok := h.resourcesFor(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, h.buildGraph(resources))
+}
 
+// Graph fetches the resources from the configured source and builds the graph, for callers
+// outside the HTTP server such as the render command
+func (h *Handler) Graph(ctx context.Context) (*types.Graph, error) {
 	resources, err := h.fetchAllResources(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
-
-	graph := h.buildGraph(resources)
-	c.JSON(http.StatusOK, graph)
+	return h.buildGraph(resources), nil
 }
 
 // HandleWebSocket handles WebSocket connections for real-time updates
@@ -97,90 +240,20 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 	}
 }
 
-// fetchAllResources fetches all Gateway API Standard channel resources
-func (h *Handler) fetchAllResources(ctx context.Context) (*types.ResourceCollection, error) {
-	collection := &types.ResourceCollection{}
-
-	log.Printf("Starting to fetch Gateway API resources...")
-
-	// Fetch Gateway Classes
-	gatewayClasses, err := h.k8sClient.GetGatewayClasses(ctx)
-	if err != nil {
-		log.Printf("Error fetching Gateway Classes: %v", err)
-	} else {
-		log.Printf("Found %d Gateway Classes", len(gatewayClasses))
-		for _, gc := range gatewayClasses {
-			log.Printf("  - GatewayClass: %s", gc.Name)
-		}
-		collection.GatewayClasses = gatewayClasses
-	}
-
-	// Fetch Gateways
-	gateways, err := h.k8sClient.GetGateways(ctx)
-	if err != nil {
-		log.Printf("Error fetching Gateways: %v", err)
-	} else {
-		log.Printf("Found %d Gateways", len(gateways))
-		for _, gw := range gateways {
-			log.Printf("  - Gateway: %s/%s", gw.Namespace, gw.Name)
-		}
-		collection.Gateways = gateways
-	}
-
-	// Fetch HTTP Routes
-	httpRoutes, err := h.k8sClient.GetHTTPRoutes(ctx)
-	if err != nil {
-		log.Printf("Error fetching HTTP Routes: %v", err)
-	} else {
-		log.Printf("Found %d HTTP Routes", len(httpRoutes))
-		for _, route := range httpRoutes {
-			log.Printf("  - HTTPRoute: %s/%s", route.Namespace, route.Name)
-		}
-		collection.HTTPRoutes = httpRoutes
-	}
-
-	// Fetch Reference Grants
-	referenceGrants, err := h.k8sClient.GetReferenceGrants(ctx)
-	if err != nil {
-		log.Printf("Error fetching Reference Grants: %v", err)
-	} else {
-		log.Printf("Found %d Reference Grants", len(referenceGrants))
-		for _, grant := range referenceGrants {
-			log.Printf("  - ReferenceGrant: %s/%s", grant.Namespace, grant.Name)
-		}
-		collection.ReferenceGrants = referenceGrants
-	}
-
-	// Fetch DNSRecords
-	dnsRecords, err := h.k8sClient.GetDNSRecords(ctx)
-	if err != nil {
-		log.Printf("Error fetching DNSRecords: %v", err)
-	} else {
-		log.Printf("Found %d DNSRecords", len(dnsRecords))
-		for _, dns := range dnsRecords {
-			name, _, _ := unstructured.NestedString(dns.Object, "metadata", "name")
-			namespace, _, _ := unstructured.NestedString(dns.Object, "metadata", "namespace")
-			log.Printf("  - DNSRecord: %s/%s", namespace, name)
-		}
-		collection.DNSRecords = dnsRecords
-	}
+// Source returns the source the handler reads resources from
+func (h *Handler) Source() source.Source {
+	return h.source
+}
 
-	// Fetch Services
-	services, err := h.k8sClient.GetServices(ctx)
-	if err != nil {
-		log.Printf("Error fetching Services: %v", err)
-	} else {
-		log.Printf("Found %d Services", len(services))
-		for _, svc := range services {
-			log.Printf("  - Service: %s/%s", svc.Namespace, svc.Name)
-		}
-		collection.Services = services
+// fetchAllResources fetches all resources from the configured source. Resources that could not be
+// read are left out, as the source has logged them, so that the rest can still be shown.
+func (h *Handler) fetchAllResources(ctx context.Context) (*types.ResourceCollection, error) {
+	resources, err := h.source.Fetch(ctx)
+	var incomplete *source.IncompleteError
+	if errors.As(err, &incomplete) {
+		return resources, nil
 	}
-
-	log.Printf("Finished fetching resources. Total nodes that will be created: %d",
-		len(collection.GatewayClasses)+len(collection.Gateways)+len(collection.HTTPRoutes)+len(collection.ReferenceGrants)+len(collection.DNSRecords)+len(collection.Services))
-
-	return collection, nil
+	return resources, err
 }
 
 // buildGraph creates a graph data structure from the resources
@@ -290,8 +363,10 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 		}
 	}
 
-	// Add HTTPRoute nodes and links to Gateways
-	for _, route := range resources.HTTPRoutes {
+	// Add HTTPRoute nodes and links to the Gateway listeners they attach to
+	routeHostnames := make(map[string][]string) // route ID -> hostnames it serves
+	for r := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[r]
 		node := types.Node{
 			ID:        string(route.UID),
 			Name:      route.Name,
@@ -305,18 +380,39 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 		nodeMap[node.ID] = nodeIndex
 		nodeIndex++
 
-		// Link HTTPRoute to Gateways
+		for _, routeHostname := range route.Spec.Hostnames {
+			routeHostnames[node.ID] = append(routeHostnames[node.ID], string(routeHostname))
+		}
+
+		// Link each attached listener with the hostnames the route serves through it. A route
+		// that attaches to no listener is linked to the Gateway so that it stays visible.
 		for _, parentRef := range route.Spec.ParentRefs {
-			for _, gw := range resources.Gateways {
-				if (parentRef.Name == "" || string(parentRef.Name) == gw.Name) &&
-					(parentRef.Namespace == nil || string(*parentRef.Namespace) == route.Namespace || string(*parentRef.Namespace) == gw.Namespace) {
-					link := types.Link{
-						Source: nodeMap[string(gw.UID)],
-						Target: nodeMap[node.ID],
-						Type:   "parentRef",
-					}
-					graph.Links = append(graph.Links, link)
+			gw := analysis.ParentGateway(resources, route.Namespace, parentRef)
+			if gw == nil {
+				continue
+			}
+
+			attached, _ := analysis.AttachedListeners(gw, route, parentRef)
+			if len(attached) == 0 {
+				graph.Links = append(graph.Links, types.Link{
+					Source: nodeMap[string(gw.UID)],
+					Target: nodeMap[node.ID],
+					Type:   "parentRef",
+				})
+				continue
+			}
+
+			for _, i := range attached {
+				effective := analysis.EffectiveHostnames(gw.Spec.Listeners[i], route)
+				if len(route.Spec.Hostnames) == 0 {
+					routeHostnames[node.ID] = append(routeHostnames[node.ID], effective...)
 				}
+				graph.Links = append(graph.Links, types.Link{
+					Source:    nodeMap[analysis.ListenerID(gw, i)],
+					Target:    nodeMap[node.ID],
+					Type:      "parentRef",
+					Hostnames: displayHostnames(effective),
+				})
 			}
 		}
 	}
@@ -337,85 +433,61 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 		nodeIndex++
 	}
 
-	// Add DNSRecord nodes and links to Gateway Listeners
-	for _, dns := range resources.DNSRecords {
-		uid, _, _ := unstructured.NestedString(dns.Object, "metadata", "uid")
-		name, _, _ := unstructured.NestedString(dns.Object, "metadata", "name")
-		namespace, _, _ := unstructured.NestedString(dns.Object, "metadata", "namespace")
-
-		// Get the DNS name from the DNSRecord spec
-		dnsName, _, _ := unstructured.NestedString(dns.Object, "spec", "dnsName")
-		// Remove trailing dot if present
-		if strings.HasSuffix(dnsName, ".") {
-			dnsName = strings.TrimSuffix(dnsName, ".")
-		}
+	// Hosted zones declared by the OpenShift cluster DNS config, if any
+	hostedZones := dnszone.HostedZones(resources.DNSConfig)
 
+	// Add a DNSRecord node for every DNS name the DNS sources publish, linked to Gateway Listeners
+	records := dnssource.Collect(resources, h.dns)
+	for _, record := range records {
 		node := types.Node{
-			ID:        uid,
-			Name:      name,
+			ID:        record.ID,
+			Name:      record.Name,
 			Type:      "DNSRecord",
-			Namespace: namespace,
-			Group:     "ingress.operator.openshift.io",
-			Version:   "v1",
-			Kind:      "DNSRecord",
-			Hostname:  dnsName,
+			Namespace: record.Namespace,
+			Group:     record.Group,
+			Version:   record.Version,
+			Kind:      record.Kind,
+			Hostname:  record.DNSName,
+			Targets:   record.Targets,
+			DNSSource: &types.DNSSource{
+				Provider:  record.Provider,
+				Kind:      record.Kind,
+				Namespace: record.Namespace,
+				Name:      record.Name,
+			},
+		}
+		// Records declared by something other than an OpenShift DNSRecord are named after the
+		// DNS name, since one object can declare several
+		if record.Kind != "DNSRecord" {
+			node.Name = record.DNSName
+		}
+		if record.Kind == "DNSRecord" && record.Object != nil {
+			for _, status := range dnszone.RecordStatuses(record.Object, hostedZones) {
+				node.ZoneStatuses = append(node.ZoneStatuses, types.ZoneStatus{Zone: status.Zone, Status: status.Status, Message: status.Message})
+			}
 		}
 		graph.Nodes = append(graph.Nodes, node)
 		nodeMap[node.ID] = nodeIndex
 		nodeIndex++
 
-		// Link DNSRecord to specific Gateway Listener based on hostname matching
-		if labels, found, _ := unstructured.NestedMap(dns.Object, "metadata", "labels"); found {
-			if gatewayName, exists := labels["gateway.networking.k8s.io/gateway-name"]; exists {
-				gatewayNameStr, ok := gatewayName.(string)
-				if ok {
-					// Get the DNS name from the DNSRecord spec
-					dnsName, _, _ := unstructured.NestedString(dns.Object, "spec", "dnsName")
-					// Remove trailing dot if present for comparison
-					if strings.HasSuffix(dnsName, ".") {
-						dnsName = strings.TrimSuffix(dnsName, ".")
-					}
-
-					// Find the matching Gateway and its listeners
-					for _, gw := range resources.Gateways {
-						if gw.Name == gatewayNameStr && gw.Namespace == namespace {
-							// Try to match DNSRecord to specific listener by hostname
-							linkedToListener := false
-							for i, listener := range gw.Spec.Listeners {
-								listenerID := fmt.Sprintf("%s-listener-%d", string(gw.UID), i)
-
-								// Check if listener hostname matches the DNS name
-								if listener.Hostname != nil && string(*listener.Hostname) == dnsName {
-									if listenerIndex, exists := nodeMap[listenerID]; exists {
-										link := types.Link{
-											Source: listenerIndex,
-											Target: nodeMap[node.ID],
-											Type:   "dnsRecord",
-										}
-										graph.Links = append(graph.Links, link)
-										linkedToListener = true
-										break
-									}
-								}
-							}
-
-							// If no specific listener matched, fall back to linking to the Gateway itself
-							// This handles wildcard DNSRecords or cases where hostname matching fails
-							if !linkedToListener {
-								if gatewayIndex, exists := nodeMap[string(gw.UID)]; exists {
-									link := types.Link{
-										Source: gatewayIndex,
-										Target: nodeMap[node.ID],
-										Type:   "dnsRecord",
-									}
-									graph.Links = append(graph.Links, link)
-								}
-							}
-							break
-						}
-					}
+		// Link the record to the Gateway listener whose hostname matches the DNS name most
+		// specifically, or to the Gateway when no listener hostname matches
+		if gw := record.Gateway; gw != nil {
+			source := nodeMap[string(gw.UID)]
+			bestScore := -1
+			for i, listener := range gw.Spec.Listeners {
+				if listener.Hostname == nil {
+					continue
+				}
+				if score, ok := hostname.Specificity(string(*listener.Hostname), record.DNSName); ok && score > bestScore {
+					source, bestScore = nodeMap[analysis.ListenerID(gw, i)], score
 				}
 			}
+			graph.Links = append(graph.Links, types.Link{
+				Source: source,
+				Target: nodeMap[node.ID],
+				Type:   "dnsRecord",
+			})
 		}
 	}
 
@@ -435,121 +507,147 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 		nodeIndex++
 	}
 
-	// Extract DNS zones and assign them to nodes with hierarchical support
-	dnsZoneMap := make(map[string][]string)    // zone name -> node IDs
-	nodeZoneMap := make(map[string][]string)   // node ID -> all zones it belongs to
-	nodePrimaryZone := make(map[string]string) // node ID -> primary (most specific) zone
-
-	// Collect all DNSRecord hostnames to identify specific records
-	dnsRecordHostnames := make(map[string]string) // hostname -> DNSRecord UID
+	// Link DNSRecords to the Service owning their Gateway's load balancer, marking records whose
+	// targets no longer match its address
+	for _, record := range records {
+		if record.Gateway == nil {
+			continue
+		}
+		svc := analysis.LoadBalancerService(resources, record.Gateway)
+		if svc == nil {
+			continue
+		}
+		linkType := "dnsTarget"
+		if stale, _ := analysis.StaleTargets(resources, record.Gateway, record.Targets); len(stale) > 0 {
+			linkType = "staleDnsTarget"
+		}
+		graph.Links = append(graph.Links, types.Link{
+			Source: nodeMap[record.ID],
+			Target: nodeMap[string(svc.UID)],
+			Type:   linkType,
+		})
+	}
 
-	// First, collect all hostnames and their hierarchical zones from DNSRecords
-	for _, dns := range resources.DNSRecords {
-		dnsName, _, _ := unstructured.NestedString(dns.Object, "spec", "dnsName")
-		dnsUID, _, _ := unstructured.NestedString(dns.Object, "metadata", "uid")
+	// Extract DNS zones and assign them to nodes with hierarchical support
+	dnsZoneMap := make(map[string][]string)                 // zone name -> node IDs
+	nodeZoneMap := make(map[string][]string)                // node ID -> all zones it belongs to
+	nodePrimaryZone := make(map[string]string)              // node ID -> primary (most specific) zone
+	nodePlacements := make(map[string]*types.ZonePlacement) // node ID -> why it is in its zones
+	ruleZones := make(map[string]bool)                      // zones named by a zone rule
+
+	// placeNode puts a node in zones and records why
+	placeNode := func(nodeID, description string, placement *types.ZonePlacement) {
+		for _, zone := range placement.Zones {
+			dnsZoneMap[zone] = append(dnsZoneMap[zone], nodeID)
+			nodeZoneMap[nodeID] = append(nodeZoneMap[nodeID], zone)
+		}
+		if _, exists := nodePrimaryZone[nodeID]; !exists {
+			nodePrimaryZone[nodeID] = placement.Zones[0]
+		}
+		nodePlacements[nodeID] = placement
+		log.Printf("%s (%s) assigned to zones %v: %s", description, nodeID, placement.Zones, placement.Reason)
+	}
 
-		// Remove trailing dot if present
-		if strings.HasSuffix(dnsName, ".") {
-			dnsName = strings.TrimSuffix(dnsName, ".")
+	// placeHostname places a node by the zone rules for one of its hostnames. A hostname outside
+	// every hosted zone places the node nowhere, but the explanation is kept for the node.
+	placeHostname := func(nodeID, description, host string) bool {
+		placement := h.zones.Place(host, hostedZones)
+		zonePlacement := &types.ZonePlacement{
+			Hostname: host,
+			Zones:    placement.Zones,
+			Rule:     placement.Rule,
+			Reason:   placement.Reason,
+		}
+		if len(placement.Zones) == 0 {
+			if _, exists := nodePlacements[nodeID]; !exists {
+				nodePlacements[nodeID] = zonePlacement
+			}
+			return false
+		}
+		if placement.Rule != "publicSuffix" {
+			for _, zone := range placement.Zones {
+				ruleZones[zone] = true
+			}
 		}
+		placeNode(nodeID, description, zonePlacement)
+		return true
+	}
 
-		if dnsName != "" {
-			dnsRecordHostnames[dnsName] = dnsUID
+	// Collect all DNSRecord hostnames to identify specific records
+	type dnsRecordName struct {
+		hostname string
+		uid      string
+		name     string
+	}
+	var dnsRecordHostnames []dnsRecordName
 
-			zones := h.extractHierarchicalZones(dnsName)
-			if len(zones) > 0 {
-				// Assign to all valid hierarchical zones
-				for _, zone := range zones {
-					dnsZoneMap[zone] = append(dnsZoneMap[zone], dnsUID)
-					nodeZoneMap[dnsUID] = append(nodeZoneMap[dnsUID], zone)
-				}
-				// Set primary zone (most specific)
-				if len(zones) > 0 {
-					nodePrimaryZone[dnsUID] = zones[0]
-					log.Printf("DNSRecord %s (%s) assigned to zones %v, primary: %s", dnsName, dnsUID, zones, zones[0])
+	// First, collect all hostnames and their hierarchical zones from DNSRecords
+	for _, dns := range records {
+		record := dnsRecordName{hostname: dns.DNSName, uid: dns.ID, name: dns.Kind + " " + dns.Namespace + "/" + dns.Name}
+		dnsRecordHostnames = append(dnsRecordHostnames, record)
+
+		// An OpenShift DNSRecord is in the hosted zones its status reports it in, published or not
+		placement := &types.ZonePlacement{Hostname: dns.DNSName, Rule: "hostedZone"}
+		if dns.Kind == "DNSRecord" && dns.Object != nil {
+			for _, status := range dnszone.RecordStatuses(dns.Object, hostedZones) {
+				if status.Hosted != nil {
+					placement.Zones = append(placement.Zones, status.Hosted.Name())
+					ruleZones[status.Hosted.Name()] = true
 				}
 			}
 		}
+		if len(placement.Zones) > 0 {
+			placement.Reason = fmt.Sprintf("status.zones reports %s in the hosted zone(s) %s", dns.DNSName, strings.Join(placement.Zones, ", "))
+			placeNode(record.uid, "DNSRecord "+dns.DNSName, placement)
+			continue
+		}
+		placeHostname(record.uid, "DNSRecord "+dns.DNSName, dns.DNSName)
 	}
 
-	// Process HTTPRoutes and assign them to hierarchical zones based on their hostnames
-	for _, route := range resources.HTTPRoutes {
-		routeID := string(route.UID)
-
-		// Check all hostnames in the HTTPRoute
-		for _, hostname := range route.Spec.Hostnames {
-			hostnameStr := string(hostname)
-
-			// If this exact hostname has a DNSRecord, assign to all the same zones as the DNSRecord
-			if dnsUID, hasSpecificDNSRecord := dnsRecordHostnames[hostnameStr]; hasSpecificDNSRecord {
-				// Use the same zones as the DNSRecord
-				if dnsZones, exists := nodeZoneMap[dnsUID]; exists {
-					for _, zone := range dnsZones {
-						dnsZoneMap[zone] = append(dnsZoneMap[zone], routeID)
-						nodeZoneMap[routeID] = append(nodeZoneMap[routeID], zone)
-					}
-					if primaryZone, exists := nodePrimaryZone[dnsUID]; exists {
-						nodePrimaryZone[routeID] = primaryZone
-					}
-					log.Printf("HTTPRoute %s/%s (%s) assigned to zones %v (matches DNSRecord)", route.Namespace, route.Name, routeID, dnsZones)
-					break
-				}
+	// assignZones puts a node in the zones of its first hostname that has any. A hostname that a
+	// DNSRecord publishes, exactly or through a wildcard record, shares the record's zones.
+	assignZones := func(nodeID, description string, hostnames []string) {
+		for _, host := range hostnames {
+			if host == "" {
+				continue
 			}
 
-			zones := h.extractHierarchicalZones(hostnameStr)
-			if len(zones) > 0 {
-				// Assign to all hierarchical zones
-				for _, zone := range zones {
-					dnsZoneMap[zone] = append(dnsZoneMap[zone], routeID)
-					nodeZoneMap[routeID] = append(nodeZoneMap[routeID], zone)
+			var published *dnsRecordName
+			bestScore := -1
+			for r, record := range dnsRecordHostnames {
+				if score, ok := hostname.Specificity(record.hostname, host); ok && score > bestScore {
+					published, bestScore = &dnsRecordHostnames[r], score
 				}
-				// Set primary zone (most specific)
-				if _, exists := nodePrimaryZone[routeID]; !exists {
-					nodePrimaryZone[routeID] = zones[0]
+			}
+			if published != nil {
+				if dnsZones, exists := nodeZoneMap[published.uid]; exists {
+					placeNode(nodeID, description, &types.ZonePlacement{
+						Hostname: host,
+						Zones:    dnsZones,
+						Rule:     "dnsRecord",
+						Reason:   fmt.Sprintf("%s is published by %s (%s) and shares its zones", host, published.name, published.hostname),
+					})
+					return
 				}
-				log.Printf("HTTPRoute %s/%s (%s) assigned to zones %v, primary: %s", route.Namespace, route.Name, routeID, zones, zones[0])
-				break // Only process first hostname for primary zone assignment
+			}
+
+			if placeHostname(nodeID, description, host) {
+				return
 			}
 		}
 	}
 
+	// Process HTTPRoutes and assign them to hierarchical zones based on the hostnames they serve
+	for _, route := range resources.HTTPRoutes {
+		assignZones(string(route.UID), fmt.Sprintf("HTTPRoute %s/%s", route.Namespace, route.Name), routeHostnames[string(route.UID)])
+	}
+
 	// Process Gateway listeners and assign them to hierarchical zones based on their hostnames
-	for _, gw := range resources.Gateways {
+	for g := range resources.Gateways {
+		gw := &resources.Gateways[g]
 		for i, listener := range gw.Spec.Listeners {
-			listenerID := fmt.Sprintf("%s-listener-%d", string(gw.UID), i)
-
 			if listener.Hostname != nil {
-				hostnameStr := string(*listener.Hostname)
-
-				// If this exact hostname has a DNSRecord, assign to all the same zones as the DNSRecord
-				if dnsUID, hasSpecificDNSRecord := dnsRecordHostnames[hostnameStr]; hasSpecificDNSRecord {
-					// Use the same zones as the DNSRecord
-					if dnsZones, exists := nodeZoneMap[dnsUID]; exists {
-						for _, zone := range dnsZones {
-							dnsZoneMap[zone] = append(dnsZoneMap[zone], listenerID)
-							nodeZoneMap[listenerID] = append(nodeZoneMap[listenerID], zone)
-						}
-						if primaryZone, exists := nodePrimaryZone[dnsUID]; exists {
-							nodePrimaryZone[listenerID] = primaryZone
-						}
-						log.Printf("Gateway listener %s (%s) assigned to zones %v (matches DNSRecord)", gw.Name, listenerID, dnsZones)
-						continue
-					}
-				}
-
-				zones := h.extractHierarchicalZones(hostnameStr)
-				if len(zones) > 0 {
-					// Assign to all hierarchical zones
-					for _, zone := range zones {
-						dnsZoneMap[zone] = append(dnsZoneMap[zone], listenerID)
-						nodeZoneMap[listenerID] = append(nodeZoneMap[listenerID], zone)
-					}
-					// Set primary zone (most specific)
-					if _, exists := nodePrimaryZone[listenerID]; !exists {
-						nodePrimaryZone[listenerID] = zones[0]
-					}
-					log.Printf("Gateway listener %s (%s) assigned to zones %v, primary: %s", gw.Name, listenerID, zones, zones[0])
-				}
+				assignZones(analysis.ListenerID(gw, i), fmt.Sprintf("Gateway listener %s", gw.Name), []string{string(*listener.Hostname)})
 			}
 		}
 	}
@@ -559,10 +657,10 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 		if primaryZone, exists := nodePrimaryZone[graph.Nodes[i].ID]; exists {
 			graph.Nodes[i].DNSZone = primaryZone
 		}
+		graph.Nodes[i].ZonePlacement = nodePlacements[graph.Nodes[i].ID]
 	}
 
 	// Create DNS zone objects with colors, but only for zones that provide meaningful separation
-	zoneColors := []string{"#e3f2fd", "#f3e5f5", "#e8f5e8", "#fff3e0", "#fce4ec", "#e0f2f1", "#f9fbe7", "#fff8e1"}
 	colorIndex := 0
 
 	log.Printf("DNS Zone Summary:")
@@ -584,19 +682,24 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 		log.Printf("  Zone %s: %d nodes - %v", zoneName, len(nodeIDs), nodeIDs)
 	}
 
-	// Sort by depth (most specific first)
+	// Sort by depth (most specific first), then by name for stable colors
 	sort.Slice(zones, func(i, j int) bool {
-		return zones[i].depth > zones[j].depth
+		if zones[i].depth != zones[j].depth {
+			return zones[i].depth > zones[j].depth
+		}
+		return zones[i].name < zones[j].name
 	})
 
 	for _, zoneInfo := range zones {
 		// Create all zones that have nodes, allowing hierarchical overlap
-		// Only skip zones that would be identical to other zones (no meaningful separation)
+		// Only skip zones that would be identical to other zones (no meaningful separation).
+		// Zones that are declared or named by a rule are always created.
 		shouldCreateZone := true
 
 		// Skip zones that are identical to a more specific zone
+		pinned := ruleZones[zoneInfo.name] || h.zones.Declared(zoneInfo.name)
 		for _, otherZone := range zones {
-			if otherZone.depth > zoneInfo.depth && len(otherZone.nodeIDs) == len(zoneInfo.nodeIDs) {
+			if !pinned && otherZone.depth > zoneInfo.depth && len(otherZone.nodeIDs) == len(zoneInfo.nodeIDs) {
 				// Check if node sets are identical
 				if h.slicesEqual(otherZone.nodeIDs, zoneInfo.nodeIDs) {
 					shouldCreateZone = false
@@ -609,7 +712,12 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 			zone := types.DNSZone{
 				Name:  zoneInfo.name,
 				Nodes: zoneInfo.nodeIDs,
-				Color: zoneColors[colorIndex%len(zoneColors)],
+				Color: h.zones.Color(zoneInfo.name, colorIndex),
+			}
+			for _, hosted := range hostedZones {
+				if hosted.Inferred && hosted.Name() == zone.Name {
+					zone.Inferred = true
+				}
 			}
 			graph.DNSZones = append(graph.DNSZones, zone)
 			colorIndex++
@@ -622,6 +730,9 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 
 	log.Printf("Total DNS zones created: %d", len(graph.DNSZones))
 
+	// Flag the hostname collisions, wildcard overlaps and missing DNS records involving each zone
+	ownership.Annotate(graph.DNSZones, ownership.Build(resources, records).Issues)
+
 	// Link HTTPRoutes to Services via backendRefs
 	for _, route := range resources.HTTPRoutes {
 		for _, rule := range route.Spec.Rules {
@@ -648,307 +759,431 @@ func (h *Handler) buildGraph(resources *types.ResourceCollection) *types.Graph {
 		}
 	}
 
-	return graph
-}
-
-// hostnamesMatch checks if a DNS name matches a hostname pattern
-// Supports exact matches and basic wildcard matching
-func (h *Handler) hostnamesMatch(dnsName, routeHostname string) bool {
-	// Exact match
-	if dnsName == routeHostname {
-		return true
+	// Link each shadowed route to the route whose rule wins its requests
+	shadowedLinks := make(map[[2]string]bool)
+	for _, shadow := range analysis.Shadowing(resources) {
+		winner, loser := string(shadow.Winner.Route.UID), string(shadow.Loser.Route.UID)
+		if winner == loser || shadowedLinks[[2]string{winner, loser}] {
+			continue
+		}
+		shadowedLinks[[2]string{winner, loser}] = true
+		graph.Links = append(graph.Links, types.Link{
+			Source: nodeMap[winner],
+			Target: nodeMap[loser],
+			Type:   "shadowed",
+		})
 	}
 
-	// Wildcard matching - if route hostname starts with "*."
-	if strings.HasPrefix(routeHostname, "*.") {
-		wildcardDomain := strings.TrimPrefix(routeHostname, "*.")
-		// Check if DNS name ends with the wildcard domain
-		if strings.HasSuffix(dnsName, "."+wildcardDomain) || dnsName == wildcardDomain {
-			return true
+	// Add a node per policy, linked to the resources it targets, and the policies that apply to
+	// each route
+	attachments := policy.Attachments(resources, policy.Merge(h.policies, resources.PolicyKinds))
+	for _, attachment := range attachments {
+		obj := attachment.Policy
+		gvk := obj.GroupVersionKind()
+		node := types.Node{
+			ID:            string(obj.GetUID()),
+			Name:          obj.GetName(),
+			Type:          "Policy",
+			Namespace:     obj.GetNamespace(),
+			Group:         gvk.Group,
+			Version:       gvk.Version,
+			Kind:          gvk.Kind,
+			PolicyTargets: attachment.Targets,
+			Inherited:     attachment.Inherited,
 		}
-	}
+		graph.Nodes = append(graph.Nodes, node)
+		nodeMap[node.ID] = nodeIndex
+		nodeIndex++
 
-	// Check if DNS name matches subdomain pattern
-	if strings.HasPrefix(dnsName, routeHostname+".") {
-		return true
+		for _, target := range attachment.Targets {
+			targetIndex, ok := nodeMap[target.NodeID]
+			if !ok {
+				continue
+			}
+			graph.Links = append(graph.Links, types.Link{
+				Source: nodeMap[node.ID],
+				Target: targetIndex,
+				Type:   "policy",
+			})
+		}
 	}
-
-	return false
-}
-
-// extractDNSZone extracts the DNS zone from a hostname with intelligent granularity
-// Examples:
-// - api.example.com -> example.com
-// - *.gwapi.apps.ci-ln-xyz.gcp-2.ci.openshift.org -> gwapi.apps.ci-ln-xyz.gcp-2.ci.openshift.org
-// - foo.abc.apps.ci-ln-xyz.gcp-2.ci.openshift.org -> abc.apps.ci-ln-xyz.gcp-2.ci.openshift.org
-func (h *Handler) extractDNSZone(hostname string) string {
-	if hostname == "" {
-		return ""
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		if index, ok := nodeMap[string(route.UID)]; ok {
+			graph.Nodes[index].Policies = policy.Effective(resources, attachments, route)
+		}
 	}
 
-	// Remove wildcard prefix if present
-	if strings.HasPrefix(hostname, "*.") {
-		hostname = strings.TrimPrefix(hostname, "*.")
-	}
+	// BackendTLSPolicies show the hostname they validate and link to their CA ConfigMaps and
+	// Secrets. Missing CA objects have no node; the analysis reports them.
+	backendTLSPolicies := analysis.BackendTLSPolicies(resources)
+	for i := range backendTLSPolicies {
+		tlsPolicy := &backendTLSPolicies[i]
+		policyIndex, ok := nodeMap[string(tlsPolicy.UID)]
+		if !ok {
+			continue
+		}
+		graph.Nodes[policyIndex].Hostname = string(tlsPolicy.Spec.Validation.Hostname)
 
-	// Split hostname into parts
-	parts := strings.Split(hostname, ".")
-	if len(parts) < 2 {
-		return hostname // Single part, treat as zone itself
+		for _, ref := range analysis.CACertificateRefs(tlsPolicy) {
+			bundle := certs.Find(resources.Certificates, ref.Kind, ref.Namespace, ref.Name, certs.CACertKey)
+			if bundle == nil || bundle.Missing {
+				continue
+			}
+			id := fmt.Sprintf("%s:%s/%s", strings.ToLower(ref.Kind), ref.Namespace, ref.Name)
+			if _, exists := nodeMap[id]; !exists {
+				graph.Nodes = append(graph.Nodes, types.Node{
+					ID:           id,
+					Name:         ref.Name,
+					Type:         ref.Kind,
+					Namespace:    ref.Namespace,
+					Version:      "v1",
+					Kind:         ref.Kind,
+					Certificates: bundle,
+				})
+				nodeMap[id] = nodeIndex
+				nodeIndex++
+			}
+			graph.Links = append(graph.Links, types.Link{
+				Source: policyIndex,
+				Target: nodeMap[id],
+				Type:   "caCertificateRef",
+			})
+		}
 	}
 
-	// Special handling for OpenShift/Kubernetes style domains
-	// Pattern: [subdomain.]service.apps.cluster-name.domain.tld
-	if h.isOpenShiftStyleDomain(parts) {
-		return h.extractOpenShiftZone(parts)
-	}
+	analysis.Attach(graph, h.analyzer.Run(resources))
 
-	// Special handling for internal cluster domains
-	// Pattern: service.namespace.svc.cluster.local
-	if h.isClusterInternalDomain(parts) {
-		return h.extractClusterInternalZone(parts)
-	}
+	return graph
+}
 
-	// For standard domains, use different granularity based on domain length
-	if len(parts) >= 6 {
-		// Very long domains - use last 4 parts for more granularity
-		return strings.Join(parts[len(parts)-4:], ".")
-	} else if len(parts) >= 4 {
-		// Medium domains - use last 3 parts
-		return strings.Join(parts[len(parts)-3:], ".")
-	} else {
-		// Short domains - use last 2 parts (standard)
-		return strings.Join(parts[len(parts)-2:], ".")
+// displayHostnames formats the effective hostnames of a route attachment, where "" stands for
+// any hostname
+func displayHostnames(hostnames []string) []string {
+	display := make([]string, len(hostnames))
+	for i, host := range hostnames {
+		display[i] = host
+		if host == "" {
+			display[i] = "*"
+		}
 	}
+	return display
 }
 
-// isOpenShiftStyleDomain checks if this looks like an OpenShift cluster domain
-// Pattern: *.apps.cluster-name.provider.region.domain.tld
-func (h *Handler) isOpenShiftStyleDomain(parts []string) bool {
-	if len(parts) < 6 {
-		return false
-	}
+// GetResourceDetails returns detailed information about a specific resource as JSON, or as YAML
+// when requested via the Accept header. managedFields and other server-side noise are stripped
+// unless ?full=true is set. With ?at=<RFC 3339 time> the resource is read from a snapshot.
+func (h *Handler) GetResourceDetails(c *gin.Context) {
+	resourceType := c.Param("type")
+	resourceName := c.Param("name")
+	namespace := c.Query("namespace")
 
-	// Look for common OpenShift patterns
-	for i, part := range parts {
-		if part == "apps" && i > 0 && i < len(parts)-3 {
-			// Check if it looks like: something.apps.cluster.domain.tld
-			return true
-		}
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	return false
-}
+	var resource interface{}
+	var err error
 
-// extractOpenShiftZone extracts zone for OpenShift style domains
-// *.gwapi.apps.cluster -> gwapi.apps.cluster...
-// foo.abc.apps.cluster -> abc.apps.cluster...
-func (h *Handler) extractOpenShiftZone(parts []string) string {
-	// Find the "apps" part
-	appsIndex := -1
-	for i, part := range parts {
-		if part == "apps" {
-			appsIndex = i
-			break
+	// Snapshots and manifests are served from the fetched collection instead of the API server
+	if at := c.Query("at"); at != "" || h.offline() {
+		var resources *types.ResourceCollection
+		if at != "" {
+			var ok bool
+			if resources, ok = h.snapshotAt(c, at); !ok {
+				return
+			}
+		} else if resources, err = h.fetchAllResources(ctx); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
-	}
 
-	if appsIndex == -1 {
-		// Fallback to standard extraction
-		return strings.Join(parts[len(parts)-3:], ".")
+		resource, err = findResource(resources, resourceType, namespace, resourceName)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		prepareForOutput(resourceType, resource, c.Query("full") == "true")
+		if wantsYAML(c) {
+			respondYAML(c, http.StatusOK, resource, nil)
+			return
+		}
+		c.JSON(http.StatusOK, resource)
+		return
 	}
 
-	// Extract the service/application part before "apps"
-	if appsIndex > 0 {
-		// Include from the service level: service.apps.cluster.domain.tld
-		return strings.Join(parts[appsIndex-1:], ".")
-	} else {
-		// apps is at the beginning, use everything
-		return strings.Join(parts, ".")
+	switch resourceType {
+	case "gatewayclass":
+		resource, err = h.k8sClient.GetGatewayClass(ctx, resourceName)
+	case "gateway":
+		resource, err = h.k8sClient.GetGateway(ctx, namespace, resourceName)
+	case "httproute":
+		resource, err = h.k8sClient.GetHTTPRoute(ctx, namespace, resourceName)
+	case "referencegrant":
+		resource, err = h.k8sClient.GetReferenceGrant(ctx, namespace, resourceName)
+	case "service":
+		resource, err = h.k8sClient.GetService(ctx, namespace, resourceName)
+	case "dnsrecord":
+		resource, err = h.k8sClient.GetDNSRecord(ctx, namespace, resourceName)
+	case "dnsendpoint":
+		resource, err = h.k8sClient.GetDNSEndpoint(ctx, namespace, resourceName)
+	default:
+		// Policy kinds are discovered at runtime, so policies are read from the fetched collection
+		var resources *types.ResourceCollection
+		if resources, err = h.fetchAllResources(ctx); err == nil {
+			resource, err = findResource(resources, resourceType, namespace, resourceName)
+		}
+		if errors.Is(err, errUnsupportedResourceType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
-}
 
-// isClusterInternalDomain checks for Kubernetes internal domains
-// Pattern: service.namespace.svc.cluster.local
-func (h *Handler) isClusterInternalDomain(parts []string) bool {
-	if len(parts) < 3 {
-		return false
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 
-	// Look for cluster.local or svc.cluster.local patterns
-	return (len(parts) >= 2 && parts[len(parts)-2] == "cluster" && parts[len(parts)-1] == "local") ||
-		(len(parts) >= 4 && parts[len(parts)-4] == "svc" && parts[len(parts)-2] == "cluster" && parts[len(parts)-1] == "local")
-}
+	prepareForOutput(resourceType, resource, c.Query("full") == "true")
 
-// extractClusterInternalZone extracts zone for cluster internal domains
-// service.namespace.svc.cluster.local -> namespace.svc.cluster.local
-func (h *Handler) extractClusterInternalZone(parts []string) string {
-	if len(parts) >= 4 && parts[len(parts)-4] == "svc" {
-		// service.namespace.svc.cluster.local -> namespace.svc.cluster.local
-		return strings.Join(parts[len(parts)-4:], ".")
+	if wantsYAML(c) {
+		respondYAML(c, http.StatusOK, resource, nil)
+		return
 	}
-
-	// Fallback
-	return strings.Join(parts[len(parts)-3:], ".")
+	c.JSON(http.StatusOK, resource)
 }
 
-// extractHierarchicalZones extracts all possible DNS zones from a hostname in hierarchical order
-// Examples: foo.abc.apps.ci-ln-xyz.gcp-2.ci.openshift.org returns:
-// - abc.apps.ci-ln-xyz.gcp-2.ci.openshift.org (most specific)
-// - apps.ci-ln-xyz.gcp-2.ci.openshift.org
-// - ci-ln-xyz.gcp-2.ci.openshift.org
-// - gcp-2.ci.openshift.org (broader)
-// - ci.openshift.org
-// - openshift.org (broadest)
-func (h *Handler) extractHierarchicalZones(hostname string) []string {
-	if hostname == "" {
-		return nil
+// UpdateResource updates a specific resource. The request body may be a full JSON or YAML object
+// (applied with server-side apply), a JSON patch, a merge patch or an apply patch in YAML,
+// selected by the Content-Type header. If the client accepts YAML, the updated object is returned
+// as YAML with the comments of a submitted YAML document carried over.
+func (h *Handler) UpdateResource(c *gin.Context) {
+	if !h.requireCluster(c) {
+		return
 	}
 
-	// Remove wildcard prefix if present
-	if strings.HasPrefix(hostname, "*.") {
-		hostname = strings.TrimPrefix(hostname, "*.")
+	req, status, err := parseUpdateRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Split hostname into parts
-	parts := strings.Split(hostname, ".")
-	if len(parts) < 2 {
-		return []string{hostname}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	before := h.currentObject(ctx, req.resourceType, req.namespace, req.name)
+	updated, err := h.updateResource(ctx, req, false)
+	h.recordAudit(c, "update", req.resourceType, req.namespace, req.name, before, updated, err)
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 
-	var zones []string
+	if wantsYAML(c) {
+		prepareForOutput(req.resourceType, updated, c.Query("full") == "true")
+		respondYAML(c, http.StatusOK, updated, req.yamlSource)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "resource updated successfully"})
+}
 
-	// For OpenShift style domains, create hierarchical zones
-	if h.isOpenShiftStyleDomain(parts) {
-		// Find the "apps" part
-		appsIndex := -1
-		for i, part := range parts {
-			if part == "apps" {
-				appsIndex = i
-				break
-			}
-		}
+// updateRequest is a parsed resource update, shared by UpdateResource and ValidateResource
+type updateRequest struct {
+	resourceType string
+	namespace    string
+	name         string
+	patchType    k8stypes.PatchType
+	object       map[string]interface{} // Set for plain JSON/YAML objects, which are converted into an apply patch
+	patch        []byte                 // Set for JSON, merge and apply patches, which are sent as-is
+	yamlSource   []byte                 // The submitted YAML document, kept so its comments can be returned
+	force        bool
+}
 
-		if appsIndex > 0 {
-			// Start from the service level and work up
-			// foo.abc.apps.cluster -> abc.apps.cluster, apps.cluster, cluster...
-			for i := appsIndex - 1; i < len(parts)-1; i++ {
-				if i >= 0 {
-					zone := strings.Join(parts[i:], ".")
-					zones = append(zones, zone)
-				}
-			}
-		}
-	} else {
-		// For regular domains, create zones from specific to general
-		// api.service.example.com -> service.example.com, example.com
-		for i := len(parts) - 2; i >= 0; i-- {
-			if i < len(parts)-1 { // Don't include the full hostname itself
-				zone := strings.Join(parts[i:], ".")
-				zones = append(zones, zone)
-			}
-		}
+// parseUpdateRequest reads the target resource and the body of an update request, returning the
+// HTTP status to respond with when the request is invalid
+func parseUpdateRequest(c *gin.Context) (*updateRequest, int, error) {
+	req := &updateRequest{
+		resourceType: c.Param("type"),
+		name:         c.Param("name"),
+		namespace:    c.Query("namespace"),
+		force:        c.Query("force") == "true",
 	}
 
-	// Remove duplicates and ensure we have at least the basic zone
-	uniqueZones := make(map[string]bool)
-	var result []string
+	if !k8s.IsSupportedResource(req.resourceType) {
+		return nil, http.StatusBadRequest, errUnsupportedResourceType
+	}
 
-	for _, zone := range zones {
-		if !uniqueZones[zone] && zone != "" {
-			uniqueZones[zone] = true
-			result = append(result, zone)
-		}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err)
 	}
 
-	// Ensure we have at least the basic zone extraction as fallback
-	basicZone := h.extractDNSZone(hostname)
-	if !uniqueZones[basicZone] && basicZone != "" {
-		result = append(result, basicZone)
+	switch c.ContentType() {
+	case "", "application/json":
+		if err := json.Unmarshal(body, &req.object); err != nil {
+			return nil, http.StatusBadRequest, errors.New("invalid JSON")
+		}
+		req.patchType = k8stypes.ApplyPatchType
+	case mimeYAML, "application/x-yaml", "text/yaml", "text/x-yaml":
+		if req.object, err = decodeYAMLObject(body); err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		req.patchType = k8stypes.ApplyPatchType
+		req.yamlSource = body
+	case string(k8stypes.JSONPatchType):
+		req.patchType = k8stypes.JSONPatchType
+		req.patch = body
+	case string(k8stypes.MergePatchType):
+		req.patchType = k8stypes.MergePatchType
+		req.patch = body
+	case string(k8stypes.ApplyPatchType), "application/apply-patch+json":
+		req.patchType = k8stypes.ApplyPatchType
+		req.patch = body
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q", c.ContentType())
 	}
 
-	return result
+	return req, http.StatusOK, nil
 }
 
-// GetResourceDetails returns detailed information about a specific resource
-func (h *Handler) GetResourceDetails(c *gin.Context) {
-	resourceType := c.Param("type")
-	resourceName := c.Param("name")
-	namespace := c.Query("namespace")
+// updateResource sends an update to the API server and returns the object as stored (or, for a
+// dry run, as it would be stored), converted to its typed form where one exists
+func (h *Handler) updateResource(ctx context.Context, req *updateRequest, dryRun bool) (interface{}, error) {
+	var updated *unstructured.Unstructured
+	var err error
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	if req.object != nil {
+		updated, err = h.k8sClient.ApplyResource(ctx, req.resourceType, req.namespace, req.name, req.object, req.force, dryRun)
+	} else {
+		updated, err = h.k8sClient.PatchResource(ctx, req.resourceType, req.namespace, req.name, req.patchType, req.patch, req.force, dryRun)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	var resource interface{}
-	var err error
+	return typedResource(req.resourceType, updated)
+}
 
+// typedResource converts an unstructured object returned by the dynamic client into the
+// typed object used in ResourceCollection. DNSRecords, DNSEndpoints and policies have no typed
+// form and are returned as-is.
+func typedResource(resourceType string, obj *unstructured.Unstructured) (interface{}, error) {
+	var typed interface{}
 	switch resourceType {
 	case "gatewayclass":
-		resource, err = h.k8sClient.GetGatewayClass(ctx, resourceName)
+		typed = &gatewayv1.GatewayClass{}
 	case "gateway":
-		resource, err = h.k8sClient.GetGateway(ctx, namespace, resourceName)
+		typed = &gatewayv1.Gateway{}
 	case "httproute":
-		resource, err = h.k8sClient.GetHTTPRoute(ctx, namespace, resourceName)
+		typed = &gatewayv1.HTTPRoute{}
 	case "referencegrant":
-		resource, err = h.k8sClient.GetReferenceGrant(ctx, namespace, resourceName)
+		typed = &gatewayv1beta1.ReferenceGrant{}
 	case "service":
-		resource, err = h.k8sClient.GetService(ctx, namespace, resourceName)
-	case "dnsrecord":
-		resource, err = h.k8sClient.GetDNSRecord(ctx, namespace, resourceName)
+		typed = &corev1.Service{}
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported resource type"})
+		return obj, nil
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", obj.GetKind(), err)
+	}
+	return typed, nil
+}
+
+// CreateResource creates a new resource from a JSON or YAML object. Pass ?dryRun=true to have the API
+// server validate the object without persisting it.
+func (h *Handler) CreateResource(c *gin.Context) {
+	if !h.requireCluster(c) {
 		return
 	}
 
+	resourceType := c.Param("type")
+	if !k8s.IsSupportedResource(resourceType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUnsupportedResourceType.Error()})
+		return
+	}
+
+	var rawResource map[string]interface{}
+	if isYAMLContentType(c.ContentType()) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err == nil {
+			rawResource, err = decodeYAMLObject(body)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else if err := c.ShouldBindJSON(&rawResource); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return
+	}
+
+	namespace := c.Query("namespace")
+	if namespace == "" {
+		namespace, _, _ = unstructured.NestedString(rawResource, "metadata", "namespace")
+	}
+	dryRun := c.Query("dryRun") == "true"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	created, err := h.k8sClient.CreateResource(ctx, resourceType, namespace, rawResource, dryRun)
+	if !dryRun {
+		name, _, _ := unstructured.NestedString(rawResource, "metadata", "name")
+		if err == nil {
+			name = created.GetName()
+		}
+		h.recordAudit(c, "create", resourceType, namespace, name, nil, created, err)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, resource)
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{"message": "resource is valid (dry run)", "resource": created.Object})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "resource created successfully", "resource": created.Object})
 }
 
-// UpdateResource updates a specific resource
-func (h *Handler) UpdateResource(c *gin.Context) {
+// DeleteResource deletes a specific resource. Pass ?dryRun=true to only validate the deletion.
+func (h *Handler) DeleteResource(c *gin.Context) {
+	if !h.requireCluster(c) {
+		return
+	}
+
 	resourceType := c.Param("type")
 	resourceName := c.Param("name")
 	namespace := c.Query("namespace")
+	dryRun := c.Query("dryRun") == "true"
 
-	var rawResource map[string]interface{}
-	if err := c.ShouldBindJSON(&rawResource); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+	if !k8s.IsSupportedResource(resourceType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUnsupportedResourceType.Error()})
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	var err error
-
-	switch resourceType {
-	case "gatewayclass":
-		err = h.k8sClient.UpdateGatewayClass(ctx, resourceName, rawResource)
-	case "gateway":
-		err = h.k8sClient.UpdateGateway(ctx, namespace, resourceName, rawResource)
-	case "httproute":
-		err = h.k8sClient.UpdateHTTPRoute(ctx, namespace, resourceName, rawResource)
-	case "referencegrant":
-		err = h.k8sClient.UpdateReferenceGrant(ctx, namespace, resourceName, rawResource)
-	case "service":
-		err = h.k8sClient.UpdateService(ctx, namespace, resourceName, rawResource)
-	case "dnsrecord":
-		err = h.k8sClient.UpdateDNSRecord(ctx, namespace, resourceName, rawResource)
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported resource type"})
-		return
+	var before map[string]interface{}
+	if !dryRun {
+		before = h.currentObject(ctx, resourceType, namespace, resourceName)
 	}
 
+	err := h.k8sClient.DeleteResource(ctx, resourceType, namespace, resourceName, dryRun)
+	if !dryRun {
+		h.recordAudit(c, "delete", resourceType, namespace, resourceName, before, nil, err)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "resource updated successfully"})
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{"message": "resource can be deleted (dry run)"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "resource deleted successfully"})
 }
 
 // slicesEqual checks if two string slices contain the same elements (order doesn't matter)
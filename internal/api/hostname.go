@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/hostview"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHostname returns everything that serves one hostname: the DNS records publishing it, the
+// listeners receiving it, the routes, rules, backends and endpoints handling it and the
+// certificate presented for it, as a focused subgraph and a plain-text summary. ?at=<RFC 3339
+// time> reads a snapshot; with Accept: text/plain only the summary is returned.
+func (h *Handler) GetHostname(c *gin.Context) {
+	fqdn := hostname.Normalize(c.Param("fqdn"))
+	if fqdn == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "hostname is required"})
+		return
+	}
+
+	resources, ok := h.resourcesFor(c)
+	if !ok {
+		return
+	}
+
+	view := hostview.Build(resources, h.buildGraph(resources), dnssource.Collect(resources, h.dns), fqdn, time.Now())
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain) == gin.MIMEPlain {
+		c.String(http.StatusOK, view.Summary)
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
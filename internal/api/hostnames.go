@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/ownership"
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHostnames returns the hostname ownership table: for every hostname claimed by a listener, an
+// attached route or a DNS record, the Gateways, listeners, routes, namespaces and DNS records
+// claiming it, plus collisions, wildcard overlaps and hostnames served without DNS.
+// ?namespace=<name> keeps the hostnames claimed from one namespace and their issues; ?at=<RFC 3339
+// time> reads a snapshot.
+func (h *Handler) GetHostnames(c *gin.Context) {
+	resources, ok := h.resourcesFor(c)
+	if !ok {
+		return
+	}
+
+	report := ownership.Build(resources, dnssource.Collect(resources, h.dns))
+	if namespace := c.Query("namespace"); namespace != "" {
+		filtered := &ownership.Report{Hostnames: []ownership.Entry{}, Issues: []types.HostnameIssue{}}
+		owned := make(map[string]bool) // IDs of the nodes claiming hostnames from the namespace
+		for _, entry := range report.Hostnames {
+			if !containsString(entry.Namespaces, namespace) {
+				continue
+			}
+			filtered.Hostnames = append(filtered.Hostnames, entry)
+			for _, owner := range entry.Owners {
+				if owner.Namespace == namespace {
+					owned[owner.NodeID] = true
+				}
+			}
+		}
+		for _, issue := range report.Issues {
+			for _, nodeID := range issue.Nodes {
+				if owned[nodeID] {
+					filtered.Issues = append(filtered.Issues, issue)
+					break
+				}
+			}
+		}
+		report = filtered
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// offline reports whether the handler reads from manifests rather than a cluster
+func (h *Handler) offline() bool {
+	return h.k8sClient == nil || !h.source.Live()
+}
+
+// requireCluster rejects calls that write to the cluster when running offline. It returns false
+// when the request has been answered.
+func (h *Handler) requireCluster(c *gin.Context) bool {
+	if h.offline() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "not available in offline mode"})
+		return false
+	}
+	return true
+}
+
+// findResource looks up a resource in a collection, for serving resource details without a
+// cluster or from a snapshot
+func findResource(resources *types.ResourceCollection, resourceType, namespace, name string) (interface{}, error) {
+	switch resourceType {
+	case "gatewayclass":
+		for i := range resources.GatewayClasses {
+			if resources.GatewayClasses[i].Name == name {
+				return &resources.GatewayClasses[i], nil
+			}
+		}
+	case "gateway":
+		for i := range resources.Gateways {
+			if resources.Gateways[i].Namespace == namespace && resources.Gateways[i].Name == name {
+				return &resources.Gateways[i], nil
+			}
+		}
+	case "httproute":
+		for i := range resources.HTTPRoutes {
+			if resources.HTTPRoutes[i].Namespace == namespace && resources.HTTPRoutes[i].Name == name {
+				return &resources.HTTPRoutes[i], nil
+			}
+		}
+	case "referencegrant":
+		for i := range resources.ReferenceGrants {
+			if resources.ReferenceGrants[i].Namespace == namespace && resources.ReferenceGrants[i].Name == name {
+				return &resources.ReferenceGrants[i], nil
+			}
+		}
+	case "service":
+		for i := range resources.Services {
+			if resources.Services[i].Namespace == namespace && resources.Services[i].Name == name {
+				return &resources.Services[i], nil
+			}
+		}
+	case "dnsrecord":
+		for i := range resources.DNSRecords {
+			if resources.DNSRecords[i].GetNamespace() == namespace && resources.DNSRecords[i].GetName() == name {
+				return &resources.DNSRecords[i], nil
+			}
+		}
+	case "dnsendpoint":
+		for i := range resources.DNSEndpoints {
+			if resources.DNSEndpoints[i].GetNamespace() == namespace && resources.DNSEndpoints[i].GetName() == name {
+				return &resources.DNSEndpoints[i], nil
+			}
+		}
+	default:
+		// Policies are addressed by their lowercased kind, e.g. backendtlspolicy
+		known := false
+		for i := range resources.Policies {
+			obj := &resources.Policies[i]
+			if !strings.EqualFold(obj.GetKind(), resourceType) {
+				continue
+			}
+			known = true
+			if obj.GetNamespace() == namespace && obj.GetName() == name {
+				return obj, nil
+			}
+		}
+		if !known {
+			return nil, errUnsupportedResourceType
+		}
+	}
+
+	return nil, fmt.Errorf("%s %s/%s not found", resourceType, namespace, name)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/report"
+
+	"github.com/gin-gonic/gin"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// GetGatewayReport returns a Markdown report of one Gateway for design reviews: its listeners,
+// attached routes and rules, backends with ready endpoints, DNS records, ReferenceGrants, a
+// Mermaid diagram and the relevant diagnostics. ?at=<RFC 3339 time> reports on a snapshot.
+func (h *Handler) GetGatewayReport(c *gin.Context) {
+	namespace, name := c.Param("namespace"), c.Param("name")
+
+	resources, ok := h.resourcesFor(c)
+	if !ok {
+		return
+	}
+
+	var gw *gatewayv1.Gateway
+	for i := range resources.Gateways {
+		if resources.Gateways[i].Namespace == namespace && resources.Gateways[i].Name == name {
+			gw = &resources.Gateways[i]
+			break
+		}
+	}
+	if gw == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("gateway %s/%s not found", namespace, name)})
+		return
+	}
+
+	var document bytes.Buffer
+	if err := report.Gateway(&document, resources, h.buildGraph(resources), dnssource.Collect(resources, h.dns), gw, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", document.Bytes())
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"gwapi-graph/internal/simulate"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Simulate routes a described request through the Gateway API resources and returns, for every
+// Gateway listening on its port, the winning route rule, the filters applied, the weighted
+// backends and the path of graph nodes the request takes. ?at=<RFC 3339 time> simulates against
+// a snapshot.
+func (h *Handler) Simulate(c *gin.Context) {
+	var req simulate.Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request description: " + err.Error()})
+		return
+	}
+	if err := req.Normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resources, ok := h.resourcesFor(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, simulate.Run(resources, req))
+}
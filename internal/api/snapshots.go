@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"gwapi-graph/internal/snapshot"
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errSnapshotsDisabled is returned by the history endpoints when no snapshot store is configured
+var errSnapshotsDisabled = errors.New("snapshots are not enabled, start the server with -snapshot-dir")
+
+// ListSnapshots returns the stored snapshots, oldest first. The since and until query parameters
+// (RFC 3339) limit the range.
+func (h *Handler) ListSnapshots(c *gin.Context) {
+	if h.snapshots == nil {
+		c.JSON(http.StatusOK, []snapshot.Info{})
+		return
+	}
+
+	var since, until time.Time
+	for param, target := range map[string]*time.Time{"since": &since, "until": &until} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + " timestamp, expected RFC 3339"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	snapshots, err := h.snapshots.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := []snapshot.Info{}
+	for _, info := range snapshots {
+		if (!since.IsZero() && info.Time.Before(since)) || (!until.IsZero() && info.Time.After(until)) {
+			continue
+		}
+		result = append(result, info)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSnapshotGraph returns the graph as it was in a snapshot
+func (h *Handler) GetSnapshotGraph(c *gin.Context) {
+	resources, ok := h.loadSnapshot(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, h.buildGraph(resources))
+}
+
+// GetSnapshotResources returns the resources stored in a snapshot
+func (h *Handler) GetSnapshotResources(c *gin.Context) {
+	resources, ok := h.loadSnapshot(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, resources)
+}
+
+// loadSnapshot reads a snapshot by ID, responding with an error when it cannot be loaded
+func (h *Handler) loadSnapshot(c *gin.Context, id string) (*types.ResourceCollection, bool) {
+	if h.snapshots == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errSnapshotsDisabled.Error()})
+		return nil, false
+	}
+
+	resources, info, err := h.snapshots.Load(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, snapshot.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	c.Header("X-Snapshot-Id", info.ID)
+	c.Header("X-Snapshot-Time", info.Time.Format(time.RFC3339))
+	return resources, true
+}
+
+// snapshotAt reads the latest snapshot taken at or before the RFC 3339 time in the at query
+// parameter, responding with an error when there is none
+func (h *Handler) snapshotAt(c *gin.Context, at string) (*types.ResourceCollection, bool) {
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid at timestamp, expected RFC 3339"})
+		return nil, false
+	}
+	if h.snapshots == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errSnapshotsDisabled.Error()})
+		return nil, false
+	}
+
+	resources, info, err := h.snapshots.At(t)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, snapshot.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	c.Header("X-Snapshot-Id", info.ID)
+	c.Header("X-Snapshot-Time", info.Time.Format(time.RFC3339))
+	return resources, true
+}
+
+// resourcesFor returns the resources a request is about: the snapshot selected by the at query
+// parameter, else the current resources. It returns false when the request has been answered.
+func (h *Handler) resourcesFor(c *gin.Context) (*types.ResourceCollection, bool) {
+	if at := c.Query("at"); at != "" {
+		return h.snapshotAt(c, at)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resources, err := h.fetchAllResources(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return resources, true
+}
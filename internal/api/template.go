@@ -0,0 +1,285 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// templateEndpoint is a graph node resolved to the resources needed to pre-fill a template
+type templateEndpoint struct {
+	node     types.Node
+	gateway  *gatewayv1.Gateway
+	listener *gatewayv1.Listener
+	route    *gatewayv1.HTTPRoute
+	service  *corev1.Service
+}
+
+// GetResourceTemplate returns a pre-filled manifest for a new resource connecting two graph nodes,
+// identified by the source and target query parameters (node IDs as returned by /api/graph):
+//   - httproute: source is a Gateway or Listener, target is a Service
+//   - referencegrant: source is an HTTPRoute, target is a Service in another namespace
+//
+// The returned object can be edited and sent to POST /api/resource/:type. What the object still
+// needs to take effect, such as a ReferenceGrant, is reported in Warning headers.
+func (h *Handler) GetResourceTemplate(c *gin.Context) {
+	resourceType := c.Param("type")
+	sourceID := c.Query("source")
+	targetID := c.Query("target")
+
+	if sourceID == "" || targetID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "source and target node IDs are required"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resources, err := h.fetchAllResources(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	graph := h.buildGraph(resources)
+	source, err := resolveTemplateEndpoint(resources, graph, sourceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	target, err := resolveTemplateEndpoint(resources, graph, targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var template map[string]interface{}
+	var warnings []string
+	switch resourceType {
+	case "httproute":
+		template, warnings, err = httpRouteTemplate(resources, source, target)
+	case "referencegrant":
+		template, err = referenceGrantTemplate(source, target)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "templates are only available for httproute and referencegrant"})
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, warning := range warnings {
+		c.Writer.Header().Add("Warning", "299 - "+strconv.Quote(warning))
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// resolveTemplateEndpoint finds the graph node with the given ID and the resources behind it
+func resolveTemplateEndpoint(resources *types.ResourceCollection, graph *types.Graph, nodeID string) (*templateEndpoint, error) {
+	for _, node := range graph.Nodes {
+		if node.ID != nodeID {
+			continue
+		}
+
+		endpoint := &templateEndpoint{node: node}
+		switch node.Type {
+		case "Gateway":
+			for i := range resources.Gateways {
+				if string(resources.Gateways[i].UID) == node.ID {
+					endpoint.gateway = &resources.Gateways[i]
+				}
+			}
+		case "Listener":
+			for i := range resources.Gateways {
+				gw := &resources.Gateways[i]
+				if node.ParentID == nil || string(gw.UID) != *node.ParentID {
+					continue
+				}
+				endpoint.gateway = gw
+				for j := range gw.Spec.Listeners {
+					if analysis.ListenerID(gw, j) == node.ID {
+						endpoint.listener = &gw.Spec.Listeners[j]
+					}
+				}
+			}
+		case "HTTPRoute":
+			for i := range resources.HTTPRoutes {
+				if string(resources.HTTPRoutes[i].UID) == node.ID {
+					endpoint.route = &resources.HTTPRoutes[i]
+				}
+			}
+		case "Service":
+			for i := range resources.Services {
+				if string(resources.Services[i].UID) == node.ID {
+					endpoint.service = &resources.Services[i]
+				}
+			}
+		}
+		return endpoint, nil
+	}
+
+	return nil, fmt.Errorf("node %s not found in the graph", nodeID)
+}
+
+// httpRouteTemplate builds an HTTPRoute attached to a Gateway (or one of its listeners) that
+// forwards all traffic to a Service. The route is placed in the Service's namespace when the
+// listeners allow routes from there, and otherwise in the Gateway's namespace, which then needs a
+// ReferenceGrant to reach the Service. The warnings say what the route still needs to take effect.
+func httpRouteTemplate(resources *types.ResourceCollection, source, target *templateEndpoint) (map[string]interface{}, []string, error) {
+	if source.gateway == nil {
+		return nil, nil, fmt.Errorf("the source of an HTTPRoute must be a Gateway or Listener, got %s", source.node.Type)
+	}
+	if target.service == nil {
+		return nil, nil, fmt.Errorf("the target of an HTTPRoute must be a Service, got %s", target.node.Type)
+	}
+
+	gw := source.gateway
+	svc := target.service
+
+	var warnings []string
+	namespace := svc.Namespace
+	if attaches, reason := routeTemplateAttaches(gw, source.listener, namespace); !attaches {
+		if namespace != gw.Namespace {
+			if gwAttaches, _ := routeTemplateAttaches(gw, source.listener, gw.Namespace); gwAttaches {
+				namespace = gw.Namespace
+			}
+		}
+		if namespace == svc.Namespace {
+			warnings = append(warnings, fmt.Sprintf("The route will not attach to Gateway %s/%s: %s.", gw.Namespace, gw.Name, reason))
+		} else if len(analysis.PermittingGrants(resources, "HTTPRoute", namespace, "Service", svc.Namespace, svc.Name)) == 0 {
+			warnings = append(warnings, fmt.Sprintf("The route is placed in namespace %s because %s. Create a ReferenceGrant in namespace %s that allows HTTPRoutes from %s to reference Service %s, e.g. from the referencegrant template once the route exists.", namespace, reason, svc.Namespace, namespace, svc.Name))
+		}
+	}
+
+	parentRef := map[string]interface{}{
+		"name": gw.Name,
+	}
+	if gw.Namespace != namespace {
+		parentRef["namespace"] = gw.Namespace
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+	}
+
+	if source.listener != nil {
+		parentRef["sectionName"] = string(source.listener.Name)
+		if source.listener.Hostname != nil && *source.listener.Hostname != "" {
+			spec["hostnames"] = []interface{}{string(*source.listener.Hostname)}
+		}
+	}
+
+	backendRef := map[string]interface{}{
+		"name": svc.Name,
+	}
+	if svc.Namespace != namespace {
+		backendRef["namespace"] = svc.Namespace
+	}
+	if len(svc.Spec.Ports) > 0 {
+		backendRef["port"] = int64(svc.Spec.Ports[0].Port)
+	}
+
+	spec["rules"] = []interface{}{
+		map[string]interface{}{
+			"matches": []interface{}{
+				map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":  "PathPrefix",
+						"value": "/",
+					},
+				},
+			},
+			"backendRefs": []interface{}{backendRef},
+		},
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata": map[string]interface{}{
+			"name":      templateName(svc.Name, "route"),
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}, warnings, nil
+}
+
+// routeTemplateAttaches reports whether a route in the namespace would attach to the Gateway,
+// through the listener when one is given, and otherwise why not
+func routeTemplateAttaches(gw *gatewayv1.Gateway, listener *gatewayv1.Listener, namespace string) (bool, string) {
+	route := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}}
+	ref := gatewayv1.ParentReference{Name: gatewayv1.ObjectName(gw.Name)}
+	if listener != nil {
+		// Check the listener alone, so that the reason is about it rather than the last listener
+		only := *gw
+		only.Spec.Listeners = []gatewayv1.Listener{*listener}
+		gw = &only
+		ref.SectionName = &listener.Name
+		if listener.Hostname != nil && *listener.Hostname != "" {
+			route.Spec.Hostnames = []gatewayv1.Hostname{*listener.Hostname}
+		}
+	}
+	attached, reason := analysis.AttachedListeners(gw, route, ref)
+	return len(attached) > 0, reason
+}
+
+// referenceGrantTemplate builds a ReferenceGrant in the Service's namespace that allows
+// HTTPRoutes in the route's namespace to reference the Service
+func referenceGrantTemplate(source, target *templateEndpoint) (map[string]interface{}, error) {
+	if source.route == nil {
+		return nil, fmt.Errorf("the source of a ReferenceGrant must be an HTTPRoute, got %s", source.node.Type)
+	}
+	if target.service == nil {
+		return nil, fmt.Errorf("the target of a ReferenceGrant must be a Service, got %s", target.node.Type)
+	}
+	if source.route.Namespace == target.service.Namespace {
+		return nil, fmt.Errorf("HTTPRoute and Service are both in namespace %s; no ReferenceGrant is needed", source.route.Namespace)
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1beta1",
+		"kind":       "ReferenceGrant",
+		"metadata": map[string]interface{}{
+			"name":      templateName("allow-"+source.route.Namespace, "routes"),
+			"namespace": target.service.Namespace,
+		},
+		"spec": map[string]interface{}{
+			"from": []interface{}{
+				map[string]interface{}{
+					"group":     "gateway.networking.k8s.io",
+					"kind":      "HTTPRoute",
+					"namespace": source.route.Namespace,
+				},
+			},
+			"to": []interface{}{
+				map[string]interface{}{
+					"group": "",
+					"kind":  "Service",
+					"name":  target.service.Name,
+				},
+			},
+		},
+	}, nil
+}
+
+// templateName joins a base name and suffix, keeping the result within the 63 character limit
+func templateName(base, suffix string) string {
+	name := strings.ToLower(base + "-" + suffix)
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-.")
+	}
+	return name
+}
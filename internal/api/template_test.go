@@ -0,0 +1,188 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestHTTPRouteTemplate(t *testing.T) {
+	all := testutil.Listener("all", gatewayv1.HTTPProtocolType, 80, "www.example.com")
+	same := testutil.Listener("same", gatewayv1.HTTPProtocolType, 80, "")
+	same.AllowedRoutes = nil
+	tlsOnly := testutil.Listener("tls-only", gatewayv1.HTTPProtocolType, 80, "")
+	tlsOnly.AllowedRoutes.Kinds = []gatewayv1.RouteGroupKind{{Kind: "TLSRoute"}}
+
+	tests := []struct {
+		name         string
+		listener     *gatewayv1.Listener
+		grants       []gatewayv1beta1.ReferenceGrant
+		wantNS       string
+		wantParent   map[string]interface{}
+		wantBackend  map[string]interface{}
+		wantHosts    []interface{}
+		wantWarnings []string
+	}{
+		{
+			name:        "listener allowing every namespace",
+			listener:    &all,
+			wantNS:      "app",
+			wantParent:  map[string]interface{}{"name": "gw", "namespace": "infra", "sectionName": "all"},
+			wantBackend: map[string]interface{}{"name": "web", "port": int64(8080)},
+			wantHosts:   []interface{}{"www.example.com"},
+		},
+		{
+			name:        "Gateway",
+			wantNS:      "app",
+			wantParent:  map[string]interface{}{"name": "gw", "namespace": "infra"},
+			wantBackend: map[string]interface{}{"name": "web", "port": int64(8080)},
+		},
+		{
+			name:        "listener allowing its own namespace",
+			listener:    &same,
+			wantNS:      "infra",
+			wantParent:  map[string]interface{}{"name": "gw", "sectionName": "same"},
+			wantBackend: map[string]interface{}{"name": "web", "namespace": "app", "port": int64(8080)},
+			wantWarnings: []string{"The route is placed in namespace infra because listener \"same\" does not allow routes from namespace app. " +
+				"Create a ReferenceGrant in namespace app that allows HTTPRoutes from infra to reference Service web, e.g. from the referencegrant template once the route exists."},
+		},
+		{
+			name:        "listener allowing its own namespace with a grant",
+			listener:    &same,
+			grants:      []gatewayv1beta1.ReferenceGrant{testutil.ReferenceGrant("app", "allow-infra", "HTTPRoute", "infra", "Service")},
+			wantNS:      "infra",
+			wantParent:  map[string]interface{}{"name": "gw", "sectionName": "same"},
+			wantBackend: map[string]interface{}{"name": "web", "namespace": "app", "port": int64(8080)},
+		},
+		{
+			name:         "listener accepting no HTTPRoutes",
+			listener:     &tlsOnly,
+			wantNS:       "app",
+			wantParent:   map[string]interface{}{"name": "gw", "namespace": "infra", "sectionName": "tls-only"},
+			wantBackend:  map[string]interface{}{"name": "web", "port": int64(8080)},
+			wantWarnings: []string{"The route will not attach to Gateway infra/gw: listener \"tls-only\" does not allow HTTPRoutes."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := testutil.Gateway("infra", "gw", all, same, tlsOnly)
+			svc := testutil.Service("app", "web", 8080, 9090)
+			resources := &types.ResourceCollection{ReferenceGrants: tt.grants}
+
+			template, warnings, err := httpRouteTemplate(resources,
+				&templateEndpoint{node: types.Node{Type: "Listener"}, gateway: &gw, listener: tt.listener},
+				&templateEndpoint{node: types.Node{Type: "Service"}, service: &svc})
+			if err != nil {
+				t.Fatalf("httpRouteTemplate: %v", err)
+			}
+
+			metadata := template["metadata"].(map[string]interface{})
+			if metadata["name"] != "web-route" || metadata["namespace"] != tt.wantNS {
+				t.Errorf("metadata = %v, want web-route in %s", metadata, tt.wantNS)
+			}
+			spec := template["spec"].(map[string]interface{})
+			if got := spec["parentRefs"].([]interface{})[0]; !reflect.DeepEqual(got, tt.wantParent) {
+				t.Errorf("parentRef = %v, want %v", got, tt.wantParent)
+			}
+			rule := spec["rules"].([]interface{})[0].(map[string]interface{})
+			if got := rule["backendRefs"].([]interface{})[0]; !reflect.DeepEqual(got, tt.wantBackend) {
+				t.Errorf("backendRef = %v, want %v", got, tt.wantBackend)
+			}
+			if got, _ := spec["hostnames"].([]interface{}); !reflect.DeepEqual(got, tt.wantHosts) {
+				t.Errorf("hostnames = %v, want %v", got, tt.wantHosts)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestHTTPRouteTemplateEndpoints(t *testing.T) {
+	gw := testutil.Gateway("infra", "gw")
+	svc := testutil.Service("app", "web", 80)
+	gateway := &templateEndpoint{node: types.Node{Type: "Gateway"}, gateway: &gw}
+	service := &templateEndpoint{node: types.Node{Type: "Service"}, service: &svc}
+
+	if _, _, err := httpRouteTemplate(&types.ResourceCollection{}, service, service); err == nil || !strings.Contains(err.Error(), "got Service") {
+		t.Errorf("httpRouteTemplate from a Service = %v, want an error", err)
+	}
+	if _, _, err := httpRouteTemplate(&types.ResourceCollection{}, gateway, gateway); err == nil || !strings.Contains(err.Error(), "got Gateway") {
+		t.Errorf("httpRouteTemplate to a Gateway = %v, want an error", err)
+	}
+}
+
+func TestReferenceGrantTemplate(t *testing.T) {
+	route := testutil.HTTPRoute("infra", "r")
+	svc := testutil.Service("app", "web", 80)
+	local := testutil.Service("infra", "local", 80)
+	routeEndpoint := &templateEndpoint{node: types.Node{Type: "HTTPRoute"}, route: &route}
+
+	template, err := referenceGrantTemplate(routeEndpoint, &templateEndpoint{node: types.Node{Type: "Service"}, service: &svc})
+	if err != nil {
+		t.Fatalf("referenceGrantTemplate: %v", err)
+	}
+	want := map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1beta1",
+		"kind":       "ReferenceGrant",
+		"metadata":   map[string]interface{}{"name": "allow-infra-routes", "namespace": "app"},
+		"spec": map[string]interface{}{
+			"from": []interface{}{map[string]interface{}{"group": "gateway.networking.k8s.io", "kind": "HTTPRoute", "namespace": "infra"}},
+			"to":   []interface{}{map[string]interface{}{"group": "", "kind": "Service", "name": "web"}},
+		},
+	}
+	if !reflect.DeepEqual(template, want) {
+		t.Errorf("template = %v, want %v", template, want)
+	}
+
+	if _, err := referenceGrantTemplate(routeEndpoint, &templateEndpoint{node: types.Node{Type: "Service"}, service: &local}); err == nil {
+		t.Errorf("referenceGrantTemplate within one namespace succeeded, want an error")
+	}
+	if _, err := referenceGrantTemplate(&templateEndpoint{node: types.Node{Type: "Service"}, service: &svc}, routeEndpoint); err == nil {
+		t.Errorf("referenceGrantTemplate from a Service succeeded, want an error")
+	}
+}
+
+func TestResolveTemplateEndpoint(t *testing.T) {
+	resources := &types.ResourceCollection{
+		Gateways: []gatewayv1.Gateway{testutil.Gateway("infra", "gw", testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, ""))},
+		Services: []corev1.Service{testutil.Service("app", "web", 80)},
+	}
+	graph := &types.Graph{Nodes: []types.Node{
+		{ID: "infra/gw", Type: "Gateway"},
+		{ID: "infra/gw-listener-0", Type: "Listener", ParentID: testutil.Ptr("infra/gw")},
+		{ID: "app/web", Type: "Service"},
+	}}
+
+	listener, err := resolveTemplateEndpoint(resources, graph, "infra/gw-listener-0")
+	if err != nil {
+		t.Fatalf("resolveTemplateEndpoint: %v", err)
+	}
+	if listener.gateway != &resources.Gateways[0] || listener.listener == nil || listener.listener.Name != "http" {
+		t.Errorf("listener endpoint = %+v, want the http listener of infra/gw", listener)
+	}
+	if service, _ := resolveTemplateEndpoint(resources, graph, "app/web"); service == nil || service.service != &resources.Services[0] {
+		t.Errorf("service endpoint = %+v, want app/web", service)
+	}
+	if _, err := resolveTemplateEndpoint(resources, graph, "missing"); err == nil {
+		t.Errorf("resolveTemplateEndpoint of a missing node succeeded, want an error")
+	}
+}
+
+func TestTemplateName(t *testing.T) {
+	if got := templateName("Web", "route"); got != "web-route" {
+		t.Errorf("templateName = %q, want web-route", got)
+	}
+	long := templateName(strings.Repeat("a", 61)+"-b", "route")
+	if len(long) > 63 || strings.HasSuffix(long, "-") {
+		t.Errorf("templateName of a long name = %q, want at most 63 characters without a trailing dash", long)
+	}
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/types"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// ValidateResource runs an update as a server-side dry run (DryRun=All) and reports whether
+// the API server would accept it, along with a preview of how the graph would change
+func (h *Handler) ValidateResource(c *gin.Context) {
+	if !h.requireCluster(c) {
+		return
+	}
+
+	req, status, err := parseUpdateRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Only a rejected object is a validation result; failing to reach or use the API server is not
+	updated, err := h.updateResource(ctx, req, true)
+	if apierrors.IsInvalid(err) || apierrors.IsBadRequest(err) {
+		c.JSON(http.StatusOK, validationFailure(err))
+		return
+	}
+	if err != nil {
+		c.JSON(apiErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	resources, err := h.fetchAllResources(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.ValidationResult{
+		Valid:   true,
+		Message: "the API server accepted the change (dry run)",
+		Preview: h.previewChange(resources, updated),
+	})
+}
+
+// validationFailure converts an update error into a ValidationResult, extracting the
+// field-level causes when the error came from the API server
+func validationFailure(err error) types.ValidationResult {
+	result := types.ValidationResult{
+		Valid:   false,
+		Message: err.Error(),
+		Errors:  []types.FieldError{},
+	}
+
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		apiStatus := status.Status()
+		result.Message = apiStatus.Message
+		if apiStatus.Details != nil {
+			for _, cause := range apiStatus.Details.Causes {
+				result.Errors = append(result.Errors, types.FieldError{
+					Field:   cause.Field,
+					Type:    string(cause.Type),
+					Message: cause.Message,
+				})
+			}
+		}
+	}
+
+	// Bad requests (e.g. immutable field checks) have no causes, so surface the message itself as
+	// the single error
+	if len(result.Errors) == 0 {
+		result.Errors = append(result.Errors, types.FieldError{Message: result.Message})
+	}
+
+	return result
+}
+
+// previewChange compares the graph built from the current resources with the graph
+// built after substituting the updated object
+func (h *Handler) previewChange(resources *types.ResourceCollection, updated interface{}) *types.GraphPreview {
+	after := withUpdatedResource(resources, updated)
+
+	beforeGraph := h.buildGraph(resources)
+	afterGraph := h.buildGraph(after)
+
+	preview := &types.GraphPreview{
+		AddedLinks:       []types.LinkChange{},
+		RemovedLinks:     []types.LinkChange{},
+		DetachedRoutes:   []string{},
+		DanglingBackends: []string{},
+	}
+
+	beforeLinks := linkChanges(beforeGraph)
+	afterLinks := linkChanges(afterGraph)
+
+	for key, link := range afterLinks {
+		if _, exists := beforeLinks[key]; !exists {
+			preview.AddedLinks = append(preview.AddedLinks, link)
+		}
+	}
+	for key, link := range beforeLinks {
+		if _, exists := afterLinks[key]; !exists {
+			preview.RemovedLinks = append(preview.RemovedLinks, link)
+		}
+	}
+	sortLinkChanges(preview.AddedLinks)
+	sortLinkChanges(preview.RemovedLinks)
+
+	// Routes that are attached today but would attach to no listener afterwards
+	beforeAttached := attachedRoutes(beforeGraph)
+	afterAttached := attachedRoutes(afterGraph)
+	for route := range beforeAttached {
+		if !afterAttached[route] {
+			preview.DetachedRoutes = append(preview.DetachedRoutes, route)
+		}
+	}
+	sort.Strings(preview.DetachedRoutes)
+
+	// backendRefs that resolve today but would no longer resolve afterwards
+	beforeDangling := make(map[string]bool)
+	for _, problem := range backendProblems(resources) {
+		beforeDangling[problem.key] = true
+	}
+	for _, problem := range backendProblems(after) {
+		if !beforeDangling[problem.key] {
+			preview.DanglingBackends = append(preview.DanglingBackends, problem.description)
+			beforeDangling[problem.key] = true
+		}
+	}
+	sort.Strings(preview.DanglingBackends)
+
+	return preview
+}
+
+// withUpdatedResource returns a copy of the collection with the matching object replaced
+// by the updated one. The original collection is left untouched.
+func withUpdatedResource(resources *types.ResourceCollection, updated interface{}) *types.ResourceCollection {
+	after := *resources
+
+	switch obj := updated.(type) {
+	case *gatewayv1.GatewayClass:
+		after.GatewayClasses = append([]gatewayv1.GatewayClass(nil), resources.GatewayClasses...)
+		for i := range after.GatewayClasses {
+			if after.GatewayClasses[i].Name == obj.Name {
+				after.GatewayClasses[i] = *obj
+			}
+		}
+	case *gatewayv1.Gateway:
+		after.Gateways = append([]gatewayv1.Gateway(nil), resources.Gateways...)
+		for i := range after.Gateways {
+			if after.Gateways[i].Namespace == obj.Namespace && after.Gateways[i].Name == obj.Name {
+				after.Gateways[i] = *obj
+			}
+		}
+	case *gatewayv1.HTTPRoute:
+		after.HTTPRoutes = append([]gatewayv1.HTTPRoute(nil), resources.HTTPRoutes...)
+		for i := range after.HTTPRoutes {
+			if after.HTTPRoutes[i].Namespace == obj.Namespace && after.HTTPRoutes[i].Name == obj.Name {
+				after.HTTPRoutes[i] = *obj
+			}
+		}
+	case *gatewayv1beta1.ReferenceGrant:
+		after.ReferenceGrants = append([]gatewayv1beta1.ReferenceGrant(nil), resources.ReferenceGrants...)
+		for i := range after.ReferenceGrants {
+			if after.ReferenceGrants[i].Namespace == obj.Namespace && after.ReferenceGrants[i].Name == obj.Name {
+				after.ReferenceGrants[i] = *obj
+			}
+		}
+	case *corev1.Service:
+		after.Services = append([]corev1.Service(nil), resources.Services...)
+		for i := range after.Services {
+			if after.Services[i].Namespace == obj.Namespace && after.Services[i].Name == obj.Name {
+				after.Services[i] = *obj
+			}
+		}
+	case *unstructured.Unstructured:
+		switch gvk := obj.GroupVersionKind(); {
+		case gvk.Group == "ingress.operator.openshift.io" && gvk.Kind == "DNSRecord":
+			after.DNSRecords = withUpdatedObject(resources.DNSRecords, obj)
+		case gvk.Group == "externaldns.k8s.io" && gvk.Kind == "DNSEndpoint":
+			after.DNSEndpoints = withUpdatedObject(resources.DNSEndpoints, obj)
+		default:
+			// Every other unstructured kind the API edits is a policy
+			after.Policies = withUpdatedObject(resources.Policies, obj)
+		}
+	}
+
+	return &after
+}
+
+// withUpdatedObject returns a copy of a list of unstructured objects with the object of the same
+// group, kind, namespace and name replaced by the updated one
+func withUpdatedObject(objects []unstructured.Unstructured, updated *unstructured.Unstructured) []unstructured.Unstructured {
+	objects = append([]unstructured.Unstructured(nil), objects...)
+	gvk := updated.GroupVersionKind()
+	for i := range objects {
+		current := objects[i].GroupVersionKind()
+		if current.Group == gvk.Group && current.Kind == gvk.Kind &&
+			objects[i].GetNamespace() == updated.GetNamespace() && objects[i].GetName() == updated.GetName() {
+			objects[i] = *updated
+		}
+	}
+	return objects
+}
+
+// nodeLabel returns a human readable identifier for a node that is stable across graph builds
+func nodeLabel(node types.Node) string {
+	if node.Namespace == "" {
+		return fmt.Sprintf("%s %s", node.Kind, node.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", node.Kind, node.Namespace, node.Name)
+}
+
+// linkChanges indexes the links of a graph by their source, target and type labels
+func linkChanges(graph *types.Graph) map[string]types.LinkChange {
+	links := make(map[string]types.LinkChange)
+	for _, link := range graph.Links {
+		if link.Source >= len(graph.Nodes) || link.Target >= len(graph.Nodes) {
+			continue
+		}
+		change := types.LinkChange{
+			Source: nodeLabel(graph.Nodes[link.Source]),
+			Target: nodeLabel(graph.Nodes[link.Target]),
+			Type:   link.Type,
+		}
+		links[change.Source+"|"+change.Type+"|"+change.Target] = change
+	}
+	return links
+}
+
+// sortLinkChanges orders link changes by source, then target, then type
+func sortLinkChanges(links []types.LinkChange) {
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Source != links[j].Source {
+			return links[i].Source < links[j].Source
+		}
+		if links[i].Target != links[j].Target {
+			return links[i].Target < links[j].Target
+		}
+		return links[i].Type < links[j].Type
+	})
+}
+
+// attachedRoutes returns the labels of all HTTPRoute nodes linked to at least one Listener. The
+// parentRef links from a Gateway only keep routes that attach to no listener visible.
+func attachedRoutes(graph *types.Graph) map[string]bool {
+	attached := make(map[string]bool)
+	for _, link := range graph.Links {
+		if link.Type != "parentRef" || link.Source >= len(graph.Nodes) || link.Target >= len(graph.Nodes) {
+			continue
+		}
+		if graph.Nodes[link.Source].Type != "Listener" {
+			continue
+		}
+		if node := graph.Nodes[link.Target]; node.Type == "HTTPRoute" {
+			attached[nodeLabel(node)] = true
+		}
+	}
+	return attached
+}
+
+// backendProblem is a backendRef that does not resolve, as reported by the analysis
+type backendProblem struct {
+	// key identifies the problem without the rule and backendRef indexes, which shift when
+	// rules or backendRefs are added or removed
+	key         string
+	description string
+}
+
+// backendProblems lists the backendRefs that refer to a missing Service or port, or to a Service
+// in another namespace that no ReferenceGrant permits
+func backendProblems(resources *types.ResourceCollection) []backendProblem {
+	var problems []backendProblem
+	for _, finding := range analysis.BackendRefProblems(resources) {
+		route := fmt.Sprintf("%s %s/%s", finding.Resource.Kind, finding.Resource.Namespace, finding.Resource.Name)
+		// Messages start with the location of the backendRef, e.g. rules[0].backendRefs[1]
+		_, problem, _ := strings.Cut(finding.Message, " ")
+		problems = append(problems, backendProblem{
+			key:         route + ": " + problem,
+			description: route + ": " + finding.Message,
+		})
+	}
+	return problems
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gwapi-graph/internal/source"
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestValidationFailure(t *testing.T) {
+	invalid := apierrors.NewInvalid(schema.GroupKind{Group: "gateway.networking.k8s.io", Kind: "Gateway"}, "gw", field.ErrorList{
+		field.Required(field.NewPath("spec", "gatewayClassName"), ""),
+		field.NotSupported(field.NewPath("spec", "listeners").Index(0).Child("protocol"), "FTP", []string{"HTTP", "HTTPS"}),
+	})
+
+	tests := []struct {
+		name        string
+		err         error
+		wantMessage string
+		wantErrors  []types.FieldError
+	}{
+		{
+			name:        "invalid object",
+			err:         fmt.Errorf("dry run: %w", invalid),
+			wantMessage: invalid.Error(),
+			wantErrors: []types.FieldError{
+				{Field: "spec.gatewayClassName", Type: "FieldValueRequired", Message: "Required value"},
+				{Field: "spec.listeners[0].protocol", Type: "FieldValueNotSupported", Message: `Unsupported value: "FTP": supported values: "HTTP", "HTTPS"`},
+			},
+		},
+		{
+			name:        "bad request without causes",
+			err:         apierrors.NewBadRequest("spec.gatewayClassName is immutable"),
+			wantMessage: "spec.gatewayClassName is immutable",
+			wantErrors:  []types.FieldError{{Message: "spec.gatewayClassName is immutable"}},
+		},
+		{
+			name:        "other error",
+			err:         errors.New("unexpected end of JSON input"),
+			wantMessage: "unexpected end of JSON input",
+			wantErrors:  []types.FieldError{{Message: "unexpected end of JSON input"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validationFailure(tt.err)
+			if result.Valid {
+				t.Errorf("Valid = true, want false")
+			}
+			if result.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", result.Message, tt.wantMessage)
+			}
+			if !reflect.DeepEqual(result.Errors, tt.wantErrors) {
+				t.Errorf("Errors = %+v, want %+v", result.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+// previewResources returns a Gateway with one listener, the route app/site attached to it and the
+// Services app/web and shared/api. One backendRef of the route already refers to a missing Service.
+func previewResources() *types.ResourceCollection {
+	route := testutil.HTTPRoute("app", "site", testutil.ParentRef("infra", "gw"))
+	route.Spec.Rules = []gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{
+		testutil.BackendRef("", "web", 8080),
+		testutil.BackendRef("", "missing", 80),
+	}}}
+	return &types.ResourceCollection{
+		GatewayClasses: []gatewayv1.GatewayClass{testutil.GatewayClass("example")},
+		Gateways:       []gatewayv1.Gateway{testutil.Gateway("infra", "gw", testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, ""))},
+		HTTPRoutes:     []gatewayv1.HTTPRoute{route},
+		Services:       []corev1.Service{testutil.Service("app", "web", 8080), testutil.Service("shared", "api", 80)},
+	}
+}
+
+func TestPreviewChange(t *testing.T) {
+	h := NewHandler(nil, WithSource(&source.Manifests{}))
+
+	tests := []struct {
+		name         string
+		resources    *types.ResourceCollection
+		update       func(route *gatewayv1.HTTPRoute)
+		wantDetached []string
+		wantDangling []string
+		wantAdded    []string
+		wantRemoved  []string
+	}{
+		{
+			// The existing rule moves to index 1 without its missing Service being reported again
+			name:      "new rule in front of the existing one",
+			resources: previewResources(),
+			update: func(route *gatewayv1.HTTPRoute) {
+				route.Spec.Rules = append([]gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{
+					testutil.BackendRef("", "web", 9090),
+					testutil.BackendRef("shared", "api", 80),
+				}}}, route.Spec.Rules...)
+			},
+			wantDangling: []string{
+				"HTTPRoute app/site: rules[0].backendRefs[0] refers to port 9090 of Service app/web, which does not expose it.",
+				"HTTPRoute app/site: rules[0].backendRefs[1] refers to Service shared/api in another namespace, and no ReferenceGrant permits it.",
+			},
+			wantAdded: []string{"HTTPRoute app/site -backendRef-> Service shared/api"},
+		},
+		{
+			name: "cross-namespace backendRef with a ReferenceGrant",
+			resources: func() *types.ResourceCollection {
+				resources := previewResources()
+				resources.ReferenceGrants = []gatewayv1beta1.ReferenceGrant{testutil.ReferenceGrant("shared", "allow-app", "HTTPRoute", "app", "Service")}
+				return resources
+			}(),
+			update: func(route *gatewayv1.HTTPRoute) {
+				route.Spec.Rules[0].BackendRefs[0] = testutil.BackendRef("shared", "api", 80)
+			},
+			wantAdded:   []string{"HTTPRoute app/site -backendRef-> Service shared/api"},
+			wantRemoved: []string{"HTTPRoute app/site -backendRef-> Service app/web"},
+		},
+		{
+			name:      "route moved to a missing Gateway",
+			resources: previewResources(),
+			update: func(route *gatewayv1.HTTPRoute) {
+				route.Spec.ParentRefs = []gatewayv1.ParentReference{testutil.ParentRef("infra", "missing")}
+			},
+			wantDetached: []string{"HTTPRoute app/site"},
+			wantRemoved:  []string{"Listener infra/http -parentRef-> HTTPRoute app/site"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := tt.resources.HTTPRoutes[0].DeepCopy()
+			tt.update(updated)
+			preview := h.previewChange(tt.resources, updated)
+
+			if !reflect.DeepEqual(preview.DetachedRoutes, nonNil(tt.wantDetached)) {
+				t.Errorf("DetachedRoutes = %q, want %q", preview.DetachedRoutes, tt.wantDetached)
+			}
+			if !reflect.DeepEqual(preview.DanglingBackends, nonNil(tt.wantDangling)) {
+				t.Errorf("DanglingBackends = %q, want %q", preview.DanglingBackends, tt.wantDangling)
+			}
+			if got := linkSummaries(preview.AddedLinks); !reflect.DeepEqual(got, tt.wantAdded) {
+				t.Errorf("AddedLinks = %q, want %q", got, tt.wantAdded)
+			}
+			if got := linkSummaries(preview.RemovedLinks); !reflect.DeepEqual(got, tt.wantRemoved) {
+				t.Errorf("RemovedLinks = %q, want %q", got, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+// linkSummaries formats link changes as "source -type-> target"
+func linkSummaries(links []types.LinkChange) []string {
+	var summaries []string
+	for _, link := range links {
+		summaries = append(summaries, fmt.Sprintf("%s -%s-> %s", link.Source, link.Type, link.Target))
+	}
+	return summaries
+}
+
+// nonNil returns an empty slice for nil, matching the preview's JSON-friendly empty lists
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
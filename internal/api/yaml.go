@@ -0,0 +1,229 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gwapi-graph/internal/k8s"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// mimeYAML is the content type used for YAML responses
+const mimeYAML = "application/yaml"
+
+// noisyAnnotations are annotations stripped from returned objects unless ?full=true is set
+var noisyAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// isYAMLContentType reports whether a media type denotes a plain YAML document
+func isYAMLContentType(contentType string) bool {
+	switch contentType {
+	case mimeYAML, "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
+
+// wantsYAML reports whether the client asked for a YAML response via the Accept header
+func wantsYAML(c *gin.Context) bool {
+	for _, accepted := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(accepted, ";", 2)[0])
+		if isYAMLContentType(mediaType) {
+			return true
+		}
+		if mediaType == "application/json" {
+			return false
+		}
+	}
+	return false
+}
+
+// decodeYAMLObject parses a single YAML document into a JSON-compatible object
+func decodeYAMLObject(body []byte) (map[string]interface{}, error) {
+	jsonBody, err := sigsyaml.YAMLToJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(jsonBody, &object); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if object == nil {
+		return nil, fmt.Errorf("invalid YAML: expected an object")
+	}
+	return object, nil
+}
+
+// prepareForOutput fills in apiVersion/kind on typed objects and, unless full is set, strips
+// managedFields and other server-side noise from the object's metadata
+func prepareForOutput(resourceType string, obj interface{}, full bool) {
+	if runtimeObj, ok := obj.(runtime.Object); ok {
+		if gvk, found := k8s.GroupVersionKind(resourceType); found && runtimeObj.GetObjectKind().GroupVersionKind().Empty() {
+			runtimeObj.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+	}
+
+	if full {
+		return
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	accessor.SetManagedFields(nil)
+
+	if annotations := accessor.GetAnnotations(); annotations != nil {
+		for _, key := range noisyAnnotations {
+			delete(annotations, key)
+		}
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		accessor.SetAnnotations(annotations)
+	}
+}
+
+// respondYAML writes obj as a YAML document. When commentsFrom holds the YAML the client submitted,
+// its comments are carried over to the matching keys of the response.
+func respondYAML(c *gin.Context, status int, obj interface{}, commentsFrom []byte) {
+	out, err := marshalYAML(obj, commentsFrom)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(status, mimeYAML+"; charset=utf-8", out)
+}
+
+// marshalYAML encodes obj as block-style YAML, keeping the field order of typed objects
+// (apiVersion, kind, metadata, spec, status) rather than sorting keys alphabetically
+func marshalYAML(obj interface{}, commentsFrom []byte) ([]byte, error) {
+	jsonBody, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal object: %w", err)
+	}
+
+	// JSON is valid YAML, so parsing it yields a node tree in flow style that only needs restyling
+	var doc yaml.Node
+	if err := yaml.Unmarshal(jsonBody, &doc); err != nil {
+		return nil, fmt.Errorf("failed to convert object to YAML: %w", err)
+	}
+	normalizeNode(&doc)
+	if len(doc.Content) > 0 {
+		moveKeyFirst(doc.Content[0], "apiVersion")
+	}
+
+	if len(commentsFrom) > 0 {
+		var source yaml.Node
+		if err := yaml.Unmarshal(commentsFrom, &source); err == nil {
+			transferComments(&source, &doc)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeNode resets the style of every node so the encoder picks block style and only quotes
+// scalars where YAML requires it, and drops mapping entries with null values (such as the
+// creationTimestamp of an object that was never stored) which carry no information
+func normalizeNode(node *yaml.Node) {
+	node.Style = 0
+
+	if node.Kind == yaml.MappingNode {
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if value := node.Content[i+1]; value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+				continue
+			}
+			content = append(content, node.Content[i], node.Content[i+1])
+		}
+		node.Content = content
+	}
+
+	for _, child := range node.Content {
+		normalizeNode(child)
+	}
+}
+
+// moveKeyFirst moves a mapping entry to the front, so that typed objects (whose TypeMeta
+// marshals kind before apiVersion) read like the manifests kubectl produces
+func moveKeyFirst(node *yaml.Node, key string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 2; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			entry := []*yaml.Node{node.Content[i], node.Content[i+1]}
+			copy(node.Content[2:i+2], node.Content[:i])
+			copy(node.Content[:2], entry)
+			return
+		}
+	}
+}
+
+// transferComments copies comments from src onto the nodes of dst at the same path. Mapping
+// entries are matched by key and sequence items by index; anything without a counterpart in
+// dst (for example a comment on a field the server dropped) is lost.
+func transferComments(src, dst *yaml.Node) {
+	if src == nil || dst == nil {
+		return
+	}
+
+	copyComments(src, dst)
+
+	switch {
+	case src.Kind == yaml.DocumentNode && dst.Kind == yaml.DocumentNode:
+		if len(src.Content) > 0 && len(dst.Content) > 0 {
+			transferComments(src.Content[0], dst.Content[0])
+		}
+	case src.Kind == yaml.MappingNode && dst.Kind == yaml.MappingNode:
+		dstEntries := make(map[string]int)
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			dstEntries[dst.Content[i].Value] = i
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			j, ok := dstEntries[src.Content[i].Value]
+			if !ok {
+				continue
+			}
+			copyComments(src.Content[i], dst.Content[j])
+			transferComments(src.Content[i+1], dst.Content[j+1])
+		}
+	case src.Kind == yaml.SequenceNode && dst.Kind == yaml.SequenceNode:
+		for i := 0; i < len(src.Content) && i < len(dst.Content); i++ {
+			transferComments(src.Content[i], dst.Content[i])
+		}
+	}
+}
+
+// copyComments copies the head, line and foot comments of a single node
+func copyComments(src, dst *yaml.Node) {
+	if src.HeadComment != "" {
+		dst.HeadComment = src.HeadComment
+	}
+	if src.LineComment != "" {
+		dst.LineComment = src.LineComment
+	}
+	if src.FootComment != "" {
+		dst.FootComment = src.FootComment
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	gw := &gatewayv1.Gateway{
+		TypeMeta:   metav1.TypeMeta{Kind: "Gateway", APIVersion: "gateway.networking.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "gw"},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "example",
+			Listeners:        []gatewayv1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType}},
+		},
+	}
+
+	out, err := marshalYAML(gw, nil)
+	if err != nil {
+		t.Fatalf("marshalYAML: %v", err)
+	}
+	// apiVersion first, then the struct's field order; the null creationTimestamp is dropped
+	want := `apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw
+  namespace: infra
+spec:
+  gatewayClassName: example
+  listeners:
+    - name: http
+      port: 80
+      protocol: HTTP
+status: {}
+`
+	if string(out) != want {
+		t.Errorf("marshalYAML =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestMarshalYAMLKeepsComments(t *testing.T) {
+	submitted := []byte(`# The public Gateway
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw # Referenced by every route
+  namespace: infra
+  annotations:
+    # Dropped by the server along with its comment
+    example.com/ignored: "true"
+spec:
+  listeners:
+    # Plain HTTP
+    - name: http
+      port: 80
+      protocol: HTTP
+`)
+	stored := map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"name": "gw", "namespace": "infra", "resourceVersion": "2"},
+		"spec": map[string]interface{}{"listeners": []interface{}{
+			map[string]interface{}{"name": "http", "port": 80, "protocol": "HTTP"},
+		}},
+	}
+
+	out, err := marshalYAML(stored, submitted)
+	if err != nil {
+		t.Fatalf("marshalYAML: %v", err)
+	}
+	for _, want := range []string{"# The public Gateway\napiVersion:", "name: gw # Referenced by every route", "# Plain HTTP\n    - name: http", "resourceVersion: \"2\""} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "Dropped by the server") {
+		t.Errorf("the comment of a removed field was kept:\n%s", out)
+	}
+
+	// Unparsable input leaves the output without comments rather than failing
+	if _, err := marshalYAML(stored, []byte("key: [")); err != nil {
+		t.Errorf("marshalYAML with unparsable comments = %v, want nil", err)
+	}
+}
+
+func TestMoveKeyFirst(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("kind: Gateway\nmetadata: {}\napiVersion: v1\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	mapping := doc.Content[0]
+	moveKeyFirst(mapping, "apiVersion")
+	moveKeyFirst(mapping, "missing")
+
+	var keys []string
+	for i := 0; i < len(mapping.Content); i += 2 {
+		keys = append(keys, mapping.Content[i].Value)
+	}
+	if got := strings.Join(keys, ","); got != "apiVersion,kind,metadata" {
+		t.Errorf("keys = %s, want apiVersion,kind,metadata", got)
+	}
+	if mapping.Content[1].Value != "v1" {
+		t.Errorf("apiVersion value = %q, want v1", mapping.Content[1].Value)
+	}
+}
+
+func TestWantsYAML(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/yaml", true},
+		{"text/yaml; charset=utf-8", true},
+		{"application/json, application/yaml", false},
+		{"text/html, application/x-yaml;q=0.9", true},
+		{"*/*", false},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/resource/gateway/infra/gw", nil)
+		c.Request.Header.Set("Accept", tt.accept)
+		if got := wantsYAML(c); got != tt.want {
+			t.Errorf("wantsYAML(%q) = %t, want %t", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeYAMLObject(t *testing.T) {
+	object, err := decodeYAMLObject([]byte("kind: Gateway\nmetadata:\n  name: gw\n"))
+	if err != nil || object["kind"] != "Gateway" {
+		t.Errorf("decodeYAMLObject = %v, %v, want a Gateway", object, err)
+	}
+	for _, body := range []string{"key: [", "- a\n- b\n", ""} {
+		if _, err := decodeYAMLObject([]byte(body)); err == nil {
+			t.Errorf("decodeYAMLObject(%q) succeeded, want an error", body)
+		}
+	}
+}
+
+func TestPrepareForOutput(t *testing.T) {
+	gw := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{
+		Name:          "gw",
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		Annotations:   map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+	}}
+
+	prepareForOutput("gateway", gw, false)
+	if gw.Kind != "Gateway" || gw.APIVersion != "gateway.networking.k8s.io/v1" {
+		t.Errorf("type = %s %s, want gateway.networking.k8s.io/v1 Gateway", gw.APIVersion, gw.Kind)
+	}
+	if gw.ManagedFields != nil || gw.Annotations != nil {
+		t.Errorf("metadata = %+v, want managedFields and the last-applied annotation stripped", gw.ObjectMeta)
+	}
+
+	full := &gatewayv1.Gateway{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}}}
+	prepareForOutput("gateway", full, true)
+	if len(full.ManagedFields) != 1 {
+		t.Errorf("full output stripped managedFields")
+	}
+}
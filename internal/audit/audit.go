@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gwapi-graph/internal/diff"
+)
+
+// maxHistory is the number of entries kept in memory for GET /api/audit
+const maxHistory = 5000
+
+// Result values recorded on entries
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Entry records a single mutating call made through the visualizer
+type Entry struct {
+	Time     time.Time     `json:"time"`
+	User     string        `json:"user"`
+	SourceIP string        `json:"sourceIP,omitempty"`
+	Action   string        `json:"action"` // create, update or delete
+	Resource Resource      `json:"resource"`
+	Changes  []diff.Change `json:"changes,omitempty"` // Field-level diff between the object before and after the call
+	Result   string        `json:"result"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Resource identifies the object an entry refers to
+type Resource struct {
+	Type       string `json:"type"` // Resource type as used in API paths, e.g. httproute
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+}
+
+// Sink receives audit entries as they are recorded
+type Sink interface {
+	Write(entry Entry) error
+}
+
+// Filter selects entries in Query. Empty fields match everything.
+type Filter struct {
+	User      string
+	Action    string
+	Type      string
+	Namespace string
+	Name      string
+	Result    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+// Logger fans entries out to its sinks and keeps a bounded in-memory history for queries
+type Logger struct {
+	mu      sync.RWMutex
+	sinks   []Sink
+	history []Entry
+}
+
+// NewLogger creates an audit logger writing to the given sinks
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{
+		sinks:   sinks,
+		history: []Entry{},
+	}
+}
+
+// Record stores an entry in the history and writes it to every sink. Sink failures are logged
+// rather than returned, so a broken sink never blocks the change it is recording.
+func (l *Logger) Record(entry Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+
+	l.mu.Lock()
+	l.appendHistory(entry)
+	sinks := l.sinks
+	l.mu.Unlock()
+
+	for _, sink := range sinks {
+		if err := sink.Write(entry); err != nil {
+			log.Printf("Error writing audit entry to %T: %v", sink, err)
+		}
+	}
+}
+
+// LoadHistory seeds the in-memory history from an existing JSON-lines audit file, so queries
+// cover changes made before the server was restarted. A missing file is not an error.
+func (l *Logger) LoadHistory(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+	defer file.Close()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Printf("Skipping malformed audit entry in %s: %v", path, err)
+			continue
+		}
+		l.appendHistory(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log %s: %w", path, err)
+	}
+	return nil
+}
+
+// appendHistory adds an entry, dropping the oldest ones beyond maxHistory. Callers hold l.mu.
+func (l *Logger) appendHistory(entry Entry) {
+	l.history = append(l.history, entry)
+	if len(l.history) > maxHistory {
+		l.history = append([]Entry(nil), l.history[len(l.history)-maxHistory:]...)
+	}
+}
+
+// Query returns the entries matching the filter, newest first
+func (l *Logger) Query(filter Filter) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := []Entry{}
+	for _, entry := range l.history {
+		if filter.matches(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.After(result[j].Time)
+	})
+
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result
+}
+
+// matches reports whether an entry satisfies every non-empty field of the filter
+func (f Filter) matches(entry Entry) bool {
+	switch {
+	case f.User != "" && entry.User != f.User:
+		return false
+	case f.Action != "" && entry.Action != f.Action:
+		return false
+	case f.Type != "" && entry.Resource.Type != f.Type:
+		return false
+	case f.Namespace != "" && entry.Resource.Namespace != f.Namespace:
+		return false
+	case f.Name != "" && entry.Resource.Name != f.Name:
+		return false
+	case f.Result != "" && entry.Result != f.Result:
+		return false
+	case !f.Since.IsZero() && entry.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && entry.Time.After(f.Until):
+		return false
+	}
+	return true
+}
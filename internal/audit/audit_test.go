@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/diff"
+)
+
+var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// testEntries returns entries an hour apart, oldest first
+func testEntries() []Entry {
+	return []Entry{
+		{Time: start, User: "alice", Action: "update", Resource: Resource{Type: "gateway", Namespace: "prod", Name: "gw"}, Result: ResultSuccess},
+		{Time: start.Add(time.Hour), User: "bob", Action: "update", Resource: Resource{Type: "httproute", Namespace: "prod", Name: "web"}, Result: ResultFailure, Error: "conflict"},
+		{Time: start.Add(2 * time.Hour), User: "alice", Action: "delete", Resource: Resource{Type: "httproute", Namespace: "dev", Name: "web"}, Result: ResultSuccess},
+		{Time: start.Add(3 * time.Hour), User: "bob", Action: "create", Resource: Resource{Type: "gateway", Namespace: "prod", Name: "gw"}, Result: ResultSuccess},
+	}
+}
+
+func TestQuery(t *testing.T) {
+	logger := NewLogger()
+	for _, entry := range testEntries() {
+		logger.Record(entry)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int // Hours after start of the expected entries
+	}{
+		{"everything, newest first", Filter{}, []int{3, 2, 1, 0}},
+		{"user", Filter{User: "alice"}, []int{2, 0}},
+		{"action", Filter{Action: "update"}, []int{1, 0}},
+		{"type and namespace", Filter{Type: "gateway", Namespace: "prod"}, []int{3, 0}},
+		{"name", Filter{Name: "web"}, []int{2, 1}},
+		{"result", Filter{Result: ResultFailure}, []int{1}},
+		{"since", Filter{Since: start.Add(2 * time.Hour)}, []int{3, 2}},
+		{"until", Filter{Until: start.Add(time.Hour)}, []int{1, 0}},
+		{"limit", Filter{Limit: 1}, []int{3}},
+		{"no match", Filter{User: "carol"}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []int{}
+			for _, entry := range logger.Query(tt.filter) {
+				got = append(got, int(entry.Time.Sub(start)/time.Hour))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordDefaultsTime(t *testing.T) {
+	logger := NewLogger()
+	logger.Record(Entry{User: "alice"})
+	if entries := logger.Query(Filter{}); len(entries) != 1 || entries[0].Time.IsZero() {
+		t.Errorf("Record did not set the time: %+v", entries)
+	}
+}
+
+func TestFileSinkHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	logger := NewLogger(sink)
+	for _, entry := range testEntries()[:2] {
+		logger.Record(entry)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening appends, and a malformed line is skipped when the history is loaded
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString("not json\n"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	sink, err = NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	NewLogger(sink).Record(testEntries()[2])
+	sink.Close()
+
+	restarted := NewLogger()
+	if err := restarted.LoadHistory(path); err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	entries := restarted.Query(Filter{})
+	if len(entries) != 3 {
+		t.Fatalf("loaded %d entries, want 3", len(entries))
+	}
+	if entries[1].User != "bob" || entries[1].Error != "conflict" || !entries[1].Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("loaded entry = %+v, want bob's failed update", entries[1])
+	}
+
+	if err := NewLogger().LoadHistory(filepath.Join(t.TempDir(), "missing.jsonl")); err != nil {
+		t.Errorf("LoadHistory of a missing file = %v, want nil", err)
+	}
+}
+
+func TestEventReason(t *testing.T) {
+	tests := []struct {
+		action string
+		result string
+		want   string
+	}{
+		{"update", ResultSuccess, "Updated"},
+		{"create", ResultSuccess, "Created"},
+		{"delete", ResultSuccess, "Deleted"},
+		{"update", ResultFailure, "UpdateFailed"},
+	}
+
+	for _, tt := range tests {
+		if got := eventReason(Entry{Action: tt.action, Result: tt.result}); got != tt.want {
+			t.Errorf("eventReason(%s, %s) = %q, want %q", tt.action, tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	entry := Entry{
+		User:    "alice",
+		Action:  "update",
+		Changes: []diff.Change{{Path: "spec.hostnames[0]"}, {Path: "metadata.labels"}},
+		Error:   "conflict",
+	}
+	if got, want := eventMessage(entry), "update via gwapi-graph by alice: spec.hostnames[0], metadata.labels (error: conflict)"; got != want {
+		t.Errorf("eventMessage = %q, want %q", got, want)
+	}
+
+	entry.Changes = nil
+	entry.Error = strings.Repeat("x", 2000)
+	if got := eventMessage(entry); len(got) != 1024 || !strings.HasSuffix(got, "...") {
+		t.Errorf("eventMessage of a long error is %d bytes, want 1024 ending in ...", len(got))
+	}
+}
@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gwapi-graph/internal/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// WriterSink writes entries as JSON lines to an io.Writer
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing JSON lines to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewStdoutSink creates a sink writing JSON lines to standard output
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink(os.Stdout)
+}
+
+// Write encodes the entry as a single JSON line
+func (s *WriterSink) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// FileSink appends entries as JSON lines to a file
+type FileSink struct {
+	*WriterSink
+	file *os.File
+}
+
+// NewFileSink opens (or creates) a JSON-lines audit file for appending
+func NewFileSink(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+
+	return &FileSink{
+		WriterSink: NewWriterSink(file),
+		file:       file,
+	}, nil
+}
+
+// Close closes the underlying file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// EventSink records entries as Kubernetes Events on the modified object, so the change shows up
+// in `kubectl describe` and `kubectl get events`
+type EventSink struct {
+	client *k8s.Client
+}
+
+// NewEventSink creates a sink that records Events through the given client
+func NewEventSink(client *k8s.Client) *EventSink {
+	return &EventSink{client: client}
+}
+
+// Write creates an Event describing the entry
+func (s *EventSink) Write(entry Entry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	eventType := corev1.EventTypeNormal
+	if entry.Result != ResultSuccess {
+		eventType = corev1.EventTypeWarning
+	}
+
+	now := metav1.NewTime(entry.Time)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: entry.Resource.Name + ".",
+			Namespace:    entry.Resource.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: entry.Resource.APIVersion,
+			Kind:       entry.Resource.Kind,
+			Namespace:  entry.Resource.Namespace,
+			Name:       entry.Resource.Name,
+			UID:        k8stypes.UID(entry.Resource.UID),
+		},
+		Reason:              eventReason(entry),
+		Message:             eventMessage(entry),
+		Type:                eventType,
+		Source:              corev1.EventSource{Component: k8s.FieldManager},
+		ReportingController: k8s.FieldManager,
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+	}
+
+	return s.client.CreateEvent(ctx, event)
+}
+
+// eventReason builds a CamelCase reason such as Updated or UpdateFailed
+func eventReason(entry Entry) string {
+	action := strings.ToUpper(entry.Action[:1]) + entry.Action[1:]
+	if entry.Result != ResultSuccess {
+		return action + "Failed"
+	}
+	return strings.TrimSuffix(action, "e") + "ed"
+}
+
+// eventMessage summarises who changed what, keeping within the Event message size limit
+func eventMessage(entry Entry) string {
+	message := fmt.Sprintf("%s via gwapi-graph by %s", entry.Action, entry.User)
+
+	if len(entry.Changes) > 0 {
+		paths := make([]string, 0, len(entry.Changes))
+		for _, change := range entry.Changes {
+			paths = append(paths, change.Path)
+		}
+		message += ": " + strings.Join(paths, ", ")
+	}
+	if entry.Error != "" {
+		message += " (error: " + entry.Error + ")"
+	}
+
+	if len(message) > 1024 {
+		message = message[:1021] + "..."
+	}
+	return message
+}
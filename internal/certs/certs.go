@@ -0,0 +1,153 @@
+// Package certs reads the public certificates of Secrets and ConfigMaps and checks which hostnames
+// they cover and when they expire. Private keys are never read.
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Data keys holding certificates
+const (
+	TLSCertKey = corev1.TLSCertKey // Serving certificate chain of a kubernetes.io/tls Secret
+	CACertKey  = "ca.crt"          // CA bundle of a Secret or ConfigMap
+)
+
+// ExpiryWarning is how long before expiry a certificate is reported as expiring soon
+const ExpiryWarning = 30 * 24 * time.Hour
+
+// Parse reads every CERTIFICATE block of PEM data, in order
+func Parse(data []byte) ([]types.Certificate, error) {
+	var certificates []types.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certificates = append(certificates, types.Certificate{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			IsCA:      cert.IsCA,
+		})
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	return certificates, nil
+}
+
+// FromSecret reads the certificates of a Secret's data key
+func FromSecret(secret *corev1.Secret, key string) types.CertificateBundle {
+	bundle := types.CertificateBundle{Kind: "Secret", Namespace: secret.Namespace, Name: secret.Name, Key: key}
+	data, ok := secret.Data[key]
+	if !ok {
+		if value, found := secret.StringData[key]; found {
+			data, ok = []byte(value), true
+		}
+	}
+	return read(bundle, data, ok)
+}
+
+// FromConfigMap reads the certificates of a ConfigMap's data key
+func FromConfigMap(configMap *corev1.ConfigMap, key string) types.CertificateBundle {
+	bundle := types.CertificateBundle{Kind: "ConfigMap", Namespace: configMap.Namespace, Name: configMap.Name, Key: key}
+	data, ok := configMap.Data[key]
+	return read(bundle, []byte(data), ok)
+}
+
+// Missing returns the bundle of an object that does not exist
+func Missing(kind, namespace, name, key string) types.CertificateBundle {
+	return types.CertificateBundle{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Key:       key,
+		Error:     fmt.Sprintf("%s %s/%s not found", kind, namespace, name),
+		Missing:   true,
+	}
+}
+
+// read fills in the certificates of a bundle, or the reason there are none
+func read(bundle types.CertificateBundle, data []byte, found bool) types.CertificateBundle {
+	if !found {
+		bundle.Error = fmt.Sprintf("%s %s/%s has no %s key", bundle.Kind, bundle.Namespace, bundle.Name, bundle.Key)
+		return bundle
+	}
+	certificates, err := Parse(data)
+	if err != nil {
+		bundle.Error = fmt.Sprintf("%s %s/%s key %s: %v", bundle.Kind, bundle.Namespace, bundle.Name, bundle.Key, err)
+		return bundle
+	}
+	bundle.Certificates = certificates
+	return bundle
+}
+
+// Find returns the bundle read from a data key of the given object, or nil when it was not
+// collected
+func Find(bundles []types.CertificateBundle, kind, namespace, name, key string) *types.CertificateBundle {
+	for i := range bundles {
+		if bundles[i].Kind == kind && bundles[i].Namespace == namespace && bundles[i].Name == name && bundles[i].Key == key {
+			return &bundles[i]
+		}
+	}
+	return nil
+}
+
+// Covers reports whether a certificate is valid for a hostname by its DNS names. A wildcard name
+// covers exactly one label, so *.example.com covers foo.example.com but neither example.com nor
+// a.b.example.com. A wildcard hostname is only covered by the same wildcard.
+func Covers(cert types.Certificate, host string) bool {
+	host = hostname.Normalize(host)
+	for _, name := range cert.DNSNames {
+		name = hostname.Normalize(name)
+		if name == host {
+			return true
+		}
+		if !hostname.IsWildcard(name) || hostname.IsWildcard(host) {
+			continue
+		}
+		label, rest, found := strings.Cut(host, ".")
+		if found && label != "" && rest == hostname.Domain(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Expiry describes when a certificate expires relative to now, e.g. "expired 3 days ago" or
+// "expires in 12 days"
+func Expiry(cert types.Certificate, now time.Time) string {
+	days := int(cert.NotAfter.Sub(now).Hours() / 24)
+	switch {
+	case now.After(cert.NotAfter):
+		return fmt.Sprintf("expired %d days ago (%s)", -days, cert.NotAfter.Format("2006-01-02"))
+	case now.Before(cert.NotBefore):
+		return fmt.Sprintf("not valid before %s", cert.NotBefore.Format("2006-01-02"))
+	default:
+		return fmt.Sprintf("expires in %d days (%s)", days, cert.NotAfter.Format("2006-01-02"))
+	}
+}
+
+// Expired reports whether a certificate is outside its validity period
+func Expired(cert types.Certificate, now time.Time) bool {
+	return now.After(cert.NotAfter) || now.Before(cert.NotBefore)
+}
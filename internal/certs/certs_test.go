@@ -0,0 +1,171 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// pemCertificate returns a self-signed PEM certificate for the DNS names, valid for a year from
+// notBefore
+func pemCertificate(t *testing.T, notBefore time.Time, dnsNames ...string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     dnsNames,
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.AddDate(1, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParse(t *testing.T) {
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("ignored")})
+	chain := append(append(pemCertificate(t, now, "a.example.com"), key...), pemCertificate(t, now, "b.example.com")...)
+
+	certificates, err := Parse(chain)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(certificates) != 2 || certificates[0].DNSNames[0] != "a.example.com" || certificates[1].DNSNames[0] != "b.example.com" {
+		t.Errorf("Parse = %+v, want the two certificates in order", certificates)
+	}
+	if certificates[0].Subject != "CN=test" || !certificates[0].NotAfter.Equal(now.AddDate(1, 0, 0)) {
+		t.Errorf("certificate = %+v, want subject CN=test expiring a year from now", certificates[0])
+	}
+
+	for name, data := range map[string][]byte{
+		"no PEM":            []byte("not a certificate"),
+		"only a key":        key,
+		"corrupt DER":       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("corrupt")}),
+		"empty certificate": nil,
+	} {
+		if _, err := Parse(data); err == nil {
+			t.Errorf("Parse(%s) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestFromSecretAndConfigMap(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "app", Name: "ca"}
+	valid := pemCertificate(t, now, "ca.example.com")
+
+	tests := []struct {
+		name      string
+		bundle    types.CertificateBundle
+		wantCerts int
+		wantError string
+	}{
+		{"Secret data", FromSecret(&corev1.Secret{ObjectMeta: meta, Data: map[string][]byte{CACertKey: valid}}, CACertKey), 1, ""},
+		{"Secret stringData", FromSecret(&corev1.Secret{ObjectMeta: meta, StringData: map[string]string{CACertKey: string(valid)}}, CACertKey), 1, ""},
+		{"Secret without the key", FromSecret(&corev1.Secret{ObjectMeta: meta}, CACertKey), 0, "Secret app/ca has no ca.crt key"},
+		{"ConfigMap", FromConfigMap(&corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{CACertKey: string(valid)}}, CACertKey), 1, ""},
+		{"unparsable ConfigMap", FromConfigMap(&corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{CACertKey: "garbage"}}, CACertKey), 0, "ConfigMap app/ca key ca.crt: no PEM certificate found"},
+		{"missing", Missing("ConfigMap", "app", "ca", CACertKey), 0, "ConfigMap app/ca not found"},
+	}
+
+	for _, tt := range tests {
+		if len(tt.bundle.Certificates) != tt.wantCerts || tt.bundle.Error != tt.wantError {
+			t.Errorf("%s: %d certificates, error %q, want %d and %q", tt.name, len(tt.bundle.Certificates), tt.bundle.Error, tt.wantCerts, tt.wantError)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	cert := types.Certificate{DNSNames: []string{"Example.com", "*.apps.example.com"}}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.com.", true},
+		{"www.example.com", false},
+		{"foo.apps.example.com", true},
+		{"apps.example.com", false},
+		{"a.b.apps.example.com", false},
+		{"*.apps.example.com", true},
+		{"*.example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := Covers(cert, tt.host); got != tt.want {
+			t.Errorf("Covers(%s) = %t, want %t", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	tests := []struct {
+		name        string
+		cert        types.Certificate
+		want        string
+		wantExpired bool
+	}{
+		{
+			name: "valid",
+			cert: types.Certificate{NotBefore: now.AddDate(0, -1, 0), NotAfter: now.AddDate(0, 0, 12)},
+			want: "expires in 12 days (2024-06-13)",
+		},
+		{
+			name: "expires today",
+			cert: types.Certificate{NotBefore: now.AddDate(0, -1, 0), NotAfter: now.Add(time.Hour)},
+			want: "expires in 0 days (2024-06-01)",
+		},
+		{
+			name:        "expired",
+			cert:        types.Certificate{NotBefore: now.AddDate(-1, 0, 0), NotAfter: now.AddDate(0, 0, -3)},
+			want:        "expired 3 days ago (2024-05-29)",
+			wantExpired: true,
+		},
+		{
+			name:        "not yet valid",
+			cert:        types.Certificate{NotBefore: now.AddDate(0, 0, 2), NotAfter: now.AddDate(1, 0, 0)},
+			want:        "not valid before 2024-06-03",
+			wantExpired: true,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := Expiry(tt.cert, now); got != tt.want {
+			t.Errorf("Expiry(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := Expired(tt.cert, now); got != tt.wantExpired {
+			t.Errorf("Expired(%s) = %t, want %t", tt.name, got, tt.wantExpired)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	bundles := []types.CertificateBundle{
+		{Kind: "Secret", Namespace: "app", Name: "ca", Key: TLSCertKey},
+		{Kind: "ConfigMap", Namespace: "app", Name: "ca", Key: CACertKey},
+	}
+	if got := Find(bundles, "ConfigMap", "app", "ca", CACertKey); got != &bundles[1] {
+		t.Errorf("Find = %+v, want the ConfigMap bundle", got)
+	}
+	if got := Find(bundles, "Secret", "app", "ca", CACertKey); got != nil {
+		t.Errorf("Find of an unread key = %+v, want nil", got)
+	}
+}
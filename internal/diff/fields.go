@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a single field-level difference between two objects
+type Change struct {
+	Path string      `json:"path"`          // JSON path of the field, e.g. spec.rules[0].backendRefs[1].port
+	Type string      `json:"type"`          // added, removed or changed
+	Old  interface{} `json:"old,omitempty"` // Value before the change (omitted when added)
+	New  interface{} `json:"new,omitempty"` // Value after the change (omitted when removed)
+}
+
+// ignoredPaths are server-managed fields that change on every write and carry no intent
+var ignoredPaths = map[string]bool{
+	"metadata.managedFields":     true,
+	"metadata.resourceVersion":   true,
+	"metadata.generation":        true,
+	"metadata.creationTimestamp": true,
+	"metadata.uid":               true,
+}
+
+// Fields compares two JSON-compatible objects (as produced by encoding/json or the dynamic client)
+// and returns their field-level differences, sorted by path. Either side may be nil.
+func Fields(before, after map[string]interface{}) []Change {
+	changes := []Change{}
+	compare("", normalize(before), normalize(after), &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// normalize round-trips an object through JSON so that numbers have the same Go type
+// (the dynamic client uses int64, encoding/json uses float64). A nil map becomes nil so that
+// comparisons treat a missing object as absent.
+func normalize(obj map[string]interface{}) interface{} {
+	if obj == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return obj
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return obj
+	}
+	return normalized
+}
+
+// compare walks both values in parallel, recording a change for each differing leaf
+func compare(path string, before, after interface{}, changes *[]Change) {
+	if ignoredPaths[path] {
+		return
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make(map[string]bool)
+		for key := range beforeMap {
+			keys[key] = true
+		}
+		for key := range afterMap {
+			keys[key] = true
+		}
+		for key := range keys {
+			compare(joinPath(path, key), beforeMap[key], afterMap[key], changes)
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		length := len(beforeList)
+		if len(afterList) > length {
+			length = len(afterList)
+		}
+		for i := 0; i < length; i++ {
+			var beforeItem, afterItem interface{}
+			if i < len(beforeList) {
+				beforeItem = beforeList[i]
+			}
+			if i < len(afterList) {
+				afterItem = afterList[i]
+			}
+			compare(fmt.Sprintf("%s[%d]", path, i), beforeItem, afterItem, changes)
+		}
+		return
+	}
+
+	switch {
+	case before == nil && after == nil:
+		return
+	case before == nil:
+		*changes = append(*changes, Change{Path: path, Type: "added", New: after})
+	case after == nil:
+		*changes = append(*changes, Change{Path: path, Type: "removed", Old: before})
+	case !reflect.DeepEqual(before, after):
+		*changes = append(*changes, Change{Path: path, Type: "changed", Old: before, New: after})
+	}
+}
+
+// joinPath appends a map key to a JSON path, quoting keys that contain dots (e.g. label keys)
+func joinPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		key = fmt.Sprintf("[%q]", key)
+		return path + key
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]interface{}
+		after  map[string]interface{}
+		want   []Change
+	}{
+		{
+			name:   "equal",
+			before: map[string]interface{}{"spec": map[string]interface{}{"port": 80}},
+			after:  map[string]interface{}{"spec": map[string]interface{}{"port": 80}},
+			want:   []Change{},
+		},
+		{
+			// The dynamic client decodes numbers as int64, encoding/json as float64
+			name:   "number types",
+			before: map[string]interface{}{"port": int64(80)},
+			after:  map[string]interface{}{"port": float64(80)},
+			want:   []Change{},
+		},
+		{
+			name:   "changed, added and removed leaves",
+			before: map[string]interface{}{"a": "x", "b": true},
+			after:  map[string]interface{}{"a": "y", "c": 1},
+			want: []Change{
+				{Path: "a", Type: "changed", Old: "x", New: "y"},
+				{Path: "b", Type: "removed", Old: true},
+				{Path: "c", Type: "added", New: float64(1)},
+			},
+		},
+		{
+			name:   "list items",
+			before: map[string]interface{}{"hostnames": []interface{}{"a.example.com"}},
+			after:  map[string]interface{}{"hostnames": []interface{}{"b.example.com", "c.example.com"}},
+			want: []Change{
+				{Path: "hostnames[0]", Type: "changed", Old: "a.example.com", New: "b.example.com"},
+				{Path: "hostnames[1]", Type: "added", New: "c.example.com"},
+			},
+		},
+		{
+			name:   "dotted keys are quoted",
+			before: map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app.kubernetes.io/name": "a"}}},
+			after:  map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app.kubernetes.io/name": "b"}}},
+			want:   []Change{{Path: `metadata.labels["app.kubernetes.io/name"]`, Type: "changed", Old: "a", New: "b"}},
+		},
+		{
+			name:   "server-managed fields are ignored",
+			before: map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "1", "generation": 1, "uid": "a"}},
+			after:  map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "2", "generation": 2, "uid": "b"}},
+			want:   []Change{},
+		},
+		{
+			name:  "missing object",
+			after: map[string]interface{}{"spec": map[string]interface{}{"port": 80}},
+			want:  []Change{{Path: "", Type: "added", New: map[string]interface{}{"spec": map[string]interface{}{"port": float64(80)}}}},
+		},
+		{
+			name:   "type change",
+			before: map[string]interface{}{"value": []interface{}{"a"}},
+			after:  map[string]interface{}{"value": "a"},
+			want:   []Change{{Path: "value", Type: "changed", Old: []interface{}{"a"}, New: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fields(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
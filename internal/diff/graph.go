@@ -0,0 +1,232 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// Change types of nodes and links in a graph diff
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// GraphDiff is the difference between two graphs
+type GraphDiff struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Summary Summary      `json:"summary"`
+	Nodes   []NodeChange `json:"nodes"`
+	Links   []LinkChange `json:"links"`
+	Overlay *types.Graph `json:"overlay"` // Union of both graphs, with nodes and links marked by their change
+}
+
+// Summary counts the changes in a GraphDiff
+type Summary struct {
+	AddedNodes   int `json:"addedNodes"`
+	RemovedNodes int `json:"removedNodes"`
+	ChangedNodes int `json:"changedNodes"`
+	AddedLinks   int `json:"addedLinks"`
+	RemovedLinks int `json:"removedLinks"`
+}
+
+// NodeChange is a node that was added, removed or whose spec changed
+type NodeChange struct {
+	Key       string   `json:"key"`
+	Type      string   `json:"type"`
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name"`
+	Change    string   `json:"change"`
+	Fields    []Change `json:"fields,omitempty"` // Spec changes of a changed node
+}
+
+// LinkChange is a link that was added or removed
+type LinkChange struct {
+	Source string `json:"source"` // Node key
+	Target string `json:"target"` // Node key
+	Type   string `json:"type"`
+	Change string `json:"change"`
+}
+
+// ObjectKey identifies a resource across sources, where UIDs differ (a recreated object, or a
+// manifest compared with the live cluster)
+func ObjectKey(nodeType, namespace, name string) string {
+	return strings.ToLower(nodeType) + "/" + namespace + "/" + name
+}
+
+// Graphs compares two graphs. Nodes are matched by kind, namespace and name (listeners by their
+// Gateway and listener name). fromObjects and toObjects hold the resource behind each node as a
+// JSON-compatible map, keyed by ObjectKey, and are used for field-level spec diffs.
+func Graphs(from, to *types.Graph, fromObjects, toObjects map[string]map[string]interface{}) *GraphDiff {
+	result := &GraphDiff{
+		Nodes:   []NodeChange{},
+		Links:   []LinkChange{},
+		Overlay: &types.Graph{Nodes: []types.Node{}, Links: []types.Link{}, DNSZones: to.DNSZones},
+	}
+
+	fromKeys := nodeKeys(from)
+	toKeys := nodeKeys(to)
+
+	fromByKey := make(map[string]int)
+	for i, key := range fromKeys {
+		fromByKey[key] = i
+	}
+	toByKey := make(map[string]int)
+	for i, key := range toKeys {
+		toByKey[key] = i
+	}
+
+	// The overlay keeps all nodes of the new graph, followed by the removed ones
+	overlayIndex := make(map[string]int)
+	for i, node := range to.Nodes {
+		key := toKeys[i]
+		overlayIndex[key] = len(result.Overlay.Nodes)
+
+		fromIndex, existed := fromByKey[key]
+		switch {
+		case !existed:
+			node.Change = Added
+			result.Summary.AddedNodes++
+			result.Nodes = append(result.Nodes, nodeChange(key, node, Added, nil))
+		default:
+			fields := nodeFields(from.Nodes[fromIndex], node, fromObjects[objectKey(from.Nodes[fromIndex])], toObjects[objectKey(node)])
+			if len(fields) > 0 {
+				node.Change = Changed
+				result.Summary.ChangedNodes++
+				result.Nodes = append(result.Nodes, nodeChange(key, node, Changed, fields))
+			}
+		}
+		result.Overlay.Nodes = append(result.Overlay.Nodes, node)
+	}
+	for i, node := range from.Nodes {
+		key := fromKeys[i]
+		if _, exists := toByKey[key]; exists {
+			continue
+		}
+		node.Change = Removed
+		overlayIndex[key] = len(result.Overlay.Nodes)
+		result.Summary.RemovedNodes++
+		result.Nodes = append(result.Nodes, nodeChange(key, node, Removed, nil))
+		result.Overlay.Nodes = append(result.Overlay.Nodes, node)
+	}
+
+	linkKey := func(keys []string, link types.Link) string {
+		return keys[link.Source] + "|" + keys[link.Target] + "|" + link.Type
+	}
+	fromLinks := make(map[string]bool)
+	for _, link := range from.Links {
+		fromLinks[linkKey(fromKeys, link)] = true
+	}
+	toLinks := make(map[string]bool)
+	for _, link := range to.Links {
+		toLinks[linkKey(toKeys, link)] = true
+	}
+
+	for _, link := range to.Links {
+		source, target := toKeys[link.Source], toKeys[link.Target]
+		overlayLink := types.Link{Source: overlayIndex[source], Target: overlayIndex[target], Type: link.Type}
+		if !fromLinks[linkKey(toKeys, link)] {
+			overlayLink.Change = Added
+			result.Summary.AddedLinks++
+			result.Links = append(result.Links, LinkChange{Source: source, Target: target, Type: link.Type, Change: Added})
+		}
+		result.Overlay.Links = append(result.Overlay.Links, overlayLink)
+	}
+	for _, link := range from.Links {
+		if toLinks[linkKey(fromKeys, link)] {
+			continue
+		}
+		source, target := fromKeys[link.Source], fromKeys[link.Target]
+		result.Summary.RemovedLinks++
+		result.Links = append(result.Links, LinkChange{Source: source, Target: target, Type: link.Type, Change: Removed})
+		result.Overlay.Links = append(result.Overlay.Links, types.Link{
+			Source: overlayIndex[source],
+			Target: overlayIndex[target],
+			Type:   link.Type,
+			Change: Removed,
+		})
+	}
+
+	sort.SliceStable(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Key < result.Nodes[j].Key
+	})
+	sort.SliceStable(result.Links, func(i, j int) bool {
+		if result.Links[i].Source != result.Links[j].Source {
+			return result.Links[i].Source < result.Links[j].Source
+		}
+		return result.Links[i].Target < result.Links[j].Target
+	})
+
+	return result
+}
+
+// nodeKeys returns the source-independent key of every node
+func nodeKeys(graph *types.Graph) []string {
+	byID := make(map[string]types.Node)
+	for _, node := range graph.Nodes {
+		byID[node.ID] = node
+	}
+
+	keys := make([]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		keys[i] = objectKey(node)
+		if node.ParentID != nil {
+			if parent, ok := byID[*node.ParentID]; ok {
+				keys[i] = objectKey(parent) + "/" + strings.ToLower(node.Type) + "/" + node.Name
+			}
+		}
+	}
+	return keys
+}
+
+// objectKey returns the ObjectKey of the resource behind a node
+func objectKey(node types.Node) string {
+	// Policies of every kind share the Policy node type
+	if node.Type == "Policy" {
+		return ObjectKey(node.Kind, node.Namespace, node.Name)
+	}
+	return ObjectKey(node.Type, node.Namespace, node.Name)
+}
+
+// nodeFields compares the spec of two matching nodes. Listeners have no object of their own
+// and are compared by their listener data.
+func nodeFields(before, after types.Node, beforeObject, afterObject map[string]interface{}) []Change {
+	if before.ListenerData != nil || after.ListenerData != nil {
+		return Fields(map[string]interface{}{"listener": before.ListenerData}, map[string]interface{}{"listener": after.ListenerData})
+	}
+
+	if beforeObject == nil || afterObject == nil {
+		return nil
+	}
+	beforeSpec, _ := beforeObject["spec"].(map[string]interface{})
+	afterSpec, _ := afterObject["spec"].(map[string]interface{})
+
+	changes := []Change{}
+	for _, change := range Fields(beforeSpec, afterSpec) {
+		switch {
+		case change.Path == "":
+			change.Path = "spec"
+		case strings.HasPrefix(change.Path, "["):
+			change.Path = "spec" + change.Path
+		default:
+			change.Path = "spec." + change.Path
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// nodeChange builds the NodeChange entry for a node
+func nodeChange(key string, node types.Node, change string, fields []Change) NodeChange {
+	return NodeChange{
+		Key:       key,
+		Type:      node.Type,
+		Namespace: node.Namespace,
+		Name:      node.Name,
+		Change:    change,
+		Fields:    fields,
+	}
+}
@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+)
+
+// testGraph returns a graph with a Gateway, its listener and the routes attached to it. IDs are
+// prefixed so that the two sides of a diff never share one, as with objects read from manifests.
+func testGraph(prefix string, port int32, routes ...string) (*types.Graph, map[string]map[string]interface{}) {
+	graph := &types.Graph{
+		Nodes: []types.Node{
+			{ID: prefix + "gw", Type: "Gateway", Namespace: "infra", Name: "gw"},
+			{ID: prefix + "http", Type: "Listener", Namespace: "infra", Name: "http", ParentID: testutil.Ptr(prefix + "gw"), ListenerData: &types.ListenerData{Port: port, Protocol: "HTTP"}},
+		},
+		Links: []types.Link{{Source: 1, Target: 0, Type: "listener"}},
+	}
+	objects := map[string]map[string]interface{}{
+		ObjectKey("Gateway", "infra", "gw"): {"spec": map[string]interface{}{"gatewayClassName": "example"}},
+	}
+	for _, route := range routes {
+		graph.Nodes = append(graph.Nodes, types.Node{ID: prefix + route, Type: "HTTPRoute", Namespace: "app", Name: route})
+		graph.Links = append(graph.Links, types.Link{Source: len(graph.Nodes) - 1, Target: 1, Type: "attached"})
+		objects[ObjectKey("HTTPRoute", "app", route)] = map[string]interface{}{"spec": map[string]interface{}{"hostnames": []interface{}{route + ".example.com"}}}
+	}
+	return graph, objects
+}
+
+func TestGraphs(t *testing.T) {
+	from, fromObjects := testGraph("old-", 80, "kept", "removed")
+	to, toObjects := testGraph("new-", 8080, "kept", "added")
+	toObjects[ObjectKey("HTTPRoute", "app", "kept")]["spec"] = map[string]interface{}{"hostnames": []interface{}{"renamed.example.com"}}
+
+	result := Graphs(from, to, fromObjects, toObjects)
+
+	want := Summary{AddedNodes: 1, RemovedNodes: 1, ChangedNodes: 2, AddedLinks: 1, RemovedLinks: 1}
+	if result.Summary != want {
+		t.Errorf("summary = %+v, want %+v", result.Summary, want)
+	}
+
+	var nodes []string
+	for _, node := range result.Nodes {
+		nodes = append(nodes, node.Change+" "+node.Key)
+	}
+	wantNodes := []string{
+		"changed gateway/infra/gw/listener/http",
+		"added httproute/app/added",
+		"changed httproute/app/kept",
+		"removed httproute/app/removed",
+	}
+	if !reflect.DeepEqual(nodes, wantNodes) {
+		t.Errorf("nodes = %v, want %v", nodes, wantNodes)
+	}
+
+	for _, node := range result.Nodes {
+		switch node.Key {
+		case "gateway/infra/gw/listener/http":
+			if len(node.Fields) != 1 || node.Fields[0].Path != "listener.port" {
+				t.Errorf("listener fields = %+v, want listener.port", node.Fields)
+			}
+		case "httproute/app/kept":
+			if len(node.Fields) != 1 || node.Fields[0].Path != "spec.hostnames[0]" {
+				t.Errorf("route fields = %+v, want spec.hostnames[0]", node.Fields)
+			}
+		}
+	}
+
+	// The overlay holds the new graph followed by the removed node, with links re-indexed
+	overlay := result.Overlay
+	if len(overlay.Nodes) != 5 || overlay.Nodes[4].Name != "removed" || overlay.Nodes[4].Change != Removed {
+		t.Fatalf("overlay nodes = %+v", overlay.Nodes)
+	}
+	if overlay.Nodes[0].Change != "" || overlay.Nodes[3].Change != Added {
+		t.Errorf("overlay marks gw %q and added route %q, want unchanged and added", overlay.Nodes[0].Change, overlay.Nodes[3].Change)
+	}
+	removedLink := overlay.Links[len(overlay.Links)-1]
+	if removedLink.Source != 4 || removedLink.Target != 1 || removedLink.Change != Removed {
+		t.Errorf("removed overlay link = %+v, want 4 → 1 removed", removedLink)
+	}
+}
+
+func TestGraphsPolicyKinds(t *testing.T) {
+	// Policy nodes share a type and are told apart by kind
+	policies := func(kind string) *types.Graph {
+		return &types.Graph{Nodes: []types.Node{{ID: kind, Type: "Policy", Kind: kind, Namespace: "app", Name: "p"}}}
+	}
+	result := Graphs(policies("BackendTLSPolicy"), policies("RateLimitPolicy"), nil, nil)
+	if result.Summary.AddedNodes != 1 || result.Summary.RemovedNodes != 1 {
+		t.Errorf("summary = %+v, want one added and one removed policy", result.Summary)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	from, fromObjects := testGraph("old-", 80, "removed")
+	to, toObjects := testGraph("new-", 80, "added")
+
+	var out bytes.Buffer
+	result := Graphs(from, to, fromObjects, toObjects)
+	result.From, result.To = "snapshot:a", "live"
+	if err := WriteMarkdown(&out, result); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Gateway API changes: snapshot:a → live",
+		"1 added, 1 removed, 0 changed resources; 1 added, 1 removed links.",
+		"- **added** HTTPRoute `app/added`",
+		"- **removed** attached: `httproute/app/removed` → `gateway/infra/gw/listener/http`",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("markdown lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	same, sameObjects := testGraph("new-", 80)
+	if err := WriteMarkdown(&out, Graphs(same, same, sameObjects, sameObjects)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No changes.") {
+		t.Errorf("markdown of equal graphs = %q, want No changes.", out.String())
+	}
+}
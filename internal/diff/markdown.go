@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown renders a graph diff as Markdown, suitable for attaching to a change review
+func WriteMarkdown(w io.Writer, d *GraphDiff) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "## Gateway API changes: %s → %s\n\n", d.From, d.To)
+
+	s := d.Summary
+	if s.AddedNodes+s.RemovedNodes+s.ChangedNodes+s.AddedLinks+s.RemovedLinks == 0 {
+		fmt.Fprintln(out, "No changes.")
+		return out.Flush()
+	}
+	fmt.Fprintf(out, "%d added, %d removed, %d changed resources; %d added, %d removed links.\n\n",
+		s.AddedNodes, s.RemovedNodes, s.ChangedNodes, s.AddedLinks, s.RemovedLinks)
+
+	if len(d.Nodes) > 0 {
+		fmt.Fprintln(out, "### Resources")
+		fmt.Fprintln(out)
+		for _, node := range d.Nodes {
+			name := node.Name
+			if node.Namespace != "" {
+				name = node.Namespace + "/" + node.Name
+			}
+			fmt.Fprintf(out, "- **%s** %s `%s`\n", node.Change, node.Type, name)
+			for _, field := range node.Fields {
+				switch field.Type {
+				case "added":
+					fmt.Fprintf(out, "  - `%s`: added `%s`\n", field.Path, compactJSON(field.New))
+				case "removed":
+					fmt.Fprintf(out, "  - `%s`: removed `%s`\n", field.Path, compactJSON(field.Old))
+				default:
+					fmt.Fprintf(out, "  - `%s`: `%s` → `%s`\n", field.Path, compactJSON(field.Old), compactJSON(field.New))
+				}
+			}
+		}
+		fmt.Fprintln(out)
+	}
+
+	if len(d.Links) > 0 {
+		fmt.Fprintln(out, "### Links")
+		fmt.Fprintln(out)
+		for _, link := range d.Links {
+			fmt.Fprintf(out, "- **%s** %s: `%s` → `%s`\n", link.Change, link.Type, link.Source, link.Target)
+		}
+	}
+
+	return out.Flush()
+}
+
+// compactJSON formats a value on a single line, shortening long values
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	text := strings.ReplaceAll(string(data), "`", "'")
+	if len(text) > 120 {
+		text = text[:117] + "..."
+	}
+	return text
+}
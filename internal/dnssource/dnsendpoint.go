@@ -0,0 +1,54 @@
+package dnssource
+
+import (
+	"fmt"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// dnsEndpoints reads ExternalDNS DNSEndpoint resources, one record per entry of spec.endpoints
+type dnsEndpoints struct{}
+
+// Name implements Provider
+func (dnsEndpoints) Name() string {
+	return "dnsendpoint"
+}
+
+// Records implements Provider. DNSEndpoints do not name a Gateway, so an endpoint belongs to the
+// Gateway whose load balancer one of its targets points to.
+func (p dnsEndpoints) Records(resources *types.ResourceCollection) []Record {
+	var records []Record
+	for i := range resources.DNSEndpoints {
+		endpoint := &resources.DNSEndpoints[i]
+		entries, _, _ := unstructured.NestedSlice(endpoint.Object, "spec", "endpoints")
+		for j, entry := range entries {
+			entryMap, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			dnsName, _, _ := unstructured.NestedString(entryMap, "dnsName")
+			recordType, _, _ := unstructured.NestedString(entryMap, "recordType")
+			targets, _, _ := unstructured.NestedStringSlice(entryMap, "targets")
+
+			records = append(records, Record{
+				ID:         fmt.Sprintf("%s-endpoint-%d", endpoint.GetUID(), j),
+				Provider:   p.Name(),
+				Group:      "externaldns.k8s.io",
+				Version:    "v1alpha1",
+				Kind:       "DNSEndpoint",
+				Namespace:  endpoint.GetNamespace(),
+				Name:       endpoint.GetName(),
+				DNSName:    hostname.Normalize(dnsName),
+				RecordType: recordType,
+				Targets:    targets,
+				Gateway:    analysis.TargetGateway(resources, targets),
+				Object:     endpoint,
+			})
+		}
+	}
+	return records
+}
@@ -0,0 +1,96 @@
+// Package dnssource collects the DNS names published for Gateway API resources from the systems
+// that publish them: OpenShift DNSRecords, ExternalDNS DNSEndpoints, and the hostnames ExternalDNS
+// publishes for Gateways and HTTPRoutes. Each name becomes a DNSRecord node of the graph.
+package dnssource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Record is one DNS name published for the cluster, whichever system publishes it
+type Record struct {
+	ID         string // Graph node ID
+	Provider   string // Name of the provider that found the record
+	Group      string // API group of the object declaring the record
+	Version    string
+	Kind       string
+	Namespace  string
+	Name       string
+	DNSName    string // Normalized
+	RecordType string
+	Targets    []string
+	Gateway    *gatewayv1.Gateway         // The Gateway whose addresses the record publishes, when known
+	Object     *unstructured.Unstructured // The declaring object, when it is an unstructured custom resource
+}
+
+// Provider finds the DNS records one system publishes
+type Provider interface {
+	Name() string
+	Records(resources *types.ResourceCollection) []Record
+}
+
+// providers creates the known providers by name
+var providers = map[string]func() Provider{
+	"dnsrecord":         func() Provider { return openShift{} },
+	"dnsendpoint":       func() Provider { return dnsEndpoints{} },
+	"external-dns":      func() Provider { return externalDNS{name: "external-dns", annotatedOnly: true} },
+	"gateway-httproute": func() Provider { return externalDNS{name: "gateway-httproute"} },
+}
+
+// DefaultNames are the providers used unless configured otherwise. ExternalDNS names are only
+// inferred for Gateways and routes that carry external-dns annotations, since a cluster without
+// ExternalDNS would otherwise show a record for every route.
+var DefaultNames = []string{"dnsrecord", "dnsendpoint", "external-dns"}
+
+// Names returns the names of all known providers, sorted
+func Names() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ByName creates the named providers, in order
+func ByName(names []string) ([]Provider, error) {
+	var result []Provider
+	for _, name := range names {
+		create, ok := providers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown DNS source %q (known: %s)", name, strings.Join(Names(), ", "))
+		}
+		result = append(result, create())
+	}
+	return result, nil
+}
+
+// Defaults creates the default providers
+func Defaults() []Provider {
+	result, _ := ByName(DefaultNames)
+	return result
+}
+
+// Collect returns the records of all providers, in provider order. A record whose ID an earlier
+// provider already returned is dropped.
+func Collect(resources *types.ResourceCollection, providers []Provider) []Record {
+	seen := make(map[string]bool)
+	var records []Record
+	for _, provider := range providers {
+		for _, record := range provider.Records(resources) {
+			if record.DNSName == "" || seen[record.ID] {
+				continue
+			}
+			seen[record.ID] = true
+			records = append(records, record)
+		}
+	}
+	return records
+}
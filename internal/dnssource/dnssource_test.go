@@ -0,0 +1,225 @@
+package dnssource
+
+import (
+	"reflect"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// addressedGateway returns a Gateway in infra with an HTTP listener for *.example.com that
+// accepts routes from every namespace, and a status address
+func addressedGateway(name, address string, annotations map[string]string) gatewayv1.Gateway {
+	gw := testutil.Gateway("infra", name, testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, "*.example.com"))
+	gw.Annotations = annotations
+	gw.Status.Addresses = []gatewayv1.GatewayStatusAddress{{Value: address}}
+	return gw
+}
+
+// annotatedRoute returns an HTTPRoute in app attached to the Gateway infra/<gateway>
+func annotatedRoute(name, gateway string, annotations map[string]string, hostnames ...string) gatewayv1.HTTPRoute {
+	route := testutil.HTTPRoute("app", name, testutil.ParentRef("infra", gateway))
+	route.Annotations = annotations
+	route.Spec.Hostnames = testutil.Hostnames(hostnames...)
+	return route
+}
+
+// recordKey summarizes a record for comparison
+func recordKey(record Record) string {
+	key := record.Kind + " " + record.Namespace + "/" + record.Name + " " + record.DNSName + " " + record.RecordType
+	for _, target := range record.Targets {
+		key += " " + target
+	}
+	if record.Gateway != nil {
+		key += " via " + record.Gateway.Name
+	}
+	return key
+}
+
+func TestExternalDNSRecords(t *testing.T) {
+	resources := &types.ResourceCollection{
+		Gateways: []gatewayv1.Gateway{
+			addressedGateway("plain", "10.0.0.1", nil),
+			addressedGateway("annotated", "2001:db8::1", map[string]string{targetAnnotation: "lb.example.net"}),
+		},
+		HTTPRoutes: []gatewayv1.HTTPRoute{
+			annotatedRoute("www", "plain", nil, "WWW.Example.com."),
+			annotatedRoute("api", "plain", map[string]string{hostnameAnnotation: "api.example.com, extra.example.org"}, "api.example.com"),
+			annotatedRoute("shop", "annotated", nil, "shop.example.com"),
+			// Outside the listener's hostname, so not attached
+			annotatedRoute("other", "plain", map[string]string{hostnameAnnotation: "other.example.org"}, "other.example.org"),
+			// Takes the hostname of the listener
+			annotatedRoute("open", "annotated", nil),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		want     []string
+	}{
+		{
+			name:     "annotated only",
+			provider: "external-dns",
+			want: []string{
+				"HTTPRoute app/api api.example.com A 10.0.0.1 via plain",
+				"HTTPRoute app/api extra.example.org A 10.0.0.1 via plain",
+				"HTTPRoute app/shop shop.example.com CNAME lb.example.net via annotated",
+				"HTTPRoute app/open *.example.com CNAME lb.example.net via annotated",
+			},
+		},
+		{
+			name:     "gateway-httproute",
+			provider: "gateway-httproute",
+			want: []string{
+				"HTTPRoute app/www www.example.com A 10.0.0.1 via plain",
+				"HTTPRoute app/api api.example.com A 10.0.0.1 via plain",
+				"HTTPRoute app/api extra.example.org A 10.0.0.1 via plain",
+				"HTTPRoute app/shop shop.example.com CNAME lb.example.net via annotated",
+				"HTTPRoute app/open *.example.com CNAME lb.example.net via annotated",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers, err := ByName([]string{tt.provider})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, record := range providers[0].Records(resources) {
+				got = append(got, recordKey(record))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordType(t *testing.T) {
+	tests := []struct {
+		targets []string
+		want    string
+	}{
+		{nil, ""},
+		{[]string{"10.0.0.1"}, "A"},
+		{[]string{"2001:db8::1"}, "AAAA"},
+		{[]string{"lb.example.net"}, "CNAME"},
+	}
+
+	for _, tt := range tests {
+		if got := recordType(tt.targets); got != tt.want {
+			t.Errorf("recordType(%v) = %q, want %q", tt.targets, got, tt.want)
+		}
+	}
+}
+
+func TestDNSEndpointRecords(t *testing.T) {
+	endpoint := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "externaldns.k8s.io/v1alpha1",
+		"kind":       "DNSEndpoint",
+		"metadata":   map[string]interface{}{"namespace": "app", "name": "records", "uid": "endpoint-uid"},
+		"spec": map[string]interface{}{"endpoints": []interface{}{
+			map[string]interface{}{"dnsName": "Foo.Example.com.", "recordType": "A", "targets": []interface{}{"10.0.0.1"}},
+			map[string]interface{}{"dnsName": "bar.example.com", "recordType": "CNAME", "targets": []interface{}{"elsewhere.example.net"}},
+		}},
+	}}
+	resources := &types.ResourceCollection{
+		Gateways:     []gatewayv1.Gateway{addressedGateway("plain", "10.0.0.1", nil)},
+		DNSEndpoints: []unstructured.Unstructured{endpoint},
+	}
+
+	records := dnsEndpoints{}.Records(resources)
+	want := []string{
+		"DNSEndpoint app/records foo.example.com A 10.0.0.1 via plain",
+		"DNSEndpoint app/records bar.example.com CNAME elsewhere.example.net",
+	}
+	var got []string
+	for _, record := range records {
+		got = append(got, recordKey(record))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+	if records[1].ID != "endpoint-uid-endpoint-1" {
+		t.Errorf("second record ID = %q, want endpoint-uid-endpoint-1", records[1].ID)
+	}
+}
+
+func TestOpenShiftRecords(t *testing.T) {
+	record := func(name, gateway string) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "ingress.operator.openshift.io/v1",
+			"kind":       "DNSRecord",
+			"metadata":   map[string]interface{}{"namespace": "infra", "name": name, "uid": name},
+			"spec":       map[string]interface{}{"dnsName": "*.example.com.", "recordType": "A", "targets": []interface{}{"10.0.0.1"}},
+		}}
+		if gateway != "" {
+			obj.SetLabels(map[string]string{"gateway.networking.k8s.io/gateway-name": gateway})
+		}
+		return obj
+	}
+	resources := &types.ResourceCollection{
+		Gateways:   []gatewayv1.Gateway{addressedGateway("plain", "10.0.0.1", nil)},
+		DNSRecords: []unstructured.Unstructured{record("labeled", "plain"), record("unlabeled", ""), record("dangling", "missing")},
+	}
+
+	var got []string
+	for _, r := range (openShift{}).Records(resources) {
+		got = append(got, recordKey(r))
+	}
+	want := []string{
+		"DNSRecord infra/labeled *.example.com A 10.0.0.1 via plain",
+		"DNSRecord infra/unlabeled *.example.com A 10.0.0.1",
+		"DNSRecord infra/dangling *.example.com A 10.0.0.1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+}
+
+// staticProvider returns fixed records
+type staticProvider []Record
+
+// Name implements Provider
+func (staticProvider) Name() string {
+	return "static"
+}
+
+// Records implements Provider
+func (p staticProvider) Records(*types.ResourceCollection) []Record {
+	return p
+}
+
+func TestCollect(t *testing.T) {
+	first := staticProvider{{ID: "a", DNSName: "a.example.com"}, {ID: "empty"}}
+	second := staticProvider{{ID: "a", DNSName: "duplicate.example.com"}, {ID: "b", DNSName: "b.example.com"}}
+
+	var got []string
+	for _, record := range Collect(&types.ResourceCollection{}, []Provider{first, second}) {
+		got = append(got, record.DNSName)
+	}
+	if want := []string{"a.example.com", "b.example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect = %v, want %v", got, want)
+	}
+}
+
+func TestByName(t *testing.T) {
+	if _, err := ByName([]string{"dnsrecord", "route53"}); err == nil {
+		t.Errorf("ByName with an unknown source succeeded, want an error")
+	}
+
+	var names []string
+	for _, provider := range Defaults() {
+		names = append(names, provider.Name())
+	}
+	if !reflect.DeepEqual(names, DefaultNames) {
+		t.Errorf("Defaults = %v, want %v", names, DefaultNames)
+	}
+}
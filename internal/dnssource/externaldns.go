@@ -0,0 +1,139 @@
+package dnssource
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ExternalDNS annotations read from Gateways and HTTPRoutes
+const (
+	annotationPrefix   = "external-dns.alpha.kubernetes.io/"
+	hostnameAnnotation = annotationPrefix + "hostname"
+	targetAnnotation   = annotationPrefix + "target"
+)
+
+// externalDNS infers the records ExternalDNS publishes for HTTPRoutes with its gateway-httproute
+// source. With annotatedOnly, only routes that carry an external-dns annotation, or whose Gateway
+// does, are considered.
+type externalDNS struct {
+	name          string
+	annotatedOnly bool
+}
+
+// Name implements Provider
+func (p externalDNS) Name() string {
+	return p.name
+}
+
+// Records implements Provider. Like the gateway-httproute source, every hostname a route serves
+// through a listener it attaches to is published, plus the hostnames of the route's hostname
+// annotation. The targets are those of the Gateway's target annotation, else the Gateway's status
+// addresses. Listeners and routes that leave the hostname open publish nothing.
+func (p externalDNS) Records(resources *types.ResourceCollection) []Record {
+	var records []Record
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		seen := make(map[string]bool)
+
+		for _, ref := range route.Spec.ParentRefs {
+			gw := analysis.ParentGateway(resources, route.Namespace, ref)
+			if gw == nil || seen[string(gw.UID)] {
+				continue
+			}
+			if p.annotatedOnly && !hasAnnotation(route.Annotations) && !hasAnnotation(gw.Annotations) {
+				continue
+			}
+			listeners, _ := analysis.AttachedListeners(gw, route, ref)
+			if len(listeners) == 0 {
+				continue
+			}
+			seen[string(gw.UID)] = true
+
+			var hostnames []string
+			for _, l := range listeners {
+				hostnames = append(hostnames, analysis.EffectiveHostnames(gw.Spec.Listeners[l], route)...)
+			}
+			hostnames = append(hostnames, splitAnnotation(route.Annotations[hostnameAnnotation])...)
+
+			targets := gatewayTargets(gw)
+			for _, host := range hostnames {
+				host = hostname.Normalize(host)
+				id := fmt.Sprintf("external-dns:%s:%s", route.UID, host)
+				if host == "" || seen[id] {
+					continue
+				}
+				seen[id] = true
+				records = append(records, Record{
+					ID:         id,
+					Provider:   p.Name(),
+					Group:      gatewayv1.GroupName,
+					Version:    "v1",
+					Kind:       "HTTPRoute",
+					Namespace:  route.Namespace,
+					Name:       route.Name,
+					DNSName:    host,
+					RecordType: recordType(targets),
+					Targets:    targets,
+					Gateway:    gw,
+				})
+			}
+		}
+	}
+	return records
+}
+
+// hasAnnotation reports whether any external-dns annotation is set
+func hasAnnotation(annotations map[string]string) bool {
+	for key := range annotations {
+		if strings.HasPrefix(key, annotationPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAnnotation splits a comma-separated annotation value, dropping empty items
+func splitAnnotation(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// gatewayTargets returns the targets ExternalDNS publishes for a Gateway's routes
+func gatewayTargets(gw *gatewayv1.Gateway) []string {
+	if targets := splitAnnotation(gw.Annotations[targetAnnotation]); len(targets) > 0 {
+		return targets
+	}
+	var targets []string
+	for _, address := range gw.Status.Addresses {
+		targets = append(targets, address.Value)
+	}
+	return targets
+}
+
+// recordType returns A or AAAA for IP targets and CNAME for hostnames, as ExternalDNS does
+func recordType(targets []string) string {
+	if len(targets) == 0 {
+		return ""
+	}
+	ip := net.ParseIP(targets[0])
+	switch {
+	case ip == nil:
+		return "CNAME"
+	case ip.To4() == nil:
+		return "AAAA"
+	default:
+		return "A"
+	}
+}
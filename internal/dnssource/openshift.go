@@ -0,0 +1,44 @@
+package dnssource
+
+import (
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// openShift reads the DNSRecords the OpenShift ingress operator publishes for Gateways
+type openShift struct{}
+
+// Name implements Provider
+func (openShift) Name() string {
+	return "dnsrecord"
+}
+
+// Records implements Provider. The Gateway is named by the record's gateway-name label.
+func (p openShift) Records(resources *types.ResourceCollection) []Record {
+	var records []Record
+	for i := range resources.DNSRecords {
+		dns := &resources.DNSRecords[i]
+		dnsName, _, _ := unstructured.NestedString(dns.Object, "spec", "dnsName")
+		recordType, _, _ := unstructured.NestedString(dns.Object, "spec", "recordType")
+		targets, _, _ := unstructured.NestedStringSlice(dns.Object, "spec", "targets")
+
+		records = append(records, Record{
+			ID:         string(dns.GetUID()),
+			Provider:   p.Name(),
+			Group:      "ingress.operator.openshift.io",
+			Version:    "v1",
+			Kind:       "DNSRecord",
+			Namespace:  dns.GetNamespace(),
+			Name:       dns.GetName(),
+			DNSName:    hostname.Normalize(dnsName),
+			RecordType: recordType,
+			Targets:    targets,
+			Gateway:    analysis.RecordGateway(resources, dns),
+			Object:     dns,
+		})
+	}
+	return records
+}
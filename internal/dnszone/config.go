@@ -0,0 +1,126 @@
+package dnszone
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gwapi-graph/internal/hostname"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Palette colors zones that do not declare a color, in order of creation
+var Palette = []string{"#e3f2fd", "#f3e5f5", "#e8f5e8", "#fff3e0", "#fce4ec", "#e0f2f1", "#f9fbe7", "#fff8e1"}
+
+// Config controls how hostnames are grouped into zones. Rules are tried in order and the first
+// match places a hostname in a single named zone; hostnames no rule matches are placed in their
+// parent domains up to the registrable domain.
+type Config struct {
+	Rules    []Rule `json:"rules,omitempty"`
+	Zones    []Zone `json:"zones,omitempty"`
+	MaxDepth *int   `json:"maxDepth,omitempty"` // Labels allowed in front of the registrable domain in derived zones; unset for no limit
+}
+
+// Rule maps matching hostnames to a named zone
+type Rule struct {
+	Suffix string `json:"suffix,omitempty"` // Matches this domain and its subdomains
+	Regex  string `json:"regex,omitempty"`  // Matches the whole lower-case ASCII hostname
+	Zone   string `json:"zone,omitempty"`   // Zone name, which may use $1 or ${name} for regex groups; defaults to the suffix
+
+	re *regexp.Regexp
+}
+
+// Zone declares a zone with a fixed color
+type Zone struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// LoadConfig reads a zone configuration from a YAML or JSON file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zone config: %w", err)
+	}
+
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse zone config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseConfig parses and validates a zone configuration
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, err
+	}
+
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		switch {
+		case rule.Suffix != "" && rule.Regex != "":
+			return nil, fmt.Errorf("rules[%d]: set either suffix or regex, not both", i)
+		case rule.Suffix != "":
+			rule.Suffix = hostname.Domain(ToASCII(strings.TrimPrefix(rule.Suffix, ".")))
+			if rule.Zone == "" {
+				rule.Zone = rule.Suffix
+			}
+		case rule.Regex != "":
+			if _, err := regexp.Compile(rule.Regex); err != nil {
+				return nil, fmt.Errorf("rules[%d]: invalid regex: %w", i, err)
+			}
+			re := regexp.MustCompile("^(?:" + rule.Regex + ")$")
+			if rule.Zone == "" {
+				return nil, fmt.Errorf("rules[%d]: regex rules need a zone", i)
+			}
+			rule.re = re
+		default:
+			return nil, fmt.Errorf("rules[%d]: set suffix or regex", i)
+		}
+	}
+
+	declared := make(map[string]bool)
+	for i, zone := range config.Zones {
+		if zone.Name == "" {
+			return nil, fmt.Errorf("zones[%d]: name is required", i)
+		}
+		if declared[zone.Name] {
+			return nil, fmt.Errorf("zones[%d]: zone %s is declared twice", i, zone.Name)
+		}
+		declared[zone.Name] = true
+	}
+
+	if config.MaxDepth != nil && *config.MaxDepth < 0 {
+		return nil, fmt.Errorf("maxDepth must not be negative")
+	}
+	return &config, nil
+}
+
+// match returns the zone a rule places a normalized hostname in, or false when it does not match
+func (r *Rule) match(host string) (string, bool) {
+	if r.re != nil {
+		indexes := r.re.FindStringSubmatchIndex(host)
+		if indexes == nil {
+			return "", false
+		}
+		return string(r.re.ExpandString(nil, r.Zone, host, indexes)), true
+	}
+
+	domain := hostname.Domain(host)
+	if domain == r.Suffix || strings.HasSuffix(domain, "."+r.Suffix) {
+		return r.Zone, true
+	}
+	return "", false
+}
+
+// String describes a rule, e.g. `suffix apps.example.com`
+func (r *Rule) String() string {
+	if r.re != nil {
+		return fmt.Sprintf("regex %s", r.Regex)
+	}
+	return fmt.Sprintf("suffix %s", r.Suffix)
+}
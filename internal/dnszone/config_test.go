@@ -0,0 +1,106 @@
+package dnszone
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testConfig = `
+rules:
+- suffix: apps.example.com
+  zone: apps
+- regex: '([a-z]+)\.svc\.example\.net'
+  zone: team-$1
+- regex: '(?P<env>dev|prod)\..*\.example\.org'
+  zone: ${env}-zone
+- suffix: .Internal.Example.COM.
+maxDepth: 1
+`
+
+func TestPlaceRules(t *testing.T) {
+	config, err := ParseConfig([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	extractor := New(nil, config)
+
+	tests := []struct {
+		host  string
+		zones []string
+		rule  string
+	}{
+		{"foo.apps.example.com", []string{"apps"}, "rules[0]"},
+		{"*.apps.example.com", []string{"apps"}, "rules[0]"},
+		{"apps.example.com", []string{"apps"}, "rules[0]"},
+		{"billing.svc.example.net", []string{"team-billing"}, "rules[1]"},
+		{"prod.shop.example.org", []string{"prod-zone"}, "rules[2]"},
+		{"db.internal.example.com", []string{"internal.example.com"}, "rules[3]"},
+		// The regex must match the whole hostname, so this one falls through to the suffix list
+		{"a.b.svc.example.net", []string{"svc.example.net", "example.net"}, "publicSuffix"},
+		// maxDepth 1 trims b.c.example.co.uk, two labels in front of example.co.uk
+		{"a.b.c.example.co.uk", []string{"c.example.co.uk", "example.co.uk"}, "publicSuffix"},
+		{"foo.example.co.uk", []string{"example.co.uk"}, "publicSuffix"},
+		{"co.uk", []string{"co.uk"}, "publicSuffix"},
+	}
+
+	for _, tt := range tests {
+		placement := extractor.Place(tt.host, nil)
+		if !reflect.DeepEqual(placement.Zones, tt.zones) || placement.Rule != tt.rule {
+			t.Errorf("Place(%q) = %v by %s, want %v by %s (%s)", tt.host, placement.Zones, placement.Rule, tt.zones, tt.rule, placement.Reason)
+		}
+	}
+}
+
+func TestPlaceMaxDepth(t *testing.T) {
+	tests := []struct {
+		maxDepth *int
+		zones    []string
+	}{
+		{nil, []string{"b.c.example.com", "c.example.com", "example.com"}},
+		{intPtr(2), []string{"b.c.example.com", "c.example.com", "example.com"}},
+		{intPtr(1), []string{"c.example.com", "example.com"}},
+		{intPtr(0), []string{"example.com"}},
+	}
+
+	for _, tt := range tests {
+		extractor := New(nil, &Config{MaxDepth: tt.maxDepth})
+		if got := extractor.Place("a.b.c.example.com", nil).Zones; !reflect.DeepEqual(got, tt.zones) {
+			t.Errorf("Place with maxDepth %v = %v, want %v", derefOrNil(tt.maxDepth), got, tt.zones)
+		}
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"suffix and regex", "rules:\n- suffix: example.com\n  regex: '.*'\n  zone: z\n"},
+		{"neither suffix nor regex", "rules:\n- zone: z\n"},
+		{"regex without zone", "rules:\n- regex: '.*'\n"},
+		{"invalid regex", "rules:\n- regex: '('\n  zone: z\n"},
+		{"zone without name", "zones:\n- color: '#fff'\n"},
+		{"zone declared twice", "zones:\n- name: a\n- name: a\n"},
+		{"negative maxDepth", "maxDepth: -1\n"},
+		{"unknown field", "rule: []\n"},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseConfig([]byte(tt.config)); err == nil {
+			t.Errorf("ParseConfig with %s succeeded, want an error", tt.name)
+		}
+	}
+}
+
+// intPtr returns a pointer to an int
+func intPtr(value int) *int {
+	return &value
+}
+
+// derefOrNil formats an optional int
+func derefOrNil(value *int) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
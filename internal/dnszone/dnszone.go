@@ -0,0 +1,199 @@
+// Package dnszone derives the DNS zones a hostname belongs to from the Public Suffix List, so that
+// zones follow real delegation boundaries: foo.example.co.uk belongs to example.co.uk, never to the
+// public suffix co.uk.
+package dnszone
+
+import (
+	"fmt"
+	"strings"
+
+	"gwapi-graph/internal/hostname"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// SuffixList finds the public suffix of a lower-case ASCII domain, such as co.uk for
+// foo.example.co.uk. publicsuffix.List, compiled into the binary, implements it.
+type SuffixList interface {
+	PublicSuffix(domain string) string
+}
+
+// Extractor derives zones from hostnames using zone rules and a public suffix list
+type Extractor struct {
+	suffixes SuffixList
+	config   *Config
+}
+
+// Placement explains which zones a hostname is placed in
+type Placement struct {
+	Zones  []string // Most specific first
+	Rule   string   // What placed the hostname: rules[<index>], hostedZone, or publicSuffix for derived zones
+	Reason string
+}
+
+// New creates an Extractor using the given suffix list, or the embedded list when it is nil, and
+// the given zone configuration, which may be nil
+func New(suffixes SuffixList, config *Config) *Extractor {
+	if suffixes == nil {
+		suffixes = publicsuffix.List
+	}
+	if config == nil {
+		config = &Config{}
+	}
+	return &Extractor{suffixes: suffixes, config: config}
+}
+
+// Config returns the zone configuration
+func (e *Extractor) Config() *Config {
+	return e.config
+}
+
+// Place places a hostname in zones: the zone of the first rule that matches it, else the hosted
+// zones that contain it when the cluster declares any, else its derived zones, limited to the
+// configured maximum depth. A hostname outside every hosted zone is placed in none.
+func (e *Extractor) Place(host string, hosted []HostedZone) Placement {
+	host = ToASCII(host)
+	for i := range e.config.Rules {
+		rule := &e.config.Rules[i]
+		if zone, ok := rule.match(host); ok {
+			return Placement{
+				Zones:  []string{zone},
+				Rule:   fmt.Sprintf("rules[%d]", i),
+				Reason: fmt.Sprintf("%s matches rules[%d] (%s)", host, i, rule),
+			}
+		}
+	}
+
+	if len(hosted) > 0 {
+		placement := Placement{Rule: "hostedZone"}
+		for _, zone := range hosted {
+			if zone.Contains(host) {
+				placement.Zones = append(placement.Zones, zone.Name())
+			}
+		}
+		if len(placement.Zones) == 0 {
+			placement.Reason = fmt.Sprintf("%s is outside every hosted zone of the cluster", host)
+		} else {
+			placement.Reason = fmt.Sprintf("%s is within the cluster's hosted zone(s) %s", host, strings.Join(placement.Zones, ", "))
+		}
+		for _, zone := range hosted {
+			if zone.Inferred {
+				placement.Reason += fmt.Sprintf("; the %s zone's domain %s is inferred from the base domain, as the DNS config identifies the zone only by ID or tags", zone.Kind, zone.Domain)
+			}
+		}
+		return placement
+	}
+
+	zones := e.Zones(host)
+	placement := Placement{Zones: zones, Rule: "publicSuffix"}
+	registrable := e.RegistrableDomain(host)
+	if registrable == "" {
+		placement.Reason = fmt.Sprintf("%s matches no rule and has no registrable domain", host)
+		return placement
+	}
+
+	placement.Reason = fmt.Sprintf("%s matches no rule; placed in its parent domains up to the registrable domain %s", host, registrable)
+	if maxDepth := e.config.MaxDepth; maxDepth != nil {
+		limit := strings.Count(registrable, ".") + 1 + *maxDepth
+		for len(placement.Zones) > 1 && strings.Count(placement.Zones[0], ".")+1 > limit {
+			placement.Zones = placement.Zones[1:]
+		}
+		placement.Reason += fmt.Sprintf(", at most %d label(s) deeper (maxDepth)", *maxDepth)
+	}
+	return placement
+}
+
+// Color returns the declared color of a zone, or else the palette color for the index-th zone
+// created
+func (e *Extractor) Color(zone string, index int) string {
+	for _, declared := range e.config.Zones {
+		if declared.Name == zone && declared.Color != "" {
+			return declared.Color
+		}
+	}
+	return Palette[index%len(Palette)]
+}
+
+// Declared reports whether a zone is declared in the configuration
+func (e *Extractor) Declared(zone string) bool {
+	for _, declared := range e.config.Zones {
+		if declared.Name == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// ToASCII normalizes a hostname for zone lookups: lower case, without a trailing dot, with
+// internationalized labels in their punycode form so that both spellings of a name group together.
+// Labels that cannot be converted are kept as they are.
+func ToASCII(host string) string {
+	host = hostname.Normalize(host)
+	wildcard := hostname.IsWildcard(host)
+	if wildcard {
+		host = host[2:]
+	}
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	if wildcard {
+		host = "*." + host
+	}
+	return host
+}
+
+// RegistrableDomain returns the public suffix of a hostname plus one label, e.g. example.co.uk for
+// foo.example.co.uk. It is "" for a public suffix itself.
+func (e *Extractor) RegistrableDomain(host string) string {
+	domain := hostname.Domain(ToASCII(host))
+	if domain == "" {
+		return ""
+	}
+
+	suffix := e.suffixes.PublicSuffix(domain)
+	if suffix == domain || !strings.HasSuffix(domain, "."+suffix) {
+		return ""
+	}
+	rest := strings.TrimSuffix(domain, "."+suffix)
+	return rest[strings.LastIndex(rest, ".")+1:] + "." + suffix
+}
+
+// Zones returns the zones a hostname belongs to, most specific first: every parent domain up to
+// and including the registrable domain. A wildcard such as *.apps.example.com covers its own
+// domain, so apps.example.com is its most specific zone. A hostname without a registrable domain,
+// such as a single label or a public suffix, is its own zone.
+func (e *Extractor) Zones(host string) []string {
+	host = ToASCII(host)
+	if host == "" {
+		return nil
+	}
+
+	domain := hostname.Domain(host)
+	registrable := e.RegistrableDomain(domain)
+	if registrable == "" {
+		return []string{domain}
+	}
+
+	// A concrete hostname is a name in its parent's zone; the registrable domain is its own apex
+	if !hostname.IsWildcard(host) && domain != registrable {
+		domain = domain[strings.Index(domain, ".")+1:]
+	}
+
+	var zones []string
+	for {
+		zones = append(zones, domain)
+		if domain == registrable {
+			return zones
+		}
+		domain = domain[strings.Index(domain, ".")+1:]
+	}
+}
+
+// Zone returns the most specific zone of a hostname, or "" when it has none
+func (e *Extractor) Zone(host string) string {
+	if zones := e.Zones(host); len(zones) > 0 {
+		return zones[0]
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package dnszone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestZones(t *testing.T) {
+	extractor := New(nil, nil)
+
+	tests := []struct {
+		host        string
+		registrable string
+		zones       []string
+	}{
+		{"foo.example.co.uk", "example.co.uk", []string{"example.co.uk"}},
+		{"foo.bar.example.co.uk", "example.co.uk", []string{"bar.example.co.uk", "example.co.uk"}},
+		{"example.co.uk", "example.co.uk", []string{"example.co.uk"}},
+		{"*.apps.example.com", "example.com", []string{"apps.example.com", "example.com"}},
+		{"*.example.com", "example.com", []string{"example.com"}},
+		{"WWW.Example.COM.", "example.com", []string{"example.com"}},
+		// The embedded list has *.kawasaki.jp with the exception !city.kawasaki.jp
+		{"www.foo.kawasaki.jp", "www.foo.kawasaki.jp", []string{"www.foo.kawasaki.jp"}},
+		{"www.city.kawasaki.jp", "city.kawasaki.jp", []string{"city.kawasaki.jp"}},
+		{"co.uk", "", []string{"co.uk"}},
+		{"localhost", "", []string{"localhost"}},
+		{"", "", nil},
+	}
+
+	for _, tt := range tests {
+		if got := extractor.RegistrableDomain(tt.host); got != tt.registrable {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", tt.host, got, tt.registrable)
+		}
+		if got := extractor.Zones(tt.host); !reflect.DeepEqual(got, tt.zones) {
+			t.Errorf("Zones(%q) = %v, want %v", tt.host, got, tt.zones)
+		}
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"Bücher.Example.de", "xn--bcher-kva.example.de"},
+		{"*.bücher.example.de", "*.xn--bcher-kva.example.de"},
+		{"xn--bcher-kva.example.de", "xn--bcher-kva.example.de"},
+		{"www.example.com.", "www.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := ToASCII(tt.host); got != tt.want {
+			t.Errorf("ToASCII(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+
+	// Both spellings of an internationalized name fall in the same zone
+	extractor := New(nil, nil)
+	if unicode, punycode := extractor.Zone("shop.bücher.de"), extractor.Zone("shop.xn--bcher-kva.de"); unicode != punycode || unicode != "xn--bcher-kva.de" {
+		t.Errorf("Zone(shop.bücher.de) = %q, Zone(shop.xn--bcher-kva.de) = %q, want xn--bcher-kva.de for both", unicode, punycode)
+	}
+}
@@ -0,0 +1,168 @@
+package dnszone
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/hostname"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Publication states of a DNSRecord in a hosted zone
+const (
+	StatusPublished = "Published"
+	StatusFailed    = "Failed"
+	StatusUnknown   = "Unknown"
+)
+
+// HostedZone is a DNS zone the cluster publishes records in, declared by the public or private
+// zone of dnses.config.openshift.io/cluster
+type HostedZone struct {
+	Kind     string            // public or private
+	ID       string            // Cloud provider zone ID, when the zone is identified by ID
+	Tags     map[string]string // Cloud provider tags, when the zone is identified by tags
+	Domain   string            // The domain the zone serves
+	Inferred bool              // The domain is a guess, as the DNS config does not name it
+}
+
+// Name is the display name of the zone, e.g. "example.com (public)", or "example.com (public,
+// inferred)" when the domain is a guess
+func (z HostedZone) Name() string {
+	if z.Inferred {
+		return fmt.Sprintf("%s (%s, inferred)", z.Domain, z.Kind)
+	}
+	return fmt.Sprintf("%s (%s)", z.Domain, z.Kind)
+}
+
+// Contains reports whether a hostname, which may be a wildcard, is within the zone's domain
+func (z HostedZone) Contains(host string) bool {
+	domain := hostname.Domain(ToASCII(host))
+	return domain == z.Domain || strings.HasSuffix(domain, "."+z.Domain)
+}
+
+// matches reports whether a dnsZone reference from a DNSRecord status denotes this zone
+func (z HostedZone) matches(ref map[string]interface{}) bool {
+	id, _, _ := unstructured.NestedString(ref, "id")
+	if id != "" || z.ID != "" {
+		return id == z.ID
+	}
+	tags, _, _ := unstructured.NestedStringMap(ref, "tags")
+	return len(tags) > 0 && reflect.DeepEqual(tags, z.Tags)
+}
+
+// HostedZones reads the hosted zones from the cluster DNS config. The private zone serves the
+// cluster's base domain. The domain of the public zone is taken from an Azure zone ID, which ends
+// in the domain. AWS and GCP zones are identified by an ID or tags that do not name the domain, so
+// it is inferred to be the parent of the base domain, where the installer usually creates the
+// cluster's records, and the zone is marked Inferred.
+func HostedZones(dnsConfig *unstructured.Unstructured) []HostedZone {
+	if dnsConfig == nil {
+		return nil
+	}
+	baseDomain, _, _ := unstructured.NestedString(dnsConfig.Object, "spec", "baseDomain")
+	baseDomain = ToASCII(baseDomain)
+	if baseDomain == "" {
+		return nil
+	}
+
+	var zones []HostedZone
+	for _, kind := range []string{"private", "public"} {
+		ref, found, _ := unstructured.NestedMap(dnsConfig.Object, "spec", kind+"Zone")
+		if !found {
+			continue
+		}
+		zone := HostedZone{Kind: kind, Domain: baseDomain}
+		zone.ID, _, _ = unstructured.NestedString(ref, "id")
+		zone.Tags, _, _ = unstructured.NestedStringMap(ref, "tags")
+
+		if kind == "public" {
+			if i := strings.LastIndex(strings.ToLower(zone.ID), "/dnszones/"); i >= 0 {
+				zone.Domain = ToASCII(zone.ID[i+len("/dnszones/"):])
+			} else if parent := strings.SplitN(baseDomain, ".", 2); len(parent) == 2 {
+				zone.Domain, zone.Inferred = parent[1], true
+			}
+		}
+		zones = append(zones, zone)
+	}
+
+	// Most specific domain first
+	sort.SliceStable(zones, func(i, j int) bool {
+		return len(zones[i].Domain) > len(zones[j].Domain)
+	})
+	return zones
+}
+
+// RecordStatus is the publication state of a DNSRecord in one zone
+type RecordStatus struct {
+	Zone    string // The hosted zone name, or the zone ID or tags when the zone is not in the DNS config
+	Status  string // Published, Failed or Unknown
+	Message string
+	Hosted  *HostedZone // Nil when the zone is not in the DNS config
+}
+
+// RecordStatuses reads the per-zone conditions from a DNSRecord's status.zones. A zone is
+// Published when its Published condition is True or its Failed condition is False, and Failed when
+// the Published condition is False or the Failed condition is True.
+func RecordStatuses(record *unstructured.Unstructured, zones []HostedZone) []RecordStatus {
+	entries, _, _ := unstructured.NestedSlice(record.Object, "status", "zones")
+
+	var statuses []RecordStatus
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ref, _, _ := unstructured.NestedMap(entryMap, "dnsZone")
+
+		status := RecordStatus{Zone: describeZoneRef(ref), Status: StatusUnknown}
+		for i := range zones {
+			if zones[i].matches(ref) {
+				status.Zone, status.Hosted = zones[i].Name(), &zones[i]
+				break
+			}
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(entryMap, "conditions")
+		for _, condition := range conditions {
+			conditionMap, ok := condition.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			conditionType, _, _ := unstructured.NestedString(conditionMap, "type")
+			conditionStatus, _, _ := unstructured.NestedString(conditionMap, "status")
+			message, _, _ := unstructured.NestedString(conditionMap, "message")
+
+			switch {
+			case conditionType == "Published" && conditionStatus == "True", conditionType == "Failed" && conditionStatus == "False":
+				if status.Status == StatusUnknown {
+					status.Status = StatusPublished
+				}
+			case conditionType == "Published" && conditionStatus == "False", conditionType == "Failed" && conditionStatus == "True":
+				status.Status, status.Message = StatusFailed, message
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// describeZoneRef formats a dnsZone reference that matches no hosted zone
+func describeZoneRef(ref map[string]interface{}) string {
+	if id, _, _ := unstructured.NestedString(ref, "id"); id != "" {
+		return "zone " + id
+	}
+	tags, _, _ := unstructured.NestedStringMap(ref, "tags")
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+tags[key])
+	}
+	return "zone with tags " + strings.Join(parts, ",")
+}
@@ -0,0 +1,148 @@
+package dnszone
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// dnsConfig returns a dnses.config.openshift.io/cluster object with the given zones
+func dnsConfig(baseDomain string, privateZone, publicZone map[string]interface{}) *unstructured.Unstructured {
+	spec := map[string]interface{}{"baseDomain": baseDomain}
+	if privateZone != nil {
+		spec["privateZone"] = privateZone
+	}
+	if publicZone != nil {
+		spec["publicZone"] = publicZone
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "config.openshift.io/v1",
+		"kind":       "DNS",
+		"metadata":   map[string]interface{}{"name": "cluster"},
+		"spec":       spec,
+	}}
+}
+
+func TestHostedZones(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *unstructured.Unstructured
+		want   []string
+	}{
+		{
+			name:   "no DNS config",
+			config: nil,
+			want:   nil,
+		},
+		{
+			name: "Azure public zone named by its ID",
+			config: dnsConfig("mycluster.example.co.uk",
+				map[string]interface{}{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/privateDnsZones/mycluster.example.co.uk"},
+				map[string]interface{}{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/dnszones/example.co.uk"}),
+			want: []string{"mycluster.example.co.uk (private)", "example.co.uk (public)"},
+		},
+		{
+			name: "AWS public zone identified by ID only",
+			config: dnsConfig("mycluster.example.com",
+				map[string]interface{}{"tags": map[string]interface{}{"Name": "mycluster-int"}},
+				map[string]interface{}{"id": "Z0123456789"}),
+			want: []string{"mycluster.example.com (private)", "example.com (public, inferred)"},
+		},
+		{
+			name:   "private zone only",
+			config: dnsConfig("mycluster.example.com", map[string]interface{}{"id": "Z1"}, nil),
+			want:   []string{"mycluster.example.com (private)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, zone := range HostedZones(tt.config) {
+				got = append(got, zone.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HostedZones = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceHostedZones(t *testing.T) {
+	hosted := HostedZones(dnsConfig("mycluster.example.co.uk",
+		map[string]interface{}{"id": "private"},
+		map[string]interface{}{"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/dnszones/example.co.uk"}))
+	config, err := ParseConfig([]byte("rules:\n- suffix: legacy.example.co.uk\n  zone: legacy\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig: %v", err)
+	}
+	extractor := New(nil, config)
+
+	tests := []struct {
+		host  string
+		zones []string
+		rule  string
+	}{
+		// The suffix list alone would place it in apps.mycluster.example.co.uk and its parents
+		{"foo.apps.mycluster.example.co.uk", []string{"mycluster.example.co.uk (private)", "example.co.uk (public)"}, "hostedZone"},
+		{"www.example.co.uk", []string{"example.co.uk (public)"}, "hostedZone"},
+		{"www.other.co.uk", nil, "hostedZone"},
+		// Rules come before hosted zones
+		{"app.legacy.example.co.uk", []string{"legacy"}, "rules[0]"},
+	}
+
+	for _, tt := range tests {
+		placement := extractor.Place(tt.host, hosted)
+		if !reflect.DeepEqual(placement.Zones, tt.zones) || placement.Rule != tt.rule {
+			t.Errorf("Place(%q) = %v by %s, want %v by %s (%s)", tt.host, placement.Zones, placement.Rule, tt.zones, tt.rule, placement.Reason)
+		}
+	}
+
+	// Without hosted zones the same hostname falls back to the suffix list
+	if got := extractor.Place("foo.apps.mycluster.example.co.uk", nil); got.Rule != "publicSuffix" || got.Zones[0] != "apps.mycluster.example.co.uk" {
+		t.Errorf("Place without hosted zones = %v by %s, want apps.mycluster.example.co.uk first by publicSuffix", got.Zones, got.Rule)
+	}
+}
+
+func TestRecordStatuses(t *testing.T) {
+	hosted := HostedZones(dnsConfig("mycluster.example.com",
+		map[string]interface{}{"tags": map[string]interface{}{"Name": "mycluster-int"}},
+		map[string]interface{}{"id": "Z0123456789"}))
+
+	zone := func(ref map[string]interface{}, conditions ...map[string]interface{}) interface{} {
+		list := make([]interface{}, len(conditions))
+		for i, condition := range conditions {
+			list[i] = condition
+		}
+		return map[string]interface{}{"dnsZone": ref, "conditions": list}
+	}
+	condition := func(conditionType, status, message string) map[string]interface{} {
+		return map[string]interface{}{"type": conditionType, "status": status, "message": message}
+	}
+	record := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"zones": []interface{}{
+			zone(map[string]interface{}{"id": "Z0123456789"}, condition("Published", "True", "")),
+			zone(map[string]interface{}{"tags": map[string]interface{}{"Name": "mycluster-int"}}, condition("Failed", "True", "throttled")),
+			zone(map[string]interface{}{"id": "Zother"}),
+		}},
+	}}
+
+	want := []RecordStatus{
+		{Zone: "example.com (public, inferred)", Status: StatusPublished},
+		{Zone: "mycluster.example.com (private)", Status: StatusFailed, Message: "throttled"},
+		{Zone: "zone Zother", Status: StatusUnknown},
+	}
+	got := RecordStatuses(record, hosted)
+	if len(got) != len(want) {
+		t.Fatalf("RecordStatuses returned %d statuses, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Zone != want[i].Zone || got[i].Status != want[i].Status || got[i].Message != want[i].Message {
+			t.Errorf("status %d = %s %s %q, want %s %s %q", i, got[i].Zone, got[i].Status, got[i].Message, want[i].Zone, want[i].Status, want[i].Message)
+		}
+		if (got[i].Hosted == nil) != (want[i].Zone == "zone Zother") {
+			t.Errorf("status %d hosted zone = %v", i, got[i].Hosted)
+		}
+	}
+}
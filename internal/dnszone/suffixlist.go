@@ -0,0 +1,83 @@
+package dnszone
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// List is a public suffix list read from a file in the public_suffix_list.dat format of
+// publicsuffix.org, used instead of the embedded list when that one is out of date
+type List struct {
+	rules      map[string]bool // Normal rules such as co.uk
+	wildcards  map[string]bool // Domains of wildcard rules, e.g. ck for *.ck
+	exceptions map[string]bool // Exception rules without the "!", e.g. www.ck
+}
+
+// LoadSuffixList reads a public suffix list file
+func LoadSuffixList(path string) (*List, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open public suffix list: %w", err)
+	}
+	defer file.Close()
+
+	list, err := ParseSuffixList(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public suffix list %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// ParseSuffixList parses a public suffix list. Each line holds one rule, optionally followed by
+// whitespace and ignored text; lines starting with // are comments.
+func ParseSuffixList(r io.Reader) (*List, error) {
+	list := &List{
+		rules:      make(map[string]bool),
+		wildcards:  make(map[string]bool),
+		exceptions: make(map[string]bool),
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+
+		rule := fields[0]
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			list.exceptions[ToASCII(rule[1:])] = true
+		case strings.HasPrefix(rule, "*."):
+			list.wildcards[ToASCII(rule[2:])] = true
+		default:
+			list.rules[ToASCII(rule)] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(list.rules)+len(list.wildcards) == 0 {
+		return nil, fmt.Errorf("no rules found")
+	}
+	return list, nil
+}
+
+// PublicSuffix returns the public suffix of a domain by the prevailing rule: an exception rule,
+// else the matching rule with the most labels, else the top-level label
+func (l *List) PublicSuffix(domain string) string {
+	labels := strings.Split(domain, ".")
+	for i := range labels {
+		candidate := strings.Join(labels[i:], ".")
+		if l.exceptions[candidate] {
+			return strings.Join(labels[i+1:], ".")
+		}
+		if l.rules[candidate] || (i+1 < len(labels) && l.wildcards[strings.Join(labels[i+1:], ".")]) {
+			return candidate
+		}
+	}
+	return labels[len(labels)-1]
+}
@@ -0,0 +1,77 @@
+package dnszone
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSuffixList = `// A cut-down public_suffix_list.dat
+// ===BEGIN ICANN DOMAINS===
+com
+uk
+co.uk
+
+// Every second-level domain of ck is a public suffix, except www.ck
+*.ck
+!www.ck
+
+公司.cn    trailing text is ignored
+`
+
+func TestParseSuffixList(t *testing.T) {
+	list, err := ParseSuffixList(strings.NewReader(testSuffixList))
+	if err != nil {
+		t.Fatalf("ParseSuffixList: %v", err)
+	}
+
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{"foo.example.com", "com"},
+		{"foo.example.co.uk", "co.uk"},
+		{"example.uk", "uk"},
+		{"foo.bar.ck", "bar.ck"},
+		{"bar.ck", "bar.ck"},
+		{"www.ck", "ck"},
+		{"foo.www.ck", "ck"},
+		{"example.xn--55qx5d.cn", "xn--55qx5d.cn"},
+		{"foo.example.org", "org"},
+	}
+
+	for _, tt := range tests {
+		if got := list.PublicSuffix(tt.domain); got != tt.want {
+			t.Errorf("PublicSuffix(%q) = %q, want %q", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestParseSuffixListExtractor(t *testing.T) {
+	list, err := ParseSuffixList(strings.NewReader(testSuffixList))
+	if err != nil {
+		t.Fatalf("ParseSuffixList: %v", err)
+	}
+	extractor := New(list, nil)
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"foo.example.co.uk", "example.co.uk"},
+		{"a.foo.bar.ck", "foo.bar.ck"},
+		{"a.www.ck", "www.ck"},
+		{"shop.example.公司.cn", "example.xn--55qx5d.cn"},
+	}
+
+	for _, tt := range tests {
+		if got := extractor.RegistrableDomain(tt.host); got != tt.want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestParseSuffixListEmpty(t *testing.T) {
+	if _, err := ParseSuffixList(strings.NewReader("// only comments\n\n")); err == nil {
+		t.Errorf("ParseSuffixList of a list without rules succeeded, want an error")
+	}
+}
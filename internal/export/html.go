@@ -0,0 +1,102 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/diff"
+	"gwapi-graph/internal/types"
+)
+
+// d3Path is where the local copy of D3 inlined into exports is read from, relative to the web
+// directory; scripts/vendor-d3.sh downloads it
+const d3Path = "static/vendor/d3.v7.min.js"
+
+// Snapshot is the data embedded in an HTML export
+type Snapshot struct {
+	GeneratedAt time.Time              `json:"generatedAt"`
+	Graph       *types.Graph           `json:"graph"`
+	Details     map[string]interface{} `json:"details,omitempty"` // Resource objects keyed by DetailsKey
+}
+
+// DetailsKey identifies a resource in Snapshot.Details, matching how the web UI looks them up
+func DetailsKey(nodeType, namespace, name string) string {
+	return diff.ObjectKey(nodeType, namespace, name)
+}
+
+// NodeDetailsKey returns the DetailsKey of the object behind a node, as resourceRef in app.js
+// resolves it: DNSRecord nodes may come from a DNSEndpoint or an HTTPRoute, and Policy nodes
+// are keyed by their kind
+func NodeDetailsKey(node types.Node) string {
+	if source := node.DNSSource; source != nil {
+		return DetailsKey(source.Kind, source.Namespace, source.Name)
+	}
+	if node.Type == "Policy" {
+		return DetailsKey(node.Kind, node.Namespace, node.Name)
+	}
+	return DetailsKey(node.Type, node.Namespace, node.Name)
+}
+
+// Prune drops details of resources that are not nodes of the graph, e.g. after filtering
+func (s *Snapshot) Prune() {
+	if s.Details == nil {
+		return
+	}
+
+	keep := make(map[string]bool)
+	for _, node := range s.Graph.Nodes {
+		keep[NodeDetailsKey(node)] = true
+	}
+	for key := range s.Details {
+		if !keep[key] {
+			delete(s.Details, key)
+		}
+	}
+}
+
+// WriteHTML renders the web UI as a single self-contained HTML file showing the snapshot.
+// webDir is the directory holding templates/index.html and the static assets.
+func WriteHTML(w io.Writer, webDir, title string, snapshot *Snapshot) error {
+	tmpl, err := template.ParseFiles(filepath.Join(webDir, "templates", "index.html"))
+	if err != nil {
+		return fmt.Errorf("failed to load page template: %w", err)
+	}
+
+	script, err := os.ReadFile(filepath.Join(webDir, "static", "app.js"))
+	if err != nil {
+		return fmt.Errorf("failed to read app script: %w", err)
+	}
+	style, err := os.ReadFile(filepath.Join(webDir, "static", "style.css"))
+	if err != nil {
+		return fmt.Errorf("failed to read stylesheet: %w", err)
+	}
+
+	data := map[string]interface{}{
+		"title":        title,
+		"snapshot":     snapshot,
+		"inlineScript": template.JS(escapeScript(string(script))),
+		"inlineStyle":  template.CSS(strings.ReplaceAll(string(style), "</style", `<\/style`)),
+	}
+
+	// Exports must open without network access, so there is no fallback to the CDN
+	d3, err := os.ReadFile(filepath.Join(webDir, d3Path))
+	if err != nil {
+		return fmt.Errorf("failed to read D3, run scripts/vendor-d3.sh to download it: %w", err)
+	}
+	data["inlineD3"] = template.JS(escapeScript(string(d3)))
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render HTML export: %w", err)
+	}
+	return nil
+}
+
+// escapeScript keeps inlined JavaScript from closing its script element early
+func escapeScript(script string) string {
+	return strings.ReplaceAll(script, "</script", `<\/script`)
+}
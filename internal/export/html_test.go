@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/types"
+)
+
+func TestPrune(t *testing.T) {
+	snapshot := &Snapshot{
+		Graph: &types.Graph{Nodes: []types.Node{
+			{Type: "Gateway", Namespace: "infra", Name: "gw"},
+			{Type: "DNSRecord", Namespace: "openshift-ingress", Name: "default-wildcard",
+				DNSSource: &types.DNSSource{Provider: "dnsrecord", Kind: "DNSRecord", Namespace: "openshift-ingress", Name: "default-wildcard"}},
+			{Type: "DNSRecord", Namespace: "app", Name: "www.example.com",
+				DNSSource: &types.DNSSource{Provider: "dnsendpoint", Kind: "DNSEndpoint", Namespace: "app", Name: "records"}},
+			{Type: "DNSRecord", Namespace: "app", Name: "shop.example.com",
+				DNSSource: &types.DNSSource{Provider: "external-dns", Kind: "HTTPRoute", Namespace: "app", Name: "shop"}},
+			{Type: "Policy", Kind: "BackendTLSPolicy", Namespace: "app", Name: "tls"},
+		}},
+		Details: map[string]interface{}{
+			"gateway/infra/gw": "kept",
+			"dnsrecord/openshift-ingress/default-wildcard": "kept",
+			"dnsendpoint/app/records":                      "kept",
+			"httproute/app/shop":                           "kept",
+			"backendtlspolicy/app/tls":                     "kept",
+			"policy/app/tls":                               "dropped",
+			"httproute/app/filtered":                       "dropped",
+			"service/app/filtered":                         "dropped",
+		},
+	}
+
+	snapshot.Prune()
+
+	var got []string
+	for key := range snapshot.Details {
+		got = append(got, key)
+	}
+	sort.Strings(got)
+	want := []string{"backendtlspolicy/app/tls", "dnsendpoint/app/records", "dnsrecord/openshift-ingress/default-wildcard", "gateway/infra/gw", "httproute/app/shop"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("details after Prune = %v, want %v", got, want)
+	}
+}
+
+// webDir copies the page template of the web UI into a temporary directory next to the given
+// static assets and returns the directory
+func webDir(t *testing.T, assets map[string]string) string {
+	t.Helper()
+	page, err := os.ReadFile(filepath.Join("..", "..", "web", "templates", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{"templates/index.html": string(page)}
+	for name, content := range assets {
+		files["static/"+name] = content
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestWriteHTML(t *testing.T) {
+	dir := webDir(t, map[string]string{
+		"app.js":              `console.log("</script>");`,
+		"style.css":           `body::after { content: "</style>"; }`,
+		"vendor/d3.v7.min.js": `var d3 = "</script>";`,
+	})
+	snapshot := &Snapshot{Graph: &types.Graph{Nodes: []types.Node{{ID: "infra/gw", Type: "Gateway", Name: "</script><b>gw"}}}}
+
+	var out bytes.Buffer
+	if err := WriteHTML(&out, dir, "Incident review", snapshot); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	page := out.String()
+
+	for _, want := range []string{
+		"<title>Incident review</title>",
+		`console.log("<\/script>");`,
+		`content: "<\/style>";`,
+		"window.GWAPI_SNAPSHOT = ",
+		`"name":"\u003c/script\u003e\u003cb\u003egw"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("export lacks %q", want)
+		}
+	}
+	for _, unwanted := range []string{"https://d3js.org", `src="/static/app.js"`, `href="/static/style.css"`} {
+		if strings.Contains(page, unwanted) {
+			t.Errorf("export refers to %q instead of inlining it", unwanted)
+		}
+	}
+	// Every script element opened is closed once, so no inlined content ended one early
+	if opened, closed := strings.Count(page, "<script>"), strings.Count(page, "</script>"); opened != closed {
+		t.Errorf("export has %d script elements but %d closing tags", opened, closed)
+	}
+}
+
+func TestWriteHTMLWithoutD3(t *testing.T) {
+	dir := webDir(t, map[string]string{"app.js": "", "style.css": ""})
+	err := WriteHTML(&bytes.Buffer{}, dir, "gwapi-graph", &Snapshot{Graph: &types.Graph{}})
+	if err == nil || !strings.Contains(err.Error(), "scripts/vendor-d3.sh") {
+		t.Errorf("WriteHTML without D3 = %v, want an error naming the vendoring script", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package hostname implements the Gateway API hostname semantics: case-insensitive names with an
+// optional trailing dot, and wildcards that replace the leftmost label with "*" and match one or
+// more labels, so *.example.com matches foo.example.com and a.b.example.com but not example.com.
+// An empty hostname, as on a listener without one, matches every hostname.
+package hostname
+
+import "strings"
+
+// exactBonus ranks exact matches above every wildcard match in Specificity
+const exactBonus = 1000
+
+// Normalize lower-cases a hostname and removes surrounding whitespace and a trailing dot
+func Normalize(hostname string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+}
+
+// IsWildcard reports whether a hostname is a wildcard such as *.example.com
+func IsWildcard(hostname string) bool {
+	return strings.HasPrefix(hostname, "*.")
+}
+
+// Matches reports whether a hostname pattern matches a hostname. Either may be a wildcard; a
+// wildcard hostname is matched when the pattern matches every name it stands for.
+func Matches(pattern, hostname string) bool {
+	_, ok := Specificity(pattern, hostname)
+	return ok
+}
+
+// Specificity reports whether a pattern matches a hostname and how specific the match is: an exact
+// match ranks above any wildcard, and longer wildcards above shorter ones. An empty pattern
+// matches everything with specificity 0.
+func Specificity(pattern, hostname string) (int, bool) {
+	pattern, hostname = Normalize(pattern), Normalize(hostname)
+	if pattern == "" {
+		return 0, true
+	}
+	if pattern == hostname {
+		return exactBonus + len(pattern), true
+	}
+	if !IsWildcard(pattern) {
+		return 0, false
+	}
+
+	// The wildcard needs at least one label in front of its suffix
+	suffix := pattern[1:]
+	if strings.HasSuffix(hostname, suffix) && len(hostname) > len(suffix) {
+		return len(pattern), true
+	}
+	return 0, false
+}
+
+// Intersect returns the hostnames two patterns have in common, as a single pattern: the more
+// specific of the two when one contains the other. ok is false when no hostname matches both.
+// An empty pattern matches everything, so the intersection with it is the other pattern.
+func Intersect(a, b string) (string, bool) {
+	a, b = Normalize(a), Normalize(b)
+	switch {
+	case a == "":
+		return b, true
+	case b == "":
+		return a, true
+	case Matches(a, b):
+		return b, true
+	case Matches(b, a):
+		return a, true
+	}
+	return "", false
+}
+
+// IntersectAll returns the effective hostnames of a route on a listener: the intersections of the
+// route hostnames with the listener hostname, without duplicates. A route without hostnames takes
+// the listener hostname, which is "" when the listener accepts every hostname.
+func IntersectAll(listener string, routes []string) []string {
+	if len(routes) == 0 {
+		return []string{Normalize(listener)}
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, route := range routes {
+		if intersection, ok := Intersect(listener, route); ok && !seen[intersection] {
+			seen[intersection] = true
+			result = append(result, intersection)
+		}
+	}
+	return result
+}
+
+// Domain returns the hostname without a wildcard label, e.g. example.com for *.example.com
+func Domain(hostname string) string {
+	return strings.TrimPrefix(Normalize(hostname), "*.")
+}
@@ -0,0 +1,166 @@
+package hostname
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"example.com", "example.com"},
+		{"Example.COM", "example.com"},
+		{"example.com.", "example.com"},
+		{"  *.Example.com. ", "*.example.com"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.hostname); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		hostname string
+		want     bool
+	}{
+		{"", "foo.example.com", true},
+		{"", "*.example.com", true},
+		{"foo.example.com", "foo.example.com", true},
+		{"FOO.example.com.", "foo.EXAMPLE.com", true},
+		{"foo.example.com", "bar.example.com", false},
+		{"*.example.com", "foo.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "fooexample.com", false},
+		{"*.example.com", "*.a.example.com", true},
+		{"*.example.com", "*.example.com", true},
+		{"*.a.example.com", "*.example.com", false},
+		{"foo.example.com", "*.example.com", false},
+		{"foo.example.com", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := Matches(tt.pattern, tt.hostname); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.pattern, tt.hostname, got, tt.want)
+		}
+	}
+}
+
+func TestSpecificity(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		hostname string
+		want     int
+		wantOK   bool
+	}{
+		{"", "foo.example.com", 0, true},
+		{"foo.example.com", "foo.example.com", exactBonus + len("foo.example.com"), true},
+		{"*.example.com", "foo.example.com", len("*.example.com"), true},
+		{"*.foo.example.com", "a.foo.example.com", len("*.foo.example.com"), true},
+		{"*.example.com", "example.com", 0, false},
+		{"bar.example.com", "foo.example.com", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Specificity(tt.pattern, tt.hostname)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Specificity(%q, %q) = %d, %v, want %d, %v", tt.pattern, tt.hostname, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSpecificityOrdering(t *testing.T) {
+	// For one hostname: exact beats every wildcard, a longer wildcard beats a shorter one, and any
+	// hostname beats a listener without one
+	host := "a.b.example.com"
+	patterns := []string{"a.b.example.com", "*.b.example.com", "*.example.com", ""}
+
+	previous := -1
+	for i := len(patterns) - 1; i >= 0; i-- {
+		got, ok := Specificity(patterns[i], host)
+		if !ok {
+			t.Fatalf("Specificity(%q, %q) did not match", patterns[i], host)
+		}
+		if got <= previous {
+			t.Errorf("Specificity(%q, %q) = %d, want more than %q's %d", patterns[i], host, got, patterns[i+1], previous)
+		}
+		previous = got
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		want   string
+		wantOK bool
+	}{
+		{"", "", "", true},
+		{"", "foo.example.com", "foo.example.com", true},
+		{"*.example.com", "", "*.example.com", true},
+		{"foo.example.com", "foo.example.com", "foo.example.com", true},
+		{"*.example.com", "foo.example.com", "foo.example.com", true},
+		{"foo.example.com", "*.example.com", "foo.example.com", true},
+		{"*.example.com", "*.foo.example.com", "*.foo.example.com", true},
+		{"*.foo.example.com", "*.example.com", "*.foo.example.com", true},
+		{"Foo.Example.com.", "*.example.com", "foo.example.com", true},
+		{"*.example.com", "example.com", "", false},
+		{"foo.example.com", "bar.example.com", "", false},
+		{"*.example.com", "*.example.org", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Intersect(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Intersect(%q, %q) = %q, %v, want %q, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestIntersectAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		listener string
+		routes   []string
+		want     []string
+	}{
+		{"no route hostnames take the listener's", "*.example.com", nil, []string{"*.example.com"}},
+		{"no hostnames at all", "", nil, []string{""}},
+		{"listener without hostname", "", []string{"a.example.com", "b.example.org"}, []string{"a.example.com", "b.example.org"}},
+		{"non-matching hostnames are dropped", "*.example.com", []string{"a.example.com", "b.example.org"}, []string{"a.example.com"}},
+		{"route wildcard narrowed by listener", "foo.example.com", []string{"*.example.com"}, []string{"foo.example.com"}},
+		{"duplicates after normalization", "*.example.com", []string{"a.example.com", "A.example.com."}, []string{"a.example.com"}},
+		{"nothing in common", "*.example.com", []string{"example.org"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IntersectAll(tt.listener, tt.routes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("IntersectAll(%q, %q) = %q, want %q", tt.listener, tt.routes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomain(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{"*.example.com", "example.com"},
+		{"Foo.Example.com.", "foo.example.com"},
+		{"example.com", "example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := Domain(tt.hostname); got != tt.want {
+			t.Errorf("Domain(%q) = %q, want %q", tt.hostname, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+// Package hostview collects everything that serves one hostname: the DNS records publishing it,
+// the listeners receiving it, the routes and rules handling it, their backends and endpoints, and
+// the TLS certificate presented for it.
+package hostview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/render"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// View is the chain of resources serving a hostname
+type View struct {
+	Hostname string       `json:"hostname"`
+	Graph    *types.Graph `json:"graph"`   // The nodes of the chain, plus EndpointSlice and Secret nodes
+	Summary  string       `json:"summary"` // The chain as plain text
+}
+
+// builder accumulates the nodes and summary of a view
+type builder struct {
+	resources *types.ResourceCollection
+	graph     *types.Graph
+	host      string
+	now       time.Time
+	shadows   []analysis.Shadow
+	keep      map[string]bool
+	extra     []types.Node // EndpointSlice and Secret nodes, which the main graph does not have
+	links     []extraLink
+	summary   strings.Builder
+}
+
+// extraLink links nodes by ID, for links to the extra nodes
+type extraLink struct {
+	source, target, linkType string
+}
+
+// Build collects the chain serving host from the resources and the graph built from them. records
+// are the DNS records of the graph's DNS sources; now is used for certificate expiry.
+func Build(resources *types.ResourceCollection, graph *types.Graph, records []dnssource.Record, host string, now time.Time) *View {
+	b := &builder{
+		resources: resources,
+		graph:     graph,
+		host:      hostname.Normalize(host),
+		now:       now,
+		shadows:   analysis.Shadowing(resources),
+		keep:      make(map[string]bool),
+	}
+
+	fmt.Fprintf(&b.summary, "Hostname %s\n", b.host)
+	b.addDNS(records)
+
+	served := false
+	for g := range resources.Gateways {
+		gw := &resources.Gateways[g]
+		listeners := b.receivingListeners(gw)
+		if len(listeners) == 0 {
+			continue
+		}
+		served = true
+		b.keep[string(gw.UID)] = true
+		fmt.Fprintf(&b.summary, "\nGateway %s/%s\n", gw.Namespace, gw.Name)
+		for _, l := range listeners {
+			b.addListener(gw, l)
+		}
+	}
+	if !served {
+		b.summary.WriteString("\nNo Gateway listener accepts the hostname.\n")
+	}
+
+	b.addFindings()
+	return &View{Hostname: b.host, Graph: b.subgraph(), Summary: b.summary.String()}
+}
+
+// addDNS adds the DNS records publishing the hostname, exactly or through a wildcard, and the
+// load balancer Services they point to
+func (b *builder) addDNS(records []dnssource.Record) {
+	b.summary.WriteString("\nDNS\n")
+	found := false
+	for _, record := range records {
+		if _, ok := hostname.Intersect(record.DNSName, b.host); !ok {
+			continue
+		}
+		found = true
+		b.keep[record.ID] = true
+		fmt.Fprintf(&b.summary, "  %s %s/%s: %s %s %s (%s)\n", record.Kind, record.Namespace, record.Name,
+			record.DNSName, record.RecordType, strings.Join(record.Targets, ", "), record.Provider)
+
+		if record.Gateway == nil {
+			continue
+		}
+		if svc := analysis.LoadBalancerService(b.resources, record.Gateway); svc != nil {
+			b.keep[string(svc.UID)] = true
+			if stale, current := analysis.StaleTargets(b.resources, record.Gateway, record.Targets); len(stale) > 0 {
+				fmt.Fprintf(&b.summary, "    Stale: the load balancer of Service %s/%s is at %s\n", svc.Namespace, svc.Name, strings.Join(current, ", "))
+			}
+		}
+	}
+	if !found {
+		b.summary.WriteString("  No DNS record publishes the hostname.\n")
+	}
+}
+
+// receivingListeners returns the listeners of a Gateway that receive requests for the hostname:
+// on each port, the listener whose hostname matches it most specifically
+func (b *builder) receivingListeners(gw *gatewayv1.Gateway) []int {
+	best := make(map[gatewayv1.PortNumber]int)
+	bestScore := make(map[gatewayv1.PortNumber]int)
+	for i, listener := range gw.Spec.Listeners {
+		pattern := ""
+		if listener.Hostname != nil {
+			pattern = string(*listener.Hostname)
+		}
+		score, ok := hostname.Specificity(pattern, b.host)
+		if !ok {
+			// A wildcard hostname may still overlap the listener hostname
+			if _, ok = hostname.Intersect(pattern, b.host); !ok {
+				continue
+			}
+			score = 0
+		}
+		if current, exists := bestScore[listener.Port]; !exists || score > current {
+			best[listener.Port], bestScore[listener.Port] = i, score
+		}
+	}
+
+	listeners := make([]int, 0, len(best))
+	for _, i := range best {
+		listeners = append(listeners, i)
+	}
+	sort.Ints(listeners)
+	return listeners
+}
+
+// addListener adds a receiving listener with its certificate and the routes attached to it
+func (b *builder) addListener(gw *gatewayv1.Gateway, l int) {
+	listener := gw.Spec.Listeners[l]
+	b.keep[analysis.ListenerID(gw, l)] = true
+
+	listenerHostname := "any hostname"
+	if listener.Hostname != nil {
+		listenerHostname = string(*listener.Hostname)
+	}
+	fmt.Fprintf(&b.summary, "  Listener %s (%s :%d, %s) receives the hostname\n", listener.Name, listener.Protocol, listener.Port, listenerHostname)
+
+	if listener.TLS != nil {
+		for _, ref := range listener.TLS.CertificateRefs {
+			b.addCertificate(gw, l, ref)
+		}
+	}
+
+	routes := 0
+	for i := range b.resources.HTTPRoutes {
+		route := &b.resources.HTTPRoutes[i]
+		if !analysis.AttachesTo(b.resources, route, gw, l) || !b.servesHostname(listener, route) {
+			continue
+		}
+		routes++
+		b.addRoute(route)
+	}
+	if routes == 0 {
+		b.summary.WriteString("    No route attached to the listener serves the hostname.\n")
+	}
+}
+
+// servesHostname reports whether a route serves the hostname through a listener
+func (b *builder) servesHostname(listener gatewayv1.Listener, route *gatewayv1.HTTPRoute) bool {
+	for _, effective := range analysis.EffectiveHostnames(listener, route) {
+		if _, ok := hostname.Intersect(effective, b.host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addCertificate adds the Secret a listener presents and whether its certificate covers the
+// hostname
+func (b *builder) addCertificate(gw *gatewayv1.Gateway, l int, ref gatewayv1.SecretObjectReference) {
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Secret") {
+		return
+	}
+	namespace := gw.Namespace
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+
+	bundle := certs.Find(b.resources.Certificates, "Secret", namespace, string(ref.Name), certs.TLSCertKey)
+	b.addExtra(types.Node{
+		ID:           fmt.Sprintf("secret:%s/%s", namespace, ref.Name),
+		Name:         string(ref.Name),
+		Type:         "Secret",
+		Namespace:    namespace,
+		Version:      "v1",
+		Kind:         "Secret",
+		Certificates: bundle,
+	}, analysis.ListenerID(gw, l), "certificateRef")
+
+	switch {
+	case bundle == nil:
+		fmt.Fprintf(&b.summary, "    Certificate Secret %s/%s: not available\n", namespace, ref.Name)
+	case bundle.Error != "":
+		fmt.Fprintf(&b.summary, "    Certificate Secret %s/%s: %s\n", namespace, ref.Name, bundle.Error)
+	default:
+		leaf := bundle.Certificates[0]
+		coverage := "covers the hostname"
+		if !certs.Covers(leaf, b.host) {
+			coverage = fmt.Sprintf("does NOT cover the hostname (DNS names %s)", strings.Join(leaf.DNSNames, ", "))
+		}
+		expiry := certs.Expiry(leaf, b.now)
+		switch {
+		case certs.Expired(leaf, b.now):
+			expiry = "NOT VALID: " + expiry
+		case leaf.NotAfter.Sub(b.now) < certs.ExpiryWarning:
+			expiry += ", renew soon"
+		}
+		fmt.Fprintf(&b.summary, "    Certificate Secret %s/%s: %s, %s, %s\n", namespace, ref.Name, leaf.Subject, coverage, expiry)
+	}
+}
+
+// addRoute adds a route serving the hostname, with its rules, backends and endpoints
+func (b *builder) addRoute(route *gatewayv1.HTTPRoute) {
+	b.keep[string(route.UID)] = true
+
+	hostnames := make([]string, len(route.Spec.Hostnames))
+	for i, routeHostname := range route.Spec.Hostnames {
+		hostnames[i] = string(routeHostname)
+	}
+	if len(hostnames) == 0 {
+		hostnames = []string{"any hostname"}
+	}
+	fmt.Fprintf(&b.summary, "    HTTPRoute %s/%s (%s)\n", route.Namespace, route.Name, strings.Join(hostnames, ", "))
+
+	matches := analysis.RouteMatches(route)
+	for r, rule := range route.Spec.Rules {
+		var conditions []string
+		for _, match := range matches {
+			if match.Rule == r {
+				conditions = append(conditions, analysis.DescribeRouteMatch(match.Match))
+			}
+		}
+		fmt.Fprintf(&b.summary, "      Rule %d: %s\n", r, strings.Join(conditions, " | "))
+
+		for _, shadow := range b.shadows {
+			if shadow.Loser.Route != route || shadow.Loser.Rule != r {
+				continue
+			}
+			if _, ok := hostname.Intersect(shadow.Hostname, b.host); !ok && shadow.Hostname != "*" {
+				continue
+			}
+			fmt.Fprintf(&b.summary, "        Match %d is shadowed by HTTPRoute %s/%s rule %d (%s)\n",
+				shadow.Loser.Index, shadow.Winner.Route.Namespace, shadow.Winner.Route.Name, shadow.Winner.Rule, shadow.Reason)
+		}
+
+		if len(rule.BackendRefs) == 0 {
+			b.summary.WriteString("        No backends\n")
+		}
+		for _, backendRef := range rule.BackendRefs {
+			b.addBackend(route, backendRef)
+		}
+	}
+}
+
+// addBackend adds a backend of a rule and the endpoints of its Service
+func (b *builder) addBackend(route *gatewayv1.HTTPRoute, backendRef gatewayv1.HTTPBackendRef) {
+	kind := "Service"
+	if backendRef.Kind != nil {
+		kind = string(*backendRef.Kind)
+	}
+	namespace := route.Namespace
+	if backendRef.Namespace != nil {
+		namespace = string(*backendRef.Namespace)
+	}
+	target := fmt.Sprintf("%s %s/%s", kind, namespace, backendRef.Name)
+	if backendRef.Port != nil {
+		target = fmt.Sprintf("%s:%d", target, *backendRef.Port)
+	}
+	weight := int32(1)
+	if backendRef.Weight != nil {
+		weight = *backendRef.Weight
+	}
+
+	var svc *corev1.Service
+	if kind == "Service" && (backendRef.Group == nil || *backendRef.Group == "") {
+		svc = analysis.FindService(b.resources, namespace, string(backendRef.Name))
+	}
+	if svc == nil {
+		fmt.Fprintf(&b.summary, "        -> %s (weight %d): not found\n", target, weight)
+		return
+	}
+	b.keep[string(svc.UID)] = true
+
+	slices := analysis.ServiceEndpointSlices(b.resources, svc)
+	for _, slice := range slices {
+		id := string(slice.UID)
+		if id == "" {
+			id = fmt.Sprintf("endpointslice:%s/%s", slice.Namespace, slice.Name)
+		}
+		b.addExtra(types.Node{
+			ID:        id,
+			Name:      slice.Name,
+			Type:      "EndpointSlice",
+			Namespace: slice.Namespace,
+			Group:     "discovery.k8s.io",
+			Version:   "v1",
+			Kind:      "EndpointSlice",
+		}, string(svc.UID), "endpoints")
+	}
+	ready, total := analysis.ReadyEndpoints(slices)
+
+	endpoints := "no EndpointSlices"
+	if len(slices) > 0 {
+		endpoints = fmt.Sprintf("%d/%d endpoints ready", ready, total)
+	}
+	fmt.Fprintf(&b.summary, "        -> %s (weight %d): %s\n", target, weight, endpoints)
+}
+
+// addExtra adds a node the main graph does not have, linked from an existing node, once
+func (b *builder) addExtra(node types.Node, source, linkType string) {
+	for _, link := range b.links {
+		if link.source == source && link.target == node.ID {
+			return
+		}
+	}
+	b.links = append(b.links, extraLink{source: source, target: node.ID, linkType: linkType})
+	for _, existing := range b.extra {
+		if existing.ID == node.ID {
+			return
+		}
+	}
+	b.extra = append(b.extra, node)
+}
+
+// addFindings lists the findings of the nodes in the chain
+func (b *builder) addFindings() {
+	var lines []string
+	for _, node := range b.graph.Nodes {
+		if !b.keep[node.ID] {
+			continue
+		}
+		for _, finding := range node.Findings {
+			lines = append(lines, fmt.Sprintf("  [%s] %s: %s\n", finding.Severity, describeNode(node), finding.Message))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	b.summary.WriteString("\nFindings\n")
+	for _, line := range lines {
+		b.summary.WriteString(line)
+	}
+}
+
+// describeNode names a node for the summary, e.g. "HTTPRoute app/shop"
+func describeNode(node types.Node) string {
+	if node.Namespace == "" {
+		return fmt.Sprintf("%s %s", node.Type, node.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", node.Type, node.Namespace, node.Name)
+}
+
+// subgraph returns the kept nodes of the graph and the extra nodes, with the links between them
+func (b *builder) subgraph() *types.Graph {
+	keep := make([]bool, len(b.graph.Nodes))
+	for i, node := range b.graph.Nodes {
+		keep[i] = b.keep[node.ID]
+	}
+	result := render.Subgraph(b.graph, keep)
+
+	index := make(map[string]int)
+	for i, node := range result.Nodes {
+		index[node.ID] = i
+	}
+	for _, node := range b.extra {
+		index[node.ID] = len(result.Nodes)
+		result.Nodes = append(result.Nodes, node)
+	}
+	for _, link := range b.links {
+		source, ok := index[link.source]
+		if !ok {
+			continue
+		}
+		result.Links = append(result.Links, types.Link{Source: source, Target: index[link.target], Type: link.linkType})
+	}
+	return result
+}
@@ -0,0 +1,258 @@
+package hostview
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// httpsListener returns a listener terminating TLS with the certificate of a Secret in infra
+func httpsListener(name, host, secret string) gatewayv1.Listener {
+	listener := testutil.Listener(name, gatewayv1.HTTPSProtocolType, 443, host)
+	listener.TLS = &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{{Name: gatewayv1.ObjectName(secret)}}}
+	return listener
+}
+
+// tlsCertificate returns the bundle of the Secret infra/<name> with a leaf certificate for the DNS
+// names that expires after the given time from now
+func tlsCertificate(name string, expiresIn time.Duration, dnsNames ...string) types.CertificateBundle {
+	return types.CertificateBundle{Kind: "Secret", Namespace: "infra", Name: name, Key: certs.TLSCertKey, Certificates: []types.Certificate{{
+		Subject:   "CN=" + dnsNames[0],
+		DNSNames:  dnsNames,
+		NotBefore: now.Add(-24 * time.Hour),
+		NotAfter:  now.Add(expiresIn),
+	}}}
+}
+
+// hostResources returns the Gateway infra/gw with a plain HTTP listener and HTTPS listeners for
+// *.example.com and shop.example.com, the route app/shop for shop.example.com whose Service has
+// two ready endpoints of three, and the route app/blog for blog.example.com
+func hostResources(shopCertificate types.CertificateBundle) (*types.ResourceCollection, *types.Graph) {
+	shop := testutil.HTTPRoute("app", "shop", testutil.ParentRef("infra", "gw"))
+	shop.Spec.Hostnames = testutil.Hostnames("shop.example.com")
+	shop.Spec.Rules = []gatewayv1.HTTPRouteRule{
+		{
+			Matches:     []gatewayv1.HTTPRouteMatch{testutil.PathMatch(gatewayv1.PathMatchPathPrefix, "/cart")},
+			BackendRefs: []gatewayv1.HTTPBackendRef{testutil.BackendRef("", "storefront", 8080), testutil.BackendRef("", "missing", 80)},
+		},
+		{},
+	}
+	blog := testutil.HTTPRoute("app", "blog", testutil.ParentRef("infra", "gw"))
+	blog.Spec.Hostnames = testutil.Hostnames("blog.example.com")
+
+	notReady := false
+	resources := &types.ResourceCollection{
+		Gateways: []gatewayv1.Gateway{testutil.Gateway("infra", "gw",
+			testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, ""),
+			httpsListener("https", "*.example.com", "wildcard"),
+			httpsListener("https-shop", "shop.example.com", "shop"),
+		)},
+		HTTPRoutes: []gatewayv1.HTTPRoute{shop, blog},
+		Services:   []corev1.Service{testutil.Service("app", "storefront", 8080)},
+		EndpointSlices: []discoveryv1.EndpointSlice{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "storefront-abc", UID: "app/storefront-abc",
+				Labels: map[string]string{discoveryv1.LabelServiceName: "storefront"}},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.1.0.1"}},
+				{Addresses: []string{"10.1.0.2"}},
+				{Addresses: []string{"10.1.0.3"}, Conditions: discoveryv1.EndpointConditions{Ready: &notReady}},
+			},
+		}},
+		Certificates: []types.CertificateBundle{tlsCertificate("wildcard", 90*24*time.Hour, "*.example.com"), shopCertificate},
+	}
+
+	gw := "infra/gw"
+	graph := &types.Graph{
+		Nodes: []types.Node{
+			{ID: "infra/gw", Type: "Gateway", Namespace: "infra", Name: "gw"},
+			{ID: "infra/gw-listener-0", Type: "Listener", Namespace: "infra", Name: "http", ParentID: &gw},
+			{ID: "infra/gw-listener-1", Type: "Listener", Namespace: "infra", Name: "https", ParentID: &gw},
+			{ID: "infra/gw-listener-2", Type: "Listener", Namespace: "infra", Name: "https-shop", ParentID: &gw},
+			{ID: "app/shop", Type: "HTTPRoute", Namespace: "app", Name: "shop", Findings: []types.Finding{
+				{Severity: types.SeverityError, Message: "rules[0].backendRefs[1] refers to Service app/missing, which does not exist."},
+			}},
+			{ID: "app/blog", Type: "HTTPRoute", Namespace: "app", Name: "blog", Findings: []types.Finding{
+				{Severity: types.SeverityWarning, Message: "Unrelated to the hostname."},
+			}},
+			{ID: "app/storefront", Type: "Service", Namespace: "app", Name: "storefront"},
+			{ID: "dnsrecord:infra/wildcard", Type: "DNSRecord", Namespace: "infra", Name: "wildcard"},
+		},
+		Links: []types.Link{
+			{Source: 0, Target: 1, Type: "listener"},
+			{Source: 0, Target: 2, Type: "listener"},
+			{Source: 0, Target: 3, Type: "listener"},
+			{Source: 1, Target: 4, Type: "parentRef"},
+			{Source: 3, Target: 4, Type: "parentRef"},
+			{Source: 1, Target: 5, Type: "parentRef"},
+			{Source: 2, Target: 5, Type: "parentRef"},
+			{Source: 2, Target: 7, Type: "dnsRecord"},
+		},
+	}
+	return resources, graph
+}
+
+// wildcardRecord is a DNSRecord publishing *.example.com
+var wildcardRecord = dnssource.Record{
+	ID:         "dnsrecord:infra/wildcard",
+	Provider:   "dnsrecord",
+	Kind:       "DNSRecord",
+	Namespace:  "infra",
+	Name:       "wildcard",
+	DNSName:    "*.example.com",
+	RecordType: "A",
+	Targets:    []string{"10.0.0.1"},
+}
+
+func TestBuild(t *testing.T) {
+	resources, graph := hostResources(tlsCertificate("shop", 90*24*time.Hour, "shop.example.com"))
+	view := Build(resources, graph, []dnssource.Record{wildcardRecord}, "Shop.Example.com.", now)
+
+	if view.Hostname != "shop.example.com" {
+		t.Errorf("Hostname = %q, want shop.example.com", view.Hostname)
+	}
+
+	want := `Hostname shop.example.com
+
+DNS
+  DNSRecord infra/wildcard: *.example.com A 10.0.0.1 (dnsrecord)
+
+Gateway infra/gw
+  Listener http (HTTP :80, any hostname) receives the hostname
+    HTTPRoute app/shop (shop.example.com)
+      Rule 0: PathPrefix /cart
+        -> Service app/storefront:8080 (weight 1): 2/3 endpoints ready
+        -> Service app/missing:80 (weight 1): not found
+      Rule 1: PathPrefix /
+        No backends
+  Listener https-shop (HTTPS :443, shop.example.com) receives the hostname
+    Certificate Secret infra/shop: CN=shop.example.com, covers the hostname, expires in 90 days (2024-08-30)
+    HTTPRoute app/shop (shop.example.com)
+      Rule 0: PathPrefix /cart
+        -> Service app/storefront:8080 (weight 1): 2/3 endpoints ready
+        -> Service app/missing:80 (weight 1): not found
+      Rule 1: PathPrefix /
+        No backends
+
+Findings
+  [error] HTTPRoute app/shop: rules[0].backendRefs[1] refers to Service app/missing, which does not exist.
+`
+	if view.Summary != want {
+		t.Errorf("Summary =\n%s\nwant\n%s", view.Summary, want)
+	}
+
+	var nodes []string
+	for _, node := range view.Graph.Nodes {
+		nodes = append(nodes, node.Type+" "+node.ID)
+	}
+	// The https listener for *.example.com and the route app/blog do not serve the hostname
+	wantNodes := []string{
+		"Gateway infra/gw", "Listener infra/gw-listener-0", "Listener infra/gw-listener-2", "HTTPRoute app/shop", "Service app/storefront",
+		"DNSRecord dnsrecord:infra/wildcard", "EndpointSlice app/storefront-abc", "Secret secret:infra/shop",
+	}
+	if !reflect.DeepEqual(nodes, wantNodes) {
+		t.Errorf("nodes = %q, want %q", nodes, wantNodes)
+	}
+
+	var links []string
+	for _, link := range view.Graph.Links {
+		links = append(links, view.Graph.Nodes[link.Source].ID+" -"+link.Type+"-> "+view.Graph.Nodes[link.Target].ID)
+	}
+	for _, want := range []string{"infra/gw-listener-2 -certificateRef-> secret:infra/shop", "app/storefront -endpoints-> app/storefront-abc"} {
+		if !containsString(links, want) {
+			t.Errorf("links = %q, want %q", links, want)
+		}
+	}
+}
+
+func TestBuildCertificate(t *testing.T) {
+	tests := []struct {
+		name        string
+		certificate types.CertificateBundle
+		want        string
+	}{
+		{
+			name:        "valid",
+			certificate: tlsCertificate("shop", certs.ExpiryWarning+24*time.Hour, "shop.example.com"),
+			want:        "Certificate Secret infra/shop: CN=shop.example.com, covers the hostname, expires in 31 days (2024-07-02)",
+		},
+		{
+			name:        "expiring within the warning period",
+			certificate: tlsCertificate("shop", certs.ExpiryWarning-24*time.Hour, "shop.example.com"),
+			want:        "Certificate Secret infra/shop: CN=shop.example.com, covers the hostname, expires in 29 days (2024-06-30), renew soon",
+		},
+		{
+			name:        "expired",
+			certificate: tlsCertificate("shop", -48*time.Hour, "shop.example.com"),
+			want:        "Certificate Secret infra/shop: CN=shop.example.com, covers the hostname, NOT VALID: expired 2 days ago (2024-05-30)",
+		},
+		{
+			name:        "other hostname",
+			certificate: tlsCertificate("shop", 90*24*time.Hour, "www.example.com", "*.shop.example.com"),
+			want:        "Certificate Secret infra/shop: CN=www.example.com, does NOT cover the hostname (DNS names www.example.com, *.shop.example.com), expires in 90 days (2024-08-30)",
+		},
+		{
+			name:        "unreadable",
+			certificate: types.CertificateBundle{Kind: "Secret", Namespace: "infra", Name: "shop", Key: certs.TLSCertKey, Error: "no PEM certificate found"},
+			want:        "Certificate Secret infra/shop: no PEM certificate found",
+		},
+		{
+			name: "not read",
+			want: "Certificate Secret infra/shop: not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources, graph := hostResources(tt.certificate)
+			view := Build(resources, graph, nil, "shop.example.com", now)
+			if !strings.Contains(view.Summary, "    "+tt.want+"\n") {
+				t.Errorf("Summary lacks %q:\n%s", tt.want, view.Summary)
+			}
+		})
+	}
+}
+
+func TestBuildUnservedHostname(t *testing.T) {
+	resources, graph := hostResources(tlsCertificate("shop", 90*24*time.Hour, "shop.example.com"))
+	resources.Gateways[0].Spec.Listeners = resources.Gateways[0].Spec.Listeners[1:]
+
+	view := Build(resources, graph, []dnssource.Record{wildcardRecord}, "www.other.com", now)
+	want := "Hostname www.other.com\n\nDNS\n  No DNS record publishes the hostname.\n\nNo Gateway listener accepts the hostname.\n"
+	if view.Summary != want {
+		t.Errorf("Summary =\n%s\nwant\n%s", view.Summary, want)
+	}
+	if len(view.Graph.Nodes) != 0 {
+		t.Errorf("nodes = %+v, want none", view.Graph.Nodes)
+	}
+
+	// A listener for the hostname without a route serving it
+	view = Build(resources, graph, nil, "api.example.com", now)
+	if !strings.Contains(view.Summary, "  Listener https (HTTPS :443, *.example.com) receives the hostname\n"+
+		"    Certificate Secret infra/wildcard: CN=*.example.com, covers the hostname, expires in 90 days (2024-08-30)\n"+
+		"    No route attached to the listener serves the hostname.\n") {
+		t.Errorf("Summary = %s, want the wildcard listener without routes", view.Summary)
+	}
+}
+
+// containsString reports whether values holds value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
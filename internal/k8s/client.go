@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -130,6 +134,42 @@ func (c *Client) GetDNSRecords(ctx context.Context) ([]unstructured.Unstructured
 	return result.Items, nil
 }
 
+// GetDNSEndpoints returns all ExternalDNS DNSEndpoint resources
+func (c *Client) GetDNSEndpoints(ctx context.Context) ([]unstructured.Unstructured, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    "externaldns.k8s.io",
+		Version:  "v1alpha1",
+		Resource: "dnsendpoints",
+	}
+
+	result, err := c.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DNSEndpoints: %w", err)
+	}
+
+	return result.Items, nil
+}
+
+// GetDNSConfig returns the OpenShift cluster DNS config, dnses.config.openshift.io/cluster, or nil
+// when the cluster has none
+func (c *Client) GetDNSConfig(ctx context.Context) (*unstructured.Unstructured, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    "config.openshift.io",
+		Version:  "v1",
+		Resource: "dnses",
+	}
+
+	config, err := c.dynamicClient.Resource(gvr).Get(ctx, "cluster", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DNS config: %w", err)
+	}
+
+	return config, nil
+}
+
 // GetServices returns all Service resources
 func (c *Client) GetServices(ctx context.Context) ([]corev1.Service, error) {
 	services, err := c.k8sClient.CoreV1().Services("").List(ctx, metav1.ListOptions{})
@@ -140,6 +180,94 @@ func (c *Client) GetServices(ctx context.Context) ([]corev1.Service, error) {
 	return services.Items, nil
 }
 
+// GetEndpointSlices returns all EndpointSlice resources
+func (c *Client) GetEndpointSlices(ctx context.Context) ([]discoveryv1.EndpointSlice, error) {
+	slices, err := c.k8sClient.DiscoveryV1().EndpointSlices("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list EndpointSlices: %w", err)
+	}
+
+	return slices.Items, nil
+}
+
+// GetCustomResourceDefinitions returns the CustomResourceDefinitions matching a label selector
+func (c *Client) GetCustomResourceDefinitions(ctx context.Context, labelSelector string) ([]unstructured.Unstructured, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}
+
+	result, err := c.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CustomResourceDefinitions: %w", err)
+	}
+
+	return result.Items, nil
+}
+
+// ResourceFor finds the resource serving a kind through API discovery. Without a version, the
+// group's preferred version is used.
+func (c *Client) ResourceFor(group, version, kind string) (schema.GroupVersionResource, error) {
+	discovery := c.k8sClient.Discovery()
+	if version == "" {
+		groups, err := discovery.ServerGroups()
+		if err != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("failed to discover API groups: %w", err)
+		}
+		for _, g := range groups.Groups {
+			if g.Name == group {
+				version = g.PreferredVersion.Version
+				break
+			}
+		}
+		if version == "" {
+			return schema.GroupVersionResource{}, fmt.Errorf("API group %s is not served", group)
+		}
+	}
+
+	groupVersion := schema.GroupVersion{Group: group, Version: version}
+	resources, err := discovery.ServerResourcesForGroupVersion(groupVersion.String())
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to discover resources of %s: %w", groupVersion, err)
+	}
+	for _, resource := range resources.APIResources {
+		// Skip subresources such as policies/status
+		if resource.Kind == kind && !strings.Contains(resource.Name, "/") {
+			return groupVersion.WithResource(resource.Name), nil
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("kind %s is not served by %s", kind, groupVersion)
+}
+
+// ListResources returns all objects of a resource, in every namespace
+func (c *Client) ListResources(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	result, err := c.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+	}
+
+	return result.Items, nil
+}
+
+// GetSecret retrieves a specific Secret resource
+func (c *Client) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	secret, err := c.k8sClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s/%s: %w", namespace, name, err)
+	}
+	return secret, nil
+}
+
+// GetConfigMap retrieves a specific ConfigMap resource
+func (c *Client) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	configMap, err := c.k8sClient.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return configMap, nil
+}
+
 // GetGateway retrieves a specific Gateway resource
 func (c *Client) GetGateway(ctx context.Context, namespace, name string) (*gatewayv1.Gateway, error) {
 	gateway, err := c.gatewayClient.GatewayV1().Gateways(namespace).Get(ctx, name, metav1.GetOptions{})
@@ -200,201 +328,275 @@ func (c *Client) GetDNSRecord(ctx context.Context, namespace, name string) (*uns
 	return resource, nil
 }
 
-// UpdateGateway updates a Gateway resource
-func (c *Client) UpdateGateway(ctx context.Context, namespace, name string, data map[string]interface{}) error {
-	// Get the existing resource first
-	existing, err := c.GetGateway(ctx, namespace, name)
+// GetDNSEndpoint retrieves a specific ExternalDNS DNSEndpoint resource
+func (c *Client) GetDNSEndpoint(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	gvr := schema.GroupVersionResource{
+		Group:    "externaldns.k8s.io",
+		Version:  "v1alpha1",
+		Resource: "dnsendpoints",
+	}
+
+	resource, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get DNSEndpoint %s/%s: %w", namespace, name, err)
 	}
+	return resource, nil
+}
 
-	// Check for immutable field changes
-	if metadata, ok := data["metadata"]; ok {
-		if metadataMap, ok := metadata.(map[string]interface{}); ok {
-			if newName, exists := metadataMap["name"]; exists && newName != existing.Name {
-				return fmt.Errorf("cannot change resource name from '%s' to '%s' - resource names are immutable", existing.Name, newName)
-			}
-			if newNamespace, exists := metadataMap["namespace"]; exists && newNamespace != existing.Namespace {
-				return fmt.Errorf("cannot change resource namespace from '%s' to '%s' - resource namespaces are immutable", existing.Namespace, newNamespace)
-			}
-		}
+// FieldManager is the field manager recorded in managedFields for changes made through gwapi-graph
+const FieldManager = "gwapi-graph"
+
+// resourceInfo describes how a resource type exposed by the API maps to the Kubernetes API
+type resourceInfo struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+}
+
+// supportedResources maps the resource type names used in API paths to their Kubernetes API details
+var supportedResources = map[string]resourceInfo{
+	"gatewayclass": {
+		gvr:  schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gatewayclasses"},
+		kind: "GatewayClass",
+	},
+	"gateway": {
+		gvr:        schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"},
+		kind:       "Gateway",
+		namespaced: true,
+	},
+	"httproute": {
+		gvr:        schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"},
+		kind:       "HTTPRoute",
+		namespaced: true,
+	},
+	"referencegrant": {
+		gvr:        schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1beta1", Resource: "referencegrants"},
+		kind:       "ReferenceGrant",
+		namespaced: true,
+	},
+	"service": {
+		gvr:        schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"},
+		kind:       "Service",
+		namespaced: true,
+	},
+	"dnsrecord": {
+		gvr:        schema.GroupVersionResource{Group: "ingress.operator.openshift.io", Version: "v1", Resource: "dnsrecords"},
+		kind:       "DNSRecord",
+		namespaced: true,
+	},
+	"dnsendpoint": {
+		gvr:        schema.GroupVersionResource{Group: "externaldns.k8s.io", Version: "v1alpha1", Resource: "dnsendpoints"},
+		kind:       "DNSEndpoint",
+		namespaced: true,
+	},
+}
+
+// IsSupportedResource reports whether the given resource type can be read and modified through the client
+func IsSupportedResource(resourceType string) bool {
+	_, ok := supportedResources[resourceType]
+	return ok
+}
+
+// GroupVersionKind returns the GroupVersionKind for a resource type, for filling in the TypeMeta
+// that typed clients leave empty on returned objects
+func GroupVersionKind(resourceType string) (schema.GroupVersionKind, bool) {
+	info, ok := supportedResources[resourceType]
+	if !ok {
+		return schema.GroupVersionKind{}, false
 	}
+	return info.gvr.GroupVersion().WithKind(info.kind), true
+}
 
-	// Update the spec if provided
-	if spec, ok := data["spec"]; ok {
-		specBytes, err := json.Marshal(spec)
-		if err != nil {
-			return fmt.Errorf("failed to marshal spec: %w", err)
-		}
-		if err := json.Unmarshal(specBytes, &existing.Spec); err != nil {
-			return fmt.Errorf("failed to unmarshal spec: %w", err)
-		}
+// resourceInterface returns the dynamic client for a resource type, scoped to the namespace when namespaced
+func (c *Client) resourceInterface(resourceType, namespace string) (dynamic.ResourceInterface, resourceInfo, error) {
+	info, ok := supportedResources[resourceType]
+	if !ok {
+		return nil, info, fmt.Errorf("unsupported resource type %q", resourceType)
 	}
+	if info.namespaced {
+		return c.dynamicClient.Resource(info.gvr).Namespace(namespace), info, nil
+	}
+	return c.dynamicClient.Resource(info.gvr), info, nil
+}
 
-	// Update mutable metadata fields (labels, annotations)
+// ApplyResource updates a resource using server-side apply with the gwapi-graph field manager.
+// Only the fields present in data (metadata labels/annotations and spec) are applied, so fields
+// owned by other managers are left untouched. When dryRun is set the API server validates and
+// admits the change without persisting it. Apply would create a missing object, so the object is
+// read first and a NotFound error returned when it does not exist; its UID is sent as a
+// precondition so an object deleted and recreated in between is not overwritten.
+func (c *Client) ApplyResource(ctx context.Context, resourceType, namespace, name string, data map[string]interface{}, force, dryRun bool) (*unstructured.Unstructured, error) {
+	_, info, err := c.resourceInterface(resourceType, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for immutable field changes
 	if metadata, ok := data["metadata"]; ok {
 		if metadataMap, ok := metadata.(map[string]interface{}); ok {
-			if labels, exists := metadataMap["labels"]; exists {
-				if labelsMap, ok := labels.(map[string]interface{}); ok {
-					stringLabels := make(map[string]string)
-					for k, v := range labelsMap {
-						if str, ok := v.(string); ok {
-							stringLabels[k] = str
-						}
-					}
-					existing.Labels = stringLabels
-				}
+			if newName, exists := metadataMap["name"]; exists && newName != name {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("cannot change resource name from '%s' to '%s' - resource names are immutable", name, newName))
 			}
-
-			if annotations, exists := metadataMap["annotations"]; exists {
-				if annotationsMap, ok := annotations.(map[string]interface{}); ok {
-					stringAnnotations := make(map[string]string)
-					for k, v := range annotationsMap {
-						if str, ok := v.(string); ok {
-							stringAnnotations[k] = str
-						}
-					}
-					existing.Annotations = stringAnnotations
-				}
+			if newNamespace, exists := metadataMap["namespace"]; exists && info.namespaced && newNamespace != namespace {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("cannot change resource namespace from '%s' to '%s' - resource namespaces are immutable", namespace, newNamespace))
 			}
 		}
 	}
 
-	_, err = c.gatewayClient.GatewayV1().Gateways(namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	// Build the apply configuration from the editable parts of the submitted object.
+	// apiVersion and kind come from the resource type, as typed GET responses omit them.
+	existing, err := c.GetResource(ctx, resourceType, namespace, name)
 	if err != nil {
-		return fmt.Errorf("failed to update Gateway %s/%s: %w", namespace, name, err)
+		return nil, err
 	}
-	return nil
-}
-
-// UpdateHTTPRoute updates an HTTPRoute resource
-func (c *Client) UpdateHTTPRoute(ctx context.Context, namespace, name string, data map[string]interface{}) error {
-	// Get the existing resource first
-	existing, err := c.GetHTTPRoute(ctx, namespace, name)
-	if err != nil {
-		return err
+	metadata := map[string]interface{}{"name": name, "uid": string(existing.GetUID())}
+	if info.namespaced {
+		metadata["namespace"] = namespace
 	}
-
-	// Update the spec if provided
-	if spec, ok := data["spec"]; ok {
-		specBytes, err := json.Marshal(spec)
-		if err != nil {
-			return fmt.Errorf("failed to marshal spec: %w", err)
+	if metadataMap, ok := data["metadata"].(map[string]interface{}); ok {
+		if labels, exists := metadataMap["labels"]; exists {
+			metadata["labels"] = labels
 		}
-		if err := json.Unmarshal(specBytes, &existing.Spec); err != nil {
-			return fmt.Errorf("failed to unmarshal spec: %w", err)
+		if annotations, exists := metadataMap["annotations"]; exists {
+			metadata["annotations"] = annotations
 		}
 	}
 
-	_, err = c.gatewayClient.GatewayV1().HTTPRoutes(namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	applyObject := map[string]interface{}{
+		"apiVersion": info.gvr.GroupVersion().String(),
+		"kind":       info.kind,
+		"metadata":   metadata,
+	}
+	if spec, ok := data["spec"]; ok {
+		applyObject["spec"] = spec
+	}
+
+	patch, err := json.Marshal(applyObject)
 	if err != nil {
-		return fmt.Errorf("failed to update HTTPRoute %s/%s: %w", namespace, name, err)
+		return nil, fmt.Errorf("failed to marshal apply configuration: %w", err)
 	}
-	return nil
+
+	return c.patchResource(ctx, resourceType, namespace, name, k8stypes.ApplyPatchType, patch, force, dryRun)
+}
+
+// PatchResource patches a resource with a JSON patch, merge patch or apply patch (JSON or YAML).
+// force only applies to server-side apply and takes ownership of conflicting fields. Like the
+// other patch types, an apply patch of a missing object fails with NotFound instead of creating it.
+func (c *Client) PatchResource(ctx context.Context, resourceType, namespace, name string, patchType k8stypes.PatchType, patch []byte, force, dryRun bool) (*unstructured.Unstructured, error) {
+	if patchType == k8stypes.ApplyPatchType {
+		if _, err := c.GetResource(ctx, resourceType, namespace, name); err != nil {
+			return nil, err
+		}
+	}
+	return c.patchResource(ctx, resourceType, namespace, name, patchType, patch, force, dryRun)
 }
 
-// UpdateGatewayClass updates a GatewayClass resource
-func (c *Client) UpdateGatewayClass(ctx context.Context, name string, data map[string]interface{}) error {
-	// Get the existing resource first
-	existing, err := c.GetGatewayClass(ctx, name)
+// patchResource sends a patch to the API server
+func (c *Client) patchResource(ctx context.Context, resourceType, namespace, name string, patchType k8stypes.PatchType, patch []byte, force, dryRun bool) (*unstructured.Unstructured, error) {
+	resourceClient, info, err := c.resourceInterface(resourceType, namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Update the spec if provided
-	if spec, ok := data["spec"]; ok {
-		specBytes, err := json.Marshal(spec)
-		if err != nil {
-			return fmt.Errorf("failed to marshal spec: %w", err)
-		}
-		if err := json.Unmarshal(specBytes, &existing.Spec); err != nil {
-			return fmt.Errorf("failed to unmarshal spec: %w", err)
-		}
+	opts := metav1.PatchOptions{FieldManager: FieldManager}
+	if patchType == k8stypes.ApplyPatchType {
+		opts.Force = &force
+	}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
 	}
 
-	_, err = c.gatewayClient.GatewayV1().GatewayClasses().Update(ctx, existing, metav1.UpdateOptions{})
+	updated, err := resourceClient.Patch(ctx, name, patchType, patch, opts)
 	if err != nil {
-		return fmt.Errorf("failed to update GatewayClass %s: %w", name, err)
+		if info.namespaced {
+			return nil, fmt.Errorf("failed to update %s %s/%s: %w", info.kind, namespace, name, err)
+		}
+		return nil, fmt.Errorf("failed to update %s %s: %w", info.kind, name, err)
 	}
-	return nil
+	return updated, nil
 }
 
-// UpdateReferenceGrant updates a ReferenceGrant resource
-func (c *Client) UpdateReferenceGrant(ctx context.Context, namespace, name string, data map[string]interface{}) error {
-	// Get the existing resource first
-	existing, err := c.GetReferenceGrant(ctx, namespace, name)
+// CreateResource creates a resource from a JSON object. apiVersion and kind are filled in from the
+// resource type and the namespace argument takes precedence over metadata.namespace.
+func (c *Client) CreateResource(ctx context.Context, resourceType, namespace string, data map[string]interface{}, dryRun bool) (*unstructured.Unstructured, error) {
+	resourceClient, info, err := c.resourceInterface(resourceType, namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Update the spec if provided
-	if spec, ok := data["spec"]; ok {
-		specBytes, err := json.Marshal(spec)
-		if err != nil {
-			return fmt.Errorf("failed to marshal spec: %w", err)
-		}
-		if err := json.Unmarshal(specBytes, &existing.Spec); err != nil {
-			return fmt.Errorf("failed to unmarshal spec: %w", err)
+	obj := &unstructured.Unstructured{Object: data}
+	obj.SetAPIVersion(info.gvr.GroupVersion().String())
+	obj.SetKind(info.kind)
+	if info.namespaced {
+		if namespace == "" {
+			return nil, fmt.Errorf("a namespace is required to create a %s", info.kind)
 		}
+		obj.SetNamespace(namespace)
+	} else {
+		obj.SetNamespace("")
+	}
+	if obj.GetName() == "" && obj.GetGenerateName() == "" {
+		return nil, fmt.Errorf("metadata.name is required to create a %s", info.kind)
+	}
+
+	opts := metav1.CreateOptions{FieldManager: FieldManager}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
 	}
 
-	_, err = c.gatewayClient.GatewayV1beta1().ReferenceGrants(namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	created, err := resourceClient.Create(ctx, obj, opts)
 	if err != nil {
-		return fmt.Errorf("failed to update ReferenceGrant %s/%s: %w", namespace, name, err)
+		return nil, fmt.Errorf("failed to create %s %s: %w", info.kind, obj.GetName(), err)
 	}
-	return nil
+	return created, nil
 }
 
-// UpdateService updates a Service resource
-func (c *Client) UpdateService(ctx context.Context, namespace, name string, data map[string]interface{}) error {
-	// Get the existing resource first
-	existing, err := c.GetService(ctx, namespace, name)
+// DeleteResource deletes a resource. When dryRun is set the API server only validates the deletion.
+func (c *Client) DeleteResource(ctx context.Context, resourceType, namespace, name string, dryRun bool) error {
+	resourceClient, info, err := c.resourceInterface(resourceType, namespace)
 	if err != nil {
 		return err
 	}
 
-	// Update the spec if provided
-	if spec, ok := data["spec"]; ok {
-		specBytes, err := json.Marshal(spec)
-		if err != nil {
-			return fmt.Errorf("failed to marshal spec: %w", err)
-		}
-		if err := json.Unmarshal(specBytes, &existing.Spec); err != nil {
-			return fmt.Errorf("failed to unmarshal spec: %w", err)
-		}
+	opts := metav1.DeleteOptions{}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
 	}
 
-	_, err = c.k8sClient.CoreV1().Services(namespace).Update(ctx, existing, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update Service %s/%s: %w", namespace, name, err)
+	if err := resourceClient.Delete(ctx, name, opts); err != nil {
+		if info.namespaced {
+			return fmt.Errorf("failed to delete %s %s/%s: %w", info.kind, namespace, name, err)
+		}
+		return fmt.Errorf("failed to delete %s %s: %w", info.kind, name, err)
 	}
 	return nil
 }
 
-// UpdateDNSRecord updates a DNSRecord resource
-func (c *Client) UpdateDNSRecord(ctx context.Context, namespace, name string, data map[string]interface{}) error {
-	gvr := schema.GroupVersionResource{
-		Group:    "ingress.operator.openshift.io",
-		Version:  "v1",
-		Resource: "dnsrecords",
+// GetResource retrieves any supported resource through the dynamic client
+func (c *Client) GetResource(ctx context.Context, resourceType, namespace, name string) (*unstructured.Unstructured, error) {
+	resourceClient, info, err := c.resourceInterface(resourceType, namespace)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get the existing resource first
-	existing, err := c.GetDNSRecord(ctx, namespace, name)
+	resource, err := resourceClient.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return err
+		if info.namespaced {
+			return nil, fmt.Errorf("failed to get %s %s/%s: %w", info.kind, namespace, name, err)
+		}
+		return nil, fmt.Errorf("failed to get %s %s: %w", info.kind, name, err)
 	}
+	return resource, nil
+}
 
-	// Update the spec if provided
-	if spec, ok := data["spec"]; ok {
-		if err := unstructured.SetNestedField(existing.Object, spec, "spec"); err != nil {
-			return fmt.Errorf("failed to set spec: %w", err)
-		}
+// CreateEvent records a Kubernetes Event. Events for cluster-scoped objects are created in the default namespace.
+func (c *Client) CreateEvent(ctx context.Context, event *corev1.Event) error {
+	if event.Namespace == "" {
+		event.Namespace = metav1.NamespaceDefault
 	}
 
-	_, err = c.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, existing, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to update DNSRecord %s/%s: %w", namespace, name, err)
+	if _, err := c.k8sClient.CoreV1().Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create Event in %s: %w", event.Namespace, err)
 	}
 	return nil
 }
@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// fakeClient returns a client whose dynamic client holds the Gateways and records the patches
+// sent to it instead of applying them
+func fakeClient(t *testing.T, gateways ...*unstructured.Unstructured) (*Client, *[]clienttesting.PatchActionImpl) {
+	t.Helper()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	for _, gw := range gateways {
+		// Add guesses the resource from the kind, which the dynamic client then does not find
+		if err := dynamicClient.Tracker().Create(supportedResources["gateway"].gvr, gw, gw.GetNamespace()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var patches []clienttesting.PatchActionImpl
+	dynamicClient.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patch := action.(clienttesting.PatchActionImpl)
+		patches = append(patches, patch)
+		return true, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "gateway.networking.k8s.io/v1",
+			"kind":       "Gateway",
+			"metadata":   map[string]interface{}{"namespace": patch.GetNamespace(), "name": patch.GetName()},
+		}}, nil
+	})
+	return &Client{dynamicClient: dynamicClient}, &patches
+}
+
+// storedGateway returns the Gateway infra/gw as the API server holds it
+func storedGateway() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"namespace": "infra", "name": "gw", "uid": "gw-uid", "resourceVersion": "7"},
+		"spec":       map[string]interface{}{"gatewayClassName": "example"},
+	}}
+}
+
+func TestApplyResource(t *testing.T) {
+	client, patches := fakeClient(t, storedGateway())
+
+	// Typed GET responses omit apiVersion and kind; metadata other than labels and annotations,
+	// and the status, are not applied
+	submitted := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"namespace":       "infra",
+			"name":            "gw",
+			"resourceVersion": "3",
+			"labels":          map[string]interface{}{"team": "edge"},
+			"annotations":     map[string]interface{}{"example.com/owner": "edge"},
+			"managedFields":   []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"spec":   map[string]interface{}{"gatewayClassName": "other"},
+		"status": map[string]interface{}{"addresses": []interface{}{}},
+	}
+	if _, err := client.ApplyResource(context.Background(), "gateway", "infra", "gw", submitted, false, true); err != nil {
+		t.Fatalf("ApplyResource: %v", err)
+	}
+
+	if len(*patches) != 1 {
+		t.Fatalf("sent %d patches, want 1", len(*patches))
+	}
+	patch := (*patches)[0]
+	if patch.GetPatchType() != k8stypes.ApplyPatchType || patch.GetNamespace() != "infra" || patch.GetName() != "gw" {
+		t.Errorf("patch = %s of %s/%s, want an apply patch of infra/gw", patch.GetPatchType(), patch.GetNamespace(), patch.GetName())
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(patch.GetPatch(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "Gateway",
+		"metadata": map[string]interface{}{
+			"namespace":   "infra",
+			"name":        "gw",
+			"uid":         "gw-uid",
+			"labels":      map[string]interface{}{"team": "edge"},
+			"annotations": map[string]interface{}{"example.com/owner": "edge"},
+		},
+		"spec": map[string]interface{}{"gatewayClassName": "other"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("apply configuration = %v, want %v", got, want)
+	}
+}
+
+func TestApplyResourceErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		resource  string
+		data      map[string]interface{}
+		check     func(error) bool
+	}{
+		{
+			name:      "renamed",
+			namespace: "infra",
+			resource:  "gw",
+			data:      map[string]interface{}{"metadata": map[string]interface{}{"name": "gw2"}},
+			check:     apierrors.IsBadRequest,
+		},
+		{
+			name:      "moved to another namespace",
+			namespace: "infra",
+			resource:  "gw",
+			data:      map[string]interface{}{"metadata": map[string]interface{}{"name": "gw", "namespace": "app"}},
+			check:     apierrors.IsBadRequest,
+		},
+		{
+			// Apply would create the object, so a missing one is reported instead
+			name:      "missing",
+			namespace: "infra",
+			resource:  "missing",
+			data:      map[string]interface{}{"spec": map[string]interface{}{}},
+			check:     apierrors.IsNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, patches := fakeClient(t, storedGateway())
+			_, err := client.ApplyResource(context.Background(), "gateway", tt.namespace, tt.resource, tt.data, false, false)
+			if !tt.check(err) {
+				t.Errorf("ApplyResource = %v, want a different error", err)
+			}
+			if len(*patches) != 0 {
+				t.Errorf("sent %d patches, want none", len(*patches))
+			}
+		})
+	}
+}
+
+func TestPatchResource(t *testing.T) {
+	mergePatch := []byte(`{"metadata":{"labels":{"team":"edge"}}}`)
+
+	client, patches := fakeClient(t, storedGateway())
+	if _, err := client.PatchResource(context.Background(), "gateway", "infra", "gw", k8stypes.MergePatchType, mergePatch, false, false); err != nil {
+		t.Fatalf("PatchResource: %v", err)
+	}
+	if len(*patches) != 1 || (*patches)[0].GetPatchType() != k8stypes.MergePatchType || string((*patches)[0].GetPatch()) != string(mergePatch) {
+		t.Errorf("patches = %+v, want the merge patch as submitted", *patches)
+	}
+
+	// An apply patch of a missing object fails before anything is sent
+	client, patches = fakeClient(t)
+	_, err := client.PatchResource(context.Background(), "gateway", "infra", "gw", k8stypes.ApplyPatchType, []byte(`{}`), true, false)
+	if !apierrors.IsNotFound(err) || len(*patches) != 0 {
+		t.Errorf("apply patch of a missing object = %v after %d patches, want NotFound before any", err, len(*patches))
+	}
+
+	if _, err := client.PatchResource(context.Background(), "pod", "infra", "gw", k8stypes.MergePatchType, mergePatch, false, false); err == nil {
+		t.Errorf("PatchResource of an unsupported type succeeded, want an error")
+	}
+}
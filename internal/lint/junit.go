@@ -0,0 +1,148 @@
+package lint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// JUnit XML in the form CI systems render as test results: one test case per resource, failing
+// when it has a finding at or above the failure severity
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// writeJUnit writes a test suite per manifest file with a test case per resource. Findings below
+// the failure severity are listed in the test case output without failing it.
+func writeJUnit(w io.Writer, report *Report) error {
+	byResource := make(map[types.ResourceRef][]Finding)
+	for _, finding := range report.Findings {
+		ref := finding.Resource
+		ref.Listener = ""
+		byResource[ref] = append(byResource[ref], finding)
+	}
+
+	root := junitTestSuites{Name: toolName + " lint"}
+	suites := make(map[string]int)
+	addCase := func(suite string, testCase junitTestCase) {
+		i, ok := suites[suite]
+		if !ok {
+			i = len(root.Suites)
+			suites[suite] = i
+			root.Suites = append(root.Suites, junitTestSuite{Name: suite})
+		}
+		root.Suites[i].Cases = append(root.Suites[i].Cases, testCase)
+		root.Suites[i].Tests++
+		root.Tests++
+		if testCase.Failure != nil {
+			root.Suites[i].Failures++
+			root.Failures++
+		}
+	}
+
+	for _, ref := range sortedResources(report.Origins) {
+		origin := report.Origins[ref]
+		testCase := report.junitCase(ref, byResource[ref])
+		testCase.ClassName = artifactURI(origin.File)
+		testCase.File = artifactURI(origin.File)
+		testCase.Line = origin.Line
+		addCase(artifactURI(origin.File), testCase)
+		delete(byResource, ref)
+	}
+
+	// Findings about resources that were not read from a manifest
+	for _, finding := range report.Findings {
+		ref := finding.Resource
+		ref.Listener = ""
+		findings, ok := byResource[ref]
+		if !ok {
+			continue
+		}
+		testCase := report.junitCase(ref, findings)
+		testCase.ClassName = ref.Kind
+		addCase("resources", testCase)
+		delete(byResource, ref)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitCase builds the test case of a resource from its findings
+func (r *Report) junitCase(ref types.ResourceRef, findings []Finding) junitTestCase {
+	testCase := junitTestCase{Name: resourceName(ref)}
+
+	var failing, other []string
+	var worst types.Severity
+	for _, finding := range findings {
+		line := fmt.Sprintf("%s [%s]: %s", finding.Severity, finding.Rule, finding.Message)
+		if finding.Resource.Listener != "" {
+			line = fmt.Sprintf("%s [%s] listener %s: %s", finding.Severity, finding.Rule, finding.Resource.Listener, finding.Message)
+		}
+		if finding.Remediation != "" {
+			line += "\n    " + finding.Remediation
+		}
+		if r.Failing(finding) {
+			failing = append(failing, line)
+			if finding.Severity.Rank() > worst.Rank() {
+				worst = finding.Severity
+			}
+		} else {
+			other = append(other, line)
+		}
+	}
+
+	if len(failing) > 0 {
+		testCase.Failure = &junitFailure{
+			Message: fmt.Sprintf("%d finding(s) at or above %s", len(failing), r.FailOn),
+			Type:    string(worst),
+			Text:    strings.Join(failing, "\n"),
+		}
+	}
+	if len(other) > 0 {
+		testCase.SystemOut = &junitOutput{Text: strings.Join(other, "\n")}
+	}
+	return testCase
+}
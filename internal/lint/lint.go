@@ -0,0 +1,138 @@
+package lint
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"gwapi-graph/internal/source"
+	"gwapi-graph/internal/types"
+)
+
+// Supported output formats
+const (
+	FormatText  = "text"
+	FormatSARIF = "sarif"
+	FormatJUnit = "junit"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatText, FormatSARIF, FormatJUnit}
+
+// toolName identifies the linter in SARIF and JUnit reports
+const toolName = "gwapi-graph"
+
+// Finding is an analysis finding located in the manifests
+type Finding struct {
+	types.Finding
+	Origin *source.Origin `json:"origin,omitempty"` // Nil when the resource was not read from a manifest
+}
+
+// Report holds the findings of a lint run
+type Report struct {
+	Findings []Finding
+	Origins  source.Origins
+	FailOn   types.Severity // Findings at or above this severity fail the run; "" never fails
+}
+
+// NewReport locates each finding in the manifests
+func NewReport(findings []types.Finding, origins source.Origins, failOn types.Severity) *Report {
+	report := &Report{Origins: origins, FailOn: failOn}
+	for _, finding := range findings {
+		located := Finding{Finding: finding}
+		if origin, ok := origins.Lookup(finding.Resource); ok {
+			located.Origin = &origin
+		}
+		report.Findings = append(report.Findings, located)
+	}
+	return report
+}
+
+// Failing reports whether a finding fails the run
+func (r *Report) Failing(finding Finding) bool {
+	return r.FailOn != "" && finding.Severity.Rank() >= r.FailOn.Rank()
+}
+
+// Failed reports whether any finding fails the run
+func (r *Report) Failed() bool {
+	for _, finding := range r.Findings {
+		if r.Failing(finding) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write renders the report in the given format
+func Write(w io.Writer, format string, report *Report) error {
+	switch format {
+	case FormatText:
+		return writeText(w, report)
+	case FormatSARIF:
+		return writeSARIF(w, report)
+	case FormatJUnit:
+		return writeJUnit(w, report)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// writeText writes one line per finding in the file:line: form editors and CI logs link to
+func writeText(w io.Writer, report *Report) error {
+	counts := make(map[types.Severity]int)
+	for _, finding := range report.Findings {
+		counts[finding.Severity]++
+		location := "-"
+		if finding.Origin != nil {
+			location = fmt.Sprintf("%s:%d (document %d)", finding.Origin.File, finding.Origin.Line, finding.Origin.Document)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s: %s [%s]\n", location, finding.Severity, resourceName(finding.Resource), finding.Message, finding.Rule); err != nil {
+			return err
+		}
+		if finding.Remediation != "" {
+			if _, err := fmt.Fprintf(w, "    %s\n", finding.Remediation); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d errors, %d warnings, %d info\n",
+		counts[types.SeverityError], counts[types.SeverityWarning], counts[types.SeverityInfo])
+	return err
+}
+
+// resourceName formats a resource reference as Kind namespace/name, with the listener if any
+func resourceName(ref types.ResourceRef) string {
+	name := ref.Name
+	if ref.Namespace != "" {
+		name = ref.Namespace + "/" + name
+	}
+	if ref.Listener != "" {
+		name += " listener " + ref.Listener
+	}
+	return ref.Kind + " " + name
+}
+
+// sortedResources returns every resource read from the manifests, in file and document order
+func sortedResources(origins source.Origins) []types.ResourceRef {
+	refs := make([]types.ResourceRef, 0, len(origins))
+	for ref := range origins {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := origins[refs[i]], origins[refs[j]]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Document != b.Document {
+			return a.Document < b.Document
+		}
+		return resourceName(refs[i]) < resourceName(refs[j])
+	})
+	return refs
+}
+
+// artifactURI turns a manifest path into the forward-slash URI SARIF and CI tools expect
+func artifactURI(file string) string {
+	return filepath.ToSlash(filepath.Clean(file))
+}
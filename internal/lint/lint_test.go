@@ -0,0 +1,215 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/source"
+	"gwapi-graph/internal/types"
+)
+
+var (
+	gatewayRef = types.ResourceRef{Kind: "Gateway", Namespace: "infra", Name: "gw"}
+	routeRef   = types.ResourceRef{Kind: "HTTPRoute", Namespace: "app", Name: "web"}
+	serviceRef = types.ResourceRef{Kind: "Service", Namespace: "app", Name: "web"}
+)
+
+// testReport returns a report with an error on the Gateway, a warning on one of its listeners,
+// a clean route and an info finding on a resource that was not read from a manifest
+func testReport(failOn types.Severity) *Report {
+	origins := source.Origins{
+		gatewayRef: {File: "manifests/gateway.yaml", Document: 0, Line: 1},
+		routeRef:   {File: "manifests/routes.yaml", Document: 1, Line: 12},
+	}
+	listener := gatewayRef
+	listener.Listener = "https"
+	findings := []types.Finding{
+		{Rule: "gatewayClass", Severity: types.SeverityError, Resource: gatewayRef, Message: "GatewayClass missing", Remediation: "Set gatewayClassName."},
+		{Rule: "listenerOverlap", Severity: types.SeverityWarning, Resource: listener, Message: "Listeners overlap"},
+		{Rule: "gatewayClass", Severity: types.SeverityInfo, Resource: serviceRef, Message: "Not read from a manifest"},
+	}
+	return NewReport(findings, origins, failOn)
+}
+
+func TestFailed(t *testing.T) {
+	tests := []struct {
+		failOn types.Severity
+		want   bool
+	}{
+		{types.SeverityError, true},
+		{types.SeverityWarning, true},
+		{types.SeverityInfo, true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := testReport(tt.failOn).Failed(); got != tt.want {
+			t.Errorf("Failed with fail-on %q = %v, want %v", tt.failOn, got, tt.want)
+		}
+	}
+
+	if (&Report{FailOn: types.SeverityError}).Failed() {
+		t.Errorf("Failed without findings = true, want false")
+	}
+}
+
+func TestNewReportOrigins(t *testing.T) {
+	report := testReport(types.SeverityError)
+	for _, finding := range report.Findings {
+		// The listener finding resolves to its Gateway's document
+		if located := finding.Origin != nil; located != (finding.Resource.Kind == "Gateway") {
+			t.Errorf("%s origin = %v", resourceName(finding.Resource), finding.Origin)
+		}
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatSARIF, testReport(types.SeverityError)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, out.String())
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("SARIF version %q with %d runs, want %s with 1", log.Version, len(log.Runs), sarifVersion)
+	}
+	run := log.Runs[0]
+
+	// Rules are sorted and default to the most serious level they reported
+	wantRules := []sarifRule{
+		{ID: "gatewayClass", DefaultConfiguration: sarifConfiguration{Level: "error"}},
+		{ID: "listenerOverlap", DefaultConfiguration: sarifConfiguration{Level: "warning"}},
+	}
+	if len(run.Tool.Driver.Rules) != len(wantRules) {
+		t.Fatalf("rules = %+v, want %+v", run.Tool.Driver.Rules, wantRules)
+	}
+	for i, rule := range wantRules {
+		if run.Tool.Driver.Rules[i] != rule {
+			t.Errorf("rule %d = %+v, want %+v", i, run.Tool.Driver.Rules[i], rule)
+		}
+	}
+
+	tests := []struct {
+		level   string
+		message string
+		uri     string
+		line    int
+	}{
+		{"error", "Gateway infra/gw: GatewayClass missing Set gatewayClassName.", "manifests/gateway.yaml", 1},
+		{"warning", "Gateway infra/gw listener https: Listeners overlap", "manifests/gateway.yaml", 1},
+		{"note", "Service app/web: Not read from a manifest", "", 0},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("%d results, want %d", len(run.Results), len(tests))
+	}
+	for i, tt := range tests {
+		result := run.Results[i]
+		if result.Level != tt.level || result.Message.Text != tt.message {
+			t.Errorf("result %d = %s %q, want %s %q", i, result.Level, result.Message.Text, tt.level, tt.message)
+		}
+		physical := result.Locations[0].PhysicalLocation
+		if tt.uri == "" {
+			if physical != nil {
+				t.Errorf("result %d has physical location %+v, want none", i, physical)
+			}
+			continue
+		}
+		if physical == nil || physical.ArtifactLocation.URI != tt.uri || physical.Region.StartLine != tt.line {
+			t.Errorf("result %d physical location = %+v, want %s:%d", i, physical, tt.uri, tt.line)
+		}
+	}
+}
+
+func TestWriteSARIFEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatSARIF, NewReport(nil, nil, types.SeverityError)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// Code scanning rejects null results and rules
+	if !strings.Contains(out.String(), `"results": []`) || !strings.Contains(out.String(), `"rules": []`) {
+		t.Errorf("empty SARIF log lacks empty results and rules:\n%s", out.String())
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	tests := []struct {
+		name     string
+		failOn   types.Severity
+		failures int
+		failing  []string
+	}{
+		{"fail on error", types.SeverityError, 1, []string{"Gateway infra/gw"}},
+		{"fail on info", types.SeverityInfo, 2, []string{"Gateway infra/gw", "Service app/web"}},
+		{"never fail", "", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Write(&out, FormatJUnit, testReport(tt.failOn)); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			var suites junitTestSuites
+			if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+				t.Fatalf("invalid JUnit XML: %v\n%s", err, out.String())
+			}
+			// A case per manifest resource, plus one for the Service outside the manifests
+			if suites.Tests != 3 || suites.Failures != tt.failures {
+				t.Errorf("tests=%d failures=%d, want tests=3 failures=%d", suites.Tests, suites.Failures, tt.failures)
+			}
+
+			var names []string
+			var failing []string
+			for _, suite := range suites.Suites {
+				names = append(names, suite.Name)
+				for _, testCase := range suite.Cases {
+					if testCase.Failure != nil {
+						failing = append(failing, testCase.Name)
+					}
+				}
+			}
+			if want := "manifests/gateway.yaml manifests/routes.yaml resources"; strings.Join(names, " ") != want {
+				t.Errorf("suites = %v, want %s", names, want)
+			}
+			if strings.Join(failing, ",") != strings.Join(tt.failing, ",") {
+				t.Errorf("failing cases = %v, want %v", failing, tt.failing)
+			}
+		})
+	}
+}
+
+func TestWriteJUnitFindingsBelowFailOn(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, FormatJUnit, testReport(types.SeverityError)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	gateway := suites.Suites[0].Cases[0]
+	if gateway.File != "manifests/gateway.yaml" || gateway.Line != 1 {
+		t.Errorf("Gateway case located at %s:%d, want manifests/gateway.yaml:1", gateway.File, gateway.Line)
+	}
+	if gateway.Failure == nil || gateway.Failure.Type != "error" || strings.Contains(gateway.Failure.Text, "overlap") {
+		t.Errorf("Gateway failure = %+v, want only the error", gateway.Failure)
+	}
+	// The warning is reported without failing the case
+	if gateway.SystemOut == nil || !strings.Contains(gateway.SystemOut.Text, "warning [listenerOverlap] listener https: Listeners overlap") {
+		t.Errorf("Gateway system-out = %+v, want the listener warning", gateway.SystemOut)
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "html", testReport(types.SeverityError)); err == nil {
+		t.Errorf("Write with an unsupported format succeeded, want an error")
+	}
+}
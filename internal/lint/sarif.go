@@ -0,0 +1,151 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gwapi-graph/internal/types"
+)
+
+// SARIF 2.1.0 as consumed by GitHub code scanning; only the fields the linter fills in are modeled
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity types.Severity) string {
+	switch severity {
+	case types.SeverityError:
+		return "error"
+	case types.SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+// writeSARIF writes the findings as a SARIF log, locating each at the line its document starts
+func writeSARIF(w io.Writer, report *Report) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: toolName, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+
+	// A rule's default level is the most serious level it reported
+	levels := make(map[string]types.Severity)
+	for _, finding := range report.Findings {
+		if current, ok := levels[finding.Rule]; !ok || finding.Severity.Rank() > current.Rank() {
+			levels[finding.Rule] = finding.Severity
+		}
+
+		message := finding.Message
+		if finding.Remediation != "" {
+			message += " " + finding.Remediation
+		}
+		result := sarifResult{
+			RuleID:  finding.Rule,
+			Level:   sarifLevel(finding.Severity),
+			Message: sarifMessage{Text: fmt.Sprintf("%s: %s", resourceName(finding.Resource), message)},
+		}
+
+		location := sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{
+				FullyQualifiedName: resourceName(finding.Resource),
+				Kind:               "resource",
+			}},
+		}
+		if finding.Origin != nil {
+			location.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: artifactURI(finding.Origin.File)},
+				Region:           sarifRegion{StartLine: finding.Origin.Line},
+			}
+			result.Properties = map[string]interface{}{"document": finding.Origin.Document}
+		}
+		result.Locations = []sarifLocation{location}
+
+		run.Results = append(run.Results, result)
+	}
+
+	rules := make([]string, 0, len(levels))
+	for rule := range levels {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   rule,
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(levels[rule])},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
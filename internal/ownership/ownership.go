@@ -0,0 +1,353 @@
+// Package ownership builds the table of who claims each hostname: the Gateways, listeners, routes,
+// namespaces and DNS records, and finds the claims a controller resolves silently.
+package ownership
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+)
+
+// Issue types
+const (
+	Collision       = "collision"       // The same hostname is claimed from several namespaces or Gateways
+	WildcardOverlap = "wildcardOverlap" // A wildcard claims the hostnames another namespace or Gateway claims explicitly
+	NoDNS           = "noDNS"           // Routes serve a hostname that no DNS record publishes
+)
+
+// Owner is one resource claiming a hostname
+type Owner struct {
+	Kind      string `json:"kind"` // Listener, HTTPRoute or DNSRecord
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Gateway   string `json:"gateway,omitempty"` // namespace/name of the Gateway the claim is made on, or a DNS record points to
+	NodeID    string `json:"nodeId"`
+}
+
+// Entry lists everything that claims one hostname
+type Entry struct {
+	Hostname   string   `json:"hostname"`
+	Gateways   []string `json:"gateways"`   // namespace/name
+	Listeners  []string `json:"listeners"`  // namespace/gateway/listener
+	Routes     []string `json:"routes"`     // namespace/name
+	Namespaces []string `json:"namespaces"` // Namespaces of the routes and of the Gateways of the listeners
+	DNSRecords []string `json:"dnsRecords"` // Kind namespace/name of the objects declaring a record for the hostname or a wildcard covering it
+	Owners     []Owner  `json:"owners"`
+}
+
+// Report is the hostname ownership table, sorted by hostname, and the problems found in it
+type Report struct {
+	Hostnames []Entry               `json:"hostnames"`
+	Issues    []types.HostnameIssue `json:"issues"`
+}
+
+// Build collects the hostnames claimed by listeners, by the routes attached to them (the
+// intersection of route and listener hostnames) and by DNS records, then reports collisions,
+// wildcard overlaps and hostnames routes serve without DNS
+func Build(resources *types.ResourceCollection, records []dnssource.Record) *Report {
+	entries := make(map[string]*Entry)
+	seen := make(map[string]bool)
+	claim := func(host string, owner Owner) {
+		host = hostname.Normalize(host)
+		key := host + "\x00" + owner.NodeID + "\x00" + owner.Gateway
+		if host == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		if entries[host] == nil {
+			entries[host] = &Entry{Hostname: host}
+		}
+		entries[host].Owners = append(entries[host].Owners, owner)
+	}
+
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		for l, listener := range gw.Spec.Listeners {
+			if listener.Hostname == nil {
+				continue
+			}
+			claim(string(*listener.Hostname), Owner{
+				Kind:      "Listener",
+				Namespace: gw.Namespace,
+				Name:      fmt.Sprintf("%s/%s", gw.Name, listener.Name),
+				Gateway:   gw.Namespace + "/" + gw.Name,
+				NodeID:    analysis.ListenerID(gw, l),
+			})
+		}
+	}
+
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		for _, ref := range route.Spec.ParentRefs {
+			gw := analysis.ParentGateway(resources, route.Namespace, ref)
+			if gw == nil {
+				continue
+			}
+			listeners, _ := analysis.AttachedListeners(gw, route, ref)
+			for _, l := range listeners {
+				for _, host := range analysis.EffectiveHostnames(gw.Spec.Listeners[l], route) {
+					claim(host, Owner{
+						Kind:      "HTTPRoute",
+						Namespace: route.Namespace,
+						Name:      route.Name,
+						Gateway:   gw.Namespace + "/" + gw.Name,
+						NodeID:    string(route.UID),
+					})
+				}
+			}
+		}
+	}
+
+	for _, record := range records {
+		owner := Owner{Kind: "DNSRecord", Namespace: record.Namespace, Name: record.Name, NodeID: record.ID}
+		if record.Gateway != nil {
+			owner.Gateway = record.Gateway.Namespace + "/" + record.Gateway.Name
+		}
+		claim(record.DNSName, owner)
+	}
+
+	report := &Report{Hostnames: []Entry{}, Issues: []types.HostnameIssue{}}
+	hosts := make([]string, 0, len(entries))
+	for host := range entries {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		entry := entries[host]
+		summarize(entry, records)
+		report.Hostnames = append(report.Hostnames, *entry)
+	}
+
+	for i := range report.Hostnames {
+		entry := &report.Hostnames[i]
+		if issue, ok := collision(entry); ok {
+			report.Issues = append(report.Issues, issue)
+		}
+		for j := range report.Hostnames {
+			if issue, ok := wildcardOverlap(entry, &report.Hostnames[j]); ok {
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+		if issue, ok := missingDNS(entry, records); ok {
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+	return report
+}
+
+// summarize fills in the Gateways, listeners, routes, namespaces and DNS records of an entry
+func summarize(entry *Entry, records []dnssource.Record) {
+	gateways, listeners, routes, namespaces, dnsRecords := newSet(), newSet(), newSet(), newSet(), newSet()
+	for _, owner := range entry.Owners {
+		switch owner.Kind {
+		case "Listener":
+			gateways.add(owner.Gateway)
+			listeners.add(owner.Namespace + "/" + owner.Name)
+			namespaces.add(owner.Namespace)
+		case "HTTPRoute":
+			gateways.add(owner.Gateway)
+			routes.add(owner.Namespace + "/" + owner.Name)
+			namespaces.add(owner.Namespace)
+		}
+	}
+	for _, record := range records {
+		// A wildcard record publishes the hostnames below it, as missingDNS counts them
+		if record.DNSName != "" && hostname.Matches(record.DNSName, entry.Hostname) {
+			dnsRecords.add(fmt.Sprintf("%s %s/%s", record.Kind, record.Namespace, record.Name))
+		}
+	}
+	entry.Gateways = gateways.sorted()
+	entry.Listeners = listeners.sorted()
+	entry.Routes = routes.sorted()
+	entry.Namespaces = namespaces.sorted()
+	entry.DNSRecords = dnsRecords.sorted()
+}
+
+// collision reports a hostname that routes from several namespaces, or listeners and routes on
+// several Gateways, claim. Within a Gateway the oldest route wins conflicting matches; across
+// Gateways DNS decides which one receives the traffic. A route attached to several Gateways, e.g.
+// an internal and an external one, serves the hostname on all of them on purpose, so Gateways
+// sharing a route count as one claim.
+func collision(entry *Entry) (types.HostnameIssue, bool) {
+	routeNamespaces := newSet()
+	routeGateways := make(map[string][]string)
+	for _, owner := range entry.Owners {
+		if owner.Kind == "HTTPRoute" {
+			routeNamespaces.add(owner.Namespace)
+			route := owner.Namespace + "/" + owner.Name
+			routeGateways[route] = append(routeGateways[route], owner.Gateway)
+		}
+	}
+
+	var reasons []string
+	if len(routeNamespaces) > 1 {
+		reasons = append(reasons, fmt.Sprintf("HTTPRoutes in namespaces %s", strings.Join(routeNamespaces.sorted(), ", ")))
+	}
+	if len(entry.Gateways) > 1 && len(gatewayGroups(entry.Gateways, routeGateways)) > 1 {
+		reasons = append(reasons, fmt.Sprintf("Gateways %s", strings.Join(entry.Gateways, ", ")))
+	}
+	if len(reasons) == 0 {
+		return types.HostnameIssue{}, false
+	}
+	return types.HostnameIssue{
+		Type:     Collision,
+		Hostname: entry.Hostname,
+		Message:  fmt.Sprintf("%s is claimed by %s.", entry.Hostname, strings.Join(reasons, " and by ")),
+		Nodes:    ownerNodes(entry.Owners, "Listener", "HTTPRoute"),
+	}, true
+}
+
+// gatewayGroups merges Gateways that share a route, given the Gateways each route is attached to,
+// and returns the resulting groups, each named after one of its Gateways
+func gatewayGroups(gateways []string, routeGateways map[string][]string) map[string]bool {
+	group := make(map[string]string, len(gateways))
+	for _, gw := range gateways {
+		group[gw] = gw
+	}
+	for _, attached := range routeGateways {
+		for _, gw := range attached[1:] {
+			from, to := group[gw], group[attached[0]]
+			for member, g := range group {
+				if g == from {
+					group[member] = to
+				}
+			}
+		}
+	}
+
+	groups := make(map[string]bool)
+	for _, g := range group {
+		groups[g] = true
+	}
+	return groups
+}
+
+// wildcardOverlap reports a wildcard hostname that covers a more specific hostname claimed from
+// another namespace or Gateway: routes from another namespace on the same Gateway, or listeners
+// of another Gateway. The more specific claim silently takes those requests.
+func wildcardOverlap(wildcard, specific *Entry) (types.HostnameIssue, bool) {
+	if !hostname.IsWildcard(wildcard.Hostname) || wildcard.Hostname == specific.Hostname || !hostname.Matches(wildcard.Hostname, specific.Hostname) {
+		return types.HostnameIssue{}, false
+	}
+
+	nodes := newSet()
+	for _, broad := range wildcard.Owners {
+		for _, narrow := range specific.Owners {
+			overlaps := false
+			switch {
+			case broad.Kind == "HTTPRoute" && narrow.Kind == "HTTPRoute":
+				overlaps = broad.Gateway == narrow.Gateway && broad.Namespace != narrow.Namespace
+			case broad.Kind == "Listener" && narrow.Kind == "Listener":
+				overlaps = broad.Gateway != narrow.Gateway
+			}
+			if overlaps {
+				nodes.add(broad.NodeID)
+				nodes.add(narrow.NodeID)
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return types.HostnameIssue{}, false
+	}
+	return types.HostnameIssue{
+		Type:     WildcardOverlap,
+		Hostname: specific.Hostname,
+		Message: fmt.Sprintf("%s (%s) overlaps %s (%s): requests for %s go to the more specific claim.",
+			wildcard.Hostname, describeOwners(wildcard.Owners), specific.Hostname, describeOwners(specific.Owners), specific.Hostname),
+		Nodes: nodes.sorted(),
+	}, true
+}
+
+// missingDNS reports a hostname routes serve that no DNS record publishes, exactly or through a
+// wildcard record. Without any record, DNS is managed outside the cluster and nothing is reported.
+func missingDNS(entry *Entry, records []dnssource.Record) (types.HostnameIssue, bool) {
+	if len(records) == 0 {
+		return types.HostnameIssue{}, false
+	}
+	routes := ownerNodes(entry.Owners, "HTTPRoute")
+	if len(routes) == 0 {
+		return types.HostnameIssue{}, false
+	}
+	for _, record := range records {
+		if record.DNSName != "" && hostname.Matches(record.DNSName, entry.Hostname) {
+			return types.HostnameIssue{}, false
+		}
+	}
+	return types.HostnameIssue{
+		Type:     NoDNS,
+		Hostname: entry.Hostname,
+		Message:  fmt.Sprintf("%s is served by HTTPRoute %s, but no DNS record publishes it.", entry.Hostname, strings.Join(entry.Routes, ", ")),
+		Nodes:    routes,
+	}, true
+}
+
+// ownerNodes returns the node IDs of the owners of the given kinds, sorted and without duplicates
+func ownerNodes(owners []Owner, kinds ...string) []string {
+	nodes := newSet()
+	for _, owner := range owners {
+		for _, kind := range kinds {
+			if owner.Kind == kind {
+				nodes.add(owner.NodeID)
+			}
+		}
+	}
+	return nodes.sorted()
+}
+
+// describeOwners lists the listeners and routes among owners, e.g. "HTTPRoute app/shop on infra/gw"
+func describeOwners(owners []Owner) string {
+	descriptions := newSet()
+	for _, owner := range owners {
+		if owner.Kind == "DNSRecord" {
+			continue
+		}
+		descriptions.add(fmt.Sprintf("%s %s/%s on %s", owner.Kind, owner.Namespace, owner.Name, owner.Gateway))
+	}
+	return strings.Join(descriptions.sorted(), ", ")
+}
+
+// set is a set of strings
+type set map[string]bool
+
+func newSet() set {
+	return make(set)
+}
+
+func (s set) add(value string) {
+	if value != "" {
+		s[value] = true
+	}
+}
+
+// sorted returns the members in order, never nil
+func (s set) sorted() []string {
+	values := make([]string, 0, len(s))
+	for value := range s {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Annotate attaches to every DNS zone the issues involving one of its nodes
+func Annotate(zones []types.DNSZone, issues []types.HostnameIssue) {
+	for i := range zones {
+		members := make(map[string]bool, len(zones[i].Nodes))
+		for _, nodeID := range zones[i].Nodes {
+			members[nodeID] = true
+		}
+		for _, issue := range issues {
+			for _, nodeID := range issue.Nodes {
+				if members[nodeID] {
+					zones[i].Annotations = append(zones[i].Annotations, issue)
+					break
+				}
+			}
+		}
+	}
+}
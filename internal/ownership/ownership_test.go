@@ -0,0 +1,138 @@
+package ownership
+
+import (
+	"reflect"
+	"testing"
+
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// httpGateway returns a Gateway with one HTTP listener without a hostname that accepts routes
+// from every namespace
+func httpGateway(namespace, name string) gatewayv1.Gateway {
+	return testutil.Gateway(namespace, name, testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, ""))
+}
+
+// hostRoute returns an HTTPRoute for a hostname attached to the Gateways infra/<name>
+func hostRoute(namespace, name, host string, gateways ...string) gatewayv1.HTTPRoute {
+	route := testutil.HTTPRoute(namespace, name)
+	route.Spec.Hostnames = testutil.Hostnames(host)
+	for _, gw := range gateways {
+		route.Spec.ParentRefs = append(route.Spec.ParentRefs, testutil.ParentRef("infra", gw))
+	}
+	return route
+}
+
+// findEntry returns the entry of a hostname
+func findEntry(t *testing.T, report *Report, host string) Entry {
+	t.Helper()
+	for _, entry := range report.Hostnames {
+		if entry.Hostname == host {
+			return entry
+		}
+	}
+	t.Fatalf("no entry for %s", host)
+	return Entry{}
+}
+
+func TestBuildDNSRecords(t *testing.T) {
+	resources := &types.ResourceCollection{
+		Gateways:   []gatewayv1.Gateway{httpGateway("infra", "gw")},
+		HTTPRoutes: []gatewayv1.HTTPRoute{hostRoute("app", "foo", "foo.apps.example.co.uk", "gw"), hostRoute("app", "bar", "bar.example.org", "gw")},
+	}
+	records := []dnssource.Record{
+		{ID: "wildcard", Kind: "DNSRecord", Namespace: "openshift-ingress", Name: "wildcard", DNSName: "*.apps.example.co.uk"},
+		{ID: "exact", Kind: "DNSEndpoint", Namespace: "app", Name: "foo", DNSName: "foo.apps.example.co.uk"},
+	}
+
+	report := Build(resources, records)
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"foo.apps.example.co.uk", []string{"DNSEndpoint app/foo", "DNSRecord openshift-ingress/wildcard"}},
+		{"*.apps.example.co.uk", []string{"DNSRecord openshift-ingress/wildcard"}},
+		{"bar.example.org", []string{}},
+	}
+	for _, tt := range tests {
+		if got := findEntry(t, report, tt.host).DNSRecords; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s dnsRecords = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+
+	var noDNS []string
+	for _, issue := range report.Issues {
+		if issue.Type == NoDNS {
+			noDNS = append(noDNS, issue.Hostname)
+		}
+	}
+	if want := []string{"bar.example.org"}; !reflect.DeepEqual(noDNS, want) {
+		t.Errorf("noDNS issues for %v, want %v", noDNS, want)
+	}
+}
+
+func TestBuildCollisions(t *testing.T) {
+	tests := []struct {
+		name      string
+		gateways  []gatewayv1.Gateway
+		routes    []gatewayv1.HTTPRoute
+		collision bool
+	}{
+		{
+			name:     "one route",
+			gateways: []gatewayv1.Gateway{httpGateway("infra", "gw")},
+			routes:   []gatewayv1.HTTPRoute{hostRoute("app", "a", "www.example.com", "gw")},
+		},
+		{
+			name:     "routes in one namespace",
+			gateways: []gatewayv1.Gateway{httpGateway("infra", "gw")},
+			routes: []gatewayv1.HTTPRoute{
+				hostRoute("app", "a", "www.example.com", "gw"),
+				hostRoute("app", "b", "www.example.com", "gw"),
+			},
+		},
+		{
+			name:     "routes in two namespaces",
+			gateways: []gatewayv1.Gateway{httpGateway("infra", "gw")},
+			routes: []gatewayv1.HTTPRoute{
+				hostRoute("app", "a", "www.example.com", "gw"),
+				hostRoute("other", "b", "www.example.com", "gw"),
+			},
+			collision: true,
+		},
+		{
+			name:     "one route attached to two Gateways",
+			gateways: []gatewayv1.Gateway{httpGateway("infra", "internal"), httpGateway("infra", "external")},
+			routes:   []gatewayv1.HTTPRoute{hostRoute("app", "a", "www.example.com", "internal", "external")},
+		},
+		{
+			name:     "routes of one namespace on two Gateways",
+			gateways: []gatewayv1.Gateway{httpGateway("infra", "internal"), httpGateway("infra", "external")},
+			routes: []gatewayv1.HTTPRoute{
+				hostRoute("app", "a", "www.example.com", "internal"),
+				hostRoute("app", "b", "www.example.com", "external"),
+			},
+			collision: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Build(&types.ResourceCollection{Gateways: tt.gateways, HTTPRoutes: tt.routes}, nil)
+			collision := false
+			for _, issue := range report.Issues {
+				if issue.Type == Collision && issue.Hostname == "www.example.com" {
+					collision = true
+				}
+			}
+			if collision != tt.collision {
+				t.Errorf("collision = %v, want %v (issues %+v)", collision, tt.collision, report.Issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Package policy implements Gateway API policy attachment: policy CRDs labeled with
+// gateway.networking.k8s.io/policy, the resources their targetRef or targetRefs point to, and the
+// policies that apply to each route, attached to it or inherited from above it.
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Label marks policy CRDs, with the value Direct or Inherited
+const Label = "gateway.networking.k8s.io/policy"
+
+// FromCRD returns the policy kind a CustomResourceDefinition declares, or false when it does not
+// carry the policy label. The version is the storage version, else the first served one.
+func FromCRD(crd *unstructured.Unstructured) (types.PolicyKind, bool) {
+	value, ok := crd.GetLabels()[Label]
+	if !ok {
+		return types.PolicyKind{}, false
+	}
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	resource, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	var version string
+	for _, v := range versions {
+		versionMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(versionMap, "name")
+		served, _, _ := unstructured.NestedBool(versionMap, "served")
+		storage, _, _ := unstructured.NestedBool(versionMap, "storage")
+		if storage {
+			version = name
+			break
+		}
+		if served && version == "" {
+			version = name
+		}
+	}
+
+	return types.PolicyKind{
+		Group:     group,
+		Version:   version,
+		Kind:      kind,
+		Resource:  resource,
+		Inherited: strings.EqualFold(value, "inherited"),
+	}, kind != ""
+}
+
+// ParseKinds parses policy kinds given as Kind.group, optionally followed by =direct or
+// =inherited, e.g. ClientTrafficPolicy.gateway.envoyproxy.io=inherited. Policies are direct unless
+// stated otherwise.
+func ParseKinds(specs []string) ([]types.PolicyKind, error) {
+	var kinds []types.PolicyKind
+	for _, spec := range specs {
+		name, attachment, _ := strings.Cut(spec, "=")
+		kind, group, found := strings.Cut(name, ".")
+		if !found || kind == "" || group == "" {
+			return nil, fmt.Errorf("invalid policy kind %q, expected Kind.group[=direct|inherited]", spec)
+		}
+		switch strings.ToLower(attachment) {
+		case "", "direct", "inherited":
+		default:
+			return nil, fmt.Errorf("invalid policy kind %q: attachment must be direct or inherited", spec)
+		}
+		kinds = append(kinds, types.PolicyKind{
+			Group:     group,
+			Kind:      kind,
+			Inherited: strings.EqualFold(attachment, "inherited"),
+		})
+	}
+	return kinds, nil
+}
+
+// Merge returns the kinds without duplicates; the first entry for a group and kind wins
+func Merge(lists ...[]types.PolicyKind) []types.PolicyKind {
+	var merged []types.PolicyKind
+	for _, kinds := range lists {
+		for _, kind := range kinds {
+			if _, found := Lookup(merged, kind.Group, kind.Kind); !found {
+				merged = append(merged, kind)
+			}
+		}
+	}
+	return merged
+}
+
+// Lookup returns the policy kind with the given group and kind
+func Lookup(kinds []types.PolicyKind, group, kind string) (types.PolicyKind, bool) {
+	for _, k := range kinds {
+		if k.Group == group && k.Kind == kind {
+			return k, true
+		}
+	}
+	return types.PolicyKind{}, false
+}
+
+// HasTargetRef reports whether an object attaches to resources through spec.targetRef or
+// spec.targetRefs
+func HasTargetRef(obj *unstructured.Unstructured) bool {
+	_, single, _ := unstructured.NestedMap(obj.Object, "spec", "targetRef")
+	_, multiple, _ := unstructured.NestedSlice(obj.Object, "spec", "targetRefs")
+	return single || multiple
+}
+
+// Attachment is a policy and the resources it attaches to
+type Attachment struct {
+	Policy    *unstructured.Unstructured
+	Inherited bool
+	Targets   []types.PolicyTarget
+}
+
+// Attachments returns the policies among the collected objects: those of a known kind, and those
+// of an unknown kind named like a policy (…Policy), which are treated as direct policies
+func Attachments(resources *types.ResourceCollection, kinds []types.PolicyKind) []Attachment {
+	var attachments []Attachment
+	for i := range resources.Policies {
+		obj := &resources.Policies[i]
+		if !HasTargetRef(obj) {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		kind, known := Lookup(kinds, gvk.Group, gvk.Kind)
+		if !known && !strings.HasSuffix(gvk.Kind, "Policy") {
+			continue
+		}
+		attachments = append(attachments, Attachment{
+			Policy:    obj,
+			Inherited: kind.Inherited,
+			Targets:   Targets(resources, obj),
+		})
+	}
+	return attachments
+}
+
+// Targets returns the targets of a policy from spec.targetRef and spec.targetRefs, resolved to
+// graph nodes. Targets are in the policy's namespace unless the reference names another one.
+func Targets(resources *types.ResourceCollection, obj *unstructured.Unstructured) []types.PolicyTarget {
+	var refs []map[string]interface{}
+	if ref, found, _ := unstructured.NestedMap(obj.Object, "spec", "targetRef"); found {
+		refs = append(refs, ref)
+	}
+	list, _, _ := unstructured.NestedSlice(obj.Object, "spec", "targetRefs")
+	for _, item := range list {
+		if ref, ok := item.(map[string]interface{}); ok {
+			refs = append(refs, ref)
+		}
+	}
+
+	targets := make([]types.PolicyTarget, 0, len(refs))
+	for _, ref := range refs {
+		target := types.PolicyTarget{Namespace: obj.GetNamespace()}
+		target.Group, _, _ = unstructured.NestedString(ref, "group")
+		target.Kind, _, _ = unstructured.NestedString(ref, "kind")
+		target.Name, _, _ = unstructured.NestedString(ref, "name")
+		target.SectionName, _, _ = unstructured.NestedString(ref, "sectionName")
+		if namespace, _, _ := unstructured.NestedString(ref, "namespace"); namespace != "" {
+			target.Namespace = namespace
+		}
+		if target.Kind == "GatewayClass" {
+			target.Namespace = ""
+		}
+		target.NodeID = resolve(resources, target)
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// resolve returns the graph node of a policy target: a Gateway, or the listener its sectionName
+// names, a GatewayClass, an HTTPRoute or a Service. Empty when the target does not exist.
+func resolve(resources *types.ResourceCollection, target types.PolicyTarget) string {
+	switch {
+	case target.Group == gatewayv1.GroupName && target.Kind == "Gateway":
+		for i := range resources.Gateways {
+			gw := &resources.Gateways[i]
+			if gw.Namespace != target.Namespace || gw.Name != target.Name {
+				continue
+			}
+			if target.SectionName == "" {
+				return string(gw.UID)
+			}
+			for l, listener := range gw.Spec.Listeners {
+				if string(listener.Name) == target.SectionName {
+					return analysis.ListenerID(gw, l)
+				}
+			}
+		}
+	case target.Group == gatewayv1.GroupName && target.Kind == "GatewayClass":
+		for _, gc := range resources.GatewayClasses {
+			if gc.Name == target.Name {
+				return string(gc.UID)
+			}
+		}
+	case target.Group == gatewayv1.GroupName && target.Kind == "HTTPRoute":
+		for _, route := range resources.HTTPRoutes {
+			if route.Namespace == target.Namespace && route.Name == target.Name {
+				return string(route.UID)
+			}
+		}
+	case target.Group == "" && target.Kind == "Service":
+		if svc := analysis.FindService(resources, target.Namespace, target.Name); svc != nil {
+			return string(svc.UID)
+		}
+	}
+	return ""
+}
+
+// Effective returns the policies that apply to a route: every policy attached to the route, then
+// the inherited policies attached to the listeners it attaches to, to their Gateways and to the
+// Gateways' classes. The list goes from the most to the least specific attachment; how a
+// policy's defaults and overrides combine is up to its kind.
+func Effective(resources *types.ResourceCollection, attachments []Attachment, route *gatewayv1.HTTPRoute) []types.EffectivePolicy {
+	type level struct {
+		nodeID, via string
+	}
+	levels := []level{{string(route.UID), fmt.Sprintf("HTTPRoute %s/%s", route.Namespace, route.Name)}}
+	var gatewayLevels, classLevels []level
+	seen := make(map[string]bool)
+	for _, ref := range route.Spec.ParentRefs {
+		gw := analysis.ParentGateway(resources, route.Namespace, ref)
+		if gw == nil {
+			continue
+		}
+		listeners, _ := analysis.AttachedListeners(gw, route, ref)
+		if len(listeners) == 0 {
+			continue
+		}
+		for _, l := range listeners {
+			id := analysis.ListenerID(gw, l)
+			if !seen[id] {
+				seen[id] = true
+				levels = append(levels, level{id, fmt.Sprintf("Gateway %s/%s listener %s", gw.Namespace, gw.Name, gw.Spec.Listeners[l].Name)})
+			}
+		}
+		if !seen[string(gw.UID)] {
+			seen[string(gw.UID)] = true
+			gatewayLevels = append(gatewayLevels, level{string(gw.UID), fmt.Sprintf("Gateway %s/%s", gw.Namespace, gw.Name)})
+		}
+		for _, gc := range resources.GatewayClasses {
+			if string(gc.Name) == string(gw.Spec.GatewayClassName) && !seen[string(gc.UID)] {
+				seen[string(gc.UID)] = true
+				classLevels = append(classLevels, level{string(gc.UID), "GatewayClass " + gc.Name})
+			}
+		}
+	}
+	levels = append(append(levels, gatewayLevels...), classLevels...)
+
+	policies := []types.EffectivePolicy{}
+	for i, lvl := range levels {
+		for _, attachment := range attachments {
+			// Above the route, only inherited policies apply
+			if i > 0 && !attachment.Inherited {
+				continue
+			}
+			for _, target := range attachment.Targets {
+				if target.NodeID != lvl.nodeID {
+					continue
+				}
+				via := lvl.via
+				if i == 0 && target.SectionName != "" {
+					via += " rule " + target.SectionName
+				}
+				policies = append(policies, types.EffectivePolicy{
+					Kind:      attachment.Policy.GetKind(),
+					Namespace: attachment.Policy.GetNamespace(),
+					Name:      attachment.Policy.GetName(),
+					Inherited: attachment.Inherited,
+					Via:       via,
+					NodeID:    string(attachment.Policy.GetUID()),
+				})
+				break
+			}
+		}
+	}
+	return policies
+}
@@ -0,0 +1,236 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// policyObject returns a policy of the example.com group in namespace app with the given targetRefs
+func policyObject(kind, name string, targetRefs ...interface{}) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"namespace": "app", "name": name, "uid": "uid-" + name},
+		"spec":       map[string]interface{}{"targetRefs": targetRefs},
+	}}
+}
+
+// targetRef returns a targetRef, with a namespace and a sectionName unless they are empty
+func targetRef(group, kind, namespace, name, section string) map[string]interface{} {
+	ref := map[string]interface{}{"group": group, "kind": kind, "name": name}
+	if namespace != "" {
+		ref["namespace"] = namespace
+	}
+	if section != "" {
+		ref["sectionName"] = section
+	}
+	return ref
+}
+
+// policyResources returns the GatewayClass example, the Gateway infra/gw with the listeners http
+// and https, the HTTPRoute app/r attached to the http listener and the Service app/web
+func policyResources() *types.ResourceCollection {
+	parent := testutil.ParentRef("infra", "gw")
+	parent.SectionName = testutil.Ptr(gatewayv1.SectionName("http"))
+	return &types.ResourceCollection{
+		GatewayClasses: []gatewayv1.GatewayClass{testutil.GatewayClass("example")},
+		Gateways: []gatewayv1.Gateway{testutil.Gateway("infra", "gw",
+			testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, ""),
+			testutil.Listener("https", gatewayv1.HTTPSProtocolType, 443, ""),
+		)},
+		HTTPRoutes: []gatewayv1.HTTPRoute{testutil.HTTPRoute("app", "r", parent)},
+		Services:   []corev1.Service{testutil.Service("app", "web", 80)},
+	}
+}
+
+func TestTargets(t *testing.T) {
+	resources := policyResources()
+	obj := policyObject("TimeoutPolicy", "p",
+		targetRef(gatewayv1.GroupName, "Gateway", "infra", "gw", "https"),
+		targetRef(gatewayv1.GroupName, "Gateway", "", "gw", ""),
+		targetRef(gatewayv1.GroupName, "GatewayClass", "app", "example", ""),
+		targetRef(gatewayv1.GroupName, "HTTPRoute", "", "r", "rule-1"),
+		targetRef("", "Service", "", "web", ""),
+		targetRef(gatewayv1.GroupName, "HTTPRoute", "", "missing", ""),
+		targetRef("storage.example.com", "Bucket", "", "web", ""),
+	)
+	obj.Object["spec"].(map[string]interface{})["targetRef"] = targetRef(gatewayv1.GroupName, "Gateway", "", "gw", "")
+
+	want := []types.PolicyTarget{
+		// The single targetRef comes first; the Gateway is looked up in the policy's namespace
+		{Group: gatewayv1.GroupName, Kind: "Gateway", Namespace: "app", Name: "gw"},
+		{Group: gatewayv1.GroupName, Kind: "Gateway", Namespace: "infra", Name: "gw", SectionName: "https", NodeID: "infra/gw-listener-1"},
+		{Group: gatewayv1.GroupName, Kind: "Gateway", Namespace: "app", Name: "gw"},
+		{Group: gatewayv1.GroupName, Kind: "GatewayClass", Name: "example", NodeID: "example"},
+		{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "app", Name: "r", SectionName: "rule-1", NodeID: "app/r"},
+		{Kind: "Service", Namespace: "app", Name: "web", NodeID: "app/web"},
+		{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "app", Name: "missing"},
+		{Group: "storage.example.com", Kind: "Bucket", Namespace: "app", Name: "web"},
+	}
+	if got := Targets(resources, &obj); !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	resources := policyResources()
+	tests := []struct {
+		name   string
+		target types.PolicyTarget
+		want   string
+	}{
+		{"Gateway", types.PolicyTarget{Group: gatewayv1.GroupName, Kind: "Gateway", Namespace: "infra", Name: "gw"}, "infra/gw"},
+		{"listener", types.PolicyTarget{Group: gatewayv1.GroupName, Kind: "Gateway", Namespace: "infra", Name: "gw", SectionName: "http"}, "infra/gw-listener-0"},
+		{"missing listener", types.PolicyTarget{Group: gatewayv1.GroupName, Kind: "Gateway", Namespace: "infra", Name: "gw", SectionName: "grpc"}, ""},
+		{"GatewayClass", types.PolicyTarget{Group: gatewayv1.GroupName, Kind: "GatewayClass", Name: "example"}, "example"},
+		{"HTTPRoute", types.PolicyTarget{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "app", Name: "r"}, "app/r"},
+		{"Service", types.PolicyTarget{Kind: "Service", Namespace: "app", Name: "web"}, "app/web"},
+		{"Service of another group", types.PolicyTarget{Group: "example.com", Kind: "Service", Namespace: "app", Name: "web"}, ""},
+		{"unknown kind", types.PolicyTarget{Group: gatewayv1.GroupName, Kind: "TLSRoute", Namespace: "app", Name: "r"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := resolve(resources, tt.target); got != tt.want {
+			t.Errorf("resolve(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEffective(t *testing.T) {
+	resources := policyResources()
+	resources.Policies = []unstructured.Unstructured{
+		policyObject("RoutePolicy", "route", targetRef(gatewayv1.GroupName, "HTTPRoute", "", "r", "rule-1")),
+		policyObject("ListenerPolicy", "http", targetRef(gatewayv1.GroupName, "Gateway", "infra", "gw", "http")),
+		policyObject("ListenerPolicy", "https", targetRef(gatewayv1.GroupName, "Gateway", "infra", "gw", "https")),
+		policyObject("GatewayPolicy", "gateway", targetRef(gatewayv1.GroupName, "Gateway", "infra", "gw", "")),
+		policyObject("DirectPolicy", "gateway-direct", targetRef(gatewayv1.GroupName, "Gateway", "infra", "gw", "")),
+		policyObject("ClassPolicy", "class", targetRef(gatewayv1.GroupName, "GatewayClass", "", "example", "")),
+		policyObject("Unrelated", "unrelated", targetRef(gatewayv1.GroupName, "HTTPRoute", "", "r", "")),
+	}
+	kinds := []types.PolicyKind{
+		{Group: "example.com", Kind: "ListenerPolicy", Inherited: true},
+		{Group: "example.com", Kind: "GatewayPolicy", Inherited: true},
+		{Group: "example.com", Kind: "ClassPolicy", Inherited: true},
+	}
+
+	attachments := Attachments(resources, kinds)
+	if len(attachments) != 6 {
+		t.Fatalf("Attachments returned %d policies, want 6 without the one not named like a policy", len(attachments))
+	}
+
+	want := []types.EffectivePolicy{
+		{Kind: "RoutePolicy", Namespace: "app", Name: "route", Via: "HTTPRoute app/r rule rule-1", NodeID: "uid-route"},
+		{Kind: "ListenerPolicy", Namespace: "app", Name: "http", Inherited: true, Via: "Gateway infra/gw listener http", NodeID: "uid-http"},
+		{Kind: "GatewayPolicy", Namespace: "app", Name: "gateway", Inherited: true, Via: "Gateway infra/gw", NodeID: "uid-gateway"},
+		{Kind: "ClassPolicy", Namespace: "app", Name: "class", Inherited: true, Via: "GatewayClass example", NodeID: "uid-class"},
+	}
+	if got := Effective(resources, attachments, &resources.HTTPRoutes[0]); !reflect.DeepEqual(got, want) {
+		t.Errorf("Effective =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// A route no listener accepts inherits nothing
+	detached := testutil.HTTPRoute("app", "detached", testutil.ParentRef("infra", "missing"))
+	if got := Effective(resources, attachments, &detached); len(got) != 0 {
+		t.Errorf("Effective of a detached route = %+v, want none", got)
+	}
+}
+
+func TestFromCRD(t *testing.T) {
+	crd := func(label string, versions ...interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": "timeoutpolicies.example.com"},
+			"spec": map[string]interface{}{
+				"group":    "example.com",
+				"names":    map[string]interface{}{"kind": "TimeoutPolicy", "plural": "timeoutpolicies"},
+				"versions": versions,
+			},
+		}}
+		if label != "" {
+			obj.SetLabels(map[string]string{Label: label})
+		}
+		return obj
+	}
+	version := func(name string, served, storage bool) map[string]interface{} {
+		return map[string]interface{}{"name": name, "served": served, "storage": storage}
+	}
+
+	tests := []struct {
+		name   string
+		crd    *unstructured.Unstructured
+		want   types.PolicyKind
+		wantOK bool
+	}{
+		{
+			name:   "storage version",
+			crd:    crd("Inherited", version("v1alpha1", true, false), version("v1", true, true)),
+			want:   types.PolicyKind{Group: "example.com", Version: "v1", Kind: "TimeoutPolicy", Resource: "timeoutpolicies", Inherited: true},
+			wantOK: true,
+		},
+		{
+			name:   "first served version",
+			crd:    crd("Direct", version("v1alpha1", false, false), version("v1beta1", true, false), version("v1", true, false)),
+			want:   types.PolicyKind{Group: "example.com", Version: "v1beta1", Kind: "TimeoutPolicy", Resource: "timeoutpolicies"},
+			wantOK: true,
+		},
+		{
+			name: "not a policy",
+			crd:  crd("", version("v1", true, true)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := FromCRD(tt.crd)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("FromCRD = %+v, %t, want %+v, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	withoutKind := crd("Direct", version("v1", true, true))
+	unstructured.RemoveNestedField(withoutKind.Object, "spec", "names", "kind")
+	if _, ok := FromCRD(withoutKind); ok {
+		t.Errorf("FromCRD of a CRD without a kind succeeded")
+	}
+}
+
+func TestParseKinds(t *testing.T) {
+	tests := []struct {
+		specs   []string
+		want    []types.PolicyKind
+		wantErr bool
+	}{
+		{
+			specs: []string{"TimeoutPolicy.example.com", "ClientTrafficPolicy.gateway.envoyproxy.io=Inherited", "BackendPolicy.example.com=direct"},
+			want: []types.PolicyKind{
+				{Group: "example.com", Kind: "TimeoutPolicy"},
+				{Group: "gateway.envoyproxy.io", Kind: "ClientTrafficPolicy", Inherited: true},
+				{Group: "example.com", Kind: "BackendPolicy"},
+			},
+		},
+		{specs: []string{"TimeoutPolicy"}, wantErr: true},
+		{specs: []string{".example.com"}, wantErr: true},
+		{specs: []string{"TimeoutPolicy."}, wantErr: true},
+		{specs: []string{"TimeoutPolicy.example.com=sometimes"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseKinds(tt.specs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseKinds(%q) error = %v, want error %t", tt.specs, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseKinds(%q) = %+v, want %+v", tt.specs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// writeDOT renders the graph as a Graphviz digraph, clustering nodes by namespace
+func writeDOT(w io.Writer, graph *types.Graph) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintln(out, "digraph gateway_api {")
+	fmt.Fprintln(out, "  rankdir=LR;")
+	fmt.Fprintln(out, `  node [shape=box, style="rounded,filled", fontname="Helvetica", fontsize=10, fontcolor=white];`)
+	fmt.Fprintln(out, `  edge [fontname="Helvetica", fontsize=8];`)
+
+	writeNode := func(indent string, i int) {
+		node := graph.Nodes[i]
+		fmt.Fprintf(out, "%sn%d [label=%s, fillcolor=%q, tooltip=%s];\n",
+			indent, i, dotString(nodeLabel(node)), colorOf(nodeColors, node.Type), dotString(node.ID))
+	}
+
+	namespaces, groups, clusterScoped := namespaceGroups(graph)
+	for _, i := range clusterScoped {
+		writeNode("  ", i)
+	}
+	for n, namespace := range namespaces {
+		fmt.Fprintf(out, "  subgraph cluster_%d {\n", n)
+		fmt.Fprintf(out, "    label=%s;\n", dotString("namespace "+namespace))
+		fmt.Fprintln(out, `    style=dashed; color="#95a5a6"; fontname="Helvetica"; fontsize=10;`)
+		for _, i := range groups[namespace] {
+			writeNode("    ", i)
+		}
+		fmt.Fprintln(out, "  }")
+	}
+
+	for _, link := range graph.Links {
+		fmt.Fprintf(out, "  n%d -> n%d [label=%s, color=%q];\n",
+			link.Source, link.Target, dotString(linkLabel(link)), colorOf(linkColors, link.Type))
+	}
+
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// dotString quotes a string as a DOT ID, keeping newlines as line breaks
+func dotString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
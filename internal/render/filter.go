@@ -0,0 +1,159 @@
+package render
+
+import (
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// Filter selects the part of a graph to render. Empty fields match everything.
+type Filter struct {
+	Namespaces []string // Keep namespaced nodes in these namespaces
+	Kinds      []string // Keep nodes of these types (case-insensitive); Gateway includes its listeners
+	Gateways   []string // Keep only what is attached to these Gateways, given as namespace/name
+}
+
+// Apply returns a copy of the graph containing only the matching nodes, the links between them
+// and the DNS zones they belong to. Cluster-scoped nodes (GatewayClasses) are kept by the
+// namespace filter when they are linked to a kept node.
+func (f Filter) Apply(graph *types.Graph) *types.Graph {
+	keep := make([]bool, len(graph.Nodes))
+	for i := range keep {
+		keep[i] = true
+	}
+
+	if len(f.Gateways) > 0 {
+		keep = gatewayNodes(graph, f.Gateways)
+	}
+
+	if len(f.Kinds) > 0 {
+		kinds := make(map[string]bool)
+		for _, kind := range f.Kinds {
+			kinds[strings.ToLower(kind)] = true
+		}
+		if kinds["gateway"] {
+			kinds["listener"] = true
+		}
+		for i, node := range graph.Nodes {
+			if !kinds[strings.ToLower(node.Type)] {
+				keep[i] = false
+			}
+		}
+	}
+
+	if len(f.Namespaces) > 0 {
+		namespaces := make(map[string]bool)
+		for _, ns := range f.Namespaces {
+			namespaces[ns] = true
+		}
+		clusterScoped := make(map[int]bool)
+		for i, node := range graph.Nodes {
+			if node.Namespace == "" {
+				clusterScoped[i] = keep[i]
+				keep[i] = false
+			} else if !namespaces[node.Namespace] {
+				keep[i] = false
+			}
+		}
+		for _, link := range graph.Links {
+			if clusterScoped[link.Source] && keep[link.Target] {
+				keep[link.Source] = true
+			}
+			if clusterScoped[link.Target] && keep[link.Source] {
+				keep[link.Target] = true
+			}
+		}
+	}
+
+	return Subgraph(graph, keep)
+}
+
+// gatewayNodes marks the given Gateways, their listeners and GatewayClass, the routes and
+// DNSRecords attached to them and the backends of those routes
+func gatewayNodes(graph *types.Graph, gateways []string) []bool {
+	keep := make([]bool, len(graph.Nodes))
+
+	wanted := make(map[string]bool)
+	for _, gw := range gateways {
+		wanted[gw] = true
+	}
+
+	roots := make(map[string]bool)
+	for i, node := range graph.Nodes {
+		if node.Type == "Gateway" && (wanted[node.Namespace+"/"+node.Name] || wanted[node.Name]) {
+			roots[node.ID] = true
+			keep[i] = true
+		}
+	}
+	for i, node := range graph.Nodes {
+		if node.ParentID != nil && roots[*node.ParentID] {
+			keep[i] = true
+		}
+	}
+
+	// Routes and DNSRecords are linked to the Gateway or one of its listeners, the class to the
+	// Gateway. The class's other Gateways are not followed.
+	routes := make(map[int]bool)
+	for _, link := range graph.Links {
+		switch {
+		case keep[link.Source] && link.Type != "backendRef" && link.Type != "gatewayClassRef":
+			if graph.Nodes[link.Target].Type == "HTTPRoute" {
+				routes[link.Target] = true
+			}
+			keep[link.Target] = true
+		case keep[link.Target] && graph.Nodes[link.Target].Type == "Gateway":
+			keep[link.Source] = true
+		}
+	}
+
+	for _, link := range graph.Links {
+		if routes[link.Source] && link.Type == "backendRef" {
+			keep[link.Target] = true
+		}
+	}
+
+	return keep
+}
+
+// Subgraph copies the kept nodes, re-indexing links and dropping DNS zones left empty
+func Subgraph(graph *types.Graph, keep []bool) *types.Graph {
+	result := &types.Graph{
+		Nodes:    []types.Node{},
+		Links:    []types.Link{},
+		DNSZones: []types.DNSZone{},
+	}
+
+	index := make(map[int]int)
+	kept := make(map[string]bool)
+	for i, node := range graph.Nodes {
+		if keep[i] {
+			index[i] = len(result.Nodes)
+			kept[node.ID] = true
+			result.Nodes = append(result.Nodes, node)
+		}
+	}
+
+	for _, link := range graph.Links {
+		source, sourceKept := index[link.Source]
+		target, targetKept := index[link.Target]
+		if sourceKept && targetKept {
+			link.Source, link.Target = source, target
+			result.Links = append(result.Links, link)
+		}
+	}
+
+	for _, zone := range graph.DNSZones {
+		var nodes []string
+		for _, id := range zone.Nodes {
+			if kept[id] {
+				nodes = append(nodes, id)
+			}
+		}
+		if len(nodes) > 0 {
+			zone.Nodes = nodes
+			result.DNSZones = append(result.DNSZones, zone)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    Filter
+		wantNodes []string
+		wantLinks int
+		wantZones []string
+	}{
+		{
+			name:      "no filter",
+			wantNodes: []string{"example", "infra/gw", "infra/gw-listener-0", "infra/other", "app/web", "app/api", "app/web-svc", "shared/api", "infra/wildcard"},
+			wantLinks: 8,
+			wantZones: []string{"example.com", "other.com"},
+		},
+		{
+			// The class is kept, but not the other Gateway of the class
+			name:      "gateway",
+			filter:    Filter{Gateways: []string{"infra/gw"}},
+			wantNodes: []string{"example", "infra/gw", "infra/gw-listener-0", "app/web", "app/web-svc", "infra/wildcard"},
+			wantLinks: 5,
+			wantZones: []string{"example.com"},
+		},
+		{
+			name:      "gateway by name",
+			filter:    Filter{Gateways: []string{"other"}},
+			wantNodes: []string{"example", "infra/other", "app/api", "shared/api"},
+			wantLinks: 3,
+			wantZones: []string{"other.com"},
+		},
+		{
+			name:      "Gateway kind includes listeners",
+			filter:    Filter{Kinds: []string{"gateway"}},
+			wantNodes: []string{"infra/gw", "infra/gw-listener-0", "infra/other"},
+			wantLinks: 1,
+		},
+		{
+			name:      "kinds",
+			filter:    Filter{Kinds: []string{"HTTPRoute", "service"}},
+			wantNodes: []string{"app/web", "app/api", "app/web-svc", "shared/api"},
+			wantLinks: 2,
+			wantZones: []string{"example.com", "other.com"},
+		},
+		{
+			name:      "namespace without cluster-scoped links",
+			filter:    Filter{Namespaces: []string{"app"}},
+			wantNodes: []string{"app/web", "app/api", "app/web-svc"},
+			wantLinks: 1,
+			wantZones: []string{"example.com", "other.com"},
+		},
+		{
+			name:      "namespace keeps the linked GatewayClass",
+			filter:    Filter{Namespaces: []string{"infra"}},
+			wantNodes: []string{"example", "infra/gw", "infra/gw-listener-0", "infra/other", "infra/wildcard"},
+			wantLinks: 4,
+			wantZones: []string{"example.com"},
+		},
+		{
+			name:      "gateway and namespace",
+			filter:    Filter{Gateways: []string{"infra/gw"}, Namespaces: []string{"app"}},
+			wantNodes: []string{"app/web", "app/web-svc"},
+			wantLinks: 1,
+			wantZones: []string{"example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := testGraph()
+			result := tt.filter.Apply(graph)
+
+			var nodes, zones []string
+			for _, node := range result.Nodes {
+				nodes = append(nodes, node.ID)
+			}
+			for _, zone := range result.DNSZones {
+				zones = append(zones, zone.Name)
+			}
+			if !reflect.DeepEqual(nodes, tt.wantNodes) {
+				t.Errorf("nodes = %q, want %q", nodes, tt.wantNodes)
+			}
+			if len(result.Links) != tt.wantLinks {
+				t.Errorf("links = %+v, want %d", result.Links, tt.wantLinks)
+			}
+			for _, link := range result.Links {
+				if link.Source >= len(result.Nodes) || link.Target >= len(result.Nodes) {
+					t.Errorf("link %+v points outside the %d nodes", link, len(result.Nodes))
+				}
+			}
+			if !reflect.DeepEqual(zones, tt.wantZones) {
+				t.Errorf("zones = %q, want %q", zones, tt.wantZones)
+			}
+			if !reflect.DeepEqual(graph, testGraph()) {
+				t.Errorf("Apply modified the input graph")
+			}
+		})
+	}
+}
+
+func TestSubgraphZones(t *testing.T) {
+	keep := make([]bool, 9)
+	keep[8] = true // infra/wildcard only
+	result := Subgraph(testGraph(), keep)
+	if len(result.DNSZones) != 1 || !reflect.DeepEqual(result.DNSZones[0].Nodes, []string{"infra/wildcard"}) {
+		t.Errorf("zones = %+v, want example.com with only infra/wildcard", result.DNSZones)
+	}
+	if len(result.Links) != 0 {
+		t.Errorf("links = %+v, want none", result.Links)
+	}
+}
@@ -0,0 +1,106 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"gwapi-graph/internal/types"
+)
+
+// graphML is the document structure of a GraphML file
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLNodeKeys are the node attributes exported, in order
+var graphMLNodeKeys = []string{"name", "type", "namespace", "group", "version", "kind", "hostname", "dnsZone", "color"}
+
+// writeGraphML renders the graph as GraphML, for yEd, Gephi and similar tools
+func writeGraphML(w io.Writer, graph *types.Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Graph: graphMLGraph{ID: "gateway-api", EdgeDefault: "directed"},
+	}
+	for _, key := range graphMLNodeKeys {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: key, For: "node", AttrName: key, AttrType: "string"})
+	}
+	doc.Keys = append(doc.Keys, graphMLKey{ID: "linkType", For: "edge", AttrName: "type", AttrType: "string"})
+
+	for _, node := range graph.Nodes {
+		values := map[string]string{
+			"name":      node.Name,
+			"type":      node.Type,
+			"namespace": node.Namespace,
+			"group":     node.Group,
+			"version":   node.Version,
+			"kind":      node.Kind,
+			"hostname":  node.Hostname,
+			"dnsZone":   node.DNSZone,
+			"color":     colorOf(nodeColors, node.Type),
+		}
+		if node.ListenerData != nil && node.ListenerData.Hostname != nil {
+			values["hostname"] = *node.ListenerData.Hostname
+		}
+
+		element := graphMLNode{ID: node.ID}
+		for _, key := range graphMLNodeKeys {
+			if values[key] != "" {
+				element.Data = append(element.Data, graphMLData{Key: key, Value: values[key]})
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, element)
+	}
+
+	for _, link := range graph.Links {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: graph.Nodes[link.Source].ID,
+			Target: graph.Nodes[link.Target].ID,
+			Data:   []graphMLData{{Key: "linkType", Value: link.Type}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode GraphML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// writeMermaid renders the graph as a Mermaid flowchart, with a subgraph per namespace
+func writeMermaid(w io.Writer, graph *types.Graph) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintln(out, "flowchart LR")
+
+	namespaces, groups, clusterScoped := namespaceGroups(graph)
+	for _, i := range clusterScoped {
+		fmt.Fprintf(out, "  n%d[%s]\n", i, mermaidString(nodeLabel(graph.Nodes[i])))
+	}
+	for n, namespace := range namespaces {
+		fmt.Fprintf(out, "  subgraph ns%d[%s]\n", n, mermaidString("namespace "+namespace))
+		for _, i := range groups[namespace] {
+			fmt.Fprintf(out, "    n%d[%s]\n", i, mermaidString(nodeLabel(graph.Nodes[i])))
+		}
+		fmt.Fprintln(out, "  end")
+	}
+
+	for _, link := range graph.Links {
+		fmt.Fprintf(out, "  n%d -->|%s| n%d\n", link.Source, mermaidString(linkLabel(link)), link.Target)
+	}
+
+	// One class per node type, colored like the web UI
+	classes := make(map[string][]string)
+	for i, node := range graph.Nodes {
+		classes[node.Type] = append(classes[node.Type], fmt.Sprintf("n%d", i))
+	}
+	nodeTypes := make([]string, 0, len(classes))
+	for nodeType := range classes {
+		nodeTypes = append(nodeTypes, nodeType)
+	}
+	sort.Strings(nodeTypes)
+	for _, nodeType := range nodeTypes {
+		fmt.Fprintf(out, "  classDef %s fill:%s,color:#fff,stroke:#fff\n", nodeType, colorOf(nodeColors, nodeType))
+		fmt.Fprintf(out, "  class %s %s\n", strings.Join(classes[nodeType], ","), nodeType)
+	}
+
+	return out.Flush()
+}
+
+// mermaidString quotes a node or link label, escaping characters Mermaid would interpret
+func mermaidString(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "\n", "<br/>")
+	return `"` + s + `"`
+}
@@ -0,0 +1,140 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// Supported output formats
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatGraphML = "graphml"
+	FormatJSON    = "json"
+	FormatSVG     = "svg"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatDOT, FormatMermaid, FormatGraphML, FormatJSON, FormatSVG}
+
+// nodeColors match the node colors of the web UI
+var nodeColors = map[string]string{
+	"GatewayClass":   "#e74c3c",
+	"Gateway":        "#3498db",
+	"Listener":       "#1abc9c",
+	"HTTPRoute":      "#2ecc71",
+	"ReferenceGrant": "#9b59b6",
+	"DNSRecord":      "#f59e0b",
+	"Service":        "#8b5cf6",
+	"Policy":         "#ec4899",
+	"ConfigMap":      "#64748b",
+	"Secret":         "#64748b",
+}
+
+// linkColors match the link colors of the web UI
+var linkColors = map[string]string{
+	"gatewayClassRef":  "#e74c3c",
+	"parentRef":        "#3498db",
+	"listener":         "#1abc9c",
+	"backendRef":       "#2ecc71",
+	"dnsRecord":        "#f59e0b",
+	"dnsTarget":        "#9b59b6",
+	"staleDnsTarget":   "#e74c3c",
+	"shadowed":         "#e67e22",
+	"policy":           "#ec4899",
+	"caCertificateRef": "#64748b",
+}
+
+const defaultColor = "#7f8c8d"
+
+// FormatFromPath infers the output format from a file extension, returning "" when unknown
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return FormatDOT
+	case ".mmd", ".mermaid":
+		return FormatMermaid
+	case ".graphml":
+		return FormatGraphML
+	case ".json":
+		return FormatJSON
+	case ".svg":
+		return FormatSVG
+	}
+	return ""
+}
+
+// Write renders the graph in the given format
+func Write(w io.Writer, format string, graph *types.Graph) error {
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, graph)
+	case FormatMermaid:
+		return writeMermaid(w, graph)
+	case FormatGraphML:
+		return writeGraphML(w, graph)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	case FormatSVG:
+		return writeSVG(w, graph)
+	}
+	return fmt.Errorf("unsupported format %q (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// nodeLabel is the display name of a node, e.g. "HTTPRoute\nweb/store" or "Listener\nhttps :443"
+func nodeLabel(node types.Node) string {
+	name := node.Name
+	if node.Namespace != "" && node.Type != "Listener" {
+		name = node.Namespace + "/" + node.Name
+	}
+	if node.ListenerData != nil {
+		name = fmt.Sprintf("%s :%d", node.Name, node.ListenerData.Port)
+	}
+	if node.Type == "Policy" {
+		return node.Kind + "\n" + name
+	}
+	return node.Type + "\n" + name
+}
+
+// linkLabel is the display text of a link, e.g. "parentRef\napi.example.com" for a route
+// attachment with its effective hostnames
+func linkLabel(link types.Link) string {
+	if len(link.Hostnames) == 0 {
+		return link.Type
+	}
+	return link.Type + "\n" + strings.Join(link.Hostnames, ", ")
+}
+
+// namespaceGroups returns the node indexes per namespace, sorted by namespace. Cluster-scoped
+// nodes are returned separately.
+func namespaceGroups(graph *types.Graph) (namespaces []string, groups map[string][]int, clusterScoped []int) {
+	groups = make(map[string][]int)
+	for i, node := range graph.Nodes {
+		if node.Namespace == "" {
+			clusterScoped = append(clusterScoped, i)
+			continue
+		}
+		if _, exists := groups[node.Namespace]; !exists {
+			namespaces = append(namespaces, node.Namespace)
+		}
+		groups[node.Namespace] = append(groups[node.Namespace], i)
+	}
+	sort.Strings(namespaces)
+	return namespaces, groups, clusterScoped
+}
+
+// colorOf returns the color for a node or link type
+func colorOf(colors map[string]string, key string) string {
+	if color, ok := colors[key]; ok {
+		return color
+	}
+	return defaultColor
+}
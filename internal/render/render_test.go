@@ -0,0 +1,212 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/types"
+)
+
+// testGraph returns two Gateways of one class in infra. The http listener of infra/gw serves the
+// route app/web and a DNSRecord; infra/other serves the route app/api, whose backend is in shared.
+func testGraph() *types.Graph {
+	gw := "infra/gw"
+	wildcard := "*.example.com"
+	return &types.Graph{
+		Nodes: []types.Node{
+			{ID: "example", Type: "GatewayClass", Name: "example"},
+			{ID: "infra/gw", Type: "Gateway", Namespace: "infra", Name: "gw"},
+			{ID: "infra/gw-listener-0", Type: "Listener", Namespace: "infra", Name: "http", ParentID: &gw,
+				ListenerData: &types.ListenerData{Port: 80, Protocol: "HTTP", Hostname: &wildcard}},
+			{ID: "infra/other", Type: "Gateway", Namespace: "infra", Name: "other"},
+			{ID: "app/web", Type: "HTTPRoute", Namespace: "app", Name: "web"},
+			{ID: "app/api", Type: "HTTPRoute", Namespace: "app", Name: "api"},
+			{ID: "app/web-svc", Type: "Service", Namespace: "app", Name: "web-svc"},
+			{ID: "shared/api", Type: "Service", Namespace: "shared", Name: "api"},
+			{ID: "infra/wildcard", Type: "DNSRecord", Namespace: "infra", Name: "wildcard"},
+		},
+		Links: []types.Link{
+			{Source: 0, Target: 1, Type: "gatewayClassRef"},
+			{Source: 0, Target: 3, Type: "gatewayClassRef"},
+			{Source: 1, Target: 2, Type: "listener"},
+			{Source: 2, Target: 4, Type: "parentRef", Hostnames: []string{"www.example.com"}},
+			{Source: 3, Target: 5, Type: "parentRef"},
+			{Source: 4, Target: 6, Type: "backendRef"},
+			{Source: 5, Target: 7, Type: "backendRef"},
+			{Source: 2, Target: 8, Type: "dnsRecord"},
+		},
+		DNSZones: []types.DNSZone{
+			{Name: "example.com", Nodes: []string{"infra/wildcard", "app/web"}},
+			{Name: "other.com", Nodes: []string{"app/api"}},
+		},
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"graph.dot", FormatDOT},
+		{"graph.GV", FormatDOT},
+		{"docs/graph.mmd", FormatMermaid},
+		{"graph.mermaid", FormatMermaid},
+		{"graph.graphml", FormatGraphML},
+		{"graph.json", FormatJSON},
+		{"graph.svg", FormatSVG},
+		{"graph.png", ""},
+		{"graph", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFromPath(tt.path); got != tt.want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+		check  func(t *testing.T, out []byte)
+	}{
+		{
+			format: FormatDOT,
+			want: []string{
+				"digraph gateway_api {",
+				`  n0 [label="GatewayClass\nexample", fillcolor="#e74c3c", tooltip="example"];`,
+				`    label="namespace app";`,
+				`    n2 [label="Listener\nhttp :80", fillcolor="#1abc9c", tooltip="infra/gw-listener-0"];`,
+				`  n2 -> n4 [label="parentRef\nwww.example.com", color="#3498db"];`,
+			},
+		},
+		{
+			format: FormatMermaid,
+			want: []string{
+				"flowchart LR",
+				"  n0[\"GatewayClass<br/>example\"]\n",
+				"  subgraph ns0[\"namespace app\"]\n    n4[\"HTTPRoute<br/>app/web\"]\n",
+				`  n2 -->|"parentRef<br/>www.example.com"| n4`,
+				"  class n4,n5 HTTPRoute",
+			},
+		},
+		{
+			format: FormatGraphML,
+			check: func(t *testing.T, out []byte) {
+				var doc graphML
+				if err := xml.Unmarshal(out, &doc); err != nil {
+					t.Fatalf("GraphML does not parse: %v", err)
+				}
+				if len(doc.Graph.Nodes) != 9 || len(doc.Graph.Edges) != 8 {
+					t.Errorf("GraphML has %d nodes and %d edges, want 9 and 8", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+				}
+				listener := doc.Graph.Nodes[2]
+				want := []graphMLData{{Key: "name", Value: "http"}, {Key: "type", Value: "Listener"}, {Key: "namespace", Value: "infra"},
+					{Key: "hostname", Value: "*.example.com"}, {Key: "color", Value: "#1abc9c"}}
+				if listener.ID != "infra/gw-listener-0" || !reflect.DeepEqual(listener.Data, want) {
+					t.Errorf("listener node = %+v, want its hostname and color", listener)
+				}
+				if edge := doc.Graph.Edges[3]; edge.Source != "infra/gw-listener-0" || edge.Target != "app/web" {
+					t.Errorf("edge = %+v, want the listener's parentRef to app/web by node ID", edge)
+				}
+			},
+		},
+		{
+			format: FormatJSON,
+			check: func(t *testing.T, out []byte) {
+				var graph types.Graph
+				if err := json.Unmarshal(out, &graph); err != nil {
+					t.Fatalf("JSON does not parse: %v", err)
+				}
+				if !reflect.DeepEqual(&graph, testGraph()) {
+					t.Errorf("JSON round trip = %+v, want the graph", graph)
+				}
+			},
+		},
+		{
+			format: FormatSVG,
+			want: []string{
+				`<g><title>infra/gw-listener-0</title>`,
+				`>http :80</text>`,
+				`marker-end="url(#arrow-parentRef)"`,
+			},
+			check: func(t *testing.T, out []byte) {
+				decoder := xml.NewDecoder(bytes.NewReader(out))
+				for {
+					if _, err := decoder.Token(); errors.Is(err, io.EOF) {
+						break
+					} else if err != nil {
+						t.Fatalf("SVG is not well-formed XML: %v", err)
+					}
+				}
+				if strings.Contains(string(out), "href=") {
+					t.Errorf("SVG refers to an external resource")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Write(&out, tt.format, testGraph()); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output lacks %q:\n%s", want, out.String())
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, out.Bytes())
+			}
+		})
+	}
+
+	if err := Write(io.Discard, "png", testGraph()); err == nil || !strings.Contains(err.Error(), "supported: dot, mermaid") {
+		t.Errorf("Write of an unknown format = %v, want an error listing the formats", err)
+	}
+}
+
+func TestQuoting(t *testing.T) {
+	if got, want := dotString("say \"hi\"\\\nbye"), `"say \"hi\"\\\nbye"`; got != want {
+		t.Errorf("dotString = %s, want %s", got, want)
+	}
+	if got, want := mermaidString("say \"hi\"\nbye"), `"say #quot;hi#quot;<br/>bye"`; got != want {
+		t.Errorf("mermaidString = %s, want %s", got, want)
+	}
+	if got := truncate("www.example.com", 8); got != "www.exa…" {
+		t.Errorf("truncate = %q, want www.exa…", got)
+	}
+}
+
+func TestSVGLayout(t *testing.T) {
+	graph := testGraph()
+	positions, width, height := svgLayout(graph)
+
+	// Routes follow the listener or Gateway they attach to: web is served by the first row
+	if positions[4].y > positions[5].y {
+		t.Errorf("route app/web is placed below app/api, want it next to the listener serving it")
+	}
+	// Columns follow the direction of traffic
+	if !(positions[1].x < positions[2].x && positions[2].x < positions[4].x && positions[4].x < positions[6].x) {
+		t.Errorf("positions = %+v, want Gateway, Listener, HTTPRoute and Service columns from left to right", positions)
+	}
+	seen := make(map[svgPosition]bool)
+	for i, pos := range positions {
+		if pos.x+svgNodeWidth > width || pos.y+svgNodeHeight > height {
+			t.Errorf("node %d at %+v lies outside %dx%d", i, pos, width, height)
+		}
+		if seen[pos] {
+			t.Errorf("node %d overlaps another node at %+v", i, pos)
+		}
+		seen[pos] = true
+	}
+}
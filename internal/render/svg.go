@@ -0,0 +1,210 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// Layout dimensions of the SVG output, in pixels
+const (
+	svgNodeWidth    = 200
+	svgNodeHeight   = 40
+	svgColumnGap    = 90
+	svgRowGap       = 16
+	svgMargin       = 30
+	svgLegendHeight = 40
+)
+
+// svgColumns places node types in columns from left to right, following the direction of traffic
+var svgColumns = map[string]int{
+	"DNSRecord":      0,
+	"GatewayClass":   0,
+	"Gateway":        1,
+	"Listener":       2,
+	"HTTPRoute":      3,
+	"ReferenceGrant": 3,
+	"Service":        4,
+	"Policy":         5,
+	"ConfigMap":      6,
+	"Secret":         6,
+}
+
+// svgPosition is the top-left corner of a node box
+type svgPosition struct {
+	x, y int
+}
+
+// writeSVG renders the graph as a standalone SVG image laid out in columns by node type. The
+// image has no external references, so it can be embedded in documents as-is.
+func writeSVG(w io.Writer, graph *types.Graph) error {
+	positions, width, height := svgLayout(graph)
+	height += svgLegendHeight
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintln(out, "  <defs>")
+	for _, linkType := range sortedKeys(linkColors) {
+		fmt.Fprintf(out, `    <marker id="arrow-%s" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker>`+"\n",
+			linkType, linkColors[linkType])
+	}
+	fmt.Fprintf(out, `    <marker id="arrow-default" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker>`+"\n", defaultColor)
+	fmt.Fprintln(out, "  </defs>")
+	fmt.Fprintf(out, `  <rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+
+	for _, link := range graph.Links {
+		source, target := positions[link.Source], positions[link.Target]
+		x1, y1 := source.x+svgNodeWidth, source.y+svgNodeHeight/2
+		x2, y2 := target.x, target.y+svgNodeHeight/2
+		if target.x <= source.x {
+			// Links within or against the column order leave and enter on the same side
+			x1, x2 = source.x, target.x
+		}
+		curve := (x2 - x1) / 2
+		if curve < 40 && curve > -40 {
+			curve = -40
+		}
+
+		marker := "default"
+		if _, ok := linkColors[link.Type]; ok {
+			marker = link.Type
+		}
+		fmt.Fprintf(out, `  <path d="M%d,%d C%d,%d %d,%d %d,%d" fill="none" stroke="%s" stroke-width="1.5" marker-end="url(#arrow-%s)"><title>%s</title></path>`+"\n",
+			x1, y1, x1+curve, y1, x2-curve, y2, x2, y2, colorOf(linkColors, link.Type), marker, html.EscapeString(link.Type))
+	}
+
+	for i, node := range graph.Nodes {
+		pos := positions[i]
+		lines := strings.SplitN(nodeLabel(node), "\n", 2)
+		fmt.Fprintf(out, `  <g><title>%s</title>`, html.EscapeString(node.ID))
+		fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="#ffffff" stroke-width="2"/>`,
+			pos.x, pos.y, svgNodeWidth, svgNodeHeight, colorOf(nodeColors, node.Type))
+		fmt.Fprintf(out, `<text x="%d" y="%d" font-size="9" fill="#ffffff" opacity="0.85">%s</text>`,
+			pos.x+8, pos.y+14, html.EscapeString(lines[0]))
+		if len(lines) > 1 {
+			fmt.Fprintf(out, `<text x="%d" y="%d" font-size="11" font-weight="bold" fill="#ffffff">%s</text>`,
+				pos.x+8, pos.y+30, html.EscapeString(truncate(lines[1], 30)))
+		}
+		fmt.Fprintln(out, "</g>")
+	}
+
+	// Legend of the node types present
+	x, y := svgMargin, height-svgLegendHeight+10
+	seen := make(map[string]bool)
+	for _, node := range graph.Nodes {
+		if seen[node.Type] {
+			continue
+		}
+		seen[node.Type] = true
+		fmt.Fprintf(out, `  <rect x="%d" y="%d" width="12" height="12" rx="2" fill="%s"/><text x="%d" y="%d" font-size="11" fill="#2c3e50">%s</text>`+"\n",
+			x, y, colorOf(nodeColors, node.Type), x+16, y+10, html.EscapeString(node.Type))
+		x += 30 + 7*len(node.Type)
+	}
+
+	fmt.Fprintln(out, "</svg>")
+	return out.Flush()
+}
+
+// svgLayout assigns each node a column by type and orders each column by the average row of
+// its linked nodes in the previous columns, which keeps most links short and uncrossed
+func svgLayout(graph *types.Graph) (positions []svgPosition, width, height int) {
+	columnCount := 0
+	for _, column := range svgColumns {
+		if column+1 > columnCount {
+			columnCount = column + 1
+		}
+	}
+	columnCount++ // Unknown node types go last
+
+	columns := make([][]int, columnCount)
+	for i, node := range graph.Nodes {
+		column, ok := svgColumns[node.Type]
+		if !ok {
+			column = columnCount - 1
+		}
+		columns[column] = append(columns[column], i)
+	}
+
+	neighbors := make(map[int][]int)
+	for _, link := range graph.Links {
+		neighbors[link.Source] = append(neighbors[link.Source], link.Target)
+		neighbors[link.Target] = append(neighbors[link.Target], link.Source)
+	}
+
+	row := make(map[int]float64)
+	for c, column := range columns {
+		weight := make(map[int]float64)
+		for _, i := range column {
+			total, count := 0.0, 0
+			for _, neighbor := range neighbors[i] {
+				if r, placed := row[neighbor]; placed {
+					total += r
+					count++
+				}
+			}
+			if count > 0 {
+				weight[i] = total / float64(count)
+			} else {
+				weight[i] = float64(len(graph.Nodes)) // Unlinked nodes go to the bottom
+			}
+		}
+
+		sort.SliceStable(column, func(a, b int) bool {
+			if c > 0 && weight[column[a]] != weight[column[b]] {
+				return weight[column[a]] < weight[column[b]]
+			}
+			return nodeLabel(graph.Nodes[column[a]]) < nodeLabel(graph.Nodes[column[b]])
+		})
+		for r, i := range column {
+			row[i] = float64(r)
+		}
+	}
+
+	positions = make([]svgPosition, len(graph.Nodes))
+	x := svgMargin
+	maxRows := 0
+	for _, column := range columns {
+		if len(column) == 0 {
+			continue
+		}
+		for r, i := range column {
+			positions[i] = svgPosition{x: x, y: svgMargin + r*(svgNodeHeight+svgRowGap)}
+		}
+		if len(column) > maxRows {
+			maxRows = len(column)
+		}
+		x += svgNodeWidth + svgColumnGap
+	}
+
+	width = x - svgColumnGap + svgMargin
+	if width < 2*svgMargin+svgNodeWidth {
+		width = 2*svgMargin + svgNodeWidth
+	}
+	height = 2*svgMargin + maxRows*(svgNodeHeight+svgRowGap)
+	return positions, width, height
+}
+
+// truncate shortens a label to at most n characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// sortedKeys returns the keys of a map in order, so output is deterministic
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,433 @@
+// Package report writes Markdown documents describing part of the topology, for design reviews
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/render"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// attachedRoute is a route whose parentRefs name the Gateway, with the listeners it attaches to
+type attachedRoute struct {
+	route     *gatewayv1.HTTPRoute
+	listeners []int
+	reason    string // Why no listener accepts the route, when none does
+}
+
+// Gateway writes the Markdown report of one Gateway: its listeners, the routes attached to it and
+// their rules, the backends with their ready endpoints, the DNS records and ReferenceGrants it
+// relies on, a Mermaid diagram of its part of the graph and the findings about that part
+func Gateway(w io.Writer, resources *types.ResourceCollection, graph *types.Graph, records []dnssource.Record, gw *gatewayv1.Gateway, now time.Time) error {
+	out := bufio.NewWriter(w)
+	routes := attachedRoutes(resources, gw)
+	subgraph := render.Filter{Gateways: []string{gw.Namespace + "/" + gw.Name}}.Apply(graph)
+
+	fmt.Fprintf(out, "# Gateway %s/%s\n\n", gw.Namespace, gw.Name)
+	fmt.Fprintf(out, "Generated %s.\n\n", now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(out, "- **GatewayClass**: %s\n", gw.Spec.GatewayClassName)
+	var addresses []string
+	for _, address := range gw.Status.Addresses {
+		addresses = append(addresses, address.Value)
+	}
+	fmt.Fprintf(out, "- **Addresses**: %s\n", orNone(strings.Join(addresses, ", ")))
+	for _, conditionType := range []string{string(gatewayv1.GatewayConditionAccepted), string(gatewayv1.GatewayConditionProgrammed)} {
+		if condition := meta.FindStatusCondition(gw.Status.Conditions, conditionType); condition != nil {
+			fmt.Fprintf(out, "- **%s**: %s %s\n", conditionType, condition.Status, condition.Reason)
+		}
+	}
+
+	writeListeners(out, resources, gw, routes, now)
+	writeRoutes(out, gw, routes)
+	writeBackends(out, resources, routes)
+	writeDNSRecords(out, resources, records, gw)
+	writeReferenceGrants(out, resources, gw, routes)
+
+	fmt.Fprintf(out, "\n## Diagram\n\n```mermaid\n")
+	var diagram bytes.Buffer
+	if err := render.Write(&diagram, render.FormatMermaid, subgraph); err != nil {
+		return fmt.Errorf("failed to render diagram: %w", err)
+	}
+	out.Write(diagram.Bytes())
+	fmt.Fprintf(out, "```\n")
+
+	writeFindings(out, subgraph)
+	return out.Flush()
+}
+
+// attachedRoutes returns the routes whose parentRefs name the Gateway, sorted by namespace and name
+func attachedRoutes(resources *types.ResourceCollection, gw *gatewayv1.Gateway) []attachedRoute {
+	var routes []attachedRoute
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		var attached *attachedRoute
+		for _, ref := range route.Spec.ParentRefs {
+			parent := analysis.ParentGateway(resources, route.Namespace, ref)
+			if parent == nil || parent.UID != gw.UID {
+				continue
+			}
+			if attached == nil {
+				routes = append(routes, attachedRoute{route: route})
+				attached = &routes[len(routes)-1]
+			}
+			listeners, reason := analysis.AttachedListeners(gw, route, ref)
+			attached.listeners = append(attached.listeners, listeners...)
+			if len(listeners) == 0 {
+				attached.reason = reason
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].route.Namespace != routes[j].route.Namespace {
+			return routes[i].route.Namespace < routes[j].route.Namespace
+		}
+		return routes[i].route.Name < routes[j].route.Name
+	})
+	return routes
+}
+
+// writeListeners writes the listeners table
+func writeListeners(out io.Writer, resources *types.ResourceCollection, gw *gatewayv1.Gateway, routes []attachedRoute, now time.Time) {
+	fmt.Fprintf(out, "\n## Listeners\n\n")
+	fmt.Fprintf(out, "| Name | Port | Protocol | Hostname | TLS | Attached routes |\n")
+	fmt.Fprintf(out, "|------|------|----------|----------|-----|-----------------|\n")
+	for l, listener := range gw.Spec.Listeners {
+		host := "*"
+		if listener.Hostname != nil {
+			host = string(*listener.Hostname)
+		}
+		attached := 0
+		for _, route := range routes {
+			for _, index := range route.listeners {
+				if index == l {
+					attached++
+					break
+				}
+			}
+		}
+		fmt.Fprintf(out, "| %s | %d | %s | %s | %s | %d |\n", cell(string(listener.Name)), listener.Port, listener.Protocol,
+			cell(host), cell(listenerTLS(resources, gw, listener, now)), attached)
+	}
+}
+
+// listenerTLS describes the TLS mode and certificates of a listener
+func listenerTLS(resources *types.ResourceCollection, gw *gatewayv1.Gateway, listener gatewayv1.Listener, now time.Time) string {
+	if listener.TLS == nil {
+		return ""
+	}
+	mode := gatewayv1.TLSModeTerminate
+	if listener.TLS.Mode != nil {
+		mode = *listener.TLS.Mode
+	}
+	parts := []string{string(mode)}
+	for _, ref := range listener.TLS.CertificateRefs {
+		namespace := gw.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		description := fmt.Sprintf("%s/%s", namespace, ref.Name)
+		if bundle := certs.Find(resources.Certificates, "Secret", namespace, string(ref.Name), certs.TLSCertKey); bundle != nil {
+			if bundle.Error != "" {
+				description += ": " + bundle.Error
+			} else {
+				description += ", " + certs.Expiry(bundle.Certificates[0], now)
+			}
+		}
+		parts = append(parts, description)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// writeRoutes writes a section per route with its rules
+func writeRoutes(out io.Writer, gw *gatewayv1.Gateway, routes []attachedRoute) {
+	fmt.Fprintf(out, "\n## Routes\n")
+	if len(routes) == 0 {
+		fmt.Fprintf(out, "\nNo HTTPRoute references this Gateway.\n")
+		return
+	}
+
+	for _, attached := range routes {
+		route := attached.route
+		fmt.Fprintf(out, "\n### HTTPRoute %s/%s\n\n", route.Namespace, route.Name)
+
+		var hostnames []string
+		for _, routeHostname := range route.Spec.Hostnames {
+			hostnames = append(hostnames, string(routeHostname))
+		}
+		fmt.Fprintf(out, "- **Hostnames**: %s\n", orNone(strings.Join(hostnames, ", ")))
+		if len(attached.listeners) == 0 {
+			fmt.Fprintf(out, "- **Not attached**: %s\n", attached.reason)
+			continue
+		}
+		var listeners []string
+		for _, l := range attached.listeners {
+			listeners = append(listeners, string(gw.Spec.Listeners[l].Name))
+		}
+		fmt.Fprintf(out, "- **Listeners**: %s\n\n", strings.Join(listeners, ", "))
+		if len(route.Spec.Rules) == 0 {
+			fmt.Fprintf(out, "No rules.\n")
+			continue
+		}
+
+		matches := analysis.RouteMatches(route)
+		fmt.Fprintf(out, "| Rule | Matches | Filters | Backends |\n")
+		fmt.Fprintf(out, "|------|---------|---------|----------|\n")
+		for r, rule := range route.Spec.Rules {
+			var conditions, filters, backends []string
+			for _, match := range matches {
+				if match.Rule == r {
+					conditions = append(conditions, analysis.DescribeRouteMatch(match.Match))
+				}
+			}
+			for _, filter := range rule.Filters {
+				filters = append(filters, string(filter.Type))
+			}
+			for _, backendRef := range rule.BackendRefs {
+				backends = append(backends, describeBackend(route, backendRef))
+			}
+			fmt.Fprintf(out, "| %d | %s | %s | %s |\n", r, cell(strings.Join(conditions, "\n")),
+				cell(strings.Join(filters, ", ")), cell(strings.Join(backends, "\n")))
+		}
+	}
+}
+
+// describeBackend formats a backendRef as namespace/name:port with its weight
+func describeBackend(route *gatewayv1.HTTPRoute, backendRef gatewayv1.HTTPBackendRef) string {
+	namespace := route.Namespace
+	if backendRef.Namespace != nil {
+		namespace = string(*backendRef.Namespace)
+	}
+	description := fmt.Sprintf("%s/%s", namespace, backendRef.Name)
+	if backendRef.Kind != nil && *backendRef.Kind != "Service" {
+		description = fmt.Sprintf("%s %s", *backendRef.Kind, description)
+	}
+	if backendRef.Port != nil {
+		description = fmt.Sprintf("%s:%d", description, *backendRef.Port)
+	}
+	weight := int32(1)
+	if backendRef.Weight != nil {
+		weight = *backendRef.Weight
+	}
+	return fmt.Sprintf("%s (weight %d)", description, weight)
+}
+
+// writeBackends writes the Services the attached routes send traffic to, with their endpoints
+func writeBackends(out io.Writer, resources *types.ResourceCollection, routes []attachedRoute) {
+	type backend struct {
+		namespace, name string
+		routes          map[string]bool
+	}
+	backends := make(map[string]*backend)
+	for _, attached := range routes {
+		route := attached.route
+		if len(attached.listeners) == 0 {
+			continue
+		}
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				if (backendRef.Group != nil && *backendRef.Group != "") || (backendRef.Kind != nil && *backendRef.Kind != "Service") {
+					continue
+				}
+				namespace := route.Namespace
+				if backendRef.Namespace != nil {
+					namespace = string(*backendRef.Namespace)
+				}
+				key := namespace + "/" + string(backendRef.Name)
+				if backends[key] == nil {
+					backends[key] = &backend{namespace: namespace, name: string(backendRef.Name), routes: make(map[string]bool)}
+				}
+				backends[key].routes[route.Namespace+"/"+route.Name] = true
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\n## Backends\n\n")
+	if len(backends) == 0 {
+		fmt.Fprintf(out, "No attached route has a Service backend.\n")
+		return
+	}
+	fmt.Fprintf(out, "| Service | Type | Ports | Ready endpoints | Routes |\n")
+	fmt.Fprintf(out, "|---------|------|-------|-----------------|--------|\n")
+	for _, key := range sortedKeys(backends) {
+		b := backends[key]
+		routeNames := sortedKeys(b.routes)
+		svc := analysis.FindService(resources, b.namespace, b.name)
+		if svc == nil {
+			fmt.Fprintf(out, "| %s | not found | | | %s |\n", cell(key), cell(strings.Join(routeNames, ", ")))
+			continue
+		}
+		serviceType := svc.Spec.Type
+		if serviceType == "" {
+			serviceType = corev1.ServiceTypeClusterIP
+		}
+		var ports []string
+		for _, port := range svc.Spec.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, protocol))
+		}
+		endpoints := "no EndpointSlices"
+		if slices := analysis.ServiceEndpointSlices(resources, svc); len(slices) > 0 {
+			ready, total := analysis.ReadyEndpoints(slices)
+			endpoints = fmt.Sprintf("%d/%d", ready, total)
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s | %s |\n", cell(key), serviceType, cell(strings.Join(ports, ", ")), endpoints,
+			cell(strings.Join(routeNames, ", ")))
+	}
+}
+
+// writeDNSRecords writes the DNS records that publish the Gateway
+func writeDNSRecords(out io.Writer, resources *types.ResourceCollection, records []dnssource.Record, gw *gatewayv1.Gateway) {
+	fmt.Fprintf(out, "\n## DNS Records\n\n")
+	var rows []string
+	for _, record := range records {
+		if record.Gateway == nil || record.Gateway.UID != gw.UID {
+			continue
+		}
+		status := "current"
+		if stale, current := analysis.StaleTargets(resources, gw, record.Targets); len(stale) > 0 {
+			status = fmt.Sprintf("stale, the load balancer is at %s", strings.Join(current, ", "))
+		}
+		source := fmt.Sprintf("%s %s/%s (%s)", record.Kind, record.Namespace, record.Name, record.Provider)
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s | %s |\n", cell(record.DNSName), record.RecordType,
+			cell(strings.Join(record.Targets, ", ")), cell(source), cell(status)))
+	}
+	if len(rows) == 0 {
+		fmt.Fprintf(out, "No DNS record publishes this Gateway.\n")
+		return
+	}
+	fmt.Fprintf(out, "| DNS name | Type | Targets | Source | Status |\n")
+	fmt.Fprintf(out, "|----------|------|---------|--------|--------|\n")
+	for _, row := range rows {
+		fmt.Fprint(out, row)
+	}
+}
+
+// writeReferenceGrants writes the cross-namespace references of the Gateway's certificates and of
+// its routes' backends, with the ReferenceGrants that permit them or a note that none does
+func writeReferenceGrants(out io.Writer, resources *types.ResourceCollection, gw *gatewayv1.Gateway, routes []attachedRoute) {
+	references := make(map[string][]string) // reference -> namespace/name of the grants permitting it
+	reference := func(from, fromKind, fromNamespace, toKind, toNamespace, toName string) {
+		key := fmt.Sprintf("%s → %s %s/%s", from, toKind, toNamespace, toName)
+		if _, seen := references[key]; seen {
+			return
+		}
+		grants := []string{}
+		for _, grant := range analysis.PermittingGrants(resources, fromKind, fromNamespace, toKind, toNamespace, toName) {
+			grants = append(grants, toNamespace+"/"+grant)
+		}
+		references[key] = grants
+	}
+
+	for _, listener := range gw.Spec.Listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, ref := range listener.TLS.CertificateRefs {
+			if ref.Namespace != nil && string(*ref.Namespace) != gw.Namespace {
+				reference(fmt.Sprintf("Gateway %s/%s", gw.Namespace, gw.Name), "Gateway", gw.Namespace, "Secret", string(*ref.Namespace), string(ref.Name))
+			}
+		}
+	}
+	for _, attached := range routes {
+		route := attached.route
+		for _, rule := range route.Spec.Rules {
+			for _, backendRef := range rule.BackendRefs {
+				if backendRef.Namespace != nil && string(*backendRef.Namespace) != route.Namespace {
+					kind := "Service"
+					if backendRef.Kind != nil {
+						kind = string(*backendRef.Kind)
+					}
+					reference(fmt.Sprintf("HTTPRoute %s/%s", route.Namespace, route.Name), "HTTPRoute", route.Namespace, kind, string(*backendRef.Namespace), string(backendRef.Name))
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\n## ReferenceGrants\n\n")
+	if len(references) == 0 {
+		fmt.Fprintf(out, "No ReferenceGrant is needed or used.\n")
+		return
+	}
+	fmt.Fprintf(out, "| Reference | ReferenceGrant |\n")
+	fmt.Fprintf(out, "|-----------|----------------|\n")
+	for _, key := range sortedKeys(references) {
+		grants := strings.Join(references[key], "\n")
+		if grants == "" {
+			grants = "missing ReferenceGrant"
+		}
+		fmt.Fprintf(out, "| %s | %s |\n", cell(key), cell(grants))
+	}
+}
+
+// writeFindings writes the findings about the nodes of the Gateway's subgraph, most serious first
+func writeFindings(out io.Writer, subgraph *types.Graph) {
+	var findings []types.Finding
+	for _, node := range subgraph.Nodes {
+		findings = append(findings, node.Findings...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity.Rank() > findings[j].Severity.Rank()
+	})
+
+	fmt.Fprintf(out, "\n## Diagnostics\n\n")
+	if len(findings) == 0 {
+		fmt.Fprintf(out, "No findings.\n")
+		return
+	}
+	fmt.Fprintf(out, "| Severity | Resource | Finding | Rule |\n")
+	fmt.Fprintf(out, "|----------|----------|---------|------|\n")
+	for _, finding := range findings {
+		resource := finding.Resource.Kind + " " + finding.Resource.Name
+		if finding.Resource.Namespace != "" {
+			resource = fmt.Sprintf("%s %s/%s", finding.Resource.Kind, finding.Resource.Namespace, finding.Resource.Name)
+		}
+		if finding.Resource.Listener != "" {
+			resource += " listener " + finding.Resource.Listener
+		}
+		message := finding.Message
+		if finding.Remediation != "" {
+			message += "\n" + finding.Remediation
+		}
+		fmt.Fprintf(out, "| %s | %s | %s | %s |\n", finding.Severity, cell(resource), cell(message), finding.Rule)
+	}
+}
+
+// cell escapes text for a Markdown table cell
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// orNone returns s, or "none" when it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// httpsGateway returns the Gateway infra/gw with one HTTPS listener whose certificate lives in
+// certNamespace
+func httpsGateway(certNamespace string) gatewayv1.Gateway {
+	listener := testutil.Listener("https", gatewayv1.HTTPSProtocolType, 443, "")
+	listener.TLS = &gatewayv1.GatewayTLSConfig{CertificateRefs: []gatewayv1.SecretObjectReference{{
+		Name:      "cert",
+		Namespace: testutil.Ptr(gatewayv1.Namespace(certNamespace)),
+	}}}
+	return testutil.Gateway("infra", "gw", listener)
+}
+
+// backendRoute returns the HTTPRoute app/r1 attached to infra/gw with a backend in backendNamespace
+func backendRoute(backendNamespace string) gatewayv1.HTTPRoute {
+	route := testutil.HTTPRoute("app", "r1", testutil.ParentRef("infra", "gw"))
+	route.Spec.Rules = []gatewayv1.HTTPRouteRule{{BackendRefs: []gatewayv1.HTTPBackendRef{testutil.BackendRef(backendNamespace, "svc2", 80)}}}
+	return route
+}
+
+func TestWriteReferenceGrants(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources types.ResourceCollection
+		want      []string
+		wantNot   []string
+	}{
+		{
+			name: "same-namespace references need no grant",
+			resources: types.ResourceCollection{
+				Gateways:   []gatewayv1.Gateway{httpsGateway("infra")},
+				HTTPRoutes: []gatewayv1.HTTPRoute{backendRoute("app")},
+			},
+			want:    []string{"No ReferenceGrant is needed or used."},
+			wantNot: []string{"| Reference |"},
+		},
+		{
+			name: "cross-namespace backend without a grant",
+			resources: types.ResourceCollection{
+				Gateways:   []gatewayv1.Gateway{httpsGateway("infra")},
+				HTTPRoutes: []gatewayv1.HTTPRoute{backendRoute("other")},
+			},
+			want:    []string{"| HTTPRoute app/r1 → Service other/svc2 | missing ReferenceGrant |"},
+			wantNot: []string{"No ReferenceGrant is needed"},
+		},
+		{
+			name: "cross-namespace backend with a grant",
+			resources: types.ResourceCollection{
+				Gateways:        []gatewayv1.Gateway{httpsGateway("infra")},
+				HTTPRoutes:      []gatewayv1.HTTPRoute{backendRoute("other")},
+				ReferenceGrants: []gatewayv1beta1.ReferenceGrant{testutil.ReferenceGrant("other", "allow-app", "HTTPRoute", "app", "Service")},
+			},
+			want:    []string{"| HTTPRoute app/r1 → Service other/svc2 | other/allow-app |"},
+			wantNot: []string{"missing ReferenceGrant", "No ReferenceGrant is needed"},
+		},
+		{
+			name: "grant in the wrong namespace does not count",
+			resources: types.ResourceCollection{
+				Gateways:        []gatewayv1.Gateway{httpsGateway("infra")},
+				HTTPRoutes:      []gatewayv1.HTTPRoute{backendRoute("other")},
+				ReferenceGrants: []gatewayv1beta1.ReferenceGrant{testutil.ReferenceGrant("app", "allow-app", "HTTPRoute", "app", "Service")},
+			},
+			want: []string{"| HTTPRoute app/r1 → Service other/svc2 | missing ReferenceGrant |"},
+		},
+		{
+			name: "cross-namespace certificate without a grant",
+			resources: types.ResourceCollection{
+				Gateways:   []gatewayv1.Gateway{httpsGateway("certs")},
+				HTTPRoutes: []gatewayv1.HTTPRoute{backendRoute("app")},
+			},
+			want: []string{"| Gateway infra/gw → Secret certs/cert | missing ReferenceGrant |"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := &tt.resources.Gateways[0]
+			var out strings.Builder
+			writeReferenceGrants(&out, &tt.resources, gw, attachedRoutes(&tt.resources, gw))
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, out.String())
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(out.String(), wantNot) {
+					t.Errorf("output contains %q:\n%s", wantNot, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package simulate
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// applyFilter describes a filter and applies its effect to the forwarded request. It returns the
+// redirect a RequestRedirect filter answers with.
+func applyFilter(filter gatewayv1.HTTPRouteFilter, req Request, match gatewayv1.HTTPRouteMatch, forwarded *Forwarded) (Filter, *Redirect) {
+	applied := Filter{Type: filter.Type}
+
+	switch filter.Type {
+	case gatewayv1.HTTPRouteFilterRequestHeaderModifier:
+		if filter.RequestHeaderModifier != nil {
+			applied.Description = describeHeaderModifier(filter.RequestHeaderModifier)
+			modifyHeaders(filter.RequestHeaderModifier, forwarded.Headers)
+		}
+	case gatewayv1.HTTPRouteFilterResponseHeaderModifier:
+		if filter.ResponseHeaderModifier != nil {
+			applied.Description = "response: " + describeHeaderModifier(filter.ResponseHeaderModifier)
+		}
+	case gatewayv1.HTTPRouteFilterRequestMirror:
+		if mirror := filter.RequestMirror; mirror != nil {
+			applied.Description = "mirror to " + describeBackendRef(mirror.BackendRef)
+		}
+	case gatewayv1.HTTPRouteFilterURLRewrite:
+		if rewrite := filter.URLRewrite; rewrite != nil {
+			var changes []string
+			if rewrite.Hostname != nil {
+				forwarded.Hostname = string(*rewrite.Hostname)
+				changes = append(changes, "hostname to "+forwarded.Hostname)
+			}
+			if rewrite.Path != nil {
+				forwarded.Path = rewritePath(rewrite.Path, match, forwarded.Path)
+				changes = append(changes, "path to "+forwarded.Path)
+			}
+			applied.Description = "rewrite " + strings.Join(changes, " and ")
+		}
+	case gatewayv1.HTTPRouteFilterRequestRedirect:
+		if redirect := filter.RequestRedirect; redirect != nil {
+			result := buildRedirect(redirect, req, match)
+			applied.Description = fmt.Sprintf("redirect with %d to %s", result.StatusCode, result.Location)
+			return applied, result
+		}
+	case gatewayv1.HTTPRouteFilterExtensionRef:
+		if ref := filter.ExtensionRef; ref != nil {
+			applied.Description = fmt.Sprintf("implementation-specific extension %s %s/%s", ref.Kind, ref.Group, ref.Name)
+		}
+	}
+
+	if applied.Description == "" {
+		applied.Description = "no configuration"
+	}
+	return applied, nil
+}
+
+// describeHeaderModifier formats the header changes of a filter
+func describeHeaderModifier(modifier *gatewayv1.HTTPHeaderFilter) string {
+	var parts []string
+	for _, header := range modifier.Set {
+		parts = append(parts, fmt.Sprintf("set %s: %s", header.Name, header.Value))
+	}
+	for _, header := range modifier.Add {
+		parts = append(parts, fmt.Sprintf("add %s: %s", header.Name, header.Value))
+	}
+	if len(modifier.Remove) > 0 {
+		parts = append(parts, "remove "+strings.Join(modifier.Remove, ", "))
+	}
+	if len(parts) == 0 {
+		return "no header changes"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// modifyHeaders applies a header modifier to request headers. Header names are canonicalized so
+// that the result shows one entry per header.
+func modifyHeaders(modifier *gatewayv1.HTTPHeaderFilter, headers map[string]string) {
+	canonical := func(name string) string {
+		return http.CanonicalHeaderKey(name)
+	}
+	for name, value := range headers {
+		if key := canonical(name); key != name {
+			delete(headers, name)
+			headers[key] = value
+		}
+	}
+
+	for _, header := range modifier.Set {
+		headers[canonical(string(header.Name))] = header.Value
+	}
+	for _, header := range modifier.Add {
+		key := canonical(string(header.Name))
+		if existing, ok := headers[key]; ok {
+			headers[key] = existing + "," + header.Value
+		} else {
+			headers[key] = header.Value
+		}
+	}
+	for _, name := range modifier.Remove {
+		delete(headers, canonical(name))
+	}
+}
+
+// rewritePath applies a path modifier. ReplacePrefixMatch swaps the prefix the rule matched.
+func rewritePath(modifier *gatewayv1.HTTPPathModifier, match gatewayv1.HTTPRouteMatch, path string) string {
+	switch modifier.Type {
+	case gatewayv1.FullPathHTTPPathModifier:
+		if modifier.ReplaceFullPath != nil {
+			return *modifier.ReplaceFullPath
+		}
+	case gatewayv1.PrefixMatchHTTPPathModifier:
+		matchType, value := analysis.PathMatch(match)
+		if modifier.ReplacePrefixMatch == nil || matchType != gatewayv1.PathMatchPathPrefix {
+			return path
+		}
+		prefix := strings.TrimSuffix(value, "/")
+		rest := strings.TrimPrefix(path, prefix)
+		replacement := strings.TrimSuffix(*modifier.ReplacePrefixMatch, "/")
+		if rest == "" && replacement == "" {
+			return "/"
+		}
+		if replacement == "" && !strings.HasPrefix(rest, "/") {
+			rest = "/" + rest
+		}
+		return replacement + rest
+	}
+	return path
+}
+
+// buildRedirect computes the Location and status code of a RequestRedirect filter
+func buildRedirect(redirect *gatewayv1.HTTPRequestRedirectFilter, req Request, match gatewayv1.HTTPRouteMatch) *Redirect {
+	scheme := req.Scheme
+	if redirect.Scheme != nil {
+		scheme = *redirect.Scheme
+	}
+	host := req.Hostname
+	if redirect.Hostname != nil {
+		host = string(*redirect.Hostname)
+	}
+	path := req.Path
+	if redirect.Path != nil {
+		path = rewritePath(redirect.Path, match, path)
+	}
+
+	// Without an explicit port, a scheme change implies the scheme's well-known port
+	port := req.Port
+	if redirect.Port != nil {
+		port = int32(*redirect.Port)
+	} else if redirect.Scheme != nil {
+		port = 80
+		if scheme == "https" {
+			port = 443
+		}
+	}
+
+	location := scheme + "://" + host
+	if !(scheme == "http" && port == 80) && !(scheme == "https" && port == 443) {
+		location += fmt.Sprintf(":%d", port)
+	}
+	location += path
+
+	status := http.StatusFound
+	if redirect.StatusCode != nil {
+		status = *redirect.StatusCode
+	}
+	return &Redirect{Location: location, StatusCode: status}
+}
+
+// weighBackends resolves the backendRefs of a rule and computes each one's share of the traffic
+func weighBackends(resources *types.ResourceCollection, route *gatewayv1.HTTPRoute, rule gatewayv1.HTTPRouteRule) []Backend {
+	var backends []Backend
+	var total int32
+
+	for _, ref := range rule.BackendRefs {
+		backend := Backend{
+			Kind:      "Service",
+			Namespace: route.Namespace,
+			Name:      string(ref.Name),
+			Weight:    1,
+		}
+		if ref.Kind != nil {
+			backend.Kind = string(*ref.Kind)
+		}
+		if ref.Namespace != nil {
+			backend.Namespace = string(*ref.Namespace)
+		}
+		if ref.Port != nil {
+			backend.Port = int32(*ref.Port)
+		}
+		if ref.Weight != nil {
+			backend.Weight = *ref.Weight
+		}
+		for _, filter := range ref.Filters {
+			applied, _ := applyFilter(filter, Request{}, gatewayv1.HTTPRouteMatch{}, &Forwarded{Headers: map[string]string{}})
+			backend.Filters = append(backend.Filters, applied)
+		}
+
+		isService := (ref.Group == nil || *ref.Group == "") && backend.Kind == "Service"
+		if isService {
+			backend.Problem = "the Service does not exist"
+			if svc := analysis.FindService(resources, backend.Namespace, backend.Name); svc != nil {
+				backend.NodeID = string(svc.UID)
+				backend.Problem = "the Service does not expose the port"
+				for _, port := range svc.Spec.Ports {
+					if port.Port == backend.Port {
+						backend.Problem = ""
+					}
+				}
+			}
+			// The Gateway refuses a cross-namespace reference no ReferenceGrant permits
+			if backend.Problem == "" && backend.Namespace != route.Namespace &&
+				len(analysis.PermittingGrants(resources, "HTTPRoute", route.Namespace, "Service", backend.Namespace, backend.Name)) == 0 {
+				backend.Problem = "no ReferenceGrant permits the reference"
+			}
+		}
+
+		total += backend.Weight
+		backends = append(backends, backend)
+	}
+
+	for i := range backends {
+		if total > 0 {
+			backends[i].Percent = float64(backends[i].Weight) * 100 / float64(total)
+		}
+	}
+	sort.SliceStable(backends, func(i, j int) bool {
+		return backends[i].Weight > backends[j].Weight
+	})
+	return backends
+}
+
+// describeBackendRef formats a backend reference
+func describeBackendRef(ref gatewayv1.BackendObjectReference) string {
+	kind := "Service"
+	if ref.Kind != nil {
+		kind = string(*ref.Kind)
+	}
+	name := string(ref.Name)
+	if ref.Namespace != nil {
+		name = string(*ref.Namespace) + "/" + name
+	}
+	if ref.Port != nil {
+		name += fmt.Sprintf(":%d", *ref.Port)
+	}
+	return kind + " " + name
+}
@@ -0,0 +1,132 @@
+package simulate
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// candidate is a route rule match that applies to the request
+type candidate struct {
+	analysis.RouteMatch
+	hostScore int // Specificity of the route hostname that matched, 0 without hostnames
+}
+
+// collectCandidates returns every match of the routes attached to the listener that applies to the
+// request
+func collectCandidates(resources *types.ResourceCollection, gw *gatewayv1.Gateway, listener int, req Request) []candidate {
+	var candidates []candidate
+
+	for i := range resources.HTTPRoutes {
+		route := &resources.HTTPRoutes[i]
+		if !analysis.AttachesTo(resources, route, gw, listener) {
+			continue
+		}
+
+		hostScore := 0
+		if len(route.Spec.Hostnames) > 0 {
+			matched := false
+			for _, routeHostname := range route.Spec.Hostnames {
+				if score, ok := hostname.Specificity(string(routeHostname), req.Hostname); ok && score > hostScore {
+					hostScore, matched = score, true
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		for _, match := range analysis.RouteMatches(route) {
+			if matchesRequest(match.Match, req) {
+				candidates = append(candidates, candidate{RouteMatch: match, hostScore: hostScore})
+			}
+		}
+	}
+
+	return candidates
+}
+
+// sortCandidates orders matches by the most specific route hostname, then by Gateway API match
+// precedence
+func sortCandidates(candidates []candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.hostScore != b.hostScore {
+			return a.hostScore > b.hostScore
+		}
+		return analysis.MatchPrecedes(a.RouteMatch, b.RouteMatch)
+	})
+}
+
+// matchesRequest reports whether every condition of a match holds for the request
+func matchesRequest(match gatewayv1.HTTPRouteMatch, req Request) bool {
+	matchType, value := analysis.PathMatch(match)
+	if !matchesPath(matchType, value, req.Path) {
+		return false
+	}
+
+	if match.Method != nil && string(*match.Method) != req.Method {
+		return false
+	}
+
+	for _, header := range match.Headers {
+		value, ok := lookupHeader(req.Headers, string(header.Name))
+		if !ok || !matchesValue(header.Type == nil || *header.Type == gatewayv1.HeaderMatchExact, header.Value, value) {
+			return false
+		}
+	}
+
+	for _, param := range match.QueryParams {
+		value, ok := req.QueryParams[string(param.Name)]
+		if !ok || !matchesValue(param.Type == nil || *param.Type == gatewayv1.QueryParamMatchExact, param.Value, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesPath matches a request path. A prefix matches whole path elements, so /foo matches /foo
+// and /foo/bar but not /foobar.
+func matchesPath(matchType gatewayv1.PathMatchType, value, path string) bool {
+	switch matchType {
+	case gatewayv1.PathMatchExact:
+		return path == value
+	case gatewayv1.PathMatchRegularExpression:
+		return matchesRegex(value, path)
+	default:
+		prefix := strings.TrimSuffix(value, "/")
+		return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+}
+
+// matchesValue matches a header or query parameter value exactly or against a regular expression
+func matchesValue(exact bool, expected, value string) bool {
+	if exact {
+		return expected == value
+	}
+	return matchesRegex(expected, value)
+}
+
+// matchesRegex reports whether a regular expression matches the whole value; invalid expressions
+// match nothing
+func matchesRegex(expression, value string) bool {
+	re, err := regexp.Compile("^(?:" + expression + ")$")
+	return err == nil && re.MatchString(value)
+}
+
+// lookupHeader finds a header by case-insensitive name
+func lookupHeader(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,125 @@
+package simulate
+
+import (
+	"testing"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/testutil"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func TestMatchesPath(t *testing.T) {
+	tests := []struct {
+		matchType gatewayv1.PathMatchType
+		value     string
+		path      string
+		want      bool
+	}{
+		{gatewayv1.PathMatchPathPrefix, "/", "/anything", true},
+		{gatewayv1.PathMatchPathPrefix, "/api", "/api", true},
+		{gatewayv1.PathMatchPathPrefix, "/api", "/api/", true},
+		{gatewayv1.PathMatchPathPrefix, "/api", "/api/v1", true},
+		{gatewayv1.PathMatchPathPrefix, "/api", "/apix", false},
+		{gatewayv1.PathMatchPathPrefix, "/api/", "/api", true},
+		{gatewayv1.PathMatchPathPrefix, "/api/", "/apix", false},
+		{gatewayv1.PathMatchPathPrefix, "/api/v1", "/api", false},
+		{gatewayv1.PathMatchExact, "/api", "/api", true},
+		{gatewayv1.PathMatchExact, "/api", "/api/", false},
+		{gatewayv1.PathMatchExact, "/api", "/api/v1", false},
+		{gatewayv1.PathMatchRegularExpression, "/api/v[0-9]+", "/api/v12", true},
+		{gatewayv1.PathMatchRegularExpression, "/api/v[0-9]+", "/api/v12/users", false},
+		{gatewayv1.PathMatchRegularExpression, "/api/v[0-9]+", "/x/api/v1", false},
+		{gatewayv1.PathMatchRegularExpression, "/api/v1|/api/v2", "/api/v2", true},
+		{gatewayv1.PathMatchRegularExpression, "/api/(", "/api/(", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesPath(tt.matchType, tt.value, tt.path); got != tt.want {
+			t.Errorf("matchesPath(%s, %q, %q) = %v, want %v", tt.matchType, tt.value, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesRequest(t *testing.T) {
+	prefix := testutil.PathMatch(gatewayv1.PathMatchPathPrefix, "/api")
+	withMethod := prefix
+	withMethod.Method = testutil.Ptr(gatewayv1.HTTPMethodPost)
+	withHeader := prefix
+	withHeader.Headers = []gatewayv1.HTTPHeaderMatch{{Name: "X-Version", Value: "2"}}
+	withHeaderRegex := prefix
+	withHeaderRegex.Headers = []gatewayv1.HTTPHeaderMatch{{Type: testutil.Ptr(gatewayv1.HeaderMatchRegularExpression), Name: "x-version", Value: "[0-9]+"}}
+	withQuery := prefix
+	withQuery.QueryParams = []gatewayv1.HTTPQueryParamMatch{{Name: "debug", Value: "true"}}
+
+	tests := []struct {
+		name  string
+		match gatewayv1.HTTPRouteMatch
+		req   Request
+		want  bool
+	}{
+		{"path only", prefix, Request{Path: "/api/users", Method: "GET"}, true},
+		{"path mismatch", prefix, Request{Path: "/apix", Method: "GET"}, false},
+		{"method matches", withMethod, Request{Path: "/api", Method: "POST"}, true},
+		{"method mismatch", withMethod, Request{Path: "/api", Method: "GET"}, false},
+		{"header names are case-insensitive", withHeader, Request{Path: "/api", Method: "GET", Headers: map[string]string{"x-version": "2"}}, true},
+		{"header value mismatch", withHeader, Request{Path: "/api", Method: "GET", Headers: map[string]string{"X-Version": "3"}}, false},
+		{"missing header", withHeader, Request{Path: "/api", Method: "GET"}, false},
+		{"header regex", withHeaderRegex, Request{Path: "/api", Method: "GET", Headers: map[string]string{"X-Version": "42"}}, true},
+		{"header regex matches the whole value", withHeaderRegex, Request{Path: "/api", Method: "GET", Headers: map[string]string{"X-Version": "v42"}}, false},
+		{"query parameter", withQuery, Request{Path: "/api", Method: "GET", QueryParams: map[string]string{"debug": "true"}}, true},
+		{"missing query parameter", withQuery, Request{Path: "/api", Method: "GET"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesRequest(tt.match, tt.req); got != tt.want {
+				t.Errorf("matchesRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortCandidates(t *testing.T) {
+	route := &gatewayv1.HTTPRoute{}
+	route.Namespace, route.Name = "app", "r"
+	exact := analysis.RouteMatch{Route: route, Rule: 0, Match: testutil.PathMatch(gatewayv1.PathMatchExact, "/api")}
+	longPrefix := analysis.RouteMatch{Route: route, Rule: 1, Match: testutil.PathMatch(gatewayv1.PathMatchPathPrefix, "/api")}
+	shortPrefix := analysis.RouteMatch{Route: route, Rule: 2, Match: testutil.PathMatch(gatewayv1.PathMatchPathPrefix, "/")}
+
+	tests := []struct {
+		name       string
+		candidates []candidate
+		wantRules  []int
+	}{
+		{
+			name: "match precedence for the same hostname",
+			candidates: []candidate{
+				{RouteMatch: shortPrefix, hostScore: 10},
+				{RouteMatch: longPrefix, hostScore: 10},
+				{RouteMatch: exact, hostScore: 10},
+			},
+			wantRules: []int{0, 1, 2},
+		},
+		{
+			name: "a more specific hostname wins over match precedence",
+			candidates: []candidate{
+				{RouteMatch: exact, hostScore: 13},
+				{RouteMatch: shortPrefix, hostScore: 1015},
+				{RouteMatch: longPrefix, hostScore: 0},
+			},
+			wantRules: []int{2, 0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortCandidates(tt.candidates)
+			for i, want := range tt.wantRules {
+				if got := tt.candidates[i].Rule; got != want {
+					t.Errorf("candidate %d is rule %d, want rule %d", i, got, want)
+				}
+			}
+		})
+	}
+}
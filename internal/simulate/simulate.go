@@ -0,0 +1,312 @@
+package simulate
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/hostname"
+	"gwapi-graph/internal/types"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Request describes the HTTP request to route
+type Request struct {
+	Hostname    string            `json:"hostname"`
+	Port        int32             `json:"port,omitempty"`   // Defaults to 80 for http and 443 for https
+	Scheme      string            `json:"scheme,omitempty"` // http or https; defaults to https on port 443, http otherwise
+	Path        string            `json:"path,omitempty"`   // May include a query string; defaults to /
+	Method      string            `json:"method,omitempty"` // Defaults to GET
+	Headers     map[string]string `json:"headers,omitempty"`
+	QueryParams map[string]string `json:"queryParams,omitempty"`
+	Gateway     string            `json:"gateway,omitempty"` // namespace/name, to only consider one Gateway
+}
+
+// Simulation is the outcome of routing a request through every Gateway that listens on its port
+type Simulation struct {
+	Request Request  `json:"request"`
+	Results []Result `json:"results"`
+}
+
+// Result describes how one Gateway routes the request
+type Result struct {
+	Gateway   types.ResourceRef         `json:"gateway"`
+	Listener  string                    `json:"listener,omitempty"`
+	Route     *types.ResourceRef        `json:"route,omitempty"` // Nil when no route rule matches
+	Rule      int                       `json:"rule"`            // Index of the winning rule in the route
+	Match     *gatewayv1.HTTPRouteMatch `json:"match,omitempty"` // The match that selected the rule
+	Filters   []Filter                  `json:"filters,omitempty"`
+	Redirect  *Redirect                 `json:"redirect,omitempty"`
+	Forwarded *Forwarded                `json:"forwarded,omitempty"` // The request as backends receive it
+	Backends  []Backend                 `json:"backends,omitempty"`
+	Path      []string                  `json:"path"`             // Graph node IDs from the Gateway to the backends
+	Trace     []string                  `json:"trace"`            // The decisions taken, in order
+	Reason    string                    `json:"reason,omitempty"` // Why the request is not forwarded, when it is not
+}
+
+// Filter is a filter applied to the request
+type Filter struct {
+	Type        gatewayv1.HTTPRouteFilterType `json:"type"`
+	Description string                        `json:"description"`
+}
+
+// Redirect is the response of a RequestRedirect filter
+type Redirect struct {
+	Location   string `json:"location"`
+	StatusCode int    `json:"statusCode"`
+}
+
+// Forwarded is the request after the filters modified it
+type Forwarded struct {
+	Hostname string            `json:"hostname"`
+	Path     string            `json:"path"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// Backend is a backendRef of the winning rule with its share of the traffic
+type Backend struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Port      int32    `json:"port,omitempty"`
+	Weight    int32    `json:"weight"`
+	Percent   float64  `json:"percent"`
+	NodeID    string   `json:"nodeId,omitempty"`
+	Filters   []Filter `json:"filters,omitempty"`
+	Problem   string   `json:"problem,omitempty"` // Why the backend cannot serve traffic
+}
+
+// Normalize validates the request and fills in defaults
+func (r *Request) Normalize() error {
+	host := strings.ToLower(strings.TrimSpace(r.Hostname))
+	if h, p, found := strings.Cut(host, ":"); found {
+		port, err := strconv.ParseInt(p, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid port in hostname %q", r.Hostname)
+		}
+		host = h
+		if r.Port == 0 {
+			r.Port = int32(port)
+		}
+	}
+	r.Hostname = strings.TrimSuffix(host, ".")
+	if r.Hostname == "" {
+		return fmt.Errorf("hostname is required")
+	}
+
+	r.Scheme = strings.ToLower(r.Scheme)
+	if r.Scheme == "" {
+		r.Scheme = "http"
+		if r.Port == 443 {
+			r.Scheme = "https"
+		}
+	}
+	if r.Scheme != "http" && r.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q, expected http or https", r.Scheme)
+	}
+	if r.Port == 0 {
+		r.Port = 80
+		if r.Scheme == "https" {
+			r.Port = 443
+		}
+	}
+
+	if r.Path == "" {
+		r.Path = "/"
+	}
+	path, rawQuery, _ := strings.Cut(r.Path, "?")
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+	r.Path = path
+	if rawQuery != "" {
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return fmt.Errorf("invalid query string: %w", err)
+		}
+		if r.QueryParams == nil {
+			r.QueryParams = make(map[string]string)
+		}
+		for name, values := range query {
+			if _, ok := r.QueryParams[name]; !ok && len(values) > 0 {
+				r.QueryParams[name] = values[0]
+			}
+		}
+	}
+
+	r.Method = strings.ToUpper(r.Method)
+	if r.Method == "" {
+		r.Method = "GET"
+	}
+	return nil
+}
+
+// Run routes a normalized request through the Gateways that have a listener on its port
+func Run(resources *types.ResourceCollection, req Request) *Simulation {
+	simulation := &Simulation{Request: req, Results: []Result{}}
+
+	gateways := make([]*gatewayv1.Gateway, 0, len(resources.Gateways))
+	for i := range resources.Gateways {
+		gw := &resources.Gateways[i]
+		if req.Gateway != "" && req.Gateway != gw.Namespace+"/"+gw.Name {
+			continue
+		}
+		gateways = append(gateways, gw)
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].Namespace+"/"+gateways[i].Name < gateways[j].Namespace+"/"+gateways[j].Name
+	})
+
+	for _, gw := range gateways {
+		if !listensOn(gw, req.Port) {
+			continue
+		}
+		simulation.Results = append(simulation.Results, routeThrough(resources, gw, req))
+	}
+	return simulation
+}
+
+// listensOn reports whether a Gateway has a listener on a port
+func listensOn(gw *gatewayv1.Gateway, port int32) bool {
+	for _, listener := range gw.Spec.Listeners {
+		if int32(listener.Port) == port {
+			return true
+		}
+	}
+	return false
+}
+
+// routeThrough routes the request through one Gateway
+func routeThrough(resources *types.ResourceCollection, gw *gatewayv1.Gateway, req Request) Result {
+	result := Result{
+		Gateway: types.ResourceRef{Kind: "Gateway", Namespace: gw.Namespace, Name: gw.Name},
+		Path:    []string{string(gw.UID)},
+		Trace:   []string{},
+	}
+	tracef := func(format string, args ...interface{}) {
+		result.Trace = append(result.Trace, fmt.Sprintf(format, args...))
+	}
+
+	index := selectListener(gw, req)
+	if index < 0 {
+		result.Reason = fmt.Sprintf("no %s listener on port %d accepts hostname %s", strings.ToUpper(req.Scheme), req.Port, req.Hostname)
+		tracef("Gateway %s/%s: %s", gw.Namespace, gw.Name, result.Reason)
+		return result
+	}
+	listener := gw.Spec.Listeners[index]
+	result.Listener = string(listener.Name)
+	result.Path = append(result.Path, analysis.ListenerID(gw, index))
+	tracef("Listener %q (%s, port %d, hostname %s) is the most specific match", listener.Name, listener.Protocol, listener.Port, hostnameOrAny(listener.Hostname))
+
+	candidates := collectCandidates(resources, gw, index, req)
+	if len(candidates) == 0 {
+		result.Reason = fmt.Sprintf("no HTTPRoute attached to listener %q matches the request; the Gateway responds with 404", listener.Name)
+		tracef("%s", result.Reason)
+		return result
+	}
+	sortCandidates(candidates)
+
+	winner := candidates[0]
+	route := winner.Route
+	result.Route = &types.ResourceRef{Kind: "HTTPRoute", Namespace: route.Namespace, Name: route.Name}
+	result.Rule = winner.Rule
+	result.Match = &winner.Match
+	result.Path = append(result.Path, string(route.UID))
+	tracef("%d route rule match(es) apply; HTTPRoute %s/%s rule %d wins with %s", len(candidates), route.Namespace, route.Name, winner.Rule, analysis.DescribeRouteMatch(winner.Match))
+	for _, other := range candidates[1:] {
+		if other.Route == route && other.Rule == winner.Rule {
+			continue
+		}
+		tracef("Outranked: HTTPRoute %s/%s rule %d (%s)", other.Route.Namespace, other.Route.Name, other.Rule, analysis.DescribeRouteMatch(other.Match))
+	}
+
+	rule := route.Spec.Rules[winner.Rule]
+	forwarded := &Forwarded{Hostname: req.Hostname, Path: req.Path, Headers: copyHeaders(req.Headers)}
+	for _, filter := range rule.Filters {
+		applied, redirect := applyFilter(filter, req, winner.Match, forwarded)
+		result.Filters = append(result.Filters, applied)
+		tracef("Filter %s: %s", applied.Type, applied.Description)
+		if redirect != nil && result.Redirect == nil {
+			result.Redirect = redirect
+		}
+	}
+	if result.Redirect != nil {
+		result.Reason = fmt.Sprintf("the request is redirected to %s with status %d", result.Redirect.Location, result.Redirect.StatusCode)
+		return result
+	}
+	result.Forwarded = forwarded
+
+	result.Backends = weighBackends(resources, route, rule)
+	served := false
+	for _, backend := range result.Backends {
+		if backend.NodeID != "" {
+			result.Path = append(result.Path, backend.NodeID)
+		}
+		if backend.Problem == "" && backend.Weight > 0 {
+			served = true
+		}
+		tracef("Backend %s %s/%s:%d receives %.1f%% of requests%s", backend.Kind, backend.Namespace, backend.Name, backend.Port, backend.Percent, problemSuffix(backend.Problem))
+	}
+	if !served {
+		result.Reason = "no backend of the winning rule can serve the request; the Gateway responds with 500"
+		tracef("%s", result.Reason)
+	}
+	return result
+}
+
+// selectListener returns the index of the listener that receives the request, or -1. A listener
+// with an exact hostname takes precedence over a wildcard, longer wildcards over shorter ones, and
+// any hostname match over a listener without a hostname.
+func selectListener(gw *gatewayv1.Gateway, req Request) int {
+	protocol := gatewayv1.HTTPProtocolType
+	if req.Scheme == "https" {
+		protocol = gatewayv1.HTTPSProtocolType
+	}
+
+	best, bestScore := -1, -1
+	for i, listener := range gw.Spec.Listeners {
+		if int32(listener.Port) != req.Port || listener.Protocol != protocol {
+			continue
+		}
+		score := 0
+		if listener.Hostname != nil {
+			var ok bool
+			if score, ok = hostname.Specificity(string(*listener.Hostname), req.Hostname); !ok {
+				continue
+			}
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// hostnameOrAny formats an optional listener hostname
+func hostnameOrAny(listenerHostname *gatewayv1.Hostname) string {
+	if listenerHostname == nil {
+		return "any"
+	}
+	return string(*listenerHostname)
+}
+
+// problemSuffix formats a backend problem for the trace
+func problemSuffix(problem string) string {
+	if problem == "" {
+		return ""
+	}
+	return " but " + problem
+}
+
+// copyHeaders copies request headers so that filters can modify them
+func copyHeaders(headers map[string]string) map[string]string {
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		copied[name] = value
+	}
+	return copied
+}
@@ -0,0 +1,179 @@
+package simulate
+
+import (
+	"math"
+	"testing"
+
+	"gwapi-graph/internal/testutil"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestSelectListener(t *testing.T) {
+	gw := &gatewayv1.Gateway{Spec: gatewayv1.GatewaySpec{Listeners: []gatewayv1.Listener{
+		testutil.Listener("any", gatewayv1.HTTPProtocolType, 80, ""),
+		testutil.Listener("wildcard", gatewayv1.HTTPProtocolType, 80, "*.example.com"),
+		testutil.Listener("longer-wildcard", gatewayv1.HTTPProtocolType, 80, "*.foo.example.com"),
+		testutil.Listener("exact", gatewayv1.HTTPProtocolType, 80, "api.foo.example.com"),
+		testutil.Listener("https", gatewayv1.HTTPSProtocolType, 443, "*.example.com"),
+	}}}
+
+	tests := []struct {
+		host   string
+		scheme string
+		port   int32
+		want   int
+	}{
+		{"api.foo.example.com", "http", 80, 3},
+		{"API.foo.example.com", "http", 80, 3},
+		{"www.foo.example.com", "http", 80, 2},
+		{"a.b.foo.example.com", "http", 80, 2},
+		{"www.example.com", "http", 80, 1},
+		{"example.com", "http", 80, 0},
+		{"other.org", "http", 80, 0},
+		{"www.example.com", "https", 443, 4},
+		{"other.org", "https", 443, -1},
+		{"www.example.com", "http", 443, -1},
+		{"www.example.com", "http", 8080, -1},
+	}
+
+	for _, tt := range tests {
+		req := Request{Hostname: tt.host, Scheme: tt.scheme, Port: tt.port}
+		if got := selectListener(gw, req); got != tt.want {
+			t.Errorf("selectListener(%s://%s:%d) = %d, want %d", tt.scheme, tt.host, tt.port, got, tt.want)
+		}
+	}
+}
+
+// backendRef returns a backendRef to a Service port with an optional weight
+func backendRef(namespace, name string, port gatewayv1.PortNumber, weight *int32) gatewayv1.HTTPBackendRef {
+	ref := testutil.BackendRef(namespace, name, port)
+	ref.Weight = weight
+	return ref
+}
+
+func TestWeighBackends(t *testing.T) {
+	route := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "r"}}
+	resources := &types.ResourceCollection{
+		Services: []corev1.Service{
+			testutil.Service("app", "stable", 80),
+			testutil.Service("app", "canary", 80),
+			testutil.Service("other", "remote", 80),
+			testutil.Service("other", "granted", 80),
+		},
+		ReferenceGrants: []gatewayv1beta1.ReferenceGrant{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "allow-app"},
+			Spec: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1.GroupName, Kind: "HTTPRoute", Namespace: "app"}},
+				To:   []gatewayv1beta1.ReferenceGrantTo{{Kind: "Service", Name: testutil.Ptr(gatewayv1.ObjectName("granted"))}},
+			},
+		}},
+	}
+
+	type want struct {
+		name    string
+		percent float64
+		problem string
+	}
+	tests := []struct {
+		name string
+		refs []gatewayv1.HTTPBackendRef
+		want []want
+	}{
+		{
+			name: "a single backend defaults to weight 1",
+			refs: []gatewayv1.HTTPBackendRef{backendRef("", "stable", 80, nil)},
+			want: []want{{"stable", 100, ""}},
+		},
+		{
+			name: "weights split the traffic, heaviest first",
+			refs: []gatewayv1.HTTPBackendRef{backendRef("", "canary", 80, testutil.Ptr(int32(10))), backendRef("", "stable", 80, testutil.Ptr(int32(90)))},
+			want: []want{{"stable", 90, ""}, {"canary", 10, ""}},
+		},
+		{
+			name: "a zero weight receives nothing",
+			refs: []gatewayv1.HTTPBackendRef{backendRef("", "stable", 80, testutil.Ptr(int32(3))), backendRef("", "canary", 80, testutil.Ptr(int32(0)))},
+			want: []want{{"stable", 100, ""}, {"canary", 0, ""}},
+		},
+		{
+			name: "missing Service and port",
+			refs: []gatewayv1.HTTPBackendRef{backendRef("", "missing", 80, nil), backendRef("", "stable", 8080, nil)},
+			want: []want{{"missing", 50, "the Service does not exist"}, {"stable", 50, "the Service does not expose the port"}},
+		},
+		{
+			name: "cross-namespace backends need a ReferenceGrant",
+			refs: []gatewayv1.HTTPBackendRef{backendRef("other", "remote", 80, nil), backendRef("other", "granted", 80, nil)},
+			want: []want{{"remote", 50, "no ReferenceGrant permits the reference"}, {"granted", 50, ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backends := weighBackends(resources, route, gatewayv1.HTTPRouteRule{BackendRefs: tt.refs})
+			if len(backends) != len(tt.want) {
+				t.Fatalf("weighBackends returned %d backends, want %d", len(backends), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := backends[i]
+				if got.Name != want.name || math.Abs(got.Percent-want.percent) > 0.01 || got.Problem != want.problem {
+					t.Errorf("backend %d = %s %.1f%% %q, want %s %.1f%% %q", i, got.Name, got.Percent, got.Problem, want.name, want.percent, want.problem)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPrefersHostnameThenMatch(t *testing.T) {
+	gw := testutil.Gateway("infra", "gw", testutil.Listener("http", gatewayv1.HTTPProtocolType, 80, ""))
+	route := func(name, host string, match gatewayv1.HTTPRouteMatch, backend string) gatewayv1.HTTPRoute {
+		route := testutil.HTTPRoute("infra", name, gatewayv1.ParentReference{Name: "gw"})
+		route.Spec.Hostnames = testutil.Hostnames(host)
+		route.Spec.Rules = []gatewayv1.HTTPRouteRule{{
+			Matches:     []gatewayv1.HTTPRouteMatch{match},
+			BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("", backend, 80, nil)},
+		}}
+		return route
+	}
+	resources := &types.ResourceCollection{
+		Gateways: []gatewayv1.Gateway{gw},
+		HTTPRoutes: []gatewayv1.HTTPRoute{
+			route("wildcard-exact", "*.example.com", testutil.PathMatch(gatewayv1.PathMatchExact, "/api"), "wildcard"),
+			route("host-prefix", "api.example.com", testutil.PathMatch(gatewayv1.PathMatchPathPrefix, "/"), "host"),
+			route("host-api", "api.example.com", testutil.PathMatch(gatewayv1.PathMatchPathPrefix, "/api"), "host-api"),
+		},
+		Services: []corev1.Service{testutil.Service("infra", "wildcard", 80), testutil.Service("infra", "host", 80), testutil.Service("infra", "host-api", 80)},
+	}
+
+	tests := []struct {
+		host, path string
+		wantRoute  string
+	}{
+		{"api.example.com", "/api", "host-api"},
+		{"api.example.com", "/apix", "host-prefix"},
+		{"www.example.com", "/api", "wildcard-exact"},
+		{"www.example.com", "/api/v1", ""},
+	}
+
+	for _, tt := range tests {
+		req := Request{Hostname: tt.host, Path: tt.path}
+		if err := req.Normalize(); err != nil {
+			t.Fatalf("Normalize: %v", err)
+		}
+		simulation := Run(resources, req)
+		if len(simulation.Results) != 1 {
+			t.Fatalf("Run returned %d results, want 1", len(simulation.Results))
+		}
+		result := simulation.Results[0]
+		got := ""
+		if result.Route != nil {
+			got = result.Route.Name
+		}
+		if got != tt.wantRoute {
+			t.Errorf("%s%s routed to %q, want %q (trace %v)", tt.host, tt.path, got, tt.wantRoute, result.Trace)
+		}
+	}
+}
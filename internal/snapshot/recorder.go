@@ -0,0 +1,146 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gwapi-graph/internal/source"
+	"gwapi-graph/internal/types"
+)
+
+// volatileMetadata are metadata fields that change without any change in intent, ignored when
+// deciding whether resources changed since the last snapshot
+var volatileMetadata = []string{"managedFields", "resourceVersion", "generation"}
+
+// volatileCollections are the collections whose content follows the state of the workloads, such
+// as EndpointSlices that change whenever a pod starts or stops, left out of the fingerprint
+var volatileCollections = []string{"endpointSlices"}
+
+// Recorder periodically captures snapshots from a source
+type Recorder struct {
+	store    *Store
+	source   source.Source
+	interval time.Duration
+	onChange bool
+	last     string // Fingerprint of the last stored snapshot
+}
+
+// NewRecorder creates a recorder polling src every interval. With onChange set, a snapshot is
+// only stored when the resources differ from the previous one.
+func NewRecorder(store *Store, src source.Source, interval time.Duration, onChange bool) *Recorder {
+	r := &Recorder{
+		store:    store,
+		source:   src,
+		interval: interval,
+		onChange: onChange,
+	}
+
+	// Carry on from the latest stored snapshot so a restart does not record a duplicate
+	if resources, _, err := store.Latest(); err == nil {
+		if r.last, err = Fingerprint(resources); err != nil {
+			log.Printf("Failed to fingerprint the latest snapshot: %v", err)
+		}
+	}
+	return r
+}
+
+// Run captures a snapshot immediately and then every interval until ctx is cancelled
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.capture(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// capture fetches the resources and stores them if needed
+func (r *Recorder) capture(ctx context.Context) {
+	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// A partial collection would show up in the history as resources removed and added back
+	resources, err := r.source.Fetch(fetchCtx)
+	if err != nil {
+		log.Printf("Failed to fetch resources for snapshot, skipping it: %v", err)
+		return
+	}
+
+	// A collection that cannot be fingerprinted is saved, as it cannot be shown to be unchanged
+	fingerprint, err := Fingerprint(resources)
+	if err != nil {
+		log.Printf("Failed to fingerprint resources, saving the snapshot anyway: %v", err)
+	} else if r.onChange && r.last != "" && fingerprint == r.last {
+		return
+	}
+
+	info, err := r.store.Save(resources, time.Now())
+	if err != nil {
+		log.Printf("Failed to save snapshot: %v", err)
+		return
+	}
+	r.last = fingerprint
+	log.Printf("Saved snapshot %s (%d bytes)", info.ID, info.Size)
+}
+
+// Fingerprint hashes a collection, ignoring volatile metadata, status and EndpointSlices, so that
+// two collections with the same fingerprint describe the same topology
+func Fingerprint(resources *types.ResourceCollection) (string, error) {
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resources: %w", err)
+	}
+
+	// Most fields hold lists of objects, some a single object such as the DNS config
+	var collection map[string]interface{}
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return "", fmt.Errorf("failed to decode resources: %w", err)
+	}
+	for _, field := range volatileCollections {
+		delete(collection, field)
+	}
+	for _, value := range collection {
+		switch value := value.(type) {
+		case []interface{}:
+			for _, obj := range value {
+				stripVolatileFields(obj)
+			}
+		case map[string]interface{}:
+			stripVolatileFields(value)
+		}
+	}
+
+	// encoding/json sorts map keys, so equal collections always encode identically
+	normalized, err := json.Marshal(collection)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode normalized resources: %w", err)
+	}
+	sum := sha256.Sum256(normalized)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// stripVolatileFields removes the volatile metadata fields and the status of an object, which
+// controllers update without any change in intent
+func stripVolatileFields(obj interface{}) {
+	object, ok := obj.(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(object, "status")
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		for _, field := range volatileMetadata {
+			delete(metadata, field)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package snapshot
+
+import (
+	"testing"
+
+	"gwapi-graph/internal/types"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// testCollection returns a collection with one Gateway and one EndpointSlice
+func testCollection() *types.ResourceCollection {
+	return &types.ResourceCollection{
+		Gateways: []gatewayv1.Gateway{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "gw", ResourceVersion: "1", Generation: 1},
+			Spec:       gatewayv1.GatewaySpec{GatewayClassName: "example", Listeners: []gatewayv1.Listener{{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType}}},
+		}},
+		EndpointSlices: []discoveryv1.EndpointSlice{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "svc-abc"},
+			Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+		}},
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*types.ResourceCollection)
+		changed bool
+	}{
+		{
+			name: "resourceVersion and generation",
+			change: func(c *types.ResourceCollection) {
+				c.Gateways[0].ResourceVersion = "2"
+				c.Gateways[0].Generation = 2
+			},
+		},
+		{
+			name: "managedFields",
+			change: func(c *types.ResourceCollection) {
+				c.Gateways[0].ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+			},
+		},
+		{
+			name: "status conditions and addresses",
+			change: func(c *types.ResourceCollection) {
+				c.Gateways[0].Status.Conditions = []metav1.Condition{{Type: "Programmed", Status: metav1.ConditionTrue}}
+				c.Gateways[0].Status.Addresses = []gatewayv1.GatewayStatusAddress{{Value: "192.0.2.1"}}
+			},
+		},
+		{
+			name: "EndpointSlices",
+			change: func(c *types.ResourceCollection) {
+				c.EndpointSlices[0].Endpoints = append(c.EndpointSlices[0].Endpoints, discoveryv1.Endpoint{Addresses: []string{"10.0.0.2"}})
+			},
+		},
+		{
+			name: "spec",
+			change: func(c *types.ResourceCollection) {
+				c.Gateways[0].Spec.Listeners[0].Port = 8080
+			},
+			changed: true,
+		},
+		{
+			name: "labels",
+			change: func(c *types.ResourceCollection) {
+				c.Gateways[0].Labels = map[string]string{"team": "a"}
+			},
+			changed: true,
+		},
+	}
+
+	before, err := Fingerprint(testCollection())
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection := testCollection()
+			tt.change(collection)
+			after, err := Fingerprint(collection)
+			if err != nil {
+				t.Fatalf("Fingerprint: %v", err)
+			}
+			if changed := after != before; changed != tt.changed {
+				t.Errorf("fingerprint changed = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+}
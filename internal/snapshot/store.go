@@ -0,0 +1,235 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gwapi-graph/internal/types"
+)
+
+const (
+	filePrefix = "snapshot-"
+	fileSuffix = ".json.gz"
+	// idFormat names snapshots by capture time, so file names sort chronologically
+	idFormat = "20060102T150405.000Z"
+)
+
+// ErrNotFound is returned when no snapshot matches an ID or time
+var ErrNotFound = errors.New("snapshot not found")
+
+// Info describes a stored snapshot
+type Info struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"` // Compressed size in bytes
+}
+
+// Retention limits how many snapshots are kept. Zero values disable the limit.
+type Retention struct {
+	MaxAge   time.Duration
+	MaxCount int
+}
+
+// Store keeps ResourceCollection snapshots as gzip-compressed JSON files in a directory
+type Store struct {
+	mu        sync.Mutex
+	dir       string
+	retention Retention
+}
+
+// NewStore opens (or creates) a snapshot directory
+func NewStore(dir string, retention Retention) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return &Store{dir: dir, retention: retention}, nil
+}
+
+// Save writes a snapshot taken at t and applies the retention policy
+func (s *Store) Save(resources *types.ResourceCollection, t time.Time) (Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t = t.UTC()
+	id := t.Format(idFormat)
+	path := s.path(id)
+
+	// Write to a temporary file first so a crash never leaves a truncated snapshot behind
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(gz).Encode(resources); err != nil {
+		tmp.Close()
+		return Info{}, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return Info{}, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Info{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Info{}, fmt.Errorf("failed to store snapshot: %w", err)
+	}
+
+	info := Info{ID: id, Time: t}
+	if stat, err := os.Stat(path); err == nil {
+		info.Size = stat.Size()
+	}
+
+	if err := s.prune(t); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// List returns the stored snapshots, oldest first
+func (s *Store) List() ([]Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+// Load reads a snapshot by ID
+func (s *Store) Load(id string) (*types.ResourceCollection, Info, error) {
+	t, err := time.Parse(idFormat, id)
+	if err != nil {
+		return nil, Info{}, ErrNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(id)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, Info{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Info{}, fmt.Errorf("failed to open snapshot %s: %w", id, err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, Info{}, fmt.Errorf("failed to decompress snapshot %s: %w", id, err)
+	}
+	defer gz.Close()
+
+	var resources types.ResourceCollection
+	if err := json.NewDecoder(gz).Decode(&resources); err != nil {
+		return nil, Info{}, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+
+	info := Info{ID: id, Time: t}
+	if stat, err := file.Stat(); err == nil {
+		info.Size = stat.Size()
+	}
+	return &resources, info, nil
+}
+
+// At loads the latest snapshot taken at or before t
+func (s *Store) At(t time.Time) (*types.ResourceCollection, Info, error) {
+	snapshots, err := s.List()
+	if err != nil {
+		return nil, Info{}, err
+	}
+
+	index := sort.Search(len(snapshots), func(i int) bool {
+		return snapshots[i].Time.After(t)
+	})
+	if index == 0 {
+		return nil, Info{}, ErrNotFound
+	}
+	return s.Load(snapshots[index-1].ID)
+}
+
+// Latest loads the most recent snapshot
+func (s *Store) Latest() (*types.ResourceCollection, Info, error) {
+	snapshots, err := s.List()
+	if err != nil {
+		return nil, Info{}, err
+	}
+	if len(snapshots) == 0 {
+		return nil, Info{}, ErrNotFound
+	}
+	return s.Load(snapshots[len(snapshots)-1].ID)
+}
+
+// list reads the snapshot directory; the caller holds the lock
+func (s *Store) list() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	snapshots := []Info{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix)
+		t, err := time.Parse(idFormat, id)
+		if err != nil {
+			continue
+		}
+		info := Info{ID: id, Time: t}
+		if stat, err := entry.Info(); err == nil {
+			info.Size = stat.Size()
+		}
+		snapshots = append(snapshots, info)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// prune deletes snapshots beyond the retention policy; the caller holds the lock
+func (s *Store) prune(now time.Time) error {
+	snapshots, err := s.list()
+	if err != nil {
+		return err
+	}
+
+	var expired []Info
+	if s.retention.MaxCount > 0 && len(snapshots) > s.retention.MaxCount {
+		expired = append(expired, snapshots[:len(snapshots)-s.retention.MaxCount]...)
+		snapshots = snapshots[len(snapshots)-s.retention.MaxCount:]
+	}
+	if s.retention.MaxAge > 0 {
+		cutoff := now.Add(-s.retention.MaxAge)
+		for _, info := range snapshots {
+			if info.Time.Before(cutoff) {
+				expired = append(expired, info)
+			}
+		}
+	}
+
+	for _, info := range expired {
+		if err := os.Remove(s.path(info.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove expired snapshot %s: %w", info.ID, err)
+		}
+	}
+	return nil
+}
+
+// path returns the file holding a snapshot
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, filePrefix+id+fileSuffix)
+}
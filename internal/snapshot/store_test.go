@@ -0,0 +1,123 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/types"
+)
+
+func TestStoreRetention(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes ...int) []time.Time {
+		times := make([]time.Time, len(minutes))
+		for i, minute := range minutes {
+			times[i] = start.Add(time.Duration(minute) * time.Minute)
+		}
+		return times
+	}
+
+	tests := []struct {
+		name      string
+		retention Retention
+		saves     []time.Time
+		want      []time.Time
+	}{
+		{
+			name:  "no limits keep everything",
+			saves: at(0, 1, 2, 3),
+			want:  at(0, 1, 2, 3),
+		},
+		{
+			name:      "max count keeps the newest",
+			retention: Retention{MaxCount: 2},
+			saves:     at(0, 1, 2, 3),
+			want:      at(2, 3),
+		},
+		{
+			name:      "max age drops snapshots older than the last save",
+			retention: Retention{MaxAge: 30 * time.Minute},
+			saves:     at(0, 10, 45, 60),
+			want:      at(45, 60),
+		},
+		{
+			name:      "both limits apply",
+			retention: Retention{MaxAge: time.Hour, MaxCount: 3},
+			saves:     at(0, 50, 70, 80, 90),
+			want:      at(70, 80, 90),
+		},
+		{
+			name:      "max age keeps the snapshot at the cutoff",
+			retention: Retention{MaxAge: 30 * time.Minute},
+			saves:     at(0, 30),
+			want:      at(0, 30),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewStore(t.TempDir(), tt.retention)
+			if err != nil {
+				t.Fatalf("NewStore: %v", err)
+			}
+			for _, saved := range tt.saves {
+				if _, err := store.Save(&types.ResourceCollection{}, saved); err != nil {
+					t.Fatalf("Save: %v", err)
+				}
+			}
+
+			snapshots, err := store.List()
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(snapshots) != len(tt.want) {
+				t.Fatalf("List returned %d snapshots, want %d: %v", len(snapshots), len(tt.want), snapshots)
+			}
+			for i, want := range tt.want {
+				if !snapshots[i].Time.Equal(want) {
+					t.Errorf("snapshot %d taken at %s, want %s", i, snapshots[i].Time, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreAt(t *testing.T) {
+	store, err := NewStore(t.TempDir(), Retention{})
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, minute := range []int{0, 10, 20} {
+		if _, err := store.Save(&types.ResourceCollection{}, start.Add(time.Duration(minute)*time.Minute)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	tests := []struct {
+		at      time.Time
+		want    time.Time
+		missing bool
+	}{
+		{at: start.Add(-time.Minute), missing: true},
+		{at: start, want: start},
+		{at: start.Add(15 * time.Minute), want: start.Add(10 * time.Minute)},
+		{at: start.Add(time.Hour), want: start.Add(20 * time.Minute)},
+	}
+	for _, tt := range tests {
+		_, info, err := store.At(tt.at)
+		if tt.missing {
+			if err != ErrNotFound {
+				t.Errorf("At(%s) error = %v, want ErrNotFound", tt.at, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("At(%s): %v", tt.at, err)
+			continue
+		}
+		if !info.Time.Equal(tt.want) {
+			t.Errorf("At(%s) = snapshot at %s, want %s", tt.at, info.Time, tt.want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package source
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/k8s"
+	"gwapi-graph/internal/policy"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// recheckInterval is how long discovered policy kinds, and the namespaces whose Secrets or ConfigMaps
+// the service account may not read, are remembered before the API server is asked again
+const recheckInterval = 5 * time.Minute
+
+// Cluster reads resources from a live cluster
+type Cluster struct {
+	k8sClient   *k8s.Client
+	policyKinds []types.PolicyKind // Policy kinds to list besides those discovered by label
+
+	mu           sync.Mutex
+	discovered   []types.PolicyKind              // The policy kinds found by the last discovery
+	discoveredAt time.Time                       // When the policy kinds were discovered; zero before the first fetch
+	forbidden    map[types.ResourceRef]time.Time // Kind and namespace, when reading one was last forbidden
+}
+
+// NewCluster creates a source backed by the given Kubernetes client. Policies of the given kinds
+// are listed along with those of the CRDs labeled as Gateway API policies.
+func NewCluster(k8sClient *k8s.Client, policyKinds ...types.PolicyKind) *Cluster {
+	return &Cluster{
+		k8sClient:   k8sClient,
+		policyKinds: policyKinds,
+	}
+}
+
+// Live reports that the cluster source can be read from and written to directly
+func (s *Cluster) Live() bool {
+	return true
+}
+
+// Fetch fetches all Gateway API Standard channel resources. When GatewayClasses, Gateways,
+// HTTPRoutes, ReferenceGrants or Services cannot be listed, the rest is returned with an
+// *IncompleteError. The other kinds are optional, e.g. not installed, and only logged.
+func (s *Cluster) Fetch(ctx context.Context) (*types.ResourceCollection, error) {
+	collection := &types.ResourceCollection{}
+	var failed []string
+
+	log.Printf("Starting to fetch Gateway API resources...")
+
+	// Fetch Gateway Classes
+	gatewayClasses, err := s.k8sClient.GetGatewayClasses(ctx)
+	if err != nil {
+		log.Printf("Error fetching Gateway Classes: %v", err)
+		failed = append(failed, "GatewayClasses")
+	} else {
+		log.Printf("Found %d Gateway Classes", len(gatewayClasses))
+		for _, gc := range gatewayClasses {
+			log.Printf("  - GatewayClass: %s", gc.Name)
+		}
+		collection.GatewayClasses = gatewayClasses
+	}
+
+	// Fetch Gateways
+	gateways, err := s.k8sClient.GetGateways(ctx)
+	if err != nil {
+		log.Printf("Error fetching Gateways: %v", err)
+		failed = append(failed, "Gateways")
+	} else {
+		log.Printf("Found %d Gateways", len(gateways))
+		for _, gw := range gateways {
+			log.Printf("  - Gateway: %s/%s", gw.Namespace, gw.Name)
+		}
+		collection.Gateways = gateways
+	}
+
+	// Fetch HTTP Routes
+	httpRoutes, err := s.k8sClient.GetHTTPRoutes(ctx)
+	if err != nil {
+		log.Printf("Error fetching HTTP Routes: %v", err)
+		failed = append(failed, "HTTPRoutes")
+	} else {
+		log.Printf("Found %d HTTP Routes", len(httpRoutes))
+		for _, route := range httpRoutes {
+			log.Printf("  - HTTPRoute: %s/%s", route.Namespace, route.Name)
+		}
+		collection.HTTPRoutes = httpRoutes
+	}
+
+	// Fetch Reference Grants
+	referenceGrants, err := s.k8sClient.GetReferenceGrants(ctx)
+	if err != nil {
+		log.Printf("Error fetching Reference Grants: %v", err)
+		failed = append(failed, "ReferenceGrants")
+	} else {
+		log.Printf("Found %d Reference Grants", len(referenceGrants))
+		for _, grant := range referenceGrants {
+			log.Printf("  - ReferenceGrant: %s/%s", grant.Namespace, grant.Name)
+		}
+		collection.ReferenceGrants = referenceGrants
+	}
+
+	// Fetch DNSRecords
+	dnsRecords, err := s.k8sClient.GetDNSRecords(ctx)
+	if err != nil {
+		log.Printf("Error fetching DNSRecords: %v", err)
+	} else {
+		log.Printf("Found %d DNSRecords", len(dnsRecords))
+		for _, dns := range dnsRecords {
+			name, _, _ := unstructured.NestedString(dns.Object, "metadata", "name")
+			namespace, _, _ := unstructured.NestedString(dns.Object, "metadata", "namespace")
+			log.Printf("  - DNSRecord: %s/%s", namespace, name)
+		}
+		collection.DNSRecords = dnsRecords
+	}
+
+	// Fetch ExternalDNS DNSEndpoints
+	dnsEndpoints, err := s.k8sClient.GetDNSEndpoints(ctx)
+	if err != nil {
+		log.Printf("Error fetching DNSEndpoints: %v", err)
+	} else {
+		log.Printf("Found %d DNSEndpoints", len(dnsEndpoints))
+		for _, endpoint := range dnsEndpoints {
+			log.Printf("  - DNSEndpoint: %s/%s", endpoint.GetNamespace(), endpoint.GetName())
+		}
+		collection.DNSEndpoints = dnsEndpoints
+	}
+
+	// Fetch the OpenShift cluster DNS config, which declares the hosted zones
+	dnsConfig, err := s.k8sClient.GetDNSConfig(ctx)
+	if err != nil {
+		log.Printf("Error fetching DNS config: %v", err)
+	} else if dnsConfig != nil {
+		baseDomain, _, _ := unstructured.NestedString(dnsConfig.Object, "spec", "baseDomain")
+		log.Printf("Found DNS config with base domain %s", baseDomain)
+		collection.DNSConfig = dnsConfig
+	}
+
+	// Fetch Services
+	services, err := s.k8sClient.GetServices(ctx)
+	if err != nil {
+		log.Printf("Error fetching Services: %v", err)
+		failed = append(failed, "Services")
+	} else {
+		log.Printf("Found %d Services", len(services))
+		for _, svc := range services {
+			log.Printf("  - Service: %s/%s", svc.Namespace, svc.Name)
+		}
+		collection.Services = services
+	}
+
+	// Fetch EndpointSlices
+	endpointSlices, err := s.k8sClient.GetEndpointSlices(ctx)
+	if err != nil {
+		log.Printf("Error fetching EndpointSlices: %v", err)
+	} else {
+		log.Printf("Found %d EndpointSlices", len(endpointSlices))
+		collection.EndpointSlices = endpointSlices
+	}
+
+	// Read the certificates of the Secrets that Gateway listeners reference
+	for _, ref := range certificateRefs(collection) {
+		if !s.mayRead(ref) {
+			continue
+		}
+		secret, err := s.k8sClient.GetSecret(ctx, ref.Namespace, ref.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			collection.Certificates = append(collection.Certificates, certs.Missing("Secret", ref.Namespace, ref.Name, certs.TLSCertKey))
+		case apierrors.IsForbidden(err):
+			s.denied(ref, err)
+		case err != nil:
+			log.Printf("Error fetching certificate Secret: %v", err)
+		default:
+			collection.Certificates = append(collection.Certificates, certs.FromSecret(secret, certs.TLSCertKey))
+		}
+	}
+
+	// Discover policy kinds and list their objects
+	collection.PolicyKinds = s.cachedPolicyKinds(ctx)
+	for _, kind := range collection.PolicyKinds {
+		gvr := schema.GroupVersionResource{Group: kind.Group, Version: kind.Version, Resource: kind.Resource}
+		policies, err := s.k8sClient.ListResources(ctx, gvr)
+		if err != nil {
+			log.Printf("Error fetching %s policies: %v", kind.Kind, err)
+			continue
+		}
+		log.Printf("Found %d %s policies", len(policies), kind.Kind)
+		collection.Policies = append(collection.Policies, policies...)
+	}
+
+	// Read the CA certificates of the ConfigMaps and Secrets that BackendTLSPolicies reference
+	for _, ref := range caCertificateRefs(collection) {
+		if !s.mayRead(ref) {
+			continue
+		}
+		var bundle types.CertificateBundle
+		var err error
+		if ref.Kind == "ConfigMap" {
+			var configMap *corev1.ConfigMap
+			if configMap, err = s.k8sClient.GetConfigMap(ctx, ref.Namespace, ref.Name); err == nil {
+				bundle = certs.FromConfigMap(configMap, certs.CACertKey)
+			}
+		} else {
+			var secret *corev1.Secret
+			if secret, err = s.k8sClient.GetSecret(ctx, ref.Namespace, ref.Name); err == nil {
+				bundle = certs.FromSecret(secret, certs.CACertKey)
+			}
+		}
+		switch {
+		case apierrors.IsNotFound(err):
+			collection.Certificates = append(collection.Certificates, certs.Missing(ref.Kind, ref.Namespace, ref.Name, certs.CACertKey))
+		case apierrors.IsForbidden(err):
+			s.denied(ref, err)
+		case err != nil:
+			log.Printf("Error fetching CA %s: %v", ref.Kind, err)
+		default:
+			collection.Certificates = append(collection.Certificates, bundle)
+		}
+	}
+
+	log.Printf("Finished fetching resources. Total nodes that will be created: %d",
+		len(collection.GatewayClasses)+len(collection.Gateways)+len(collection.HTTPRoutes)+len(collection.ReferenceGrants)+len(collection.DNSRecords)+len(collection.DNSEndpoints)+len(collection.Services))
+
+	if len(failed) > 0 {
+		return collection, &IncompleteError{Failed: failed}
+	}
+	return collection, nil
+}
+
+// cachedPolicyKinds returns the policy kinds, discovering them again once recheckInterval has passed,
+// so that each fetch does not repeat API discovery
+func (s *Cluster) cachedPolicyKinds(ctx context.Context) []types.PolicyKind {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.discoveredAt.IsZero() || time.Since(s.discoveredAt) >= recheckInterval {
+		s.discovered = s.discoverPolicyKinds(ctx)
+		s.discoveredAt = time.Now()
+	}
+	return s.discovered
+}
+
+// mayRead reports whether an object should be read: not while the last attempt to read its kind
+// in its namespace within recheckInterval was forbidden, as reading Secrets and ConfigMaps takes
+// an optional role bound per namespace
+func (s *Cluster) mayRead(ref types.ResourceRef) bool {
+	key := types.ResourceRef{Kind: ref.Kind, Namespace: ref.Namespace}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	since, ok := s.forbidden[key]
+	if !ok {
+		return true
+	}
+	if time.Since(since) < recheckInterval {
+		return false
+	}
+	delete(s.forbidden, key)
+	return true
+}
+
+// denied records that reading objects of a kind in a namespace is forbidden and logs it once
+func (s *Cluster) denied(ref types.ResourceRef, err error) {
+	key := types.ResourceRef{Kind: ref.Kind, Namespace: ref.Namespace}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.forbidden[key]; ok {
+		return
+	}
+	if s.forbidden == nil {
+		s.forbidden = make(map[types.ResourceRef]time.Time)
+	}
+	s.forbidden[key] = time.Now()
+	log.Printf("Not allowed to read %ss in %s, their certificates are left out for %s: %v", ref.Kind, ref.Namespace, recheckInterval, err)
+}
+
+// discoverPolicyKinds returns the configured policy kinds that the API server serves, followed by
+// the kinds of the CRDs labeled as Gateway API policies. A configured kind overrides the label.
+func (s *Cluster) discoverPolicyKinds(ctx context.Context) []types.PolicyKind {
+	var configured []types.PolicyKind
+	for _, kind := range s.policyKinds {
+		gvr, err := s.k8sClient.ResourceFor(kind.Group, kind.Version, kind.Kind)
+		if err != nil {
+			log.Printf("Error resolving policy kind %s.%s: %v", kind.Kind, kind.Group, err)
+			continue
+		}
+		kind.Version, kind.Resource = gvr.Version, gvr.Resource
+		configured = append(configured, kind)
+	}
+
+	var labeled []types.PolicyKind
+	crds, err := s.k8sClient.GetCustomResourceDefinitions(ctx, policy.Label)
+	if err != nil {
+		log.Printf("Error discovering policy CRDs: %v", err)
+	}
+	for i := range crds {
+		if kind, ok := policy.FromCRD(&crds[i]); ok {
+			labeled = append(labeled, kind)
+		}
+	}
+
+	return policy.Merge(configured, labeled)
+}
+
+// caCertificateRefs returns the ConfigMaps and Secrets the collected BackendTLSPolicies reference
+// as CA certificates, once each
+func caCertificateRefs(collection *types.ResourceCollection) []types.ResourceRef {
+	seen := make(map[types.ResourceRef]bool)
+	var refs []types.ResourceRef
+	policies := analysis.BackendTLSPolicies(collection)
+	for i := range policies {
+		for _, ref := range analysis.CACertificateRefs(&policies[i]) {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// certificateRefs returns the Secrets the listeners of the collected Gateways reference as
+// certificates, once each
+func certificateRefs(collection *types.ResourceCollection) []types.ResourceRef {
+	seen := make(map[types.ResourceRef]bool)
+	var refs []types.ResourceRef
+	for _, gw := range collection.Gateways {
+		for _, listener := range gw.Spec.Listeners {
+			if listener.TLS == nil {
+				continue
+			}
+			for _, certRef := range listener.TLS.CertificateRefs {
+				if (certRef.Group != nil && *certRef.Group != "") || (certRef.Kind != nil && *certRef.Kind != "Secret") {
+					continue
+				}
+				ref := types.ResourceRef{Kind: "Secret", Namespace: gw.Namespace, Name: string(certRef.Name)}
+				if certRef.Namespace != nil {
+					ref.Namespace = string(*certRef.Namespace)
+				}
+				if !seen[ref] {
+					seen[ref] = true
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,40 @@
+package source
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gwapi-graph/internal/types"
+)
+
+func TestForbiddenPerNamespace(t *testing.T) {
+	s := NewCluster(nil)
+	secret := func(namespace string) types.ResourceRef {
+		return types.ResourceRef{Kind: "Secret", Namespace: namespace, Name: "cert"}
+	}
+
+	s.denied(secret("team-a"), errors.New("forbidden"))
+
+	tests := []struct {
+		ref  types.ResourceRef
+		want bool
+	}{
+		{secret("team-a"), false},
+		{types.ResourceRef{Kind: "Secret", Namespace: "team-a", Name: "other"}, false},
+		// The certificate-reader role is bound per namespace
+		{secret("team-b"), true},
+		{types.ResourceRef{Kind: "ConfigMap", Namespace: "team-a", Name: "ca"}, true},
+	}
+	for _, tt := range tests {
+		if got := s.mayRead(tt.ref); got != tt.want {
+			t.Errorf("mayRead(%s %s/%s) = %v, want %v", tt.ref.Kind, tt.ref.Namespace, tt.ref.Name, got, tt.want)
+		}
+	}
+
+	// Once recheckInterval has passed the namespace is read again
+	s.forbidden[types.ResourceRef{Kind: "Secret", Namespace: "team-a"}] = time.Now().Add(-recheckInterval)
+	if !s.mayRead(secret("team-a")) {
+		t.Errorf("mayRead after recheckInterval = false, want true")
+	}
+}
@@ -0,0 +1,401 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gwapi-graph/internal/certs"
+	"gwapi-graph/internal/policy"
+	"gwapi-graph/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// defaultNamespace is assigned to namespaced objects that do not set metadata.namespace,
+// matching what kubectl apply would do without --namespace
+const defaultNamespace = "default"
+
+// Manifests reads resources from multi-document YAML or JSON manifests, either from a directory
+// tree (re-read on every Fetch, so edits show up on refresh) or from a stream read once up front
+type Manifests struct {
+	dir  string
+	name string // Display name of the stream, used in place of a file name
+	data []byte
+}
+
+// manifestDocument is a single document split out of a manifest file
+type manifestDocument struct {
+	file  string
+	index int // Zero-based position of the document within the file
+	line  int // One-based line where the document starts
+	body  []byte
+}
+
+// Origin is where an object was read from in the manifests
+type Origin struct {
+	File     string `json:"file"`
+	Document int    `json:"document"` // Zero-based position of the document within the file
+	Line     int    `json:"line"`     // One-based line where the document starts
+}
+
+// Origins maps each object loaded from manifests to where it was read from
+type Origins map[types.ResourceRef]Origin
+
+// Lookup returns the origin of a resource. Listener references resolve to their Gateway.
+func (o Origins) Lookup(ref types.ResourceRef) (Origin, bool) {
+	ref.Listener = ""
+	origin, ok := o[ref]
+	return origin, ok
+}
+
+// NewManifestDir creates a source reading every .yaml, .yml and .json file below dir
+func NewManifestDir(dir string) (*Manifests, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &Manifests{dir: dir}, nil
+}
+
+// NewManifestReader creates a source from a manifest stream such as the output of
+// `kustomize build` or `helm template`. The stream is read to the end immediately.
+func NewManifestReader(name string, r io.Reader) (*Manifests, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests from %s: %w", name, err)
+	}
+	return &Manifests{name: name, data: data}, nil
+}
+
+// Live reports that manifests are an offline source
+func (s *Manifests) Live() bool {
+	return false
+}
+
+// Fetch parses the manifests into a ResourceCollection
+func (s *Manifests) Fetch(ctx context.Context) (*types.ResourceCollection, error) {
+	collection, _, err := s.FetchWithOrigins(ctx)
+	return collection, err
+}
+
+// FetchWithOrigins parses the manifests like Fetch and also returns where each object was read
+// from, so that problems can be reported against the file and document
+func (s *Manifests) FetchWithOrigins(ctx context.Context) (*types.ResourceCollection, Origins, error) {
+	documents, err := s.documents()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	collection := &types.ResourceCollection{}
+	origins := make(Origins)
+	for _, doc := range documents {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if err := addDocument(collection, origins, doc); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	addCACertificates(collection, origins)
+
+	log.Printf("Loaded %d documents from manifests: %d GatewayClasses, %d Gateways, %d HTTPRoutes, %d ReferenceGrants, %d DNSRecords, %d DNSEndpoints, %d Services, %d policies",
+		len(documents), len(collection.GatewayClasses), len(collection.Gateways), len(collection.HTTPRoutes),
+		len(collection.ReferenceGrants), len(collection.DNSRecords), len(collection.DNSEndpoints), len(collection.Services), len(collection.Policies))
+
+	return collection, origins, nil
+}
+
+// documents splits all manifest files (or the stream) into individual documents
+func (s *Manifests) documents() ([]manifestDocument, error) {
+	if s.dir == "" {
+		return splitDocuments(s.name, s.data), nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != s.dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk manifest directory %s: %w", s.dir, err)
+	}
+	sort.Strings(files)
+
+	var documents []manifestDocument
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", file, err)
+		}
+		documents = append(documents, splitDocuments(file, data)...)
+	}
+	return documents, nil
+}
+
+// splitDocuments splits a YAML stream on "---" separator lines, keeping the position of each
+// document. A JSON file is a single document.
+func splitDocuments(file string, data []byte) []manifestDocument {
+	var documents []manifestDocument
+	var current bytes.Buffer
+	index, startLine, lineNumber := 0, 1, 0
+
+	flush := func() {
+		if len(bytes.TrimSpace(current.Bytes())) > 0 {
+			documents = append(documents, manifestDocument{
+				file:  file,
+				index: index,
+				line:  startLine,
+				body:  append([]byte(nil), current.Bytes()...),
+			})
+		}
+		index++
+		current.Reset()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t") {
+			// A separator before any content does not start a new document
+			if lineNumber > 1 || current.Len() > 0 {
+				flush()
+			}
+			startLine = lineNumber + 1
+			continue
+		}
+		current.WriteString(line)
+		current.WriteByte('\n')
+	}
+	flush()
+
+	return documents
+}
+
+// addDocument decodes a document and adds the objects it contains to the collection, recording
+// their origin. Documents that are not a YAML or JSON object, such as unrendered Helm templates
+// or top-level JSON arrays, are skipped with a warning so that the other documents still load.
+func addDocument(collection *types.ResourceCollection, origins Origins, doc manifestDocument) error {
+	jsonBody, err := sigsyaml.YAMLToJSON(doc.body)
+	if err != nil {
+		log.Printf("Skipping invalid YAML in %s (document %d, line %d): %v", doc.file, doc.index, doc.line, err)
+		return nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(jsonBody, &object); err != nil {
+		log.Printf("Skipping %s (document %d, line %d), which is not a manifest object: %v", doc.file, doc.index, doc.line, err)
+		return nil
+	}
+	if object == nil {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{Object: object}
+
+	// Expand lists such as the output of `kubectl get -o yaml`
+	if obj.IsList() {
+		list, err := obj.ToList()
+		if err != nil {
+			return fmt.Errorf("%s (document %d, line %d): invalid list: %w", doc.file, doc.index, doc.line, err)
+		}
+		for i := range list.Items {
+			if err := addObject(collection, &list.Items[i]); err != nil {
+				return fmt.Errorf("%s (document %d, line %d): %w", doc.file, doc.index, doc.line, err)
+			}
+			origins[objectRef(&list.Items[i])] = doc.origin()
+		}
+		return nil
+	}
+
+	if err := addObject(collection, obj); err != nil {
+		return fmt.Errorf("%s (document %d, line %d): %w", doc.file, doc.index, doc.line, err)
+	}
+	origins[objectRef(obj)] = doc.origin()
+	return nil
+}
+
+// origin returns where the document was read from
+func (d manifestDocument) origin() Origin {
+	return Origin{File: d.file, Document: d.index, Line: d.line}
+}
+
+// objectRef identifies an object the way findings refer to it
+func objectRef(obj *unstructured.Unstructured) types.ResourceRef {
+	return types.ResourceRef{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+}
+
+// addObject converts a single object into its typed form and appends it to the collection.
+// Kinds the graph does not use are skipped.
+func addObject(collection *types.ResourceCollection, obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+
+	// Manifests carry no server-assigned UID, but node IDs are derived from it,
+	// so give every object a stable identity based on its kind and name
+	namespaced := gvk.Kind != "GatewayClass" && !isDNSConfig(gvk) && !isCRD(gvk)
+	if namespaced && obj.GetNamespace() == "" {
+		obj.SetNamespace(defaultNamespace)
+	}
+	if obj.GetUID() == "" {
+		obj.SetUID(k8stypes.UID(manifestUID(gvk.Kind, obj.GetNamespace(), obj.GetName())))
+	}
+
+	switch {
+	case gvk.Group == gatewayv1.GroupName && gvk.Kind == "GatewayClass":
+		var gc gatewayv1.GatewayClass
+		if err := fromUnstructured(obj, &gc); err != nil {
+			return err
+		}
+		collection.GatewayClasses = append(collection.GatewayClasses, gc)
+	case gvk.Group == gatewayv1.GroupName && gvk.Kind == "Gateway":
+		var gw gatewayv1.Gateway
+		if err := fromUnstructured(obj, &gw); err != nil {
+			return err
+		}
+		collection.Gateways = append(collection.Gateways, gw)
+	case gvk.Group == gatewayv1.GroupName && gvk.Kind == "HTTPRoute":
+		var route gatewayv1.HTTPRoute
+		if err := fromUnstructured(obj, &route); err != nil {
+			return err
+		}
+		collection.HTTPRoutes = append(collection.HTTPRoutes, route)
+	case gvk.Group == gatewayv1.GroupName && gvk.Kind == "ReferenceGrant":
+		var grant gatewayv1beta1.ReferenceGrant
+		if err := fromUnstructured(obj, &grant); err != nil {
+			return err
+		}
+		collection.ReferenceGrants = append(collection.ReferenceGrants, grant)
+	case gvk.Group == "ingress.operator.openshift.io" && gvk.Kind == "DNSRecord":
+		collection.DNSRecords = append(collection.DNSRecords, *obj)
+	case gvk.Group == "externaldns.k8s.io" && gvk.Kind == "DNSEndpoint":
+		collection.DNSEndpoints = append(collection.DNSEndpoints, *obj)
+	case isDNSConfig(gvk) && obj.GetName() == "cluster":
+		collection.DNSConfig = obj
+	case gvk.Group == "" && gvk.Kind == "Service":
+		var svc corev1.Service
+		if err := fromUnstructured(obj, &svc); err != nil {
+			return err
+		}
+		collection.Services = append(collection.Services, svc)
+	case gvk.Group == "discovery.k8s.io" && gvk.Kind == "EndpointSlice":
+		var slice discoveryv1.EndpointSlice
+		if err := fromUnstructured(obj, &slice); err != nil {
+			return err
+		}
+		collection.EndpointSlices = append(collection.EndpointSlices, slice)
+	case gvk.Group == "" && gvk.Kind == "Secret":
+		// Only the certificates are kept; keys and other data are dropped
+		var secret corev1.Secret
+		if err := fromUnstructured(obj, &secret); err != nil {
+			return err
+		}
+		if _, ok := secret.Data[certs.TLSCertKey]; ok || secret.StringData[certs.TLSCertKey] != "" {
+			collection.Certificates = append(collection.Certificates, certs.FromSecret(&secret, certs.TLSCertKey))
+		}
+		if _, ok := secret.Data[certs.CACertKey]; ok || secret.StringData[certs.CACertKey] != "" {
+			collection.Certificates = append(collection.Certificates, certs.FromSecret(&secret, certs.CACertKey))
+		}
+	case gvk.Group == "" && gvk.Kind == "ConfigMap":
+		var configMap corev1.ConfigMap
+		if err := fromUnstructured(obj, &configMap); err != nil {
+			return err
+		}
+		if _, ok := configMap.Data[certs.CACertKey]; ok {
+			collection.Certificates = append(collection.Certificates, certs.FromConfigMap(&configMap, certs.CACertKey))
+		}
+	case isCRD(gvk):
+		if kind, ok := policy.FromCRD(obj); ok {
+			collection.PolicyKinds = append(collection.PolicyKinds, kind)
+		}
+	case policy.HasTargetRef(obj):
+		collection.Policies = append(collection.Policies, *obj)
+	}
+
+	return nil
+}
+
+// addCACertificates accounts for the CA ConfigMaps and Secrets of BackendTLSPolicies that were
+// not read as certificates: they either lack a ca.crt key or are not in the manifests at all
+func addCACertificates(collection *types.ResourceCollection, origins Origins) {
+	for _, ref := range caCertificateRefs(collection) {
+		if certs.Find(collection.Certificates, ref.Kind, ref.Namespace, ref.Name, certs.CACertKey) != nil {
+			continue
+		}
+		bundle := certs.Missing(ref.Kind, ref.Namespace, ref.Name, certs.CACertKey)
+		if _, found := origins[ref]; found {
+			meta := metav1.ObjectMeta{Namespace: ref.Namespace, Name: ref.Name}
+			if ref.Kind == "ConfigMap" {
+				bundle = certs.FromConfigMap(&corev1.ConfigMap{ObjectMeta: meta}, certs.CACertKey)
+			} else {
+				bundle = certs.FromSecret(&corev1.Secret{ObjectMeta: meta}, certs.CACertKey)
+			}
+		}
+		collection.Certificates = append(collection.Certificates, bundle)
+	}
+}
+
+// isDNSConfig reports whether a kind is the cluster-scoped OpenShift DNS config
+func isDNSConfig(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == "config.openshift.io" && gvk.Kind == "DNS"
+}
+
+// isCRD reports whether a kind is a cluster-scoped CustomResourceDefinition
+func isCRD(gvk schema.GroupVersionKind) bool {
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
+
+// fromUnstructured converts an unstructured object into a typed one. Earlier API versions of the
+// Gateway API kinds share the v1 schema, so they convert directly.
+func fromUnstructured(obj *unstructured.Unstructured, into interface{}) error {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into); err != nil {
+		return fmt.Errorf("failed to decode %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	if typed, ok := into.(metav1.Object); ok && typed.GetName() == "" {
+		return fmt.Errorf("%s is missing metadata.name", obj.GetKind())
+	}
+	return nil
+}
+
+// manifestUID builds the synthetic UID given to objects loaded from manifests
+func manifestUID(kind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("manifest:%s:%s", kind, name)
+	}
+	return fmt.Sprintf("manifest:%s:%s/%s", kind, namespace, name)
+}
@@ -0,0 +1,212 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gwapi-graph/internal/types"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string // index@line: first line of the body
+	}{
+		{
+			name: "single document",
+			data: "kind: Service\n",
+			want: []string{"0@1: kind: Service"},
+		},
+		{
+			name: "leading separator",
+			data: "---\nkind: Service\n---\nkind: Gateway\n",
+			want: []string{"0@2: kind: Service", "1@4: kind: Gateway"},
+		},
+		{
+			// Empty documents are dropped but still count towards the index
+			name: "empty documents and comments after separators",
+			data: "kind: Service\n---\n\n--- # second\nkind: Gateway\n---\t\n",
+			want: []string{"0@1: kind: Service", "2@5: kind: Gateway"},
+		},
+		{
+			name: "separator-like content",
+			data: "kind: ConfigMap\ndata:\n  a: |\n    ----\n    ---x\n",
+			want: []string{"0@1: kind: ConfigMap"},
+		},
+		{
+			name: "JSON",
+			data: `{"kind": "Service"}`,
+			want: []string{`0@1: {"kind": "Service"}`},
+		},
+		{
+			name: "empty",
+			data: "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, doc := range splitDocuments("file.yaml", []byte(tt.data)) {
+				first, _, _ := strings.Cut(string(doc.body), "\n")
+				got = append(got, fmt.Sprintf("%d@%d: %s", doc.index, doc.line, first))
+				if doc.file != "file.yaml" {
+					t.Errorf("file = %q, want file.yaml", doc.file)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("documents = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// manifestDir writes manifest files into a temporary directory and returns it
+func manifestDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFetchWithOrigins(t *testing.T) {
+	dir := manifestDir(t, map[string]string{
+		"gateway.yaml": `apiVersion: gateway.networking.k8s.io/v1
+kind: GatewayClass
+metadata:
+  name: example
+spec:
+  controllerName: example.com/gateway-controller
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw
+  namespace: infra
+spec:
+  gatewayClassName: example
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+`,
+		"apps/list.yaml": `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+  spec:
+    ports:
+    - port: 80
+- apiVersion: gateway.networking.k8s.io/v1
+  kind: HTTPRoute
+  metadata:
+    name: web
+    namespace: app
+  spec:
+    parentRefs:
+    - name: gw
+      namespace: infra
+`,
+		"apps/notes.txt":       "not a manifest",
+		".hidden/ignored.yaml": "kind: [",
+	})
+
+	source, err := NewManifestDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection, origins, err := source.FetchWithOrigins(context.Background())
+	if err != nil {
+		t.Fatalf("FetchWithOrigins: %v", err)
+	}
+
+	if len(collection.GatewayClasses) != 1 || len(collection.Gateways) != 1 || len(collection.Services) != 1 || len(collection.HTTPRoutes) != 1 {
+		t.Fatalf("collection = %d GatewayClasses, %d Gateways, %d Services, %d HTTPRoutes, want one of each",
+			len(collection.GatewayClasses), len(collection.Gateways), len(collection.Services), len(collection.HTTPRoutes))
+	}
+	if svc := collection.Services[0]; svc.Namespace != defaultNamespace || svc.UID != "manifest:Service:default/web" {
+		t.Errorf("Service %s/%s has UID %q, want it in default with a manifest UID", svc.Namespace, svc.Name, svc.UID)
+	}
+	if gc := collection.GatewayClasses[0]; gc.Namespace != "" {
+		t.Errorf("GatewayClass namespace = %q, want none", gc.Namespace)
+	}
+
+	list := filepath.Join(dir, "apps", "list.yaml")
+	want := Origins{
+		{Kind: "GatewayClass", Name: "example"}:                     {File: filepath.Join(dir, "gateway.yaml"), Document: 0, Line: 1},
+		{Kind: "Gateway", Namespace: "infra", Name: "gw"}:           {File: filepath.Join(dir, "gateway.yaml"), Document: 1, Line: 8},
+		{Kind: "Service", Namespace: defaultNamespace, Name: "web"}: {File: list, Document: 0, Line: 1},
+		{Kind: "HTTPRoute", Namespace: "app", Name: "web"}:          {File: list, Document: 0, Line: 1},
+	}
+	if !reflect.DeepEqual(origins, want) {
+		t.Errorf("origins = %+v, want %+v", origins, want)
+	}
+
+	if origin, ok := origins.Lookup(types.ResourceRef{Kind: "Gateway", Namespace: "infra", Name: "gw", Listener: "http"}); !ok || origin.Line != 8 {
+		t.Errorf("Lookup of a listener = %+v, %t, want the Gateway's origin", origin, ok)
+	}
+}
+
+func TestFetchInvalidObject(t *testing.T) {
+	source, err := NewManifestReader("stdin", strings.NewReader("apiVersion: gateway.networking.k8s.io/v1\nkind: Gateway\nmetadata:\n  namespace: infra\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "stdin (document 0, line 1)") {
+		t.Errorf("Fetch of a Gateway without a name = %v, want an error locating it", err)
+	}
+}
+
+func TestFetchSkipsUnparsableDocuments(t *testing.T) {
+	dir := manifestDir(t, map[string]string{
+		"chart/templates/route.yaml": `apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: {{ include "chart.fullname" . }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: app
+`,
+		"array.json": `[{"kind": "Service"}]`,
+		"svc.yaml":   "apiVersion: v1\nkind: Service\nmetadata:\n  name: api\n  namespace: app\n",
+	})
+
+	source, err := NewManifestDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection, origins, err := source.FetchWithOrigins(context.Background())
+	if err != nil {
+		t.Fatalf("FetchWithOrigins = %v, want the unparsable documents skipped", err)
+	}
+	var names []string
+	for _, svc := range collection.Services {
+		names = append(names, svc.Name)
+	}
+	if want := []string{"web", "api"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Services = %v, want %v", names, want)
+	}
+	if origin := origins[types.ResourceRef{Kind: "Service", Namespace: "app", Name: "web"}]; origin.Document != 1 || origin.Line != 6 {
+		t.Errorf("origin of the document after the template = %+v, want document 1 at line 6", origin)
+	}
+}
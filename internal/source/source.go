@@ -0,0 +1,29 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gwapi-graph/internal/types"
+)
+
+// Source loads the resources the graph is built from
+type Source interface {
+	// Fetch returns the current set of resources. An *IncompleteError comes with a collection
+	// that lacks the resources that could not be read.
+	Fetch(ctx context.Context) (*types.ResourceCollection, error)
+	// Live reports whether the source is a cluster that can be read from and written to directly
+	Live() bool
+}
+
+// IncompleteError reports resources that could not be read. The collection returned with it holds
+// everything else, so it can be shown, but must not be taken for the complete set of resources.
+type IncompleteError struct {
+	Failed []string // The kinds that could not be listed
+}
+
+// Error lists the kinds that could not be read
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("failed to list %s", strings.Join(e.Failed, ", "))
+}
@@ -0,0 +1,116 @@
+// Package testutil builds the Kubernetes and Gateway API objects that the tests of several
+// packages share. Each builder fills in only what the Gateway API requires; tests set the rest.
+package testutil
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// Ptr returns a pointer to a value
+func Ptr[T any](value T) *T {
+	return &value
+}
+
+// objectMeta returns the metadata of a namespaced object, with a UID derived from its name
+func objectMeta(namespace, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Namespace: namespace, Name: name, UID: k8stypes.UID(namespace + "/" + name)}
+}
+
+// GatewayClass returns a GatewayClass handled by an example controller
+func GatewayClass(name string) gatewayv1.GatewayClass {
+	return gatewayv1.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: k8stypes.UID(name)},
+		Spec:       gatewayv1.GatewayClassSpec{ControllerName: "example.com/gateway-controller"},
+	}
+}
+
+// Gateway returns a Gateway of the GatewayClass "example" with the given listeners
+func Gateway(namespace, name string, listeners ...gatewayv1.Listener) gatewayv1.Gateway {
+	return gatewayv1.Gateway{
+		ObjectMeta: objectMeta(namespace, name),
+		Spec:       gatewayv1.GatewaySpec{GatewayClassName: "example", Listeners: listeners},
+	}
+}
+
+// Listener returns a listener accepting routes from every namespace, for a hostname unless host
+// is empty
+func Listener(name string, protocol gatewayv1.ProtocolType, port gatewayv1.PortNumber, host string) gatewayv1.Listener {
+	listener := gatewayv1.Listener{
+		Name:          gatewayv1.SectionName(name),
+		Protocol:      protocol,
+		Port:          port,
+		AllowedRoutes: &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: Ptr(gatewayv1.NamespacesFromAll)}},
+	}
+	if host != "" {
+		listener.Hostname = Ptr(gatewayv1.Hostname(host))
+	}
+	return listener
+}
+
+// HTTPRoute returns an HTTPRoute attached to the given parents
+func HTTPRoute(namespace, name string, parents ...gatewayv1.ParentReference) gatewayv1.HTTPRoute {
+	return gatewayv1.HTTPRoute{
+		ObjectMeta: objectMeta(namespace, name),
+		Spec:       gatewayv1.HTTPRouteSpec{CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parents}},
+	}
+}
+
+// ParentRef returns a reference to a Gateway
+func ParentRef(namespace, name string) gatewayv1.ParentReference {
+	return gatewayv1.ParentReference{Name: gatewayv1.ObjectName(name), Namespace: Ptr(gatewayv1.Namespace(namespace))}
+}
+
+// Hostnames converts hostnames to their Gateway API type
+func Hostnames(hosts ...string) []gatewayv1.Hostname {
+	hostnames := make([]gatewayv1.Hostname, len(hosts))
+	for i, host := range hosts {
+		hostnames[i] = gatewayv1.Hostname(host)
+	}
+	return hostnames
+}
+
+// PathMatch returns a match on a path of the given type
+func PathMatch(matchType gatewayv1.PathMatchType, value string) gatewayv1.HTTPRouteMatch {
+	return gatewayv1.HTTPRouteMatch{Path: &gatewayv1.HTTPPathMatch{Type: Ptr(matchType), Value: Ptr(value)}}
+}
+
+// BackendRef returns a backendRef to a Service port, in the route's namespace when namespace is
+// empty
+func BackendRef(namespace, name string, port gatewayv1.PortNumber) gatewayv1.HTTPBackendRef {
+	ref := gatewayv1.HTTPBackendRef{BackendRef: gatewayv1.BackendRef{
+		BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name), Port: Ptr(port)},
+	}}
+	if namespace != "" {
+		ref.Namespace = Ptr(gatewayv1.Namespace(namespace))
+	}
+	return ref
+}
+
+// Service returns a Service exposing the given ports
+func Service(namespace, name string, ports ...int32) corev1.Service {
+	svc := corev1.Service{ObjectMeta: objectMeta(namespace, name)}
+	for _, port := range ports {
+		svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Port: port})
+	}
+	return svc
+}
+
+// ReferenceGrant returns a ReferenceGrant in namespace that lets objects of fromKind in
+// fromNamespace refer to every object of the core kind toKind
+func ReferenceGrant(namespace, name, fromKind, fromNamespace, toKind string) gatewayv1beta1.ReferenceGrant {
+	return gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: objectMeta(namespace, name),
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{
+				Group:     gatewayv1.GroupName,
+				Kind:      gatewayv1.Kind(fromKind),
+				Namespace: gatewayv1.Namespace(fromNamespace),
+			}},
+			To: []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: gatewayv1.Kind(toKind)}},
+		},
+	}
+}
@@ -1,20 +1,81 @@
 package types
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
-// ResourceCollection holds all Gateway API Standard channel resources for v1.2.1 plus DNSRecord and Services
+// ResourceCollection holds all Gateway API Standard channel resources for v1.2.1 plus DNSRecord, DNSEndpoint, Services and policies
 type ResourceCollection struct {
 	GatewayClasses  []gatewayv1.GatewayClass        `json:"gatewayClasses"`
 	Gateways        []gatewayv1.Gateway             `json:"gateways"`
 	HTTPRoutes      []gatewayv1.HTTPRoute           `json:"httpRoutes"`
 	ReferenceGrants []gatewayv1beta1.ReferenceGrant `json:"referenceGrants"`
 	DNSRecords      []unstructured.Unstructured     `json:"dnsRecords"`
+	DNSEndpoints    []unstructured.Unstructured     `json:"dnsEndpoints,omitempty"` // ExternalDNS externaldns.k8s.io DNSEndpoints
 	Services        []corev1.Service                `json:"services"`
+	EndpointSlices  []discoveryv1.EndpointSlice     `json:"endpointSlices,omitempty"`
+	Certificates    []CertificateBundle             `json:"certificates,omitempty"` // Certificates of the Secrets Gateways reference and the CA bundles BackendTLSPolicies reference; never private keys
+	DNSConfig       *unstructured.Unstructured      `json:"dnsConfig,omitempty"`    // OpenShift dnses.config.openshift.io/cluster, when present
+	PolicyKinds     []PolicyKind                    `json:"policyKinds,omitempty"`  // Policy CRDs discovered by their gateway.networking.k8s.io/policy label
+	Policies        []unstructured.Unstructured     `json:"policies,omitempty"`     // Objects of any kind that attach through spec.targetRef or spec.targetRefs
+}
+
+// PolicyKind is a policy CRD whose objects attach to resources through spec.targetRef or
+// spec.targetRefs
+type PolicyKind struct {
+	Group     string `json:"group"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind"`
+	Resource  string `json:"resource,omitempty"` // Plural resource name used to list the objects
+	Inherited bool   `json:"inherited"`          // Inherited policies also apply below their target; direct ones only to it
+}
+
+// PolicyTarget is a resource a policy attaches to
+type PolicyTarget struct {
+	Group       string `json:"group"`
+	Kind        string `json:"kind"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name"`
+	SectionName string `json:"sectionName,omitempty"` // Listener of a Gateway, rule of a route or port of a Service
+	NodeID      string `json:"nodeId,omitempty"`      // Graph node of the target; empty when it does not exist
+}
+
+// EffectivePolicy is a policy that applies to a route, attached to it or inherited from a resource
+// above it
+type EffectivePolicy struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Inherited bool   `json:"inherited"`
+	Via       string `json:"via"` // The resource the policy attaches to, e.g. "Gateway infra/gw listener https"
+	NodeID    string `json:"nodeId"`
+}
+
+// Certificate is the public part of an X.509 certificate
+type Certificate struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dnsNames,omitempty"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+	IsCA      bool      `json:"isCA,omitempty"`
+}
+
+// CertificateBundle holds the certificates read from one key of a Secret or ConfigMap
+type CertificateBundle struct {
+	Kind         string        `json:"kind"` // Secret or ConfigMap
+	Namespace    string        `json:"namespace"`
+	Name         string        `json:"name"`
+	Key          string        `json:"key"`                    // The data key read, e.g. tls.crt
+	Certificates []Certificate `json:"certificates,omitempty"` // In the order of the bundle, the leaf first for a chain
+	Error        string        `json:"error,omitempty"`        // Why no certificates could be read
+	Missing      bool          `json:"missing,omitempty"`      // The Secret or ConfigMap does not exist
 }
 
 // Graph represents the graph structure for D3.js
@@ -26,25 +87,69 @@ type Graph struct {
 
 // DNSZone represents a DNS zone grouping
 type DNSZone struct {
-	Name  string   `json:"name"`
-	Nodes []string `json:"nodes"` // Node IDs that belong to this zone
-	Color string   `json:"color"`
+	Name     string   `json:"name"`
+	Nodes    []string `json:"nodes"` // Node IDs that belong to this zone
+	Color    string   `json:"color"`
+	Inferred bool     `json:"inferred,omitempty"` // A hosted zone whose domain is guessed rather than read from the DNS config
+
+	Annotations []HostnameIssue `json:"annotations,omitempty"` // Hostname problems involving the zone's nodes
+}
+
+// HostnameIssue is a problem with the ownership of a hostname
+type HostnameIssue struct {
+	Type     string   `json:"type"` // collision, wildcardOverlap or noDNS
+	Hostname string   `json:"hostname"`
+	Message  string   `json:"message"`
+	Nodes    []string `json:"nodes"` // IDs of the graph nodes involved
+}
+
+// ZonePlacement explains why a node is in its DNS zones
+type ZonePlacement struct {
+	Hostname string   `json:"hostname"` // The hostname that placed the node
+	Zones    []string `json:"zones"`    // Most specific first
+	Rule     string   `json:"rule"`     // rules[<index>], hostedZone, publicSuffix, or dnsRecord when the node shares a DNSRecord's zones
+	Reason   string   `json:"reason"`
+}
+
+// ZoneStatus is the publication state of a DNSRecord in one DNS zone
+type ZoneStatus struct {
+	Zone    string `json:"zone"`
+	Status  string `json:"status"` // Published, Failed or Unknown
+	Message string `json:"message,omitempty"`
+}
+
+// DNSSource identifies where the DNS name of a DNSRecord node comes from
+type DNSSource struct {
+	Provider  string `json:"provider"` // dnsrecord, dnsendpoint, external-dns or gateway-httproute
+	Kind      string `json:"kind"`     // Kind of the object declaring the name: DNSRecord, DNSEndpoint or HTTPRoute
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
 }
 
 // Node represents a node in the graph
 type Node struct {
-	ID           string        `json:"id"`
-	Name         string        `json:"name"`
-	Type         string        `json:"type"`
-	Namespace    string        `json:"namespace"`
-	Group        string        `json:"group"`
-	Version      string        `json:"version"`
-	Kind         string        `json:"kind"`
-	ParentID     *string       `json:"parentId,omitempty"`     // For listener nodes, reference to parent Gateway
-	ListenerData *ListenerData `json:"listenerData,omitempty"` // Additional data for listener nodes
-	Hidden       bool          `json:"hidden,omitempty"`       // Whether node should be hidden by default
-	DNSZone      string        `json:"dnsZone,omitempty"`      // DNS zone this resource belongs to
-	Hostname     string        `json:"hostname,omitempty"`     // Hostname for DNSRecord and other hostname-based resources
+	ID            string             `json:"id"`
+	Name          string             `json:"name"`
+	Type          string             `json:"type"`
+	Namespace     string             `json:"namespace"`
+	Group         string             `json:"group"`
+	Version       string             `json:"version"`
+	Kind          string             `json:"kind"`
+	ParentID      *string            `json:"parentId,omitempty"`      // For listener nodes, reference to parent Gateway
+	ListenerData  *ListenerData      `json:"listenerData,omitempty"`  // Additional data for listener nodes
+	Hidden        bool               `json:"hidden,omitempty"`        // Whether node should be hidden by default
+	DNSZone       string             `json:"dnsZone,omitempty"`       // DNS zone this resource belongs to
+	ZonePlacement *ZonePlacement     `json:"zonePlacement,omitempty"` // Why the node is in its DNS zones
+	ZoneStatuses  []ZoneStatus       `json:"zoneStatuses,omitempty"`  // Per-zone publication state of a DNSRecord
+	Hostname      string             `json:"hostname,omitempty"`      // Hostname for DNSRecord and other hostname-based resources
+	Targets       []string           `json:"targets,omitempty"`       // Addresses a DNSRecord points to
+	DNSSource     *DNSSource         `json:"dnsSource,omitempty"`     // Object and provider a DNSRecord node comes from
+	Certificates  *CertificateBundle `json:"certificates,omitempty"`  // Certificates of a Secret or ConfigMap node
+	PolicyTargets []PolicyTarget     `json:"policyTargets,omitempty"` // Resources a Policy node attaches to
+	Inherited     bool               `json:"inherited,omitempty"`     // Whether a Policy node is an inherited policy
+	Policies      []EffectivePolicy  `json:"policies,omitempty"`      // Policies that apply to an HTTPRoute, most specific attachment first
+	Change        string             `json:"change,omitempty"`        // Set in diff overlays: added, removed or changed
+	Findings      []Finding          `json:"findings,omitempty"`      // Analysis findings about this resource
 }
 
 // ListenerData contains additional information for Gateway listener nodes
@@ -57,7 +162,80 @@ type ListenerData struct {
 
 // Link represents a connection between nodes
 type Link struct {
-	Source int    `json:"source"`
-	Target int    `json:"target"`
+	Source    int      `json:"source"`
+	Target    int      `json:"target"`
+	Type      string   `json:"type"`
+	Hostnames []string `json:"hostnames,omitempty"` // Hostnames a route serves through a listener, "*" for any
+	Change    string   `json:"change,omitempty"`    // Set in diff overlays: added or removed
+}
+
+// ValidationResult is the outcome of a server-side dry-run of a resource edit
+type ValidationResult struct {
+	Valid   bool          `json:"valid"`
+	Message string        `json:"message,omitempty"`
+	Errors  []FieldError  `json:"errors,omitempty"`
+	Preview *GraphPreview `json:"preview,omitempty"`
+}
+
+// FieldError is a single field-level error reported by the API server
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+// GraphPreview describes how the graph would change if an edit were applied
+type GraphPreview struct {
+	AddedLinks       []LinkChange `json:"addedLinks"`
+	RemovedLinks     []LinkChange `json:"removedLinks"`
+	DetachedRoutes   []string     `json:"detachedRoutes"`   // Routes that would no longer attach to any listener
+	DanglingBackends []string     `json:"danglingBackends"` // backendRefs that would no longer resolve to a Service port or lose their ReferenceGrant
+}
+
+// LinkChange identifies a link by the resources at either end rather than by node index
+type LinkChange struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
 	Type   string `json:"type"`
 }
+
+// Severity ranks how serious a Finding is
+type Severity string
+
+// Finding severities, from most to least serious
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Rank orders severities so that more serious ones compare higher; unknown severities rank lowest
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// Finding is a problem detected by static analysis of the resources
+type Finding struct {
+	Rule        string      `json:"rule"`
+	Severity    Severity    `json:"severity"`
+	Resource    ResourceRef `json:"resource"`
+	NodeID      string      `json:"nodeId,omitempty"` // Graph node the finding is attached to
+	Message     string      `json:"message"`
+	Remediation string      `json:"remediation,omitempty"`
+}
+
+// ResourceRef identifies the resource a Finding is about
+type ResourceRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Listener  string `json:"listener,omitempty"` // Set when the finding is about a single Gateway listener
+}
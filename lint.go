@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/analysis"
+	"gwapi-graph/internal/lint"
+	"gwapi-graph/internal/types"
+)
+
+// errLintFailed is returned by runLint when a finding is at or above the -fail-on severity
+var errLintFailed = errors.New("lint found problems")
+
+// runLint implements `gwapi-graph lint <dir>`: it runs the analysis rules over a manifest
+// directory (or stdin) without a cluster and reports the findings for CI
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := fs.String("format", lint.FormatText, "output format: "+strings.Join(lint.Formats, ", "))
+	output := fs.String("o", "", "output file (default stdout)")
+	failOn := fs.String("fail-on", string(types.SeverityError), "exit with status 1 when a finding is at or above this severity: error, warning, info or none")
+	minimum := fs.String("severity", string(types.SeverityInfo), "only report findings at or above this severity")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s lint [flags] <dir>\n\nCheck Gateway API manifests in a directory (or - for stdin) without a cluster.\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+
+	// Allow flags after the directory as well as before it
+	var dirs []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		dirs = append(dirs, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(dirs) != 1 {
+		fs.Usage()
+		return errors.New("exactly one manifest directory is required")
+	}
+
+	validFormat := false
+	for _, f := range lint.Formats {
+		validFormat = validFormat || f == *format
+	}
+	if !validFormat {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+	failSeverity := types.Severity(strings.ToLower(*failOn))
+	if failSeverity == "none" {
+		failSeverity = ""
+	} else if failSeverity.Rank() == 0 {
+		return fmt.Errorf("invalid -fail-on severity %q", *failOn)
+	}
+	minSeverity := types.Severity(strings.ToLower(*minimum))
+	if minSeverity.Rank() == 0 {
+		return fmt.Errorf("invalid -severity %q", *minimum)
+	}
+
+	src, err := manifestSource(dirs[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resources, origins, err := src.FetchWithOrigins(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load manifests: %w", err)
+	}
+
+	var findings []types.Finding
+	for _, finding := range analysis.New(analysis.DefaultRules()...).Run(resources) {
+		if finding.Severity.Rank() >= minSeverity.Rank() {
+			findings = append(findings, finding)
+		}
+	}
+	report := lint.NewReport(findings, origins, failSeverity)
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := lint.Write(w, *format, report); err != nil {
+		return err
+	}
+
+	if report.Failed() {
+		return errLintFailed
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lintManifests has a Gateway whose GatewayClass does not exist, an error finding
+const lintManifests = `apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw
+  namespace: infra
+spec:
+  gatewayClassName: missing
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+`
+
+// exitStatus returns the status main exits with for a runLint result
+func exitStatus(err error) int {
+	switch {
+	case errors.Is(err, errLintFailed):
+		return 1
+	case err != nil:
+		return 2
+	}
+	return 0
+}
+
+func TestRunLintExitStatus(t *testing.T) {
+	dir := writeManifests(t, lintManifests)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"error finding", []string{dir}, 1},
+		{"flags after the directory", []string{dir, "-fail-on", "error"}, 1},
+		{"fail-on none", []string{"-fail-on", "none", dir}, 0},
+		{"no findings", []string{writeManifests(t, "")}, 0},
+		{"sarif output", []string{"-format", "sarif", dir}, 1},
+		{"junit output", []string{"-format", "junit", dir}, 1},
+		{"unsupported format", []string{"-format", "html", dir}, 2},
+		{"invalid fail-on", []string{"-fail-on", "fatal", dir}, 2},
+		{"invalid severity", []string{"-severity", "debug", dir}, 2},
+		{"missing directory", []string{filepath.Join(dir, "missing")}, 2},
+		{"two directories", []string{dir, dir}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "report")
+			err := runLint(append([]string{"-o", output}, tt.args...))
+			if got := exitStatus(err); got != tt.want {
+				t.Errorf("runLint(%s) exits with %d (%v), want %d", strings.Join(tt.args, " "), got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunLintReport(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.txt")
+	if err := runLint([]string{"-o", output, writeManifests(t, lintManifests)}); !errors.Is(err, errLintFailed) {
+		t.Fatalf("runLint = %v, want %v", err, errLintFailed)
+	}
+
+	report, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "gateway.yaml:1 (document 0): error: Gateway infra/gw: GatewayClass \"missing\" does not exist") {
+		t.Errorf("report lacks the located GatewayClass finding:\n%s", report)
+	}
+}
@@ -1,27 +1,142 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"gwapi-graph/internal/api"
+	"gwapi-graph/internal/audit"
 	"gwapi-graph/internal/k8s"
+	"gwapi-graph/internal/snapshot"
+	"gwapi-graph/internal/source"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Initialize Kubernetes client
-	k8sClient, err := k8s.NewClient()
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		if err := runRender(os.Args[2:]); err != nil {
+			log.Fatalf("Render failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if err := runDiff(os.Args[2:]); errors.Is(err, errDifferences) {
+			os.Exit(1)
+		} else if err != nil {
+			log.Fatalf("Diff failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		if err := runLint(os.Args[2:]); errors.Is(err, errLintFailed) {
+			os.Exit(1)
+		} else if err != nil {
+			// Status 2 tells tool errors apart from findings in CI
+			log.Printf("Lint failed: %v", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	addr := flag.String("addr", ":8080", "address to listen on")
+	auditFile := flag.String("audit-file", "", "append audit entries as JSON lines to this file")
+	auditStdout := flag.Bool("audit-stdout", true, "write audit entries as JSON lines to stdout")
+	auditTrustProxy := flag.Bool("audit-trust-proxy-headers", false, "take the audit user and client address from the headers and basic auth user of an authenticating proxy; only set when all requests pass through one")
+	auditEvents := flag.Bool("audit-events", false, "record audit entries as Kubernetes Events on the modified objects")
+	snapshotDir := flag.String("snapshot-dir", "", "store snapshots of the resources in this directory, enabling the history view")
+	snapshotInterval := flag.Duration("snapshot-interval", time.Minute, "how often to check the resources for a snapshot")
+	snapshotOnChange := flag.Bool("snapshot-on-change", true, "only store a snapshot when the resources changed since the previous one")
+	snapshotMaxAge := flag.Duration("snapshot-max-age", 7*24*time.Hour, "delete snapshots older than this (0 keeps them forever)")
+	snapshotMaxCount := flag.Int("snapshot-max-count", 10000, "keep at most this many snapshots (0 for no limit)")
+	manifests := flag.String("manifests", "", "build the graph offline from a directory of YAML/JSON manifests, or - to read them from stdin")
+	graphOpts := addGraphFlags(flag.CommandLine)
+	flag.Parse()
+
+	handlerOpts := []api.Option{}
+
+	// Initialize Kubernetes client, unless running offline from manifests
+	var k8sClient *k8s.Client
+	if *manifests != "" {
+		src, err := manifestSource(*manifests)
+		if err != nil {
+			log.Fatalf("Failed to load manifests: %v", err)
+		}
+		handlerOpts = append(handlerOpts, api.WithSource(src))
+		log.Printf("Running offline from manifests in %s; editing is disabled", *manifests)
+	} else {
+		var err error
+		k8sClient, err = k8s.NewClient()
+		if err != nil {
+			log.Fatalf("Failed to create Kubernetes client: %v", err)
+		}
+	}
+
+	// Setup audit logging
+	var auditSinks []audit.Sink
+	if *auditStdout {
+		auditSinks = append(auditSinks, audit.NewStdoutSink())
+	}
+	if *auditFile != "" {
+		fileSink, err := audit.NewFileSink(*auditFile)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer fileSink.Close()
+		auditSinks = append(auditSinks, fileSink)
+	}
+	if *auditEvents && k8sClient == nil {
+		log.Printf("Ignoring -audit-events in offline mode")
+	} else if *auditEvents {
+		auditSinks = append(auditSinks, audit.NewEventSink(k8sClient))
+	}
+
+	auditLog := audit.NewLogger(auditSinks...)
+	if *auditFile != "" {
+		if err := auditLog.LoadHistory(*auditFile); err != nil {
+			log.Printf("Failed to load audit history: %v", err)
+		}
+	}
+
+	// Setup snapshot history
+	var snapshots *snapshot.Store
+	if *snapshotDir != "" {
+		var err error
+		snapshots, err = snapshot.NewStore(*snapshotDir, snapshot.Retention{MaxAge: *snapshotMaxAge, MaxCount: *snapshotMaxCount})
+		if err != nil {
+			log.Fatalf("Failed to open snapshot store: %v", err)
+		}
+		handlerOpts = append(handlerOpts, api.WithSnapshotStore(snapshots))
+	}
+
+	graphOptions, _, err := graphOpts.options()
 	if err != nil {
-		log.Fatalf("Failed to create Kubernetes client: %v", err)
+		log.Fatalf("Failed to configure the graph: %v", err)
 	}
+	handlerOpts = append(handlerOpts, graphOptions...)
 
 	// Create API handler
-	apiHandler := api.NewHandler(k8sClient)
+	handlerOpts = append(handlerOpts, api.WithAuditLogger(auditLog), api.WithTrustedProxyHeaders(*auditTrustProxy))
+	apiHandler := api.NewHandler(k8sClient, handlerOpts...)
+
+	if snapshots != nil {
+		recorder := snapshot.NewRecorder(snapshots, apiHandler.Source(), *snapshotInterval, *snapshotOnChange)
+		go recorder.Run(context.Background())
+	}
 
 	// Setup Gin router
 	r := gin.Default()
+	if !*auditTrustProxy {
+		// Without a trusted proxy, X-Forwarded-For is as forgeable as the user headers
+		if err := r.SetTrustedProxies(nil); err != nil {
+			log.Fatalf("Failed to configure trusted proxies: %v", err)
+		}
+	}
 
 	// Serve static files
 	r.Static("/static", "./web/static")
@@ -40,10 +155,35 @@ func main() {
 		api.GET("/resources", apiHandler.GetResources)
 		api.GET("/graph", apiHandler.GetGraph)
 		api.GET("/ws", apiHandler.HandleWebSocket)
+		api.POST("/resource/:type", apiHandler.CreateResource)
 		api.GET("/resource/:type/:name", apiHandler.GetResourceDetails)
 		api.PUT("/resource/:type/:name", apiHandler.UpdateResource)
+		api.DELETE("/resource/:type/:name", apiHandler.DeleteResource)
+		api.POST("/resource/:type/:name/validate", apiHandler.ValidateResource)
+		api.GET("/template/:type", apiHandler.GetResourceTemplate)
+		api.GET("/audit", apiHandler.GetAudit)
+		api.GET("/export/html", apiHandler.ExportHTML)
+		api.GET("/diff", apiHandler.GetDiff)
+		api.POST("/diff", apiHandler.GetDiff)
+		api.GET("/snapshots", apiHandler.ListSnapshots)
+		api.GET("/snapshots/:id/graph", apiHandler.GetSnapshotGraph)
+		api.GET("/snapshots/:id/resources", apiHandler.GetSnapshotResources)
+		api.GET("/diagnostics", apiHandler.GetDiagnostics)
+		api.POST("/simulate", apiHandler.Simulate)
+		api.GET("/dnszones", apiHandler.GetDNSZones)
+		api.GET("/hostnames", apiHandler.GetHostnames)
+		api.GET("/hostname/:fqdn", apiHandler.GetHostname)
+		api.GET("/report/gateway/:namespace/:name", apiHandler.GetGatewayReport)
 	}
 
-	log.Println("Starting server on :8080")
-	r.Run(":8080")
+	log.Printf("Starting server on %s", *addr)
+	r.Run(*addr)
+}
+
+// manifestSource creates an offline source reading from a manifest directory, or from stdin for "-"
+func manifestSource(path string) (*source.Manifests, error) {
+	if path == "-" {
+		return source.NewManifestReader("stdin", os.Stdin)
+	}
+	return source.NewManifestDir(path)
 }
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gwapi-graph/internal/api"
+	"gwapi-graph/internal/dnssource"
+	"gwapi-graph/internal/dnszone"
+	"gwapi-graph/internal/export"
+	"gwapi-graph/internal/k8s"
+	"gwapi-graph/internal/policy"
+	"gwapi-graph/internal/render"
+	"gwapi-graph/internal/types"
+)
+
+// formatHTML selects a self-contained HTML export of the web UI instead of a diagram format
+const formatHTML = "html"
+
+// runRender implements `gwapi-graph render`: it builds the graph from a cluster or manifests and
+// writes it to a file or stdout without starting the web server
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	manifests := fs.String("manifests", "", "read resources from a directory of YAML/JSON manifests, or - for stdin, instead of the cluster")
+	output := fs.String("o", "", "output file (default stdout)")
+	format := fs.String("format", "", "output format: "+strings.Join(append(render.Formats, formatHTML), ", ")+" (default from the output file extension, else dot)")
+	details := fs.Bool("details", false, "embed resource details in the html format")
+	webDir := fs.String("web-dir", "web", "directory holding the web UI, used by the html format")
+	namespaces := fs.String("namespace", "", "comma-separated namespaces to include")
+	kinds := fs.String("kind", "", "comma-separated node kinds to include, e.g. Gateway,HTTPRoute")
+	gateways := fs.String("gateway", "", "comma-separated gateways (namespace/name) whose attached resources to include")
+	graphOpts := addGraphFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s render [flags]\n\nRender the Gateway API graph to a file.\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *format == "" {
+		*format = render.FormatFromPath(*output)
+		if ext := strings.ToLower(filepath.Ext(*output)); ext == ".html" || ext == ".htm" {
+			*format = formatHTML
+		}
+	}
+	if *format == "" {
+		*format = render.FormatDOT
+	}
+
+	opts, _, err := graphOpts.options()
+	if err != nil {
+		return err
+	}
+
+	handler, err := newGraphHandler(*manifests, opts...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	snapshot, err := handler.Snapshot(ctx, *details)
+	if err != nil {
+		return fmt.Errorf("failed to build graph: %w", err)
+	}
+
+	filter := render.Filter{
+		Namespaces: splitList(*namespaces),
+		Kinds:      splitList(*kinds),
+		Gateways:   splitList(*gateways),
+	}
+	snapshot.Graph = filter.Apply(snapshot.Graph)
+	snapshot.Prune()
+	graph := snapshot.Graph
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == formatHTML {
+		err = export.WriteHTML(w, *webDir, "Gateway API Graph Visualizer", snapshot)
+	} else {
+		err = render.Write(w, *format, graph)
+	}
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		log.Printf("Wrote %d nodes and %d links to %s", len(graph.Nodes), len(graph.Links), *output)
+	}
+	return nil
+}
+
+// newGraphHandler creates a handler reading from manifests when a path is given, and
+// from the cluster otherwise
+func newGraphHandler(manifests string, opts ...api.Option) (*api.Handler, error) {
+	if manifests != "" {
+		src, err := manifestSource(manifests)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load manifests: %w", err)
+		}
+		return api.NewHandler(nil, append(opts, api.WithSource(src))...), nil
+	}
+
+	k8sClient, err := k8s.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return api.NewHandler(k8sClient, opts...), nil
+}
+
+// graphFlags are the flags that shape the graph built from the resources, shared by the server and
+// the subcommands so that the same resources give the same graph everywhere
+type graphFlags struct {
+	suffixList  *string
+	zoneConfig  *string
+	dnsSources  *string
+	policyKinds *string
+}
+
+// addGraphFlags defines the graph flags on a flag set
+func addGraphFlags(fs *flag.FlagSet) *graphFlags {
+	return &graphFlags{
+		suffixList:  fs.String("public-suffix-list", "", "derive DNS zones from this public_suffix_list.dat file instead of the embedded list"),
+		zoneConfig:  fs.String("dns-zones", "", "YAML file with DNS zone rules, declared zones and maxDepth"),
+		dnsSources:  fs.String("dns-sources", strings.Join(dnssource.DefaultNames, ","), "comma-separated DNS record sources: "+strings.Join(dnssource.Names(), ", ")),
+		policyKinds: fs.String("policy-kinds", "", "comma-separated policy kinds to show besides those of the CRDs labeled "+policy.Label+", as Kind.group[=direct|inherited]"),
+	}
+}
+
+// options returns the handler options the graph flags select, and the configured policy kinds
+// for sources that list resources themselves
+func (f *graphFlags) options() ([]api.Option, []types.PolicyKind, error) {
+	var opts []api.Option
+	if *f.suffixList != "" || *f.zoneConfig != "" {
+		zones, err := zoneExtractor(*f.suffixList, *f.zoneConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to configure DNS zones: %w", err)
+		}
+		opts = append(opts, api.WithZoneExtractor(zones))
+	}
+
+	providers, err := dnssource.ByName(splitList(*f.dnsSources))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure DNS sources: %w", err)
+	}
+	opts = append(opts, api.WithDNSSources(providers...))
+
+	kinds, err := policy.ParseKinds(splitList(*f.policyKinds))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure policy kinds: %w", err)
+	}
+	opts = append(opts, api.WithPolicyKinds(kinds...))
+
+	return opts, kinds, nil
+}
+
+// zoneExtractor creates a DNS zone extractor from a public suffix list file and a zone config
+// file, either of which may be empty
+func zoneExtractor(suffixListPath, configPath string) (*dnszone.Extractor, error) {
+	var suffixes dnszone.SuffixList
+	if suffixListPath != "" {
+		list, err := dnszone.LoadSuffixList(suffixListPath)
+		if err != nil {
+			return nil, err
+		}
+		suffixes = list
+	}
+
+	var config *dnszone.Config
+	if configPath != "" {
+		var err error
+		if config, err = dnszone.LoadConfig(configPath); err != nil {
+			return nil, err
+		}
+	}
+	return dnszone.New(suffixes, config), nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
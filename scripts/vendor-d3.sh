@@ -0,0 +1,38 @@
+#!/bin/sh
+# Downloads the pinned D3 release into web/static/vendor, where HTML exports inline it from, and
+# verifies it before installing it.
+#
+# Usage: [D3_SHA256=<digest>] scripts/vendor-d3.sh
+#
+# With D3_SHA256, dist/d3.min.js is checked against that SHA-256 digest, which must come from a
+# trusted source, never from the downloaded file itself. Without it, the npm package tarball is
+# checked against the sha512 integrity the npm registry publishes for the release, which cannot
+# change once published, and the file is taken from the tarball.
+set -eu
+
+D3_VERSION="${D3_VERSION:-7.9.0}"
+D3_SHA256="${D3_SHA256:-}"
+
+dir="$(dirname "$0")/../web/static/vendor"
+mkdir -p "$dir"
+tmp="$(mktemp -d)"
+trap 'rm -rf "$tmp"' EXIT
+
+if [ -n "$D3_SHA256" ]; then
+    wget -q -O "$tmp/d3.min.js" "https://cdn.jsdelivr.net/npm/d3@${D3_VERSION}/dist/d3.min.js"
+    echo "${D3_SHA256}  $tmp/d3.min.js" | sha256sum -c -
+else
+    wget -q -O "$tmp/meta.json" "https://registry.npmjs.org/d3/${D3_VERSION}"
+    integrity="$(sed -n 's/.*"integrity":"sha512-\([^"]*\)".*/\1/p' "$tmp/meta.json")"
+    if [ -z "$integrity" ]; then
+        echo "no sha512 integrity published for d3@${D3_VERSION}" >&2
+        exit 1
+    fi
+    sha512="$(echo "$integrity" | base64 -d | od -An -tx1 | tr -d ' \n')"
+    wget -q -O "$tmp/d3.tgz" "https://registry.npmjs.org/d3/-/d3-${D3_VERSION}.tgz"
+    echo "${sha512}  $tmp/d3.tgz" | sha512sum -c -
+    tar -xzf "$tmp/d3.tgz" -C "$tmp" package/dist/d3.min.js
+    mv "$tmp/package/dist/d3.min.js" "$tmp/d3.min.js"
+fi
+
+mv "$tmp/d3.min.js" "$dir/d3.v7.min.js"
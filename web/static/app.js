@@ -15,6 +15,15 @@ class GatewayGraphVisualizer {
         this.zoom = null;
         this.layout = 'force';
         this.showDNSZones = true;
+        this.templateSource = null;
+        // Set when the page is a static HTML export: the graph and resource details are embedded
+        // and there is no server to talk to
+        this.snapshot = window.GWAPI_SNAPSHOT || null;
+        this.readOnly = this.snapshot !== null;
+        // Graph history from /api/snapshots; historyIndex is null while showing the live graph
+        this.history = [];
+        this.historyIndex = null;
+        this.replayTimer = null;
         
         this.init();
         console.log('GatewayGraphVisualizer initialized');
@@ -23,10 +32,154 @@ class GatewayGraphVisualizer {
     init() {
         this.setupSVG();
         this.setupEventListeners();
-        this.setupWebSocket();
+        if (this.snapshot) {
+            this.setupSnapshotMode();
+        } else {
+            this.setupWebSocket();
+            this.setupHistoryControls();
+        }
         this.loadData();
     }
 
+    setupHistoryControls() {
+        const slider = document.getElementById('history-slider');
+        slider.addEventListener('input', () => {
+            this.stopReplay();
+            this.showHistory(parseInt(slider.value, 10));
+        });
+
+        document.getElementById('history-diff-btn').addEventListener('click', () => {
+            this.stopReplay();
+            this.showHistoryDiff();
+        });
+
+        document.getElementById('history-play-btn').addEventListener('click', () => {
+            if (this.replayTimer) {
+                this.stopReplay();
+            } else {
+                this.startReplay();
+            }
+        });
+
+        this.loadHistory();
+        // Pick up new snapshots while the page is open
+        setInterval(() => {
+            if (this.historyIndex === null) {
+                this.loadHistory();
+            }
+        }, 60000);
+    }
+
+    async loadHistory() {
+        try {
+            const response = await fetch('/api/snapshots');
+            if (!response.ok) {
+                return;
+            }
+            this.history = await response.json();
+        } catch (error) {
+            console.error('Error loading snapshots:', error);
+            return;
+        }
+
+        const controls = document.getElementById('history-controls');
+        controls.classList.toggle('available', this.history.length > 0);
+
+        // The last slider position is the live graph
+        const slider = document.getElementById('history-slider');
+        slider.max = this.history.length;
+        slider.value = this.historyIndex === null ? this.history.length : this.historyIndex;
+    }
+
+    async showHistory(index) {
+        const label = document.getElementById('history-label');
+
+        if (index >= this.history.length) {
+            this.historyIndex = null;
+            this.readOnly = false;
+            label.textContent = 'Live';
+            label.classList.remove('past');
+            await this.loadData();
+            return;
+        }
+
+        const snapshot = this.history[index];
+        this.historyIndex = index;
+        // Past states cannot be edited
+        this.readOnly = true;
+        label.textContent = new Date(snapshot.time).toLocaleString();
+        label.classList.add('past');
+
+        try {
+            const response = await fetch(`/api/snapshots/${encodeURIComponent(snapshot.id)}/graph`);
+            if (!response.ok) {
+                throw new Error(`Failed to load snapshot: ${response.status}`);
+            }
+            this.updateGraph(await response.json());
+        } catch (error) {
+            console.error('Error loading snapshot:', error);
+        }
+    }
+
+    async showHistoryDiff() {
+        if (this.historyIndex === null) {
+            return;
+        }
+
+        const snapshot = this.history[this.historyIndex];
+        try {
+            const response = await fetch(`/api/diff?from=${encodeURIComponent('snapshot:' + snapshot.id)}&to=live`);
+            if (!response.ok) {
+                throw new Error(`Failed to load diff: ${response.status}`);
+            }
+            const result = await response.json();
+            const s = result.summary;
+            document.getElementById('history-label').textContent =
+                `Changes since ${new Date(snapshot.time).toLocaleString()}: +${s.addedNodes} -${s.removedNodes} ~${s.changedNodes}`;
+            this.updateGraph(result.overlay);
+        } catch (error) {
+            console.error('Error loading diff:', error);
+        }
+    }
+
+    startReplay() {
+        const slider = document.getElementById('history-slider');
+        let index = this.historyIndex === null ? 0 : this.historyIndex;
+
+        document.getElementById('history-play-btn').textContent = 'Stop';
+        const step = () => {
+            slider.value = index;
+            this.showHistory(index);
+            if (index >= this.history.length) {
+                this.stopReplay();
+                return;
+            }
+            index++;
+        };
+        step();
+        this.replayTimer = setInterval(step, 1500);
+    }
+
+    stopReplay() {
+        if (this.replayTimer) {
+            clearInterval(this.replayTimer);
+            this.replayTimer = null;
+        }
+        document.getElementById('history-play-btn').textContent = 'Replay';
+    }
+
+    setupSnapshotMode() {
+        // Nothing to refresh from in an exported file
+        document.getElementById('refresh-btn').style.display = 'none';
+        document.getElementById('auto-refresh-btn').style.display = 'none';
+        document.getElementById('simulate-btn').style.display = 'none';
+
+        const title = document.querySelector('header h1');
+        if (title && this.snapshot.generatedAt) {
+            title.textContent += ` (snapshot ${new Date(this.snapshot.generatedAt).toLocaleString()})`;
+        }
+    }
+
     setupSVG() {
         const container = document.getElementById('graph-container');
         this.width = container.clientWidth;
@@ -96,6 +249,11 @@ class GatewayGraphVisualizer {
         document.getElementById('dns-zones-toggle-btn').addEventListener('click', () => {
             this.toggleDNSZones();
         });
+
+        // Request routing simulator
+        document.getElementById('simulate-btn').addEventListener('click', () => {
+            this.showSimulateForm();
+        });
     }
 
     setupWebSocket() {
@@ -105,6 +263,10 @@ class GatewayGraphVisualizer {
         this.websocket = new WebSocket(wsUrl);
         
         this.websocket.onmessage = (event) => {
+            // Keep showing the selected point in history
+            if (this.historyIndex !== null) {
+                return;
+            }
             const data = JSON.parse(event.data);
             this.updateGraph(data);
         };
@@ -123,6 +285,15 @@ class GatewayGraphVisualizer {
     }
 
     async loadData() {
+        if (this.snapshot) {
+            this.updateGraph(this.snapshot.graph);
+            return;
+        }
+        if (this.historyIndex !== null) {
+            await this.showHistory(this.historyIndex);
+            return;
+        }
+
         console.log('Loading data from /api/graph...');
         try {
             const response = await fetch('/api/graph');
@@ -278,6 +449,13 @@ class GatewayGraphVisualizer {
                 case 'ReferenceGrant':
                     node.hierarchyLevel = 3;
                     break;
+                case 'Policy':
+                    node.hierarchyLevel = 3.5; // Beside the resources it targets
+                    break;
+                case 'ConfigMap':
+                case 'Secret':
+                    node.hierarchyLevel = 4.5; // CA certificates of BackendTLSPolicies
+                    break;
                 default:
                     node.hierarchyLevel = 4;
             }
@@ -343,18 +521,64 @@ class GatewayGraphVisualizer {
         // Add new links
         const newLinks = links.enter()
             .append('line')
-            .attr('class', d => `link ${d.type}`)
+            .attr('class', d => this.linkClass(d))
             .style('opacity', 0)
-            .on('mouseover', (event, d) => this.showTooltip(event, `${d.type} connection`))
+            .on('mouseover', (event, d) => this.showTooltip(event, this.getLinkTooltip(d)))
             .on('mouseout', () => this.hideTooltip());
 
         // Update all links
         newLinks.merge(links)
+            .attr('class', d => this.linkClass(d))
             .transition()
             .duration(300)
             .style('opacity', 1);
     }
 
+    resourceRef(node) {
+        // The Kubernetes object behind a node; DNSRecord nodes may come from a DNSEndpoint or an HTTPRoute
+        const source = node.dnsSource;
+        if (source) {
+            return { type: source.kind, name: source.name, namespace: source.namespace || '' };
+        }
+        if (node.type === 'Policy') {
+            // Policies are served by their own kind
+            return { type: node.kind, name: node.name, namespace: node.namespace || '' };
+        }
+        return { type: node.type, name: node.name, namespace: node.namespace || '' };
+    }
+
+    zonePlacementNote(node, fallback) {
+        // Explain which zone rule placed the node, as reported by /api/dnszones
+        const placement = node.zonePlacement;
+        if (!placement) {
+            return fallback;
+        }
+        return this.escapeHtml(`${placement.reason} [${placement.rule}]`);
+    }
+
+    getLinkTooltip(d) {
+        if (d.hostnames && d.hostnames.length > 0) {
+            return `${d.type} connection: ${d.hostnames.join(', ')}`;
+        }
+        return `${d.type} connection`;
+    }
+
+    linkClass(d) {
+        // Diff overlays mark added and removed links
+        return `link ${d.type}${d.change ? ` change-${d.change}` : ''}`;
+    }
+
+    nodeClass(d) {
+        // Diff overlays mark changes; findings mark the most serious problem
+        let cls = `node ${d.type.toLowerCase()}${d.change ? ` change-${d.change}` : ''}`;
+        if (d.findings && d.findings.some(f => f.severity === 'error')) {
+            cls += ' has-error';
+        } else if (d.findings && d.findings.some(f => f.severity === 'warning')) {
+            cls += ' has-warning';
+        }
+        return cls;
+    }
+
     renderNodes(g) {
         // Bind data to nodes
         const nodeSelection = g.selectAll('.nodes')
@@ -393,7 +617,7 @@ class GatewayGraphVisualizer {
         // Add circles for new nodes
         newNodes.append('circle')
             .attr('r', d => this.getNodeRadius(d))
-            .attr('class', d => `node ${d.type.toLowerCase()}`)
+            .attr('class', d => this.nodeClass(d))
             .on('click', (event, d) => this.handleNodeClick(event, d))
             .on('mouseover', (event, d) => this.showTooltip(event, this.getNodeTooltip(d)))
             .on('mouseout', () => this.hideTooltip());
@@ -420,7 +644,7 @@ class GatewayGraphVisualizer {
         // Update existing node properties that might have changed
         allNodes.select('circle')
             .attr('r', d => this.getNodeRadius(d))
-            .attr('class', d => `node ${d.type.toLowerCase()}`);
+            .attr('class', d => this.nodeClass(d));
 
         allNodes.select('.node-label')
             .attr('dy', d => this.getNodeRadius(d) + 15)
@@ -440,6 +664,9 @@ class GatewayGraphVisualizer {
             'DNSRecord': 0.9,
             'Service': 1.1,
             'ReferenceGrant': 0.8,
+            'Policy': 0.8,
+            'ConfigMap': 0.7,
+            'Secret': 0.7,
             'Listener': 0.7
         };
         return baseRadius * (typeMultipliers[d.type] || 1.0);
@@ -449,6 +676,9 @@ class GatewayGraphVisualizer {
         if (d.type === 'Listener' && d.listenerData) {
             return `${d.type}: ${d.name} (Port ${d.listenerData.port}, ${d.listenerData.protocol}${d.listenerData.hostname ? `, ${d.listenerData.hostname}` : ''})`;
         }
+        if (d.type === 'Policy') {
+            return `${d.kind}: ${d.name}${d.namespace ? ` (${d.namespace})` : ''}`;
+        }
         return `${d.type}: ${d.name}${d.namespace ? ` (${d.namespace})` : ''}`;
     }
 
@@ -554,7 +784,7 @@ class GatewayGraphVisualizer {
             .style('cursor', 'pointer')
             .text(d => {
                 console.log(`Adding label for zone: ${d.name}`);
-                return d.name;
+                return d.annotations && d.annotations.length ? `⚠️ ${d.name}` : d.name;
             })
             .on('click', (event, d) => {
                 event.stopPropagation();
@@ -761,8 +991,10 @@ class GatewayGraphVisualizer {
         // Show basic info immediately
         this.showBasicNodeInfo(node);
         
-        // Skip detailed loading for Listener nodes (they don't have full K8s resources)
-        if (node.type === 'Listener') {
+        // Skip detailed loading for Listener nodes (they don't have full K8s resources) and for CA
+        // ConfigMaps and Secrets, whose certificates are already on the node and whose data is
+        // never served
+        if (node.type === 'Listener' || node.type === 'ConfigMap' || node.type === 'Secret') {
             return;
         }
         
@@ -835,6 +1067,24 @@ class GatewayGraphVisualizer {
             `;
         });
 
+        // Add hostname ownership problems involving the zone
+        if (zone.annotations && zone.annotations.length) {
+            const titles = { collision: 'Collision', wildcardOverlap: 'Wildcard overlap', noDNS: 'No DNS record' };
+            html += `
+                <div class="resource-section">
+                    <h5>⚠️ Hostname Issues (${zone.annotations.length})</h5>
+                    <div class="resource-section-content">
+                        ${zone.annotations.map(issue => `
+                            <div style="margin-bottom: 0.5rem; padding: 0.5rem; background: #fff3cd; border-radius: 4px;">
+                                <strong>${titles[issue.type] || this.escapeHtml(issue.type)}: ${this.escapeHtml(issue.hostname)}</strong>
+                                <div style="font-size: 0.85rem; color: #6c757d;">${this.escapeHtml(issue.message)}</div>
+                            </div>
+                        `).join('')}
+                    </div>
+                </div>
+            `;
+        }
+
         // Add zone hierarchy information
         const depth = zone.name.split('.').length;
         if (depth > 2) {
@@ -875,6 +1125,8 @@ class GatewayGraphVisualizer {
             </div>
         `;
 
+        html += this.getFindingsSection(node);
+
         // Add listener-specific information
         if (node.type === 'Listener' && node.listenerData) {
             html += `
@@ -898,6 +1150,11 @@ class GatewayGraphVisualizer {
             `;
         }
 
+        // Listeners have no detail view, so offer template controls here
+        if (node.type === 'Listener') {
+            html += this.getTemplateControls(node);
+        }
+
         // Add gateway-specific information for gateways with listeners
         if (node.type === 'Gateway') {
             const listenerNodes = this.nodes.filter(n => 
@@ -939,7 +1196,7 @@ class GatewayGraphVisualizer {
                         <div class="resource-section-content">
                             <div style="padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
                                 <strong>${node.dnsZone}</strong>
-                                <div style="font-size: 0.85rem; color: #6c757d;">This route belongs to the DNS zone shown as a colored area</div>
+                                <div style="font-size: 0.85rem; color: #6c757d;">${this.zonePlacementNote(node, 'This route belongs to the DNS zone shown as a colored area')}</div>
                             </div>
                         </div>
                     </div>
@@ -962,6 +1219,23 @@ class GatewayGraphVisualizer {
                 `;
             }
 
+            const policies = node.policies || [];
+            if (policies.length > 0) {
+                html += `
+                    <div class="resource-section">
+                        <h5>🛡️ Policies (${policies.length})</h5>
+                        <div class="resource-section-content">
+                            ${policies.map(p => `
+                                <div style="margin-bottom: 0.5rem; padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
+                                    <strong>${this.escapeHtml(p.kind)} ${this.escapeHtml(p.name)}</strong> (${this.escapeHtml(p.namespace || 'cluster-scoped')})
+                                    <div style="font-size: 0.85rem; color: #6c757d;">${p.inherited ? 'Inherited' : 'Attached'} via ${this.escapeHtml(p.via)}</div>
+                                </div>
+                            `).join('')}
+                        </div>
+                    </div>
+                `;
+            }
+
             if (relatedServices.length > 0) {
                 html += `
                     <div class="resource-section">
@@ -979,6 +1253,59 @@ class GatewayGraphVisualizer {
             }
         }
 
+        // Add policy-specific information: the resources the policy targets
+        if (node.type === 'Policy') {
+            const targets = node.policyTargets || [];
+            html += `
+                <div class="resource-section">
+                    <h5>🛡️ Targets (${targets.length})</h5>
+                    <div class="resource-section-content">
+                        <div style="font-size: 0.85rem; color: #6c757d; margin-bottom: 0.5rem;">
+                            ${node.inherited ? 'Inherited policy: also applies to the resources below its targets' : 'Direct policy: applies to its targets only'}
+                        </div>
+                        ${node.hostname ? `
+                            <div class="resource-metadata" style="margin-bottom: 0.5rem;">
+                                <span class="label">Validation hostname:</span>
+                                <span class="value">${this.escapeHtml(node.hostname)}</span>
+                            </div>
+                        ` : ''}
+                        ${targets.map(target => `
+                            <div style="margin-bottom: 0.5rem; padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
+                                <strong>${this.escapeHtml(target.kind)} ${this.escapeHtml(target.namespace ? `${target.namespace}/${target.name}` : target.name)}</strong>
+                                ${target.sectionName ? ` section ${this.escapeHtml(target.sectionName)}` : ''}
+                                ${target.nodeId ? '' : '<div style="font-size: 0.85rem; color: #e74c3c;">not found</div>'}
+                            </div>
+                        `).join('')}
+                    </div>
+                </div>
+            `;
+        }
+
+        // Add the CA certificates of ConfigMaps and Secrets
+        if (node.certificates) {
+            const bundle = node.certificates;
+            const now = new Date();
+            html += `
+                <div class="resource-section">
+                    <h5>🔐 Certificates (${bundle.key})</h5>
+                    <div class="resource-section-content">
+                        ${bundle.error ? `<div style="color: #e74c3c;">${this.escapeHtml(bundle.error)}</div>` : ''}
+                        ${(bundle.certificates || []).map(cert => {
+                            const notAfter = new Date(cert.notAfter);
+                            const expired = notAfter < now || new Date(cert.notBefore) > now;
+                            return `
+                                <div style="margin-bottom: 0.5rem; padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
+                                    <strong>${this.escapeHtml(cert.subject)}</strong>
+                                    <div style="font-size: 0.85rem; color: #6c757d;">Issuer: ${this.escapeHtml(cert.issuer)}</div>
+                                    <div style="font-size: 0.85rem; color: ${expired ? '#e74c3c' : '#6c757d'};">${expired ? `NOT VALID: valid from ${cert.notBefore.slice(0, 10)} to` : 'Expires'} ${notAfter.toISOString().slice(0, 10)}</div>
+                                </div>
+                            `;
+                        }).join('')}
+                    </div>
+                </div>
+            `;
+        }
+
         // Add DNSRecord-specific information showing traffic flow
         if (node.type === 'DNSRecord') {
             // Find related HTTPRoutes (in the same DNS zone)
@@ -992,13 +1319,70 @@ class GatewayGraphVisualizer {
                         <div class="resource-section-content">
                             <div style="padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
                                 <strong>${node.dnsZone}</strong>
-                                <div style="font-size: 0.85rem; color: #6c757d;">This DNS record belongs to the zone shown as a colored area</div>
+                                <div style="font-size: 0.85rem; color: #6c757d;">${this.zonePlacementNote(node, 'This DNS record belongs to the zone shown as a colored area')}</div>
+                            </div>
+                        </div>
+                    </div>
+                `;
+            }
+
+            // Find the load balancer Service of the record's Gateway (linked by dnsTarget)
+            const targetLink = this.links
+                .find(link => (link.type === 'dnsTarget' || link.type === 'staleDnsTarget') && this.nodes[link.source]?.id === node.id);
+            const loadBalancer = targetLink ? this.nodes[targetLink.target] : null;
+
+            if (node.dnsSource) {
+                const sources = {
+                    'dnsrecord': 'OpenShift DNSRecord',
+                    'dnsendpoint': 'ExternalDNS DNSEndpoint',
+                    'external-dns': 'ExternalDNS annotations',
+                    'gateway-httproute': 'ExternalDNS gateway-httproute source'
+                };
+                html += `
+                    <div class="resource-section">
+                        <h5>📇 Source</h5>
+                        <div class="resource-section-content">
+                            <div style="padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
+                                <strong>${sources[node.dnsSource.provider] || this.escapeHtml(node.dnsSource.provider)}</strong>
+                                <div style="font-size: 0.85rem; color: #6c757d;">${node.dnsSource.kind} ${this.escapeHtml(node.dnsSource.namespace ? `${node.dnsSource.namespace}/${node.dnsSource.name}` : node.dnsSource.name)}</div>
+                            </div>
+                        </div>
+                    </div>
+                `;
+            }
+
+            if (node.targets && node.targets.length > 0) {
+                const stale = targetLink && targetLink.type === 'staleDnsTarget';
+                html += `
+                    <div class="resource-section">
+                        <h5>🎯 Targets</h5>
+                        <div class="resource-section-content">
+                            <div style="padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
+                                <strong>${node.targets.map(target => this.escapeHtml(target)).join(', ')}</strong>
+                                ${loadBalancer ? `<div style="font-size: 0.85rem; color: ${stale ? '#dc3545' : '#6c757d'};">${stale ? 'Does not match' : 'Matches'} the load balancer of Service ${loadBalancer.namespace}/${loadBalancer.name}</div>` : ''}
                             </div>
                         </div>
                     </div>
                 `;
             }
 
+            if (node.zoneStatuses && node.zoneStatuses.length > 0) {
+                html += `
+                    <div class="resource-section">
+                        <h5>📡 Publication (${node.zoneStatuses.length})</h5>
+                        <div class="resource-section-content">
+                            ${node.zoneStatuses.map(status => `
+                                <div style="margin-bottom: 0.5rem; padding: 0.5rem; background: #f8f9fa; border-radius: 4px;">
+                                    <strong>${this.escapeHtml(status.zone)}</strong>
+                                    <span style="color: ${status.status === 'Published' ? '#28a745' : status.status === 'Failed' ? '#dc3545' : '#6c757d'};">${status.status}</span>
+                                    ${status.message ? `<div style="font-size: 0.85rem; color: #6c757d;">${this.escapeHtml(status.message)}</div>` : ''}
+                                </div>
+                            `).join('')}
+                        </div>
+                    </div>
+                `;
+            }
+
             if (relatedHTTPRoutes.length > 0) {
                 html += `
                     <div class="resource-section">
@@ -1030,9 +1414,29 @@ class GatewayGraphVisualizer {
         `;
         infoContent.innerHTML = loadingHtml;
 
+        const ref = this.resourceRef(node);
+        if (this.snapshot) {
+            const key = `${ref.type.toLowerCase()}/${ref.namespace}/${ref.name}`;
+            const resourceData = (this.snapshot.details || {})[key];
+            if (resourceData) {
+                this.showDetailedResourceInfo(node, resourceData);
+            } else {
+                this.showResourceError(node, 'resource details were not included in this export');
+            }
+            return;
+        }
+
         try {
-            const resourceType = node.type.toLowerCase();
-            const url = `/api/resource/${resourceType}/${node.name}${node.namespace ? `?namespace=${node.namespace}` : ''}`;
+            const resourceType = ref.type.toLowerCase();
+            const params = new URLSearchParams();
+            if (ref.namespace) {
+                params.set('namespace', ref.namespace);
+            }
+            if (this.historyIndex !== null) {
+                params.set('at', this.history[this.historyIndex].time);
+            }
+            const query = params.toString();
+            const url = `/api/resource/${resourceType}/${ref.name}${query ? `?${query}` : ''}`;
             
             const response = await fetch(url);
             if (!response.ok) {
@@ -1048,6 +1452,158 @@ class GatewayGraphVisualizer {
         }
     }
 
+    showSimulateForm() {
+        const infoContent = document.getElementById('info-content');
+        const last = this.lastSimulation || {};
+
+        infoContent.innerHTML = `
+            <h4>Simulate Request</h4>
+            <form id="simulate-form" class="simulate-form">
+                <label>Hostname <input name="hostname" required placeholder="app.example.com" value="${this.escapeHtml(last.hostname || '')}"></label>
+                <label>Scheme
+                    <select name="scheme">
+                        <option value="http" ${last.scheme === 'https' ? '' : 'selected'}>http</option>
+                        <option value="https" ${last.scheme === 'https' ? 'selected' : ''}>https</option>
+                    </select>
+                </label>
+                <label>Port <input name="port" type="number" min="1" max="65535" placeholder="80 or 443" value="${last.port || ''}"></label>
+                <label>Method <input name="method" placeholder="GET" value="${this.escapeHtml(last.method || '')}"></label>
+                <label>Path <input name="path" placeholder="/api/users?page=2" value="${this.escapeHtml(last.path || '')}"></label>
+                <label>Headers <textarea name="headers" rows="3" placeholder="X-Canary: true">${this.escapeHtml(last.headersText || '')}</textarea></label>
+                <div class="edit-controls">
+                    <button type="submit" class="btn-primary">Simulate</button>
+                    <button type="button" class="btn-secondary" id="simulate-clear-btn">Clear Highlight</button>
+                </div>
+            </form>
+            <div id="simulate-results"></div>
+        `;
+
+        document.getElementById('simulate-form').addEventListener('submit', (e) => {
+            e.preventDefault();
+            this.runSimulation(new FormData(e.target));
+        });
+        document.getElementById('simulate-clear-btn').addEventListener('click', () => {
+            this.highlightPath(null);
+        });
+    }
+
+    async runSimulation(form) {
+        const headersText = form.get('headers') || '';
+        const headers = {};
+        headersText.split('\n').forEach(line => {
+            const separator = line.indexOf(':');
+            if (separator > 0) {
+                headers[line.slice(0, separator).trim()] = line.slice(separator + 1).trim();
+            }
+        });
+
+        const request = {
+            hostname: form.get('hostname'),
+            scheme: form.get('scheme'),
+            port: form.get('port') ? parseInt(form.get('port'), 10) : 0,
+            method: form.get('method'),
+            path: form.get('path'),
+            headers
+        };
+        this.lastSimulation = { ...request, headersText };
+
+        const results = document.getElementById('simulate-results');
+        try {
+            const params = new URLSearchParams();
+            if (this.historyIndex !== null) {
+                params.set('at', this.history[this.historyIndex].time);
+            }
+            const query = params.toString();
+            const response = await fetch(`/api/simulate${query ? `?${query}` : ''}`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(request)
+            });
+            const simulation = await response.json();
+            if (!response.ok) {
+                throw new Error(simulation.error || `Simulation failed: ${response.status}`);
+            }
+
+            results.innerHTML = this.formatSimulation(simulation);
+            this.highlightPath(simulation.results.flatMap(result => result.path));
+        } catch (error) {
+            results.innerHTML = `<div class="finding finding-error">${this.escapeHtml(error.message)}</div>`;
+            this.highlightPath(null);
+        }
+    }
+
+    formatSimulation(simulation) {
+        const req = simulation.request;
+        if (simulation.results.length === 0) {
+            return `<div class="finding finding-error">No Gateway listens on port ${req.port}.</div>`;
+        }
+
+        return simulation.results.map(result => `
+            <div class="resource-section">
+                <h5>Gateway ${this.escapeHtml(result.gateway.namespace)}/${this.escapeHtml(result.gateway.name)}${result.listener ? ` → ${this.escapeHtml(result.listener)}` : ''}</h5>
+                <div class="resource-section-content">
+                    ${result.route ? `
+                        <div><strong>Route:</strong> ${this.escapeHtml(result.route.namespace)}/${this.escapeHtml(result.route.name)}, rule ${result.rule}</div>
+                    ` : ''}
+                    ${result.reason ? `<div class="finding finding-warning">${this.escapeHtml(result.reason)}</div>` : ''}
+                    ${(result.filters || []).length > 0 ? `
+                        <div><strong>Filters:</strong></div>
+                        <ul>${result.filters.map(f => `<li>${this.escapeHtml(f.type)}: ${this.escapeHtml(f.description)}</li>`).join('')}</ul>
+                    ` : ''}
+                    ${result.forwarded ? `
+                        <div><strong>Forwarded as:</strong> ${this.escapeHtml(result.forwarded.hostname)}${this.escapeHtml(result.forwarded.path)}</div>
+                    ` : ''}
+                    ${(result.backends || []).length > 0 ? `
+                        <div><strong>Backends:</strong></div>
+                        <ul>${result.backends.map(b => `
+                            <li>${this.escapeHtml(b.kind)} ${this.escapeHtml(b.namespace)}/${this.escapeHtml(b.name)}${b.port ? `:${b.port}` : ''}
+                                (weight ${b.weight}, ${b.percent.toFixed(1)}%)${b.problem ? ` <em>${this.escapeHtml(b.problem)}</em>` : ''}</li>
+                        `).join('')}</ul>
+                    ` : ''}
+                    <details>
+                        <summary>Trace</summary>
+                        <ol>${result.trace.map(step => `<li>${this.escapeHtml(step)}</li>`).join('')}</ol>
+                    </details>
+                </div>
+            </div>
+        `).join('');
+    }
+
+    highlightPath(nodeIds) {
+        // Dim everything off the simulated path; null clears the highlight
+        const onPath = nodeIds ? new Set(nodeIds) : null;
+        const idOf = end => (typeof end === 'object' ? end.id : this.nodes[end]?.id);
+
+        this.svg.selectAll('.node')
+            .classed('on-path', d => !!onPath && onPath.has(d.id))
+            .classed('off-path', d => !!onPath && !onPath.has(d.id));
+        this.svg.selectAll('.link')
+            .classed('on-path', d => !!onPath && onPath.has(idOf(d.source)) && onPath.has(idOf(d.target)))
+            .classed('off-path', d => !!onPath && !(onPath.has(idOf(d.source)) && onPath.has(idOf(d.target))));
+    }
+
+    getFindingsSection(node) {
+        // Findings of the static analysis rules, most serious first
+        if (!node.findings || node.findings.length === 0) {
+            return '';
+        }
+
+        return `
+            <div class="resource-section">
+                <h5>Diagnostics (${node.findings.length})</h5>
+                <div class="resource-section-content">
+                    ${node.findings.map(finding => `
+                        <div class="finding finding-${finding.severity}">
+                            <div><span class="finding-severity">${finding.severity}</span> <code>${finding.rule}</code></div>
+                            <div>${this.escapeHtml(finding.message)}</div>
+                            ${finding.remediation ? `<div class="finding-remediation">${this.escapeHtml(finding.remediation)}</div>` : ''}
+                        </div>
+                    `).join('')}
+                </div>
+            </div>
+        `;
+    }
+
     showDetailedResourceInfo(node, resourceData) {
         const infoContent = document.getElementById('info-content');
         
@@ -1067,6 +1623,8 @@ class GatewayGraphVisualizer {
             </div>
         `;
 
+        html += this.getFindingsSection(node);
+
         // Add metadata section
         if (resourceData.metadata) {
             html += `
@@ -1136,17 +1694,24 @@ class GatewayGraphVisualizer {
             `;
         }
 
-        // Add edit controls
-        html += `
-            <div class="edit-controls">
-                <button class="btn-primary" onclick="window.gatewayGraph.startEditing('${node.type}', '${node.name}', '${node.namespace || ''}')">
-                    Edit Resource
-                </button>
-                <button class="btn-secondary" onclick="window.gatewayGraph.viewFullYaml('${node.type}', '${node.name}', '${node.namespace || ''}')">
-                    View Full YAML
-                </button>
-            </div>
-        `;
+        // Add edit controls, unless this is a read-only snapshot
+        if (!this.readOnly) {
+            const ref = this.resourceRef(node);
+            html += `
+                <div class="edit-controls">
+                    <button class="btn-primary" onclick="window.gatewayGraph.startEditing('${ref.type}', '${ref.name}', '${ref.namespace}')">
+                        Edit Resource
+                    </button>
+                    <button class="btn-secondary" onclick="window.gatewayGraph.viewFullYaml('${ref.type}', '${ref.name}', '${ref.namespace}')">
+                        View Full YAML
+                    </button>
+                    <button class="btn-danger" onclick="window.gatewayGraph.deleteResource('${ref.type}', '${ref.name}', '${ref.namespace}')">
+                        Delete
+                    </button>
+                </div>
+                ${this.getTemplateControls(node)}
+            `;
+        }
 
         infoContent.innerHTML = html;
     }
@@ -1220,14 +1785,18 @@ class GatewayGraphVisualizer {
 
         try {
             const url = `/api/resource/${resourceType.toLowerCase()}/${resourceName}${namespace ? `?namespace=${namespace}` : ''}`;
-            const response = await fetch(url);
+            const response = await fetch(url, {
+                headers: {
+                    'Accept': 'application/yaml',
+                },
+            });
             
             if (!response.ok) {
                 throw new Error(`Failed to load resource: ${response.status}`);
             }
             
-            const resourceData = await response.json();
-            this.showEditingInterface(resourceType, resourceName, namespace, resourceData);
+            const yamlContent = await response.text();
+            this.showEditingInterface(resourceType, resourceName, namespace, yamlContent);
             
         } catch (error) {
             console.error('Error loading resource for editing:', error);
@@ -1239,13 +1808,9 @@ class GatewayGraphVisualizer {
         }
     }
 
-    showEditingInterface(resourceType, resourceName, namespace, resourceData) {
+    showEditingInterface(resourceType, resourceName, namespace, yamlContent) {
         const infoContent = document.getElementById('info-content');
         
-        // Create a clean, editable version (like 'oc edit' does)
-        const editableResource = this.createEditableResource(resourceData);
-        const yamlContent = this.resourceToYaml(editableResource);
-        
         const html = `
             <h4>Edit ${resourceType}: ${resourceName}</h4>
             <div class="resource-section">
@@ -1257,7 +1822,7 @@ class GatewayGraphVisualizer {
                             <li>Resource name (metadata.name)</li>
                             <li>Namespace (metadata.namespace)</li>
                         </ul>
-                        <strong>What you can change:</strong> spec, labels, annotations
+                        <strong>What you can change:</strong> spec, labels, annotations (comments are kept)
                         ${namespace && (namespace.includes('openshift') || namespace.includes('system')) ? `
                             <br/><br/>
                             <strong style="color: #d63384;">⚠️ Warning:</strong> This resource is in a system namespace (${namespace}). 
@@ -1269,7 +1834,7 @@ class GatewayGraphVisualizer {
             <div class="resource-section">
                 <h5>Resource YAML (Editable)</h5>
                 <div class="resource-section-content">
-                    <textarea class="yaml-editor" id="yaml-editor">${yamlContent}</textarea>
+                    <textarea class="yaml-editor" id="yaml-editor">${this.escapeHtml(yamlContent)}</textarea>
                 </div>
             </div>
             <div class="edit-controls">
@@ -1277,6 +1842,10 @@ class GatewayGraphVisualizer {
                     <span id="save-spinner" style="display: none;" class="loading-spinner"></span>
                     Save Changes
                 </button>
+                <button class="btn-primary" onclick="window.gatewayGraph.validateResource('${resourceType}', '${resourceName}', '${namespace}')">
+                    <span id="validate-spinner" style="display: none;" class="loading-spinner"></span>
+                    Validate (Dry Run)
+                </button>
                 <button class="btn-secondary" onclick="window.gatewayGraph.cancelEditing('${resourceType}', '${resourceName}', '${namespace}')">
                     Cancel
                 </button>
@@ -1290,7 +1859,7 @@ class GatewayGraphVisualizer {
         infoContent.innerHTML = html;
     }
 
-    async saveResource(resourceType, resourceName, namespace) {
+    async saveResource(resourceType, resourceName, namespace, force = false) {
         const yamlEditor = document.getElementById('yaml-editor');
         const saveSpinner = document.getElementById('save-spinner');
         const messagesDiv = document.getElementById('edit-messages');
@@ -1300,18 +1869,38 @@ class GatewayGraphVisualizer {
         messagesDiv.innerHTML = '';
         
         try {
-            // Parse YAML back to JSON
-            const resourceData = this.yamlToResource(yamlEditor.value);
-            
-            const url = `/api/resource/${resourceType.toLowerCase()}/${resourceName}${namespace ? `?namespace=${namespace}` : ''}`;
+            const params = new URLSearchParams();
+            if (namespace) {
+                params.set('namespace', namespace);
+            }
+            if (force) {
+                params.set('force', 'true');
+            }
+            const query = params.toString();
+            const url = `/api/resource/${resourceType.toLowerCase()}/${resourceName}${query ? `?${query}` : ''}`;
             const response = await fetch(url, {
                 method: 'PUT',
                 headers: {
-                    'Content-Type': 'application/json',
+                    'Content-Type': 'application/yaml',
                 },
-                body: JSON.stringify(resourceData)
+                body: yamlEditor.value
             });
             
+            if (response.status === 409) {
+                // Other field managers own some of the fields; only take them over when asked to
+                const errorData = await response.json();
+                messagesDiv.innerHTML = `
+                    <div class="error-message">
+                        Conflict: ${this.escapeHtml(errorData.error || 'fields are owned by another manager')}
+                        <div style="margin-top: 0.5rem;">
+                            <button class="btn-secondary" onclick="window.gatewayGraph.saveResource('${resourceType}', '${resourceName}', '${namespace}', true)">
+                                Save anyway and take ownership of these fields
+                            </button>
+                        </div>
+                    </div>
+                `;
+                return;
+            }
             if (!response.ok) {
                 const errorData = await response.json();
                 throw new Error(errorData.error || `Failed to update resource: ${response.status}`);
@@ -1335,51 +1924,222 @@ class GatewayGraphVisualizer {
         }
     }
 
-    cancelEditing(resourceType, resourceName, namespace) {
-        // Find the node and reload its details
-        const node = this.nodes.find(n => 
-            n.type === resourceType && 
-            n.name === resourceName && 
-            (n.namespace || '') === (namespace || '')
-        );
-        
-        if (node) {
-            this.updateInfoPanel(node);
+    async validateResource(resourceType, resourceName, namespace) {
+        const yamlEditor = document.getElementById('yaml-editor');
+        const validateSpinner = document.getElementById('validate-spinner');
+        const messagesDiv = document.getElementById('edit-messages');
+
+        validateSpinner.style.display = 'inline-block';
+        messagesDiv.innerHTML = '';
+
+        try {
+            const url = `/api/resource/${resourceType.toLowerCase()}/${resourceName}/validate${namespace ? `?namespace=${namespace}` : ''}`;
+            const response = await fetch(url, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/yaml',
+                },
+                body: yamlEditor.value
+            });
+
+            const result = await response.json();
+            if (!response.ok) {
+                throw new Error(result.error || `Failed to validate resource: ${response.status}`);
+            }
+
+            messagesDiv.innerHTML = this.formatValidationResult(result);
+        } catch (error) {
+            console.error('Error validating resource:', error);
+            messagesDiv.innerHTML = `<div class="error-message">Failed to validate resource: ${error.message}</div>`;
+        } finally {
+            validateSpinner.style.display = 'none';
         }
     }
 
-    createEditableResource(resource) {
-        // Create a clean resource for editing, similar to what 'oc edit' shows
-        // Remove read-only fields and system-generated metadata
-        
-        const editable = {
-            apiVersion: resource.apiVersion || 'v1',
-            kind: resource.kind || 'Unknown',
-            metadata: {
-                name: resource.metadata?.name || 'unknown'
+    formatValidationResult(result) {
+        if (!result.valid) {
+            return `
+                <div class="error-message">
+                    <strong>Rejected by the API server:</strong> ${result.message}
+                    <ul style="margin: 0.5rem 0 0 1.5rem;">
+                        ${(result.errors || []).map(e => `<li>${e.field ? `<code>${e.field}</code>: ` : ''}${e.message}</li>`).join('')}
+                    </ul>
+                </div>
+            `;
+        }
+
+        const preview = result.preview || {};
+        const section = (title, items, format) => items && items.length > 0 ? `
+            <div style="margin-top: 0.5rem;"><strong>${title} (${items.length}):</strong>
+                <ul style="margin: 0.25rem 0 0 1.5rem;">${items.map(format).join('')}</ul>
+            </div>
+        ` : '';
+        const formatLink = l => `<li>${l.source} → ${l.target} <em>(${l.type})</em></li>`;
+
+        const details = [
+            section('Links added', preview.addedLinks, formatLink),
+            section('Links removed', preview.removedLinks, formatLink),
+            section('Routes losing attachment', preview.detachedRoutes, r => `<li>${r}</li>`),
+            section('Backends becoming dangling', preview.danglingBackends, b => `<li>${b}</li>`)
+        ].join('');
+
+        return `
+            <div class="success-message">
+                ${result.message}
+                ${details || '<div style="margin-top: 0.5rem;">No changes to the graph.</div>'}
+            </div>
+        `;
+    }
+
+    async deleteResource(resourceType, resourceName, namespace) {
+        const label = `${resourceType} ${namespace ? `${namespace}/` : ''}${resourceName}`;
+        if (!confirm(`Delete ${label}? This cannot be undone.`)) {
+            return;
+        }
+
+        try {
+            const url = `/api/resource/${resourceType.toLowerCase()}/${resourceName}${namespace ? `?namespace=${namespace}` : ''}`;
+            const response = await fetch(url, { method: 'DELETE' });
+            const result = await response.json();
+            if (!response.ok) {
+                throw new Error(result.error || `Failed to delete resource: ${response.status}`);
             }
-        };
 
-        // Add namespace if present (skip for cluster-scoped resources)
-        if (resource.metadata?.namespace) {
-            editable.metadata.namespace = resource.metadata.namespace;
+            document.getElementById('info-content').innerHTML = `<div class="success-message">${label} deleted.</div>`;
+            this.loadData();
+        } catch (error) {
+            console.error('Error deleting resource:', error);
+            alert(`Failed to delete ${label}: ${error.message}`);
         }
+    }
 
-        // Add editable metadata fields
-        if (resource.metadata?.labels && Object.keys(resource.metadata.labels).length > 0) {
-            editable.metadata.labels = { ...resource.metadata.labels };
+    getTemplateControls(node) {
+        if (this.readOnly) {
+            return '';
         }
 
-        if (resource.metadata?.annotations && Object.keys(resource.metadata.annotations).length > 0) {
-            editable.metadata.annotations = { ...resource.metadata.annotations };
+        const source = this.templateSource;
+        let buttons = '';
+
+        if (['Gateway', 'Listener', 'HTTPRoute'].includes(node.type)) {
+            buttons += `
+                <button class="btn-secondary" onclick="window.gatewayGraph.setTemplateSource('${node.id}')">
+                    ${source && source.id === node.id ? 'Selected as Template Source' : 'Use as Template Source'}
+                </button>
+            `;
         }
 
-        // Add spec if present
-        if (resource.spec && Object.keys(resource.spec).length > 0) {
-            editable.spec = JSON.parse(JSON.stringify(resource.spec)); // Deep copy
+        if (node.type === 'Service' && source) {
+            if (source.type === 'Gateway' || source.type === 'Listener') {
+                buttons += `
+                    <button class="btn-primary" onclick="window.gatewayGraph.createFromTemplate('httproute', '${source.id}', '${node.id}')">
+                        New HTTPRoute from ${source.name}
+                    </button>
+                `;
+            }
+            if (source.type === 'HTTPRoute' && source.namespace !== node.namespace) {
+                buttons += `
+                    <button class="btn-primary" onclick="window.gatewayGraph.createFromTemplate('referencegrant', '${source.id}', '${node.id}')">
+                        New ReferenceGrant for ${source.name}
+                    </button>
+                `;
+            }
         }
 
-        return editable;
+        return buttons ? `<div class="edit-controls">${buttons}</div>` : '';
+    }
+
+    setTemplateSource(nodeId) {
+        this.templateSource = this.nodes.find(n => n.id === nodeId) || null;
+        if (this.selectedNode) {
+            this.updateInfoPanel(this.selectedNode);
+        }
+    }
+
+    async createFromTemplate(resourceType, sourceId, targetId) {
+        const infoContent = document.getElementById('info-content');
+
+        try {
+            const response = await fetch(`/api/template/${resourceType}?source=${encodeURIComponent(sourceId)}&target=${encodeURIComponent(targetId)}`);
+            const template = await response.json();
+            if (!response.ok) {
+                throw new Error(template.error || `Failed to load template: ${response.status}`);
+            }
+
+            // What the resource still needs is sent as Warning: 299 - "<message>" headers
+            const warnings = [...(response.headers.get('Warning') || '').matchAll(/299 - "((?:[^"\\]|\\.)*)"/g)]
+                .map(match => match[1].replace(/\\(.)/g, '$1'));
+
+            infoContent.innerHTML = `
+                <h4>New ${template.kind}</h4>
+                ${warnings.map(warning => `<div class="warning-message">${this.escapeHtml(warning)}</div>`).join('')}
+                <div class="resource-section">
+                    <h5>Resource YAML (Editable)</h5>
+                    <div class="resource-section-content">
+                        <textarea class="yaml-editor" id="yaml-editor">${this.resourceToYaml(template)}</textarea>
+                    </div>
+                </div>
+                <div class="edit-controls">
+                    <button class="btn-success" onclick="window.gatewayGraph.createResource('${resourceType}', false)">
+                        Create
+                    </button>
+                    <button class="btn-primary" onclick="window.gatewayGraph.createResource('${resourceType}', true)">
+                        Validate (Dry Run)
+                    </button>
+                    <button class="btn-secondary" onclick="window.gatewayGraph.clearSelection()">
+                        Cancel
+                    </button>
+                </div>
+                <div id="edit-messages"></div>
+            `;
+        } catch (error) {
+            console.error('Error loading template:', error);
+            infoContent.innerHTML = `<div class="error-message">Failed to load template: ${error.message}</div>`;
+        }
+    }
+
+    async createResource(resourceType, dryRun) {
+        const yamlEditor = document.getElementById('yaml-editor');
+        const messagesDiv = document.getElementById('edit-messages');
+        messagesDiv.innerHTML = '';
+
+        try {
+            const resourceData = this.yamlToResource(yamlEditor.value);
+            const response = await fetch(`/api/resource/${resourceType}${dryRun ? '?dryRun=true' : ''}`, {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json',
+                },
+                body: JSON.stringify(resourceData)
+            });
+
+            const result = await response.json();
+            if (!response.ok) {
+                throw new Error(result.error || `Failed to create resource: ${response.status}`);
+            }
+
+            messagesDiv.innerHTML = `<div class="success-message">${result.message}</div>`;
+            if (!dryRun) {
+                this.templateSource = null;
+                this.loadData();
+            }
+        } catch (error) {
+            console.error('Error creating resource:', error);
+            messagesDiv.innerHTML = `<div class="error-message">Failed to create resource: ${error.message}</div>`;
+        }
+    }
+
+    cancelEditing(resourceType, resourceName, namespace) {
+        // Find the node and reload its details
+        const node = this.nodes.find(n => 
+            n.type === resourceType && 
+            n.name === resourceName && 
+            (n.namespace || '') === (namespace || '')
+        );
+        
+        if (node) {
+            this.updateInfoPanel(node);
+        }
     }
 
     async viewFullYaml(resourceType, resourceName, namespace) {
@@ -1393,15 +2153,18 @@ class GatewayGraphVisualizer {
         `;
 
         try {
-            const url = `/api/resource/${resourceType.toLowerCase()}/${resourceName}${namespace ? `?namespace=${namespace}` : ''}`;
-            const response = await fetch(url);
+            const url = `/api/resource/${resourceType.toLowerCase()}/${resourceName}?full=true${namespace ? `&namespace=${namespace}` : ''}`;
+            const response = await fetch(url, {
+                headers: {
+                    'Accept': 'application/yaml',
+                },
+            });
             
             if (!response.ok) {
                 throw new Error(`Failed to load resource: ${response.status}`);
             }
             
-            const resourceData = await response.json();
-            const yamlContent = this.resourceToYaml(resourceData);
+            const yamlContent = this.escapeHtml(await response.text());
             
             const html = `
                 <h4>${resourceType}: ${resourceName} (Full YAML)</h4>
@@ -1447,6 +2210,12 @@ class GatewayGraphVisualizer {
         }
     }
 
+    escapeHtml(text) {
+        const div = document.createElement('div');
+        div.textContent = text;
+        return div.innerHTML;
+    }
+
     showTooltip(event, text) {
         const tooltip = document.getElementById('tooltip');
         tooltip.style.left = event.pageX + 10 + 'px';